@@ -24,6 +24,8 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/common/signals"
 	"github.com/DataDog/datadog-agent/cmd/agent/gui"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	settingshttp "github.com/DataDog/datadog-agent/pkg/config/settings/http"
 	"github.com/DataDog/datadog-agent/pkg/flare"
@@ -62,6 +64,7 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/{component}/configs", componentConfigHandler).Methods("GET")
 	r.HandleFunc("/gui/csrf-token", getCSRFToken).Methods("GET")
 	r.HandleFunc("/config-check", getConfigCheck).Methods("GET")
+	r.HandleFunc("/check-history/{name}", getCheckHistory).Methods("GET")
 	r.HandleFunc("/config", settingshttp.Server.GetFull("")).Methods("GET")
 	r.HandleFunc("/config/list-runtime", settingshttp.Server.ListConfigurable).Methods("GET")
 	r.HandleFunc("/config/{setting}", settingshttp.Server.GetValue).Methods("GET")
@@ -69,6 +72,7 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/tagger-list", getTaggerList).Methods("GET")
 	r.HandleFunc("/workload-list/short", getShortWorkloadList).Methods("GET")
 	r.HandleFunc("/workload-list/verbose", getVerboseWorkloadList).Methods("GET")
+	r.HandleFunc("/workload-list/entities", getWorkloadEntities).Methods("GET")
 	r.HandleFunc("/secrets", secretInfo).Methods("GET")
 
 	return r
@@ -359,6 +363,7 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	response.ResolveWarnings = autodiscovery.GetResolveWarnings()
 	response.ConfigErrors = autodiscovery.GetConfigErrors()
 	response.Unresolved = common.AC.GetUnresolvedTemplates()
+	response.ValidationErrors = schema.ValidateConfigs(configSlice)
 
 	jsonConfig, err := json.Marshal(response)
 	if err != nil {
@@ -371,6 +376,27 @@ func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonConfig)
 }
 
+func getCheckHistory(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	response := response.CheckHistoryResponse{Instances: expvars.CheckHistory(name)}
+
+	if len(response.Instances) == 0 {
+		body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("no run history for check %s", name)})
+		http.Error(w, string(body), 404)
+		return
+	}
+
+	jsonHistory, err := json.Marshal(response)
+	if err != nil {
+		log.Errorf("Unable to marshal check history response: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Write(jsonHistory)
+}
+
 func getTaggerList(w http.ResponseWriter, r *http.Request) {
 	// query at the highest cardinality between checks and dogstatsd cardinalities
 	cardinality := collectors.TagCardinality(max(int(tagger.ChecksCardinality), int(tagger.DogstatsdCardinality)))
@@ -394,6 +420,19 @@ func getShortWorkloadList(w http.ResponseWriter, r *http.Request) {
 	workloadList(w, false)
 }
 
+func getWorkloadEntities(w http.ResponseWriter, r *http.Request) {
+	response := workloadmeta.GetGlobalStore().DumpEntities()
+	jsonDump, err := json.Marshal(response)
+	if err != nil {
+		log.Errorf("Unable to marshal workload entities response: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Write(jsonDump)
+}
+
 func workloadList(w http.ResponseWriter, verbose bool) {
 	response := workloadmeta.GetGlobalStore().Dump(verbose)
 	jsonDump, err := json.Marshal(response)
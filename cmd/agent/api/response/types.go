@@ -7,14 +7,17 @@ package response
 
 import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
 )
 
 // ConfigCheckResponse holds the config check response
 type ConfigCheckResponse struct {
-	Configs         []integration.Config            `json:"configs"`
-	ResolveWarnings map[string][]string             `json:"resolve_warnings"`
-	ConfigErrors    map[string]string               `json:"config_errors"`
-	Unresolved      map[string][]integration.Config `json:"unresolved"`
+	Configs          []integration.Config            `json:"configs"`
+	ResolveWarnings  map[string][]string             `json:"resolve_warnings"`
+	ConfigErrors     map[string]string               `json:"config_errors"`
+	Unresolved       map[string][]integration.Config `json:"unresolved"`
+	ValidationErrors map[string][]schema.Error       `json:"validation_errors"`
 }
 
 // TaggerListResponse holds the tagger list response
@@ -25,4 +28,11 @@ type TaggerListResponse struct {
 // TaggerListEntity holds the tagging info about an entity
 type TaggerListEntity struct {
 	Tags map[string][]string `json:"tags"`
+	// Rules maps the tags of each source produced by tag rules to the name of their rule
+	Rules map[string]map[string]string `json:"rules,omitempty"`
+}
+
+// CheckHistoryResponse holds the run history of the instances of a check
+type CheckHistoryResponse struct {
+	Instances map[check.ID][]check.RunResult `json:"instances"`
 }
@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/simulation"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/flare"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	simulateJSON      bool
+	simulateConfdPath string
+	simulateNoFiles   bool
+)
+
+func init() {
+	AgentCmd.AddCommand(autodiscoveryCmd)
+	autodiscoveryCmd.AddCommand(autodiscoverySimulateCmd)
+
+	autodiscoverySimulateCmd.Flags().BoolVarP(&simulateJSON, "json", "", false, "print out raw json")
+	autodiscoverySimulateCmd.Flags().StringVarP(&simulateConfdPath, "confd-path", "", "", "directory to load the auto_conf templates from, defaults to the confd_path setting")
+	autodiscoverySimulateCmd.Flags().BoolVarP(&simulateNoFiles, "no-file-templates", "", false, "don't load the auto_conf templates from files")
+}
+
+var autodiscoveryCmd = &cobra.Command{
+	Use:   "autodiscovery [command]",
+	Short: "Autodiscovery troubleshooting commands",
+	Long:  ``,
+}
+
+var autodiscoverySimulateCmd = &cobra.Command{
+	Use:   "simulate <file>...",
+	Short: "Print the configs autodiscovery would schedule for a set of workloads, without running them",
+	Long: `Run workloads through the autodiscovery listeners, config providers and template
+resolution offline, and print the check and logs configs that would be scheduled.
+
+The files can hold Kubernetes manifests (pods and the pod templates of deployments,
+statefulsets, daemonsets, replicasets, jobs and cronjobs), docker-compose services or
+a dump of the workload store of a running agent, as printed by
+'agent workload-list --entities'.
+
+Pods and containers without an IP get one in the 192.0.2.0/24 documentation range.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		err := common.SetupConfigWithoutSecrets(confFilePath, "")
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		err = config.SetupLogger(loggerName, config.GetEnvDefault("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+		if err != nil {
+			fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+			return err
+		}
+
+		dump, err := simulation.LoadFiles(args)
+		if err != nil {
+			return err
+		}
+
+		var fileTemplates []integration.Config
+		if !simulateNoFiles {
+			confdPath := simulateConfdPath
+			if confdPath == "" {
+				confdPath = config.Datadog.GetString("confd_path")
+			}
+			fileTemplates, err = loadFileTemplates([]string{confdPath, filepath.Join(common.GetDistPath(), "conf.d")})
+			if err != nil {
+				return err
+			}
+		}
+
+		result, err := simulation.Run(dump, fileTemplates)
+		if err != nil {
+			return err
+		}
+
+		if simulateJSON {
+			r, err := json.Marshal(result)
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(r))
+			return nil
+		}
+
+		printSimulation(color.Output, result)
+		return nil
+	},
+}
+
+// loadFileTemplates returns the templates of the config files found in paths
+func loadFileTemplates(paths []string) ([]integration.Config, error) {
+	configs, err := providers.NewFileConfigProvider(paths).Collect(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make([]integration.Config, 0, len(configs))
+	for _, config := range configs {
+		if config.IsTemplate() {
+			config.Provider = names.File
+			templates = append(templates, config)
+		}
+	}
+	return templates, nil
+}
+
+// printSimulation prints the configs resolved for each service of a simulation
+func printSimulation(w io.Writer, result simulation.Result) {
+	if len(result.TemplateErrors) > 0 {
+		fmt.Fprintf(w, "=== Template %s ===\n", color.RedString("errors"))
+		entities := make([]string, 0, len(result.TemplateErrors))
+		for entity := range result.TemplateErrors {
+			entities = append(entities, entity)
+		}
+		sort.Strings(entities)
+		for _, entity := range entities {
+			fmt.Fprintf(w, "\n%s:\n", color.RedString(entity))
+			for _, err := range result.TemplateErrors[entity] {
+				fmt.Fprintf(w, "* %s\n", err)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	for _, svc := range result.Services {
+		fmt.Fprintf(w, "\n##### Service %s #####\n", color.GreenString(svc.Entity))
+		if len(svc.Configs) == 0 && len(svc.ResolveWarnings) == 0 {
+			fmt.Fprintln(w, "No config would be scheduled")
+		}
+		for _, c := range svc.Configs {
+			if c.Name == "" {
+				// logs config of a pod annotation or container label without check
+				fmt.Fprintf(w, "\n=== %s ===\n", color.GreenString("logs"))
+				fmt.Fprintf(w, "%s: %s\n", color.BlueString("Configuration provider"), color.CyanString(c.Provider))
+				fmt.Fprintf(w, "%s:\n%s\n===\n", color.BlueString("Log Config"), string(c.LogsConfig))
+				continue
+			}
+			flare.PrintConfig(w, c, "")
+		}
+		for _, warning := range svc.ResolveWarnings {
+			fmt.Fprintf(w, "%s: %s\n", color.YellowString("Not scheduled"), warning)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyJSON       bool
+	historyLast       int
+	historyErrorsOnly bool
+)
+
+func init() {
+	AgentCmd.AddCommand(checkHistoryCommand)
+
+	checkHistoryCommand.Flags().BoolVarP(&historyJSON, "json", "", false, "print out raw json")
+	checkHistoryCommand.Flags().IntVarP(&historyLast, "last", "n", 0, "only print the last N runs of each instance")
+	checkHistoryCommand.Flags().BoolVarP(&historyErrorsOnly, "errors-only", "", false, "only print the runs with errors or warnings")
+}
+
+var checkHistoryCommand = &cobra.Command{
+	Use:   "check-history <check_name|check_id>",
+	Short: "Print the results of the last runs of a check in a running agent",
+	Long:  ``,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		err := common.SetupConfigWithoutSecrets(confFilePath, "")
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		err = config.SetupLogger(loggerName, config.GetEnvDefault("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+		if err != nil {
+			fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+			return err
+		}
+
+		c := util.GetClient(false) // FIX: get certificates right then make this true
+
+		// Set session token
+		err = util.SetAuthToken()
+		if err != nil {
+			return err
+		}
+		ipcAddress, err := config.GetIPCAddress()
+		if err != nil {
+			return err
+		}
+		urlstr := fmt.Sprintf("https://%v:%v/agent/check-history/%s", ipcAddress, config.Datadog.GetInt("cmd_port"), url.PathEscape(args[0]))
+		r, err := util.DoGet(c, urlstr)
+		if err != nil {
+			if r != nil && string(r) != "" {
+				return fmt.Errorf("the agent ran into an error while getting the check history: %s", string(r))
+			}
+			return fmt.Errorf("failed to query the agent (running?): %s", err)
+		}
+
+		if historyJSON {
+			fmt.Println(string(r))
+			return nil
+		}
+
+		hr := response.CheckHistoryResponse{}
+		if err = json.Unmarshal(r, &hr); err != nil {
+			return err
+		}
+
+		printCheckHistory(color.Output, hr, historyLast, historyErrorsOnly)
+		return nil
+	},
+}
+
+// printCheckHistory prints the run history of the instances of a check, the most recent runs last
+func printCheckHistory(w io.Writer, hr response.CheckHistoryResponse, last int, errorsOnly bool) {
+	ids := make([]string, 0, len(hr.Instances))
+	for id := range hr.Instances {
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		results := hr.Instances[check.ID(id)]
+		if errorsOnly {
+			filtered := make([]check.RunResult, 0, len(results))
+			for _, result := range results {
+				if result.Error != "" || len(result.Warnings) > 0 {
+					filtered = append(filtered, result)
+				}
+			}
+			results = filtered
+		}
+		if last > 0 && len(results) > last {
+			results = results[len(results)-last:]
+		}
+
+		fmt.Fprintf(w, "\n=== Instance %s ===\n", color.GreenString(id))
+		if len(results) == 0 {
+			fmt.Fprintln(w, "No runs to display")
+			continue
+		}
+
+		for _, result := range results {
+			fmt.Fprintf(w, "%s  %s  duration: %dms, metric samples: %d, events: %d, service checks: %d\n",
+				time.Unix(result.Timestamp, 0).Format(time.RFC3339), runState(result),
+				result.ExecutionTime, result.MetricSamples, result.Events, result.ServiceChecks)
+
+			if len(result.ServiceCheckStatuses) > 0 {
+				names := make([]string, 0, len(result.ServiceCheckStatuses))
+				for name := range result.ServiceCheckStatuses {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				statuses := make([]string, 0, len(names))
+				for _, name := range names {
+					statuses = append(statuses, fmt.Sprintf("%s: %s", name, serviceCheckStatusColor(result.ServiceCheckStatuses[name])))
+				}
+				fmt.Fprintf(w, "    Service Checks: %s\n", strings.Join(statuses, ", "))
+			}
+			if result.Error != "" {
+				fmt.Fprintf(w, "    Error: %s\n", runErrorMessage(result.Error))
+			}
+			for _, warning := range result.Warnings {
+				fmt.Fprintf(w, "    Warning: %s\n", warning)
+			}
+		}
+	}
+}
+
+func runState(result check.RunResult) string {
+	switch {
+	case result.TimedOut:
+		return color.RedString("[TIMEOUT]")
+	case result.Error != "":
+		return color.RedString("[ERROR]  ")
+	case len(result.Warnings) > 0:
+		return color.YellowString("[WARNING]")
+	default:
+		return color.GreenString("[OK]     ")
+	}
+}
+
+func serviceCheckStatusColor(status string) string {
+	switch status {
+	case "OK":
+		return color.GreenString(status)
+	case "WARNING":
+		return color.YellowString(status)
+	case "CRITICAL":
+		return color.RedString(status)
+	default:
+		return status
+	}
+}
+
+// runErrorMessage returns the message of a run error, python checks report their
+// errors as a JSON list of messages with their traceback
+func runErrorMessage(runError string) string {
+	var errs []map[string]string
+	if err := json.Unmarshal([]byte(runError), &errs); err == nil && len(errs) > 0 {
+		if msg, ok := errs[0]["message"]; ok {
+			return msg
+		}
+	}
+	return runError
+}
@@ -7,6 +7,7 @@ package app
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
@@ -18,12 +19,16 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var withDebug bool
+var (
+	withDebug      bool
+	withValidation bool
+)
 
 func init() {
 	AgentCmd.AddCommand(configCheckCommand)
 
 	configCheckCommand.Flags().BoolVarP(&withDebug, "verbose", "v", false, "print additional debug info")
+	configCheckCommand.Flags().BoolVarP(&withValidation, "validate", "", false, "validate the configurations against the schema of their check, fails if some of them are invalid")
 }
 
 var configCheckCommand = &cobra.Command{
@@ -48,9 +53,10 @@ var configCheckCommand = &cobra.Command{
 		}
 		var b bytes.Buffer
 		color.Output = &b
-		err = flare.GetConfigCheck(color.Output, withDebug)
-		if err != nil {
-			return fmt.Errorf("unable to get config: %v", err)
+		// invalid configurations are still printed before returning the error
+		checkErr := flare.GetConfigCheck(color.Output, withDebug, withValidation)
+		if checkErr != nil && !errors.Is(checkErr, flare.ErrInvalidConfigs) {
+			return fmt.Errorf("unable to get config: %v", checkErr)
 		}
 
 		scrubbed, err := scrubber.ScrubBytes(b.Bytes())
@@ -59,6 +65,6 @@ var configCheckCommand = &cobra.Command{
 		}
 
 		fmt.Println(string(scrubbed))
-		return nil
+		return checkErr
 	},
 }
@@ -21,8 +21,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var explainTags bool
+
 func init() {
 	AgentCmd.AddCommand(taggerListCommand)
+	taggerListCommand.Flags().BoolVarP(&explainTags, "explain", "", false, "print out the tag rule that produced each tag, for the tags produced by the tag_rules")
 }
 
 var taggerListCommand = &cobra.Command{
@@ -105,6 +108,10 @@ var taggerListCommand = &cobra.Command{
 				}
 
 				fmt.Fprintln(color.Output, "]")
+
+				if explainTags {
+					printTagRules(tagItem.Rules[source])
+				}
 			}
 
 			fmt.Fprintln(color.Output, "===")
@@ -113,3 +120,21 @@ var taggerListCommand = &cobra.Command{
 		return nil
 	},
 }
+
+// printTagRules prints the tag rule that produced each tag of a source
+func printTagRules(rules map[string]string) {
+	if len(rules) == 0 {
+		return
+	}
+
+	tags := make([]string, 0, len(rules))
+	for tag := range rules {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	fmt.Fprintln(color.Output, "Rules:")
+	for _, tag := range tags {
+		fmt.Fprintf(color.Output, "  %s <- rule %s\n", color.BlueString(tag), color.YellowString(rules[tag]))
+	}
+}
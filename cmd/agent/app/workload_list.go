@@ -18,11 +18,15 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var verboseList bool
+var (
+	verboseList  bool
+	entitiesList bool
+)
 
 func init() {
 	AgentCmd.AddCommand(workloadListCommand)
 	workloadListCommand.Flags().BoolVarP(&verboseList, "verbose", "v", false, "print out a full dump of the workload store")
+	workloadListCommand.Flags().BoolVarP(&entitiesList, "entities", "", false, "print out the containers and pods of the workload store as JSON, for `autodiscovery simulate`")
 }
 
 var workloadListCommand = &cobra.Command{
@@ -58,6 +62,18 @@ var workloadListCommand = &cobra.Command{
 			return err
 		}
 
+		if entitiesList {
+			r, err := util.DoGet(c, fmt.Sprintf("https://%v:%v/agent/workload-list/entities", ipcAddress, config.Datadog.GetInt("cmd_port")))
+			if err != nil {
+				if r != nil && string(r) != "" {
+					return fmt.Errorf("the agent ran into an error while getting the workload entities: %s", string(r))
+				}
+				return fmt.Errorf("failed to query the agent (running?): %s", err)
+			}
+			fmt.Println(string(r))
+			return nil
+		}
+
 		r, err := util.DoGet(c, workloadURL(verboseList, ipcAddress, config.Datadog.GetInt("cmd_port")))
 		if err != nil {
 			if r != nil && string(r) != "" {
@@ -9,6 +9,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/scheduler"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	confad "github.com/DataDog/datadog-agent/pkg/config/autodiscovery"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -26,6 +27,7 @@ var (
 
 func setupAutoDiscovery(confSearchPaths []string, metaScheduler *scheduler.MetaScheduler) *autodiscovery.AutoConfig {
 	ad := autodiscovery.NewAutoConfig(metaScheduler)
+	schema.LoadFromConfd(confSearchPaths)
 	ad.AddConfigProvider(providers.NewFileConfigProvider(confSearchPaths), false, 0)
 
 	// Autodiscovery cannot easily use config.RegisterOverrideFunc() due to Unmarshalling
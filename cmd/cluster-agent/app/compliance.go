@@ -84,7 +84,7 @@ func startCompliance(stopper restart.Stopper, apiCl *apiserver.APIClient, isLead
 	runner := runner.NewRunner()
 	stopper.Add(runner)
 
-	scheduler := scheduler.NewScheduler(runner.GetChan())
+	scheduler := scheduler.NewScheduler(runner.GetChan(), runner.GetCriticalChan())
 	runner.SetScheduler(scheduler)
 
 	checkInterval := coreconfig.Datadog.GetDuration("compliance_config.check_interval")
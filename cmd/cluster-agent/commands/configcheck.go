@@ -19,7 +19,7 @@ import (
 )
 
 func GetConfigCheckCobraCmd(flagNoColor *bool, confPath *string, loggerName config.LoggerName) *cobra.Command {
-	var withDebug bool
+	var withDebug, withValidation bool
 	configCheckCommand := &cobra.Command{
 		Use:   "configcheck",
 		Short: "Print all configurations loaded & resolved of a running cluster agent",
@@ -43,7 +43,7 @@ func GetConfigCheckCobraCmd(flagNoColor *bool, confPath *string, loggerName conf
 				return err
 			}
 
-			err = flare.GetClusterAgentConfigCheck(color.Output, withDebug)
+			err = flare.GetClusterAgentConfigCheck(color.Output, withDebug, withValidation)
 			if err != nil {
 				return err
 			}
@@ -51,5 +51,6 @@ func GetConfigCheckCobraCmd(flagNoColor *bool, confPath *string, loggerName conf
 		},
 	}
 	configCheckCommand.Flags().BoolVarP(&withDebug, "verbose", "v", false, "print additional debug info")
+	configCheckCommand.Flags().BoolVarP(&withValidation, "validate", "", false, "validate the configurations against the schema of their check, fails if some of them are invalid")
 	return configCheckCommand
 }
@@ -19,7 +19,9 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/ksm"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/kubernetesapiserver"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster/orchestrator"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/httpprobe"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/net"
+	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/openmetrics"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/cpu"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/disk"
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/system/filehandles"
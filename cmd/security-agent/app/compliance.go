@@ -120,7 +120,7 @@ func startCompliance(hostname string, stopper restart.Stopper, statsdClient *ddg
 	runner := runner.NewRunner()
 	stopper.Add(runner)
 
-	scheduler := scheduler.NewScheduler(runner.GetChan())
+	scheduler := scheduler.NewScheduler(runner.GetChan(), runner.GetCriticalChan())
 	runner.SetScheduler(scheduler)
 
 	checkInterval := coreconfig.Datadog.GetDuration("compliance_config.check_interval")
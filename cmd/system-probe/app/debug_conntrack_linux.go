@@ -0,0 +1,171 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+// +build !android
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/network"
+	networkconfig "github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/network/netlink"
+	"github.com/spf13/cobra"
+)
+
+const conntrackDumpTimeout = time.Minute
+
+var (
+	conntrackArgs = struct {
+		capture      string
+		record       string
+		maxStateSize int
+		dump         bool
+		jsonOutput   bool
+	}{}
+
+	conntrackCommand = &cobra.Command{
+		Use:   "conntrack",
+		Short: "Compare the conntrack cache of a running system-probe with the conntrack table of the host",
+		Long: `Dump the NAT translations cached by the conntracker of a running system-probe, compare them to
+the conntrack table of the host and report missing, stale, mismatched and orphaned entries.
+
+With --record, the conntrack table of the host is dumped locally and the raw netlink messages are written
+to a file. With --capture, such a recording is replayed offline through the conntrack decoder and cache.`,
+		Args: cobra.NoArgs,
+		RunE: debugConntrack,
+	}
+)
+
+func init() {
+	conntrackCommand.Flags().StringVar(&conntrackArgs.capture, "capture", "", "Replay a recorded netlink capture instead of querying system-probe")
+	conntrackCommand.Flags().StringVar(&conntrackArgs.record, "record", "", "Record the conntrack table of the host to the given file")
+	conntrackCommand.Flags().IntVar(&conntrackArgs.maxStateSize, "max-state-size", 0, "Size of the conntrack cache used to replay a capture (defaults to the configured conntrack_max_state_size)")
+	conntrackCommand.Flags().BoolVar(&conntrackArgs.dump, "dump", false, "Print all the cached entries")
+	conntrackCommand.Flags().BoolVar(&conntrackArgs.jsonOutput, "json", false, "Print the report as JSON")
+	debugCommand.AddCommand(conntrackCommand)
+}
+
+func debugConntrack(_ *cobra.Command, _ []string) error {
+	if conntrackArgs.capture != "" && conntrackArgs.record != "" {
+		return fmt.Errorf("--capture and --record are mutually exclusive")
+	}
+
+	if _, err := setupConfig(); err != nil {
+		return err
+	}
+	cfg := networkconfig.New()
+
+	ctx, cancel := context.WithTimeout(context.Background(), conntrackDumpTimeout)
+	defer cancel()
+
+	var cached, host []network.NATEntry
+	switch {
+	case conntrackArgs.record != "":
+		f, err := os.Create(conntrackArgs.record)
+		if err != nil {
+			return fmt.Errorf("unable to create capture file: %w", err)
+		}
+		defer f.Close()
+
+		entries, err := netlink.DumpHostTable(ctx, cfg.ProcRoot, cfg.EnableConntrackAllNamespaces, f)
+		if err != nil {
+			return fmt.Errorf("unable to record conntrack table: %w", err)
+		}
+		fmt.Printf("recorded %d NAT entries to %s\n", len(entries), conntrackArgs.record)
+		return nil
+
+	case conntrackArgs.capture != "":
+		f, err := os.Open(conntrackArgs.capture)
+		if err != nil {
+			return fmt.Errorf("unable to open capture file: %w", err)
+		}
+		defer f.Close()
+
+		maxStateSize := conntrackArgs.maxStateSize
+		if maxStateSize <= 0 {
+			maxStateSize = cfg.ConntrackMaxStateSize
+		}
+		if cached, host, err = netlink.ReplayCapture(ctx, f, maxStateSize); err != nil {
+			return fmt.Errorf("unable to replay capture: %w", err)
+		}
+
+	default:
+		c, err := getSystemProbeClient()
+		if err != nil {
+			return err
+		}
+		if err := getDebugJSON(c, "conntrack/cached", &cached); err != nil {
+			return err
+		}
+		if err := getDebugJSON(c, "conntrack/host", &host); err != nil {
+			return err
+		}
+	}
+
+	drift := network.CompareNATTables(cached, host)
+	if conntrackArgs.jsonOutput {
+		report := struct {
+			network.NATDrift
+			Cached []network.NATEntry `json:"cached,omitempty"`
+		}{NATDrift: drift}
+		if conntrackArgs.dump {
+			report.Cached = cached
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	if conntrackArgs.dump {
+		network.SortNATEntries(cached)
+		printNATEntries(os.Stdout, "Cached entries", cached)
+	}
+	printNATDrift(os.Stdout, drift)
+	return nil
+}
+
+func getDebugJSON(c *http.Client, path string, v interface{}) error {
+	r, err := util.DoGet(c, "http://localhost/debug/"+path)
+	if err != nil {
+		return fmt.Errorf("Could not reach %s: %v \nMake sure the %s is running and network_config.enabled is set to true", targetProcessName, err, targetProcessName)
+	}
+	if err := json.Unmarshal(r, v); err != nil {
+		return fmt.Errorf("unable to parse %s response: %w", path, err)
+	}
+	return nil
+}
+
+func printNATDrift(w io.Writer, drift network.NATDrift) {
+	fmt.Fprintf(w, "Cached entries: %d\n", drift.CachedEntries)
+	fmt.Fprintf(w, "Host entries:   %d\n\n", drift.HostEntries)
+
+	printNATEntries(w, "Orphaned entries", drift.Orphans)
+	printNATEntries(w, "Missing from the cache", drift.Missing)
+	printNATEntries(w, "Stale in the cache", drift.Stale)
+
+	fmt.Fprintf(w, "Mismatched translations (%d)\n", len(drift.Mismatched))
+	for _, m := range drift.Mismatched {
+		fmt.Fprintf(w, "  cached: %s\n    host: %s\n", m.Cached, m.Host)
+	}
+}
+
+func printNATEntries(w io.Writer, title string, entries []network.NATEntry) {
+	fmt.Fprintf(w, "%s (%d)\n", title, len(entries))
+	for _, e := range entries {
+		fmt.Fprintf(w, "  %s\n", e)
+	}
+	fmt.Fprintln(w)
+}
@@ -3,12 +3,14 @@
 package modules
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -29,6 +31,12 @@ var ErrSysprobeUnsupported = errors.New("system-probe unsupported")
 
 const inactivityLogDuration = 10 * time.Minute
 const inactivityRestartDuration = 20 * time.Minute
+const containerCacheValidity = 10 * time.Second
+const conntrackDumpTimeout = time.Minute
+
+// containersClientIDPrefix prefixes the client IDs of the /connections/containers requests, so
+// that they keep their own delta state and don't consume the deltas of the /connections clients
+const containersClientIDPrefix = "containers-"
 
 // NetworkTracer is a factory for NPM's tracer
 var NetworkTracer = module.Factory{
@@ -44,7 +52,15 @@ var NetworkTracer = module.Factory{
 		log.Infof("Creating tracer for: %s", filepath.Base(os.Args[0]))
 
 		t, err := tracer.NewTracer(ncfg)
-		return &networkTracer{tracer: t}, err
+		if err != nil {
+			return nil, err
+		}
+
+		containers, err := network.NewContainerResolver(ncfg.ProcRoot, containerCacheValidity)
+		if err != nil {
+			log.Infof("per-container network stats are disabled: %s", err)
+		}
+		return &networkTracer{tracer: t, containers: containers}, nil
 	},
 }
 
@@ -52,6 +68,7 @@ var _ module.Module = &networkTracer{}
 
 type networkTracer struct {
 	tracer       *tracer.Tracer
+	containers   network.ContainerIDResolver
 	restartTimer *time.Timer
 }
 
@@ -84,6 +101,63 @@ func (nt *networkTracer) Register(httpMux *module.Router) error {
 		logRequests(id, count, len(cs.Conns), start)
 	})
 
+	// /connections/stream returns the same connection deltas as /connections, streamed as
+	// chunked frames of opened, updated and closed connections. The size of each frame
+	// can be controlled with the optional ?chunk_size= argument.
+	httpMux.HandleFunc("/connections/stream", func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		id := getClientID(req)
+		chunkSize, err := getChunkSize(req)
+		if err != nil {
+			log.Errorf("unable to stream connections: %s", err)
+			w.WriteHeader(400)
+			return
+		}
+
+		cs, err := nt.tracer.GetActiveConnections(id)
+		if err != nil {
+			log.Errorf("unable to retrieve connections: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+		contentType := req.Header.Get("Accept")
+		marshaler := encoding.GetMarshaler(contentType)
+		streamConnections(w, marshaler, chunkSize, cs)
+
+		if nt.restartTimer != nil {
+			nt.restartTimer.Reset(inactivityRestartDuration)
+		}
+		count := atomic.AddUint64(&runCounter, 1)
+		logRequests(id, count, len(cs.Conns), start)
+	})
+
+	// /connections/containers returns the connection deltas since the previous request of the
+	// client aggregated by container. They are tracked apart from the deltas of /connections.
+	httpMux.HandleFunc("/connections/containers", func(w http.ResponseWriter, req *http.Request) {
+		if nt.containers == nil {
+			w.WriteHeader(404)
+			return
+		}
+
+		start := time.Now()
+		id := getClientID(req)
+		cs, err := nt.tracer.GetActiveConnections(containersClientIDPrefix + id)
+		if err != nil {
+			log.Errorf("unable to retrieve connections: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+		defer network.Reclaim(cs)
+
+		utils.WriteAsJSON(w, network.AggregateByContainer(cs.Conns, nt.containers))
+
+		if nt.restartTimer != nil {
+			nt.restartTimer.Reset(inactivityRestartDuration)
+		}
+		count := atomic.AddUint64(&runCounter, 1)
+		logRequests(id, count, len(cs.Conns), start)
+	})
+
 	httpMux.HandleFunc("/debug/net_maps", func(w http.ResponseWriter, req *http.Request) {
 		cs, err := nt.tracer.DebugNetworkMaps()
 		if err != nil {
@@ -108,6 +182,34 @@ func (nt *networkTracer) Register(httpMux *module.Router) error {
 		utils.WriteAsJSON(w, stats)
 	})
 
+	httpMux.HandleFunc("/debug/conntrack/cached", func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancelFunc := context.WithTimeout(req.Context(), conntrackDumpTimeout)
+		defer cancelFunc()
+
+		table, err := nt.tracer.DebugCachedConntrack(ctx)
+		if err != nil {
+			log.Errorf("unable to retrieve cached conntrack table: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+
+		utils.WriteAsJSON(w, table)
+	})
+
+	httpMux.HandleFunc("/debug/conntrack/host", func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancelFunc := context.WithTimeout(req.Context(), conntrackDumpTimeout)
+		defer cancelFunc()
+
+		table, err := nt.tracer.DebugHostConntrack(ctx)
+		if err != nil {
+			log.Errorf("unable to retrieve host conntrack table: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+
+		utils.WriteAsJSON(w, table)
+	})
+
 	httpMux.HandleFunc("/debug/http_monitoring", func(w http.ResponseWriter, req *http.Request) {
 		id := getClientID(req)
 		cs, err := nt.tracer.GetActiveConnections(id)
@@ -182,6 +284,39 @@ func getClientID(req *http.Request) string {
 	return clientID
 }
 
+func getChunkSize(req *http.Request) (int, error) {
+	rawSize := req.URL.Query().Get("chunk_size")
+	if rawSize == "" {
+		return encoding.DefaultStreamChunkSize, nil
+	}
+
+	size, err := strconv.Atoi(rawSize)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("invalid chunk_size %q", rawSize)
+	}
+	return size, nil
+}
+
+func streamConnections(w http.ResponseWriter, marshaler encoding.Marshaler, chunkSize int, cs *network.Connections) {
+	defer network.Reclaim(cs)
+
+	w.Header().Set("Content-type", marshaler.ContentType())
+	w.Header().Set("Transfer-Encoding", "chunked")
+
+	onFrame := func() {}
+	if flusher, ok := w.(http.Flusher); ok {
+		onFrame = flusher.Flush
+	}
+
+	frames, err := encoding.NewStreamEncoder(w, marshaler, chunkSize).Encode(cs, onFrame)
+	if err != nil {
+		// headers were most likely already sent, we can only drop the stream here
+		log.Errorf("unable to stream connections with type %s: %s", marshaler.ContentType(), err)
+		return
+	}
+	log.Tracef("/connections/stream: %d connections, %d frames", len(cs.Conns), frames)
+}
+
 func writeConnections(w http.ResponseWriter, marshaler encoding.Marshaler, cs *network.Connections) {
 	defer network.Reclaim(cs)
 
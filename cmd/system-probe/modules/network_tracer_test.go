@@ -3,6 +3,7 @@
 package modules
 
 import (
+	"io"
 	"net/http/httptest"
 	"testing"
 
@@ -60,3 +61,71 @@ func TestDecode(t *testing.T) {
 	assert.Equal(t, expected, out)
 
 }
+
+func TestStreamConnections(t *testing.T) {
+	rec := httptest.NewRecorder()
+
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: []network.ConnectionStats{
+				{
+					Source:             util.AddressFromString("10.1.1.1"),
+					Dest:               util.AddressFromString("10.2.2.2"),
+					SPort:              1000,
+					DPort:              9000,
+					LastTCPEstablished: 1,
+					Type:               network.TCP,
+					Family:             network.AFINET,
+				},
+				{
+					Source:        util.AddressFromString("10.1.1.1"),
+					Dest:          util.AddressFromString("10.2.2.2"),
+					SPort:         1001,
+					DPort:         9000,
+					LastSentBytes: 12,
+					Type:          network.TCP,
+					Family:        network.AFINET,
+				},
+			},
+		},
+	}
+
+	marshaller := encoding.GetMarshaler(encoding.ContentTypeProtobuf)
+	streamConnections(rec, marshaller, 1, in)
+	assert.Equal(t, encoding.ContentTypeProtobuf, rec.Header().Get("Content-type"))
+	assert.True(t, rec.Flushed)
+
+	decoder := encoding.NewStreamDecoder(rec.Body, encoding.GetUnmarshaler(encoding.ContentTypeProtobuf))
+	kind, out, err := decoder.Next()
+	require.NoError(t, err)
+	assert.Equal(t, encoding.FrameOpened, kind)
+	require.Len(t, out.Conns, 1)
+	assert.Equal(t, int32(1000), out.Conns[0].Laddr.Port)
+
+	kind, out, err = decoder.Next()
+	require.NoError(t, err)
+	assert.Equal(t, encoding.FrameUpdated, kind)
+	require.Len(t, out.Conns, 1)
+	assert.Equal(t, int32(1001), out.Conns[0].Laddr.Port)
+
+	_, _, err = decoder.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestGetChunkSize(t *testing.T) {
+	req := httptest.NewRequest("GET", "/connections/stream", nil)
+	size, err := getChunkSize(req)
+	require.NoError(t, err)
+	assert.Equal(t, encoding.DefaultStreamChunkSize, size)
+
+	req = httptest.NewRequest("GET", "/connections/stream?chunk_size=50", nil)
+	size, err = getChunkSize(req)
+	require.NoError(t, err)
+	assert.Equal(t, 50, size)
+
+	for _, invalid := range []string{"0", "-3", "abc"} {
+		req = httptest.NewRequest("GET", "/connections/stream?chunk_size="+invalid, nil)
+		_, err = getChunkSize(req)
+		assert.Error(t, err)
+	}
+}
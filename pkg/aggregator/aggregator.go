@@ -12,6 +12,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator/tagbudget"
 	"github.com/DataDog/datadog-agent/pkg/epforwarder"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
@@ -244,7 +245,8 @@ type BufferedAggregator struct {
 	ServerlessFlushDone    chan struct{}
 	stopChan               chan struct{}
 	health                 *health.Handle
-	agentName              string // Name of the agent for telemetry metrics
+	agentName              string            // Name of the agent for telemetry metrics
+	tagBudget              *tagbudget.Budget // limits the distinct values of the tag keys, nil when disabled
 
 	tlmContainerTagsEnabled bool                                              // Whether we should call the tagger to tag agent telemetry metrics
 	agentTags               func(collectors.TagCardinality) ([]string, error) // This function gets the agent tags from the tagger (defined as a struct field to ease testing)
@@ -301,6 +303,16 @@ func NewBufferedAggregator(s serializer.MetricSerializer, eventPlatformForwarder
 		ServerlessFlushDone:     make(chan struct{}),
 	}
 
+	if budgetConfig := tagbudget.ConfigFromDatadog(); budgetConfig != nil {
+		budget, err := tagbudget.New("aggregator", *budgetConfig)
+		if err != nil {
+			log.Errorf("Invalid tag_cardinality_budget, the tag cardinality budget of the aggregator is disabled: %s", err)
+		} else {
+			aggregator.tagBudget = budget
+			aggregator.statsdSampler.contextResolver.resolver.setBudget(budget, "dogstatsd")
+		}
+	}
+
 	return aggregator
 }
 
@@ -373,11 +385,15 @@ func (agg *BufferedAggregator) registerSender(id check.ID) error {
 	if _, ok := agg.checkSamplers[id]; ok {
 		return fmt.Errorf("Sender with ID '%s' has already been registered, will use existing sampler", id)
 	}
-	agg.checkSamplers[id] = newCheckSampler(
+	sampler := newCheckSampler(
 		config.Datadog.GetInt("check_sampler_bucket_commits_count_expiry"),
 		config.Datadog.GetBool("check_sampler_expire_metrics"),
 		config.Datadog.GetDuration("check_sampler_stateful_metric_expiration_time"),
 	)
+	if agg.tagBudget != nil {
+		sampler.contextResolver.resolver.setBudget(agg.tagBudget, check.IDToCheckName(id))
+	}
+	agg.checkSamplers[id] = sampler
 	return nil
 }
 
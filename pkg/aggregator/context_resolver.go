@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/tagbudget"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
 )
@@ -27,6 +28,12 @@ type contextResolver struct {
 	// buffer slice allocated once per contextResolver to combine and sort
 	// tags, origin detection tags and k8s tags.
 	tagsBuffer *tagset.HashingTagsAccumulator
+	// budget limits the distinct values of the tag keys, nil when disabled
+	budget       *tagbudget.Budget
+	budgetSource string
+	// budgetedKeys maps the key of the tags of a sample to the key of its context once the
+	// budget is applied, so that the budget is only applied when a new context is seen
+	budgetedKeys map[ckey.ContextKey]ckey.ContextKey
 }
 
 // generateContextKey generates the contextKey associated with the context of the metricSample
@@ -42,11 +49,29 @@ func newContextResolver() *contextResolver {
 	}
 }
 
+// setBudget applies a tag cardinality budget to the tags of the contexts, reported as coming from source
+func (cr *contextResolver) setBudget(budget *tagbudget.Budget, source string) {
+	cr.budget = budget
+	cr.budgetSource = source
+	cr.budgetedKeys = make(map[ckey.ContextKey]ckey.ContextKey)
+}
+
 // trackContext returns the contextKey associated with the context of the metricSample and tracks that context
 func (cr *contextResolver) trackContext(metricSampleContext metrics.MetricSampleContext) ckey.ContextKey {
 	metricSampleContext.GetTags(cr.tagsBuffer)               // tags here are not sorted and can contain duplicates
 	contextKey := cr.generateContextKey(metricSampleContext) // the generator will remove duplicates from cr.tagsBuffer (and doesn't mind the order)
 
+	if cr.budget != nil {
+		sampleKey := contextKey
+		if budgetedKey, found := cr.budgetedKeys[sampleKey]; found {
+			cr.tagsBuffer.Reset()
+			return budgetedKey
+		}
+		cr.budget.Apply(cr.tagsBuffer, cr.budgetSource)
+		contextKey = cr.generateContextKey(metricSampleContext)
+		cr.budgetedKeys[sampleKey] = contextKey
+	}
+
 	if _, ok := cr.contextsByKey[contextKey]; !ok {
 		// making a copy of tags for the context since tagsBuffer
 		// will be reused later. This allow us to allocate one slice
@@ -75,6 +100,22 @@ func (cr *contextResolver) removeKeys(expiredContextKeys []ckey.ContextKey) {
 	for _, expiredContextKey := range expiredContextKeys {
 		delete(cr.contextsByKey, expiredContextKey)
 	}
+
+	if len(cr.budgetedKeys) > 0 && len(expiredContextKeys) > 0 {
+		for sampleKey, contextKey := range cr.budgetedKeys {
+			if _, found := cr.contextsByKey[contextKey]; !found {
+				delete(cr.budgetedKeys, sampleKey)
+			}
+		}
+	}
+
+	// the budget isn't applied to the samples of the known contexts, refresh the
+	// values of the remaining contexts so that they keep their slot in the budget
+	if cr.budget != nil && cr.budget.TouchDue() {
+		for _, context := range cr.contextsByKey {
+			cr.budget.Touch(context.Tags)
+		}
+	}
 }
 
 // timestampContextResolver allows tracking and expiring contexts based on time.
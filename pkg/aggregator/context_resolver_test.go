@@ -11,12 +11,14 @@ import (
 	// stdlib
 
 	"testing"
+	"time"
 
 	// 3p
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/tagbudget"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
@@ -168,3 +170,82 @@ func TestTagDeduplication(t *testing.T) {
 	assert.Equal(t, len(resolver.contextsByKey[ckey].Tags), 1)
 	assert.Equal(t, resolver.contextsByKey[ckey].Tags, []string{"bar"})
 }
+
+func TestTagCardinalityBudget(t *testing.T) {
+	budget, err := tagbudget.New("aggregator", tagbudget.Config{
+		DefaultLimit: 1,
+		Action:       tagbudget.ActionDrop,
+	})
+	require.NoError(t, err)
+
+	resolver := newContextResolver()
+	resolver.setBudget(budget, "dogstatsd")
+
+	ckey1 := resolver.trackContext(&metrics.MetricSample{
+		Name: "foo",
+		Tags: []string{"env:prod", "request_id:1"},
+	})
+	ckey2 := resolver.trackContext(&metrics.MetricSample{
+		Name: "foo",
+		Tags: []string{"request_id:2", "env:prod"},
+	})
+	ckey3 := resolver.trackContext(&metrics.MetricSample{
+		Name: "foo",
+		Tags: []string{"request_id:3", "env:prod"},
+	})
+
+	// the tags exceeding the budget are dropped, the contexts are merged
+	assert.NotEqual(t, ckey1, ckey2)
+	assert.Equal(t, ckey2, ckey3)
+	assert.Equal(t, 2, resolver.length())
+	assert.Equal(t, []string{"env:prod"}, resolver.contextsByKey[ckey2].Tags)
+
+	offenders := budget.Offenders()
+	require.Len(t, offenders, 1)
+	assert.Equal(t, "request_id", offenders[0].Key)
+	assert.Equal(t, "dogstatsd", offenders[0].Source)
+	assert.Equal(t, uint64(2), offenders[0].Exceeded)
+
+	// the budget is only applied to the samples of new contexts
+	assert.Equal(t, ckey2, resolver.trackContext(&metrics.MetricSample{
+		Name: "foo",
+		Tags: []string{"env:prod", "request_id:2"},
+	}))
+	assert.Equal(t, uint64(2), budget.Offenders()[0].Exceeded)
+
+	// the samples of an expired context are mapped again
+	resolver.removeKeys([]ckey.ContextKey{ckey2})
+	assert.Len(t, resolver.budgetedKeys, 1)
+}
+
+func TestTagCardinalityBudgetLiveContexts(t *testing.T) {
+	budget, err := tagbudget.New("aggregator", tagbudget.Config{
+		DefaultLimit: 1,
+		Action:       tagbudget.ActionDrop,
+		Window:       100 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	resolver := newContextResolver()
+	resolver.setBudget(budget, "dogstatsd")
+
+	sample := &metrics.MetricSample{
+		Name: "foo",
+		Tags: []string{"env:prod", "request_id:1"},
+	}
+	ckey1 := resolver.trackContext(sample)
+
+	// the context stays in use across several windows, its values keep their slot
+	for i := 0; i < 8; i++ {
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, ckey1, resolver.trackContext(sample))
+		resolver.removeKeys(nil)
+	}
+
+	ckey2 := resolver.trackContext(&metrics.MetricSample{
+		Name: "foo",
+		Tags: []string{"env:prod", "request_id:2"},
+	})
+	assert.NotEqual(t, ckey1, ckey2)
+	assert.Equal(t, []string{"env:prod"}, resolver.contextsByKey[ckey2].Tags)
+}
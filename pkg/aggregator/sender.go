@@ -360,6 +360,7 @@ func (s *checkSender) ServiceCheck(checkName string, status metrics.ServiceCheck
 
 	s.statsLock.Lock()
 	s.metricStats.ServiceChecks++
+	s.metricStats.ServiceCheckStatuses[checkName] = status.String()
 	s.statsLock.Unlock()
 }
 
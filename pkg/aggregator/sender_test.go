@@ -211,6 +211,22 @@ func TestGetSenderServiceTagServiceCheck(t *testing.T) {
 	assert.Equal(t, append(checkTags, "service:service2"), sc.Tags)
 }
 
+func TestSenderStatsServiceCheckStatuses(t *testing.T) {
+	s := initSender(checkID1, "")
+
+	s.sender.ServiceCheck("test.can_connect", metrics.ServiceCheckCritical, "testhostname", nil, "")
+	<-s.serviceCheckChan
+	s.sender.ServiceCheck("test.can_connect", metrics.ServiceCheckOK, "testhostname", nil, "")
+	<-s.serviceCheckChan
+	s.sender.ServiceCheck("test.health", metrics.ServiceCheckWarning, "testhostname", nil, "")
+	<-s.serviceCheckChan
+	s.sender.Commit()
+
+	stats := s.sender.GetSenderStats()
+	assert.Equal(t, int64(3), stats.ServiceChecks)
+	assert.Equal(t, map[string]string{"test.can_connect": "OK", "test.health": "WARNING"}, stats.ServiceCheckStatuses)
+}
+
 func TestGetSenderServiceTagEvent(t *testing.T) {
 	resetAggregator()
 	InitAggregator(nil, nil, "testhostname")
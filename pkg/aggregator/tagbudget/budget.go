@@ -0,0 +1,365 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package tagbudget limits the number of distinct values of the tag keys of the
+// metric contexts of the aggregator and of the entities of the tagger.
+package tagbudget
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/twmb/murmur3"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Actions taken on the tags exceeding their cardinality budget
+const (
+	// ActionReport only reports the tag keys exceeding their budget
+	ActionReport = "report"
+	// ActionDrop drops the tags exceeding their budget
+	ActionDrop = "drop"
+	// ActionHash replaces the values exceeding the budget with a fixed number of hashed values
+	ActionHash = "hash"
+)
+
+// sweepsPerWindow is the number of times per window the idle values are forgotten
+const sweepsPerWindow = 4
+
+var (
+	tlmExceeded = telemetry.NewCounter("tag_budget", "exceeded",
+		[]string{"component", "tag_key", "source"}, "Count of tags exceeding the cardinality budget of their key")
+	tlmOffenders = telemetry.NewGauge("tag_budget", "offenders",
+		[]string{"component"}, "Number of tag keys exceeding their cardinality budget")
+
+	budgetsMutex sync.Mutex
+	budgets      = make(map[string]*Budget)
+)
+
+// Config configures a Budget
+type Config struct {
+	// DefaultLimit is the maximum number of distinct values of a tag key, 0 means unlimited
+	DefaultLimit int
+	// Limits overrides the limit of some tag keys
+	Limits map[string]int
+	// Action is taken on the tags exceeding their budget: report, drop or hash
+	Action string
+	// Window is the period after which a value that wasn't seen is forgotten, 0 means never
+	Window time.Duration
+	// HashBuckets is the number of values the hash action replaces the exceeding values with
+	HashBuckets int
+}
+
+// Offender is a tag key of a source exceeding its cardinality budget
+type Offender struct {
+	Key          string
+	Source       string
+	Limit        int
+	Exceeded     uint64
+	LastExceeded time.Time
+}
+
+type offenderKey struct {
+	key    string
+	source string
+}
+
+// Budget tracks the distinct values of the tag keys, and applies an action on the
+// tags whose key exceeds its budget.
+//
+// A value admitted in the budget of its key keeps its slot until it isn't seen for
+// a whole window, so that the contexts using it keep their tags.
+//
+// It is safe for concurrent use.
+type Budget struct {
+	component string
+	config    Config
+	now       func() time.Time
+
+	m         sync.Mutex
+	lastSweep time.Time
+	lastTouch time.Time
+	// values holds the time the distinct tags were last seen, by tag key and tag hash,
+	// a key never holds more values than its limit
+	values    map[string]map[uint64]time.Time
+	offenders map[offenderKey]*Offender
+}
+
+// ConfigFromDatadog returns the budget configured in `tag_cardinality_budget`, nil if it's disabled
+func ConfigFromDatadog() *Config {
+	if !config.Datadog.GetBool("tag_cardinality_budget.enabled") {
+		return nil
+	}
+
+	return &Config{
+		DefaultLimit: config.Datadog.GetInt("tag_cardinality_budget.max_values_per_key"),
+		Limits:       toIntMap(config.Datadog.GetStringMap("tag_cardinality_budget.key_limits")),
+		Action:       config.Datadog.GetString("tag_cardinality_budget.action"),
+		Window:       config.Datadog.GetDuration("tag_cardinality_budget.window") * time.Second,
+		HashBuckets:  config.Datadog.GetInt("tag_cardinality_budget.hash_buckets"),
+	}
+}
+
+func toIntMap(m map[string]interface{}) map[string]int {
+	limits := make(map[string]int, len(m))
+	for key, value := range m {
+		switch v := value.(type) {
+		case int:
+			limits[key] = v
+		case int64:
+			limits[key] = int(v)
+		case float64:
+			limits[key] = int(v)
+		default:
+			log.Warnf("Ignoring the cardinality budget of tag key %s: %v is not a number", key, value)
+		}
+	}
+	return limits
+}
+
+// New returns a budget for the tags of a component and registers it for the
+// status page, replacing the previous budget of the component
+func New(component string, cfg Config) (*Budget, error) {
+	switch cfg.Action {
+	case ActionReport, ActionDrop:
+	case ActionHash:
+		if cfg.HashBuckets <= 0 {
+			return nil, fmt.Errorf("hash_buckets must be positive, got %d", cfg.HashBuckets)
+		}
+	default:
+		return nil, fmt.Errorf("unknown action %q, expected report, drop or hash", cfg.Action)
+	}
+
+	b := &Budget{
+		component: component,
+		config:    cfg,
+		now:       time.Now,
+		values:    make(map[string]map[uint64]time.Time),
+		offenders: make(map[offenderKey]*Offender),
+	}
+	b.lastSweep = b.now()
+	b.lastTouch = b.lastSweep
+
+	budgetsMutex.Lock()
+	budgets[component] = b
+	budgetsMutex.Unlock()
+
+	return b, nil
+}
+
+// Apply applies the budget to the tags of a source accumulated in h, it drops or replaces
+// the tags exceeding the budget in place
+func (b *Budget) Apply(h *tagset.HashingTagsAccumulator, source string) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	now := b.now()
+	b.sweep(now)
+
+	tags, hashes := h.Get(), h.Hashes()
+	j := 0
+	for i := range tags {
+		tag, keep := b.check(tags[i], hashes[i], source, now)
+		if !keep {
+			continue
+		}
+		if tag != tags[i] {
+			tags[j], hashes[j] = tag, murmur3.StringSum64(tag)
+		} else {
+			tags[j], hashes[j] = tags[i], hashes[i]
+		}
+		j++
+	}
+	h.Truncate(j)
+}
+
+// ApplyToSlice applies the budget to the tags of a source, tags isn't modified and
+// is returned as is when all its tags fit in the budget
+func (b *Budget) ApplyToSlice(tags []string, source string) []string {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	now := b.now()
+	b.sweep(now)
+
+	var result []string
+	for i, t := range tags {
+		tag, keep := b.check(t, murmur3.StringSum64(t), source, now)
+		if result == nil && (!keep || tag != t) {
+			result = make([]string, i, len(tags))
+			copy(result, tags[:i])
+		}
+		if result != nil && keep {
+			result = append(result, tag)
+		}
+	}
+
+	if result == nil {
+		return tags
+	}
+	return result
+}
+
+// TouchDue returns whether the values of the tags that are still in use must be refreshed
+// with Touch so that they keep their slot. It returns true at most once per sweep period.
+func (b *Budget) TouchDue() bool {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	now := b.now()
+	if b.config.Window <= 0 || now.Sub(b.lastTouch) < b.config.Window/sweepsPerWindow {
+		return false
+	}
+	b.lastTouch = now
+	return true
+}
+
+// Touch refreshes the values of tags that were admitted in the budget, for the users of the
+// budget that only apply it to the tags they haven't seen yet
+func (b *Budget) Touch(tags []string) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	now := b.now()
+	for _, tag := range tags {
+		sep := strings.IndexByte(tag, ':')
+		if sep <= 0 {
+			continue
+		}
+		values, found := b.values[tag[:sep]]
+		if !found {
+			continue
+		}
+		hash := murmur3.StringSum64(tag)
+		if _, found := values[hash]; found {
+			values[hash] = now
+		}
+	}
+}
+
+// sweep forgets the values that weren't seen during the last window, and the offenders
+// that didn't exceed their budget during the last window
+func (b *Budget) sweep(now time.Time) {
+	if b.config.Window <= 0 || now.Sub(b.lastSweep) < b.config.Window/sweepsPerWindow {
+		return
+	}
+	b.lastSweep = now
+
+	idleSince := now.Add(-b.config.Window)
+	for key, values := range b.values {
+		for hash, lastSeen := range values {
+			if lastSeen.Before(idleSince) {
+				delete(values, hash)
+			}
+		}
+		if len(values) == 0 {
+			delete(b.values, key)
+		}
+	}
+
+	for k, offender := range b.offenders {
+		if offender.LastExceeded.Before(idleSince) {
+			delete(b.offenders, k)
+		}
+	}
+	tlmOffenders.Set(float64(len(b.offenders)), b.component)
+}
+
+// check returns the tag to keep in place of tag, false if it must be dropped
+func (b *Budget) check(tag string, hash uint64, source string, now time.Time) (string, bool) {
+	sep := strings.IndexByte(tag, ':')
+	if sep <= 0 {
+		return tag, true
+	}
+	key := tag[:sep]
+
+	limit, found := b.config.Limits[key]
+	if !found {
+		limit = b.config.DefaultLimit
+	}
+	if limit <= 0 {
+		return tag, true
+	}
+
+	values, found := b.values[key]
+	if !found {
+		values = make(map[uint64]time.Time)
+		b.values[key] = values
+	}
+	if _, found := values[hash]; found || len(values) < limit {
+		values[hash] = now
+		return tag, true
+	}
+
+	b.exceeded(key, source, limit, now)
+
+	switch b.config.Action {
+	case ActionDrop:
+		return "", false
+	case ActionHash:
+		bucket := murmur3.StringSum64(tag[sep+1:]) % uint64(b.config.HashBuckets)
+		return fmt.Sprintf("%s:hash_%d", key, bucket), true
+	default:
+		return tag, true
+	}
+}
+
+func (b *Budget) exceeded(key, source string, limit int, now time.Time) {
+	k := offenderKey{key: key, source: source}
+	offender, found := b.offenders[k]
+	if !found {
+		offender = &Offender{Key: key, Source: source, Limit: limit}
+		b.offenders[k] = offender
+		tlmOffenders.Set(float64(len(b.offenders)), b.component)
+		log.Warnf("Tag key %q of %s exceeded its budget of %d distinct values in the %s, action: %s", key, source, limit, b.component, b.config.Action)
+	}
+	offender.Exceeded++
+	offender.LastExceeded = now
+	tlmExceeded.Inc(b.component, key, source)
+}
+
+// Offenders returns the tag keys exceeding their budget, the ones exceeding it the most first
+func (b *Budget) Offenders() []Offender {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	offenders := make([]Offender, 0, len(b.offenders))
+	for _, offender := range b.offenders {
+		offenders = append(offenders, *offender)
+	}
+	sort.Slice(offenders, func(i, j int) bool {
+		if offenders[i].Exceeded != offenders[j].Exceeded {
+			return offenders[i].Exceeded > offenders[j].Exceeded
+		}
+		if offenders[i].Key != offenders[j].Key {
+			return offenders[i].Key < offenders[j].Key
+		}
+		return offenders[i].Source < offenders[j].Source
+	})
+	return offenders
+}
+
+// GetStats returns the configuration and the offending tag keys of the budgets, by component
+func GetStats() map[string]interface{} {
+	budgetsMutex.Lock()
+	defer budgetsMutex.Unlock()
+
+	stats := make(map[string]interface{}, len(budgets))
+	for component, b := range budgets {
+		stats[component] = map[string]interface{}{
+			"Action":       b.config.Action,
+			"DefaultLimit": b.config.DefaultLimit,
+			"Offenders":    b.Offenders(),
+		}
+	}
+	return stats
+}
@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagbudget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/murmur3"
+
+	"github.com/DataDog/datadog-agent/pkg/tagset"
+)
+
+func newTestBudget(t *testing.T, action string, now *time.Time) *Budget {
+	b, err := New("test", Config{
+		DefaultLimit: 2,
+		Limits:       map[string]int{"pod_name": 0, "request_id": 1},
+		Action:       action,
+		Window:       time.Hour,
+		HashBuckets:  4,
+	})
+	require.NoError(t, err)
+	b.now = func() time.Time { return *now }
+	b.lastSweep = *now
+	return b
+}
+
+func TestNewInvalid(t *testing.T) {
+	_, err := New("test", Config{Action: "ignore"})
+	assert.Error(t, err)
+
+	_, err = New("test", Config{Action: ActionHash})
+	assert.Error(t, err)
+}
+
+func TestBudgetDrop(t *testing.T) {
+	now := time.Now()
+	b := newTestBudget(t, ActionDrop, &now)
+
+	tb := tagset.NewHashingTagsAccumulatorWithTags([]string{"env:prod", "request_id:1", "pod_name:a", "standalone"})
+	b.Apply(tb, "dogstatsd")
+	assert.Equal(t, []string{"env:prod", "request_id:1", "pod_name:a", "standalone"}, tb.Get())
+
+	tb = tagset.NewHashingTagsAccumulatorWithTags([]string{"env:prod", "request_id:2", "pod_name:b", "env:dev"})
+	b.Apply(tb, "dogstatsd")
+	assert.Equal(t, []string{"env:prod", "pod_name:b", "env:dev"}, tb.Get())
+	assert.Equal(t, []uint64{murmur3.StringSum64("env:prod"), murmur3.StringSum64("pod_name:b"), murmur3.StringSum64("env:dev")}, tb.Hashes())
+
+	// the known values of a key exceeding its budget are kept
+	tb = tagset.NewHashingTagsAccumulatorWithTags([]string{"request_id:1", "request_id:3", "env:staging"})
+	b.Apply(tb, "my_check")
+	assert.Equal(t, []string{"request_id:1"}, tb.Get())
+
+	offenders := b.Offenders()
+	require.Len(t, offenders, 3)
+	assert.Equal(t, "env", offenders[0].Key)
+	assert.Equal(t, "my_check", offenders[0].Source)
+	assert.Equal(t, 2, offenders[0].Limit)
+	assert.Equal(t, uint64(1), offenders[0].Exceeded)
+	assert.Equal(t, "request_id", offenders[1].Key)
+	assert.Equal(t, "dogstatsd", offenders[1].Source)
+	assert.Equal(t, 1, offenders[1].Limit)
+	assert.Equal(t, "request_id", offenders[2].Key)
+	assert.Equal(t, "my_check", offenders[2].Source)
+
+	// the admitted values keep their slot while they are seen
+	now = now.Add(50 * time.Minute)
+	tb = tagset.NewHashingTagsAccumulatorWithTags([]string{"request_id:1"})
+	b.Apply(tb, "dogstatsd")
+	now = now.Add(50 * time.Minute)
+	tb = tagset.NewHashingTagsAccumulatorWithTags([]string{"request_id:1", "request_id:4"})
+	b.Apply(tb, "dogstatsd")
+	assert.Equal(t, []string{"request_id:1"}, tb.Get())
+	// the offenders that didn't exceed their budget during the last window are forgotten
+	offenders = b.Offenders()
+	require.Len(t, offenders, 1)
+	assert.Equal(t, "dogstatsd", offenders[0].Source)
+
+	// the values are forgotten once they weren't seen for a window, the offenders as well
+	now = now.Add(time.Hour + time.Minute)
+	tb = tagset.NewHashingTagsAccumulatorWithTags([]string{"request_id:4"})
+	b.Apply(tb, "dogstatsd")
+	assert.Equal(t, []string{"request_id:4"}, tb.Get())
+	assert.Empty(t, b.Offenders())
+}
+
+func TestBudgetHash(t *testing.T) {
+	now := time.Now()
+	b := newTestBudget(t, ActionHash, &now)
+
+	tags := b.ApplyToSlice([]string{"request_id:1", "env:prod"}, "tagger")
+	assert.Equal(t, []string{"request_id:1", "env:prod"}, tags)
+
+	input := []string{"request_id:2", "env:prod"}
+	tags = b.ApplyToSlice(input, "tagger")
+	require.Len(t, tags, 2)
+	assert.Regexp(t, `^request_id:hash_[0-3]$`, tags[0])
+	assert.Equal(t, "env:prod", tags[1])
+	assert.Equal(t, []string{"request_id:2", "env:prod"}, input)
+
+	// the same value is always hashed to the same bucket
+	assert.Equal(t, tags, b.ApplyToSlice([]string{"request_id:2", "env:prod"}, "tagger"))
+}
+
+func TestBudgetReport(t *testing.T) {
+	now := time.Now()
+	b := newTestBudget(t, ActionReport, &now)
+
+	input := []string{"request_id:1", "request_id:2", "request_id:3"}
+	assert.Equal(t, input, b.ApplyToSlice(input, "tagger"))
+
+	offenders := b.Offenders()
+	require.Len(t, offenders, 1)
+	assert.Equal(t, uint64(2), offenders[0].Exceeded)
+
+	stats := GetStats()["test"].(map[string]interface{})
+	assert.Equal(t, ActionReport, stats["Action"])
+	assert.Equal(t, offenders, stats["Offenders"])
+}
+
+func TestBudgetTouch(t *testing.T) {
+	now := time.Now()
+	b := newTestBudget(t, ActionDrop, &now)
+	b.lastTouch = now
+
+	assert.Equal(t, []string{"request_id:1"}, b.ApplyToSlice([]string{"request_id:1"}, "test"))
+
+	now = now.Add(10 * time.Minute)
+	assert.False(t, b.TouchDue())
+	now = now.Add(10 * time.Minute)
+	assert.True(t, b.TouchDue())
+	assert.False(t, b.TouchDue())
+
+	// only the admitted values are refreshed
+	b.Touch([]string{"request_id:1", "request_id:2", "env:prod"})
+	assert.Len(t, b.values["request_id"], 1)
+	assert.NotContains(t, b.values, "env")
+
+	// the value touched is kept past the window of its last use
+	now = now.Add(50 * time.Minute)
+	assert.Empty(t, b.ApplyToSlice([]string{"request_id:2"}, "test"))
+}
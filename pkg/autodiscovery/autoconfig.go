@@ -197,10 +197,19 @@ func (ac *AutoConfig) AddConfigProvider(provider providers.ConfigProvider, shoul
 
 // LoadAndRun loads all of the integration configs it can find
 // and schedules them. Should always be run once so providers
-// that don't need polling will be queried at least once
+// that don't need polling will be queried at least once.
+// Streaming providers start sending their changes once these
+// configs are scheduled.
 func (ac *AutoConfig) LoadAndRun() {
 	resolvedConfigs := ac.GetAllConfigs()
 	ac.schedule(resolvedConfigs)
+
+	ac.m.Lock()
+	for _, pd := range ac.providers {
+		pd.startStreaming(ac)
+	}
+	ac.m.Unlock()
+
 	atomic.StoreUint32(&ac.ranOnce, 1)
 	log.Debug("LoadAndRun done.")
 }
@@ -219,6 +228,8 @@ func (ac *AutoConfig) GetAllConfigs() []integration.Config {
 	var resolvedConfigs []integration.Config
 
 	for _, pd := range ac.providers {
+		// the configs of streaming providers are also updated as their changes are streamed
+		pd.Lock()
 		cfgs, err := pd.provider.Collect(context.TODO())
 		if err != nil {
 			log.Debugf("Unexpected error returned when collecting configurations from provider %v: %v", pd.provider, err)
@@ -251,6 +262,7 @@ func (ac *AutoConfig) GetAllConfigs() []integration.Config {
 		}
 		// Store all raw configs in the provider
 		pd.configs = cfgs
+		pd.Unlock()
 
 		// resolve configs if needed
 		for _, config := range cfgs {
@@ -296,8 +308,8 @@ func (ac *AutoConfig) processNewConfig(config integration.Config) []integration.
 		// try to resolve the template
 		resolvedConfigs := ac.resolveTemplate(config)
 		if len(resolvedConfigs) == 0 {
-			e := fmt.Sprintf("Can't resolve the template for %s at this moment.", config.Name)
-			errorStats.setResolveWarning(config.Name, e)
+			e := fmt.Sprintf("Can't resolve the template %s at this moment.", templateName(config))
+			errorStats.setResolveWarning(config, "", "", e)
 			log.Debug(e)
 			return configs
 		}
@@ -476,6 +488,8 @@ func (ac *AutoConfig) removeConfigTemplates(configs []integration.Config) {
 			removedConfigs := ac.store.removeConfigsForTemplate(tplDigest)
 			ac.processRemovedConfigs(removedConfigs)
 
+			errorStats.removeTemplateResolveWarnings(c)
+
 			// Remove template from the cache
 			err := ac.store.templateCache.Del(c)
 			if err != nil {
@@ -505,8 +519,8 @@ func (ac *AutoConfig) resolveTemplate(tpl integration.Config) []integration.Conf
 		// check out whether any service we know has this identifier
 		serviceIds, found := ac.store.getServiceEntitiesForADID(id)
 		if !found {
-			s := fmt.Sprintf("No service found with this AD identifier: %s", id)
-			errorStats.setResolveWarning(tpl.Name, s)
+			s := fmt.Sprintf("No service found with this AD identifier for template %s: %s", templateName(tpl), id)
+			errorStats.setResolveWarning(tpl, "", id, s)
 			log.Debugf(s)
 			continue
 		}
@@ -539,8 +553,8 @@ func (ac *AutoConfig) resolveTemplate(tpl integration.Config) []integration.Conf
 func (ac *AutoConfig) resolveTemplateForService(tpl integration.Config, svc listeners.Service) (integration.Config, error) {
 	config, tagsHash, err := configresolver.Resolve(tpl, svc)
 	if err != nil {
-		newErr := fmt.Errorf("error resolving template %s for service %s: %v", tpl.Name, svc.GetEntity(), err)
-		errorStats.setResolveWarning(tpl.Name, newErr.Error())
+		newErr := fmt.Errorf("error resolving template %s for service %s: %v", templateName(tpl), svc.GetEntity(), err)
+		errorStats.setResolveWarning(tpl, svc.GetEntity(), "", newErr.Error())
 		return tpl, log.Warn(newErr)
 	}
 	resolvedConfig, err := decryptConfig(config)
@@ -555,10 +569,19 @@ func (ac *AutoConfig) resolveTemplateForService(tpl integration.Config, svc list
 		svc.GetTaggerEntity(),
 		tagsHash,
 	)
-	errorStats.removeResolveWarnings(tpl.Name)
+	errorStats.removeResolveWarnings(tpl, svc.GetEntity())
 	return resolvedConfig, nil
 }
 
+// templateName returns the name of a template along with its source, if known,
+// so that users can tell apart the templates of a same check
+func templateName(tpl integration.Config) string {
+	if tpl.Source == "" {
+		return tpl.Name
+	}
+	return fmt.Sprintf("%s (%s)", tpl.Name, tpl.Source)
+}
+
 // MapOverLoadedConfigs calls the given function with the map of all
 // loaded configs.  This is done with the config store locked, so
 // callers should perform minimal work within f.
@@ -657,6 +680,7 @@ func (ac *AutoConfig) processDelService(svc listeners.Service) {
 	removedConfigs := ac.store.removeConfigsForService(svc.GetEntity())
 	ac.processRemovedConfigs(removedConfigs)
 	ac.store.removeTagsHashForService(svc.GetTaggerEntity())
+	errorStats.removeServiceResolveWarnings(svc.GetEntity())
 	// FIXME: unschedule remove services as well
 	ac.unschedule([]integration.Config{
 		{
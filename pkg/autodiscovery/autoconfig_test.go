@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -543,3 +544,42 @@ func TestSkipSecretDecrypt(t *testing.T) {
 
 	assert.True(t, mockDecrypt.haveAllScenariosNotCalled())
 }
+
+type MockStreamingProvider struct {
+	MockProvider
+	changes  chan providers.ConfigChanges
+	streamed int32
+}
+
+func (p *MockStreamingProvider) Stream(ctx context.Context) <-chan providers.ConfigChanges {
+	atomic.StoreInt32(&p.streamed, 1)
+	return p.changes
+}
+
+func TestStreamingProvider(t *testing.T) {
+	ac := NewAutoConfig(scheduler.NewMetaScheduler())
+	mp := &MockStreamingProvider{changes: make(chan providers.ConfigChanges)}
+	ac.AddConfigProvider(mp, false, 0)
+	// changes are only streamed once the initially collected configs are scheduled
+	assert.Equal(t, int32(0), atomic.LoadInt32(&mp.streamed))
+	ac.LoadAndRun()
+	defer ac.Stop()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&mp.streamed))
+
+	c1 := integration.Config{Name: "foo", Instances: []integration.Data{integration.Data("a: 1")}}
+	c2 := integration.Config{Name: "bar", Instances: []integration.Data{integration.Data("b: 2")}}
+
+	mp.changes <- providers.ConfigChanges{Schedule: []integration.Config{c1, c2}}
+	assert.Eventually(t, func() bool { return countLoadedConfigs(ac) == 2 }, time.Second, 10*time.Millisecond)
+
+	// scheduling a known config again is a noop
+	mp.changes <- providers.ConfigChanges{Schedule: []integration.Config{c1}, Unschedule: []integration.Config{c2}}
+	assert.Eventually(t, func() bool { return countLoadedConfigs(ac) == 1 }, time.Second, 10*time.Millisecond)
+
+	ac.MapOverLoadedConfigs(func(loadedConfigs map[string]integration.Config) {
+		for _, c := range loadedConfigs {
+			assert.Equal(t, "foo", c.Name)
+			assert.Equal(t, "mocked", c.Provider)
+		}
+	})
+}
@@ -10,14 +10,25 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/common/types"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 const (
-	openmetricsCheckName  = "openmetrics"
-	openmetricsInitConfig = "{}"
+	openmetricsCheckName     = "openmetrics"
+	openmetricsCoreCheckName = "openmetrics_core"
+	openmetricsInitConfig    = "{}"
 )
 
+// checkName returns the name of the check scheduled to scrape the Prometheus endpoints,
+// the core check is used instead of the Python one with prometheus_scrape.use_core_check
+func checkName() string {
+	if config.Datadog.GetBool("prometheus_scrape.use_core_check") {
+		return openmetricsCoreCheckName
+	}
+	return openmetricsCheckName
+}
+
 // buildInstances generates check config instances based on the Prometheus config and the object annotations
 // The second returned value is true if more than one instance is found
 func buildInstances(pc *types.PrometheusCheck, annotations map[string]string, namespacedName string) ([]integration.Data, bool) {
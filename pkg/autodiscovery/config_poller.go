@@ -8,6 +8,7 @@ package autodiscovery
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -18,13 +19,17 @@ import (
 
 // configPoller keeps track of the configurations loaded by a certain
 // `ConfigProvider` and whether it should be polled or not.
+// Providers implementing `StreamingConfigProvider` are never polled,
+// their changes are applied as they are streamed.
 type configPoller struct {
+	sync.Mutex
 	provider     providers.ConfigProvider
 	configs      []integration.Config
 	canPoll      bool
 	isPolling    bool
 	pollInterval time.Duration
 	stopChan     chan struct{}
+	cancelStream context.CancelFunc
 	healthHandle *health.Handle
 }
 
@@ -47,8 +52,15 @@ func (pd *configPoller) contains(c *integration.Config) bool {
 	return false
 }
 
-// stop stops the provider descriptor if it's polling
+// stop stops the provider descriptor if it's polling or streaming
 func (pd *configPoller) stop() {
+	if _, ok := pd.provider.(providers.StreamingConfigProvider); ok {
+		if pd.cancelStream != nil {
+			pd.cancelStream()
+			pd.cancelStream = nil
+		}
+		return
+	}
 	if !pd.canPoll || pd.isPolling {
 		return
 	}
@@ -56,8 +68,12 @@ func (pd *configPoller) stop() {
 	pd.isPolling = false
 }
 
-// start starts polling the provider descriptor
+// start starts polling the provider descriptor if needed.
+// Streaming providers are never polled, see startStreaming.
 func (pd *configPoller) start(ac *AutoConfig) {
+	if _, ok := pd.provider.(providers.StreamingConfigProvider); ok {
+		return
+	}
 	if !pd.canPoll {
 		return
 	}
@@ -67,6 +83,21 @@ func (pd *configPoller) start(ac *AutoConfig) {
 	go pd.poll(ac)
 }
 
+// startStreaming starts applying the changes of a streaming provider.
+// Streamed changes are relative to the configs collected initially, so this must only
+// be called once these configs are scheduled, otherwise a streamed removal could be
+// applied before the schedule of the config it removes.
+func (pd *configPoller) startStreaming(ac *AutoConfig) {
+	sp, ok := pd.provider.(providers.StreamingConfigProvider)
+	if !ok || pd.cancelStream != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	pd.cancelStream = cancel
+	pd.healthHandle = health.RegisterLiveness(fmt.Sprintf("ad-config-provider-%s", pd.provider.String()))
+	go pd.stream(ctx, ac, sp.Stream(ctx))
+}
+
 // poll polls config of the corresponding config provider
 func (pd *configPoller) poll(ac *AutoConfig) {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -116,6 +147,60 @@ func (pd *configPoller) poll(ac *AutoConfig) {
 	}
 }
 
+// stream applies the configuration changes sent by a streaming config provider
+func (pd *configPoller) stream(ctx context.Context, ac *AutoConfig, changesChan <-chan providers.ConfigChanges) {
+	defer pd.healthHandle.Deregister() //nolint:errcheck
+	for {
+		select {
+		case <-pd.healthHandle.C:
+		case <-ctx.Done():
+			return
+		case changes, ok := <-changesChan:
+			if !ok {
+				if ctx.Err() == nil {
+					log.Warnf("%v provider stopped streaming configuration changes", pd.provider)
+				}
+				return
+			}
+			pd.applyChanges(ac, changes)
+		}
+	}
+}
+
+// applyChanges schedules and unschedules the configurations of a change set,
+// ignoring the ones that are already in the expected state
+func (pd *configPoller) applyChanges(ac *AutoConfig, changes providers.ConfigChanges) {
+	pd.Lock()
+	var newConfigs, removedConfigs []integration.Config
+	for _, c := range changes.Unschedule {
+		for i := range pd.configs {
+			if pd.configs[i].Equal(&c) {
+				pd.configs = append(pd.configs[:i], pd.configs[i+1:]...)
+				removedConfigs = append(removedConfigs, c)
+				break
+			}
+		}
+	}
+	for _, c := range changes.Schedule {
+		if !pd.contains(&c) {
+			pd.configs = append(pd.configs, c)
+			newConfigs = append(newConfigs, c)
+		}
+	}
+	pd.Unlock()
+
+	log.Infof("%v provider: streamed %d new configurations, removed %d", pd.provider, len(newConfigs), len(removedConfigs))
+
+	ac.processRemovedConfigs(removedConfigs)
+	ac.removeConfigTemplates(removedConfigs)
+
+	for _, config := range newConfigs {
+		config.Provider = pd.provider.String()
+		resolvedConfigs := ac.processNewConfig(config)
+		ac.schedule(resolvedConfigs)
+	}
+}
+
 // collect is just a convenient wrapper to fetch configurations from a provider and
 // see what changed from the last time we called Collect().
 func (pd *configPoller) collect(ctx context.Context) ([]integration.Config, []integration.Config) {
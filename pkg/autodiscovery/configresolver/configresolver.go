@@ -11,7 +11,11 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
+	"strings"
+
+	"go.uber.org/multierr"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
@@ -24,12 +28,46 @@ import (
 type variableGetter func(ctx context.Context, key []byte, svc listeners.Service) ([]byte, error)
 
 var templateVariables = map[string]variableGetter{
-	"host":     getHost,
-	"pid":      getPid,
-	"port":     getPort,
-	"hostname": getHostname,
-	"extra":    getAdditionalTplVariables,
-	"kube":     getAdditionalTplVariables,
+	"host":       getHost,
+	"pid":        getPid,
+	"port":       getPort,
+	"hostname":   getHostname,
+	"extra":      getAdditionalTplVariables,
+	"kube":       getAdditionalTplVariables,
+	"label":      getLabel,
+	"annotation": getAnnotation,
+	"image":      getImage,
+}
+
+type filterFunc func(value []byte, arg []byte) []byte
+
+// templateFilters transform the value of a template variable, they are applied
+// in order after the variable is resolved: %%env_FOO|default:bar|lower%%.
+// The default filter is handled separately as it applies when the variable
+// cannot be resolved.
+var templateFilters = map[string]filterFunc{
+	"lower": func(value, _ []byte) []byte { return bytes.ToLower(value) },
+	"upper": func(value, _ []byte) []byte { return bytes.ToUpper(value) },
+	"trim":  func(value, _ []byte) []byte { return bytes.TrimSpace(value) },
+}
+
+const defaultFilter = "default"
+
+// TemplateVarError is returned when a template variable can't be resolved,
+// it holds the raw variable so that users can find it in their template
+type TemplateVarError struct {
+	Var string
+	Err error
+}
+
+// Error implements the error interface
+func (e *TemplateVarError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Var, e.Err)
+}
+
+// Unwrap returns the error encountered while resolving the variable
+func (e *TemplateVarError) Unwrap() error {
+	return e.Err
 }
 
 // SubstituteTemplateEnvVars replaces %%ENV_VARIABLE%% from environment
@@ -125,18 +163,18 @@ func Resolve(tpl integration.Config, svc listeners.Service) (integration.Config,
 
 // substituteTemplateVariables replaces %%VARIABLES%% in the config init,
 // instances, and logs config.
-// When there is an error, it stops processing.
+// When a variable can't be resolved, it keeps processing the other ones and
+// returns the errors of all the unresolved variables.
 func substituteTemplateVariables(ctx context.Context, config *integration.Config, svc listeners.Service) error {
-	var err error
+	var errs error
 
 	for _, toResolve := range dataToResolve(config) {
+		var err error
 		*toResolve, err = resolveDataWithTemplateVars(ctx, *toResolve, svc)
-		if err != nil {
-			return err
-		}
+		errs = multierr.Append(errs, err)
 	}
 
-	return nil
+	return errs
 }
 
 func dataToResolve(config *integration.Config) []*integration.Data {
@@ -154,20 +192,23 @@ func dataToResolve(config *integration.Config) []*integration.Data {
 }
 
 func resolveDataWithTemplateVars(ctx context.Context, data integration.Data, svc listeners.Service) ([]byte, error) {
+	var errs error
 	res := append([]byte(nil), data...)
 
 	templateVars := tmplvar.Parse(data)
 	for _, tVar := range templateVars {
 		if f, found := templateVariables[string(tVar.Name)]; found {
 			resolvedVar, err := f(ctx, tVar.Key, svc)
+			resolvedVar, err = applyFilters(resolvedVar, err, tVar.Filters)
 			if err != nil {
-				return res, err
+				errs = multierr.Append(errs, &TemplateVarError{Var: string(tVar.Raw), Err: err})
+				continue
 			}
 			res = bytes.Replace(res, tVar.Raw, resolvedVar, -1)
 		}
 	}
 
-	return res, nil
+	return res, errs
 }
 
 func resolveDataWithEnvs(data integration.Data) ([]byte, error) {
@@ -178,10 +219,11 @@ func resolveDataWithEnvs(data integration.Data) ([]byte, error) {
 	for _, tVar := range templateVars {
 		if "env" == string(tVar.Name) {
 			resolvedVar, err := getEnvvar(tVar.Key)
+			resolvedVar, err = applyFilters(resolvedVar, err, tVar.Filters)
 			if err != nil {
 				log.Warnf("variable not replaced: %s", err)
 				if retErr == nil {
-					retErr = err
+					retErr = &TemplateVarError{Var: string(tVar.Raw), Err: err}
 				}
 				continue
 			}
@@ -192,6 +234,39 @@ func resolveDataWithEnvs(data integration.Data) ([]byte, error) {
 	return res, retErr
 }
 
+// applyFilters applies the filters of a template variable to its resolved value.
+// The default filter replaces the value if it couldn't be resolved, the other
+// filters are skipped until a value is available.
+func applyFilters(value []byte, err error, filters []tmplvar.Filter) ([]byte, error) {
+	for _, filter := range filters {
+		name := string(filter.Name)
+		if name == defaultFilter {
+			if err != nil {
+				value, err = append([]byte(nil), filter.Arg...), nil
+			}
+			continue
+		}
+
+		f, found := templateFilters[name]
+		if !found {
+			return nil, fmt.Errorf("unknown filter %q, supported filters are: %s", name, supportedFilters())
+		}
+		if err == nil {
+			value = f(value, filter.Arg)
+		}
+	}
+	return value, err
+}
+
+func supportedFilters() string {
+	filters := []string{defaultFilter}
+	for name := range templateFilters {
+		filters = append(filters, name)
+	}
+	sort.Strings(filters)
+	return strings.Join(filters, ", ")
+}
+
 func addServiceTags(resolvedConfig *integration.Config, tags []string) error {
 	for i := 0; i < len(resolvedConfig.Instances); i++ {
 		if err := resolvedConfig.Instances[i].MergeAdditionalTags(tags); err != nil {
@@ -261,13 +336,20 @@ func getPort(ctx context.Context, tplVar []byte, svc listeners.Service) ([]byte,
 
 	idx, err := strconv.Atoi(string(tplVar))
 	if err != nil {
-		// The template variable is not an index so try to lookup port by name.
-		for _, port := range ports {
-			if port.Name == string(tplVar) {
-				return []byte(strconv.Itoa(port.Port)), nil
+		// The template variable is not an index so try to lookup port by name,
+		// the name can be given explicitly with %%port_named_<name>%%
+		names := []string{string(tplVar)}
+		if name := bytes.TrimPrefix(tplVar, []byte("named_")); len(name) < len(tplVar) {
+			names = append(names, string(name))
+		}
+		for _, name := range names {
+			for _, port := range ports {
+				if port.Name == name {
+					return []byte(strconv.Itoa(port.Port)), nil
+				}
 			}
 		}
-		return nil, fmt.Errorf("port %s not found, skipping container %s", string(tplVar), svc.GetEntity())
+		return nil, fmt.Errorf("port %s not found, skipping container %s", names[len(names)-1], svc.GetEntity())
 	}
 	if len(ports) <= idx {
 		return nil, fmt.Errorf("index given for the port template var is too big, skipping container %s", svc.GetEntity())
@@ -307,6 +389,73 @@ func getAdditionalTplVariables(_ context.Context, tplVar []byte, svc listeners.S
 	return value, nil
 }
 
+// getLabel returns the value of a label of the service's container or pod
+func getLabel(ctx context.Context, tplVar []byte, svc listeners.Service) ([]byte, error) {
+	msvc, ok := svc.(listeners.MetadataService)
+	if !ok {
+		return nil, fmt.Errorf("labels are not supported for service %s", svc.GetEntity())
+	}
+	labels, err := msvc.GetLabels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get labels for service %s, skipping config - %s", svc.GetEntity(), err)
+	}
+	return lookupMetadata("label", tplVar, labels, svc)
+}
+
+// getAnnotation returns the value of an annotation of the service's pod
+func getAnnotation(ctx context.Context, tplVar []byte, svc listeners.Service) ([]byte, error) {
+	msvc, ok := svc.(listeners.MetadataService)
+	if !ok {
+		return nil, fmt.Errorf("annotations are not supported for service %s", svc.GetEntity())
+	}
+	annotations, err := msvc.GetAnnotations(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get annotations for service %s, skipping config - %s", svc.GetEntity(), err)
+	}
+	return lookupMetadata("annotation", tplVar, annotations, svc)
+}
+
+func lookupMetadata(kind string, tplVar []byte, metadata map[string]string, svc listeners.Service) ([]byte, error) {
+	if len(tplVar) == 0 {
+		return nil, fmt.Errorf("%s name is missing", kind)
+	}
+	value, found := metadata[string(tplVar)]
+	if !found {
+		return nil, fmt.Errorf("%s %s not found, skipping service %s", kind, tplVar, svc.GetEntity())
+	}
+	return []byte(value), nil
+}
+
+// getImage returns the name, short name or tag of the image of the service's container
+func getImage(ctx context.Context, tplVar []byte, svc listeners.Service) ([]byte, error) {
+	msvc, ok := svc.(listeners.MetadataService)
+	if !ok {
+		return nil, fmt.Errorf("images are not supported for service %s", svc.GetEntity())
+	}
+	image, err := msvc.GetImage(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get image for service %s, skipping config - %s", svc.GetEntity(), err)
+	}
+
+	var value string
+	switch string(tplVar) {
+	case "", "name":
+		value = image.Name
+	case "short_name":
+		value = image.ShortName
+	case "tag":
+		value = image.Tag
+	case "raw_name":
+		value = image.RawName
+	default:
+		return nil, fmt.Errorf("image attribute %q is not supported, use one of name, short_name, tag or raw_name", tplVar)
+	}
+	if value == "" {
+		return nil, fmt.Errorf("image %s is not known for service %s", tplVar, svc.GetEntity())
+	}
+	return []byte(value), nil
+}
+
 // getEnvvar returns a system environment variable if found
 func getEnvvar(envVar []byte) ([]byte, error) {
 	if len(envVar) == 0 {
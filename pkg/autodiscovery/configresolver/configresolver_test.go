@@ -7,6 +7,7 @@ package configresolver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -240,7 +241,7 @@ func TestResolve(t *testing.T) {
 				Instances:     []integration.Data{integration.Data("host: %%host%%")},
 				Entity:        "a5901276aed1",
 			},
-			errorString: "no network found for container a5901276aed1, ignoring it",
+			errorString: "%%host%%: no network found for container a5901276aed1, ignoring it",
 		},
 		//// %%port%% tag testing
 		{
@@ -312,7 +313,7 @@ func TestResolve(t *testing.T) {
 				ADIdentifiers: []string{"redis"},
 				Instances:     []integration.Data{integration.Data("port: %%port_qux%%")},
 			},
-			errorString: "port qux not found, skipping container a5901276aed1",
+			errorString: "%%port_qux%%: port qux not found, skipping container a5901276aed1",
 		},
 		{
 			testName: "%%port_4%% too high, error",
@@ -326,7 +327,7 @@ func TestResolve(t *testing.T) {
 				ADIdentifiers: []string{"redis"},
 				Instances:     []integration.Data{integration.Data("port: %%port_4%%")},
 			},
-			errorString: "index given for the port template var is too big, skipping container a5901276aed1",
+			errorString: "%%port_4%%: index given for the port template var is too big, skipping container a5901276aed1",
 		},
 		{
 			testName: "%%port%% but no port in service, error",
@@ -340,7 +341,7 @@ func TestResolve(t *testing.T) {
 				ADIdentifiers: []string{"redis"},
 				Instances:     []integration.Data{integration.Data("port: %%port%%")},
 			},
-			errorString: "no port found for container a5901276aed1 - ignoring it",
+			errorString: "%%port%%: no port found for container a5901276aed1 - ignoring it",
 		},
 		//// logs config
 		{
@@ -378,7 +379,7 @@ func TestResolve(t *testing.T) {
 				LogsConfig:    integration.Data("host: %%host%%"),
 				Entity:        "a5901276aed1",
 			},
-			errorString: "no network found for container a5901276aed1, ignoring it",
+			errorString: "%%host%%: no network found for container a5901276aed1, ignoring it",
 		},
 		//// envvars (metrics check)
 		{
@@ -412,7 +413,7 @@ func TestResolve(t *testing.T) {
 				ADIdentifiers: []string{"redis"},
 				Instances:     []integration.Data{integration.Data("test: %%env_test_envvar_not_set%%")},
 			},
-			errorString: "failed to retrieve envvar test_envvar_not_set, skipping service a5901276aed1"},
+			errorString: "%%env_test_envvar_not_set%%: failed to retrieve envvar test_envvar_not_set, skipping service a5901276aed1"},
 		{
 			testName: "invalid %%env%% (metrics check)",
 			svc: &dummyService{
@@ -425,7 +426,7 @@ func TestResolve(t *testing.T) {
 				ADIdentifiers: []string{"redis"},
 				Instances:     []integration.Data{integration.Data("test: %%env%%")},
 			},
-			errorString: "envvar name is missing, skipping service a5901276aed1",
+			errorString: "%%env%%: envvar name is missing, skipping service a5901276aed1",
 		},
 		//// envvars (logs check)
 		{
@@ -462,7 +463,7 @@ func TestResolve(t *testing.T) {
 				Instances:     []integration.Data{},
 				LogsConfig:    integration.Data("test: %%env_test_envvar_not_set%%"),
 			},
-			errorString: "failed to retrieve envvar test_envvar_not_set, skipping service a5901276aed1"},
+			errorString: "%%env_test_envvar_not_set%%: failed to retrieve envvar test_envvar_not_set, skipping service a5901276aed1"},
 		{
 			testName: "invalid %%env%% (logs check)",
 			svc: &dummyService{
@@ -476,7 +477,7 @@ func TestResolve(t *testing.T) {
 				Instances:     []integration.Data{},
 				LogsConfig:    integration.Data("test: %%env%%"),
 			},
-			errorString: "envvar name is missing, skipping service a5901276aed1",
+			errorString: "%%env%%: envvar name is missing, skipping service a5901276aed1",
 		},
 		//// hostname
 		{
@@ -733,3 +734,120 @@ func newFakeContainerPorts() []listeners.ContainerPort {
 		{Port: 3, Name: "baz"},
 	}
 }
+
+type dummyMetadataService struct {
+	dummyService
+	Image       listeners.ContainerImage
+	Labels      map[string]string
+	Annotations map[string]string
+}
+
+// GetImage returns a dummy image
+func (s *dummyMetadataService) GetImage(context.Context) (listeners.ContainerImage, error) {
+	return s.Image, nil
+}
+
+// GetLabels returns dummy labels
+func (s *dummyMetadataService) GetLabels(context.Context) (map[string]string, error) {
+	return s.Labels, nil
+}
+
+// GetAnnotations returns dummy annotations
+func (s *dummyMetadataService) GetAnnotations(context.Context) (map[string]string, error) {
+	return s.Annotations, nil
+}
+
+func TestResolveTemplateExpressions(t *testing.T) {
+	err := os.Setenv("test_envvar_upper", "VALUE")
+	require.NoError(t, err)
+	defer os.Unsetenv("test_envvar_upper")
+
+	svc := &dummyMetadataService{
+		dummyService: dummyService{
+			ID:            "a5901276aed1",
+			ADIdentifiers: []string{"redis"},
+			Ports:         newFakeContainerPorts(),
+		},
+		Image:       listeners.ContainerImage{RawName: "gcr.io/redis:6.2", Name: "gcr.io/redis", ShortName: "redis", Tag: "6.2"},
+		Labels:      map[string]string{"app": "cache", "app.kubernetes.io/version": "v1"},
+		Annotations: map[string]string{"team": "Storage"},
+	}
+
+	testCases := []struct {
+		testName    string
+		svc         listeners.Service
+		instance    string
+		out         string
+		errorString string
+	}{
+		{
+			testName: "named port",
+			svc:      svc,
+			instance: "port: %%port_named_bar%%",
+			out:      "port: 2",
+		},
+		{
+			testName: "missing named port with default",
+			svc:      svc,
+			instance: "port: %%port_named_http|default:8080%%",
+			out:      "port: 8080",
+		},
+		{
+			testName: "labels, annotations and image",
+			svc:      svc,
+			instance: "app: %%label_app%%\nversion: %%label_app.kubernetes.io/version%%\nteam: %%annotation_team|lower%%\nimage: %%image_short_name%%:%%image_tag%%",
+			out:      "app: cache\nversion: v1\nteam: storage\nimage: redis:6.2",
+		},
+		{
+			testName: "env with filters",
+			svc:      svc,
+			instance: "a: %%env_test_envvar_upper|lower%%\nb: %%env_test_envvar_not_set|default:FOO|lower%%",
+			out:      "a: value\nb: foo",
+		},
+		{
+			testName:    "all unresolved variables are reported",
+			svc:         svc,
+			instance:    "a: %%label_missing%%\nb: %%image_digest%%",
+			errorString: "%%label_missing%%: label missing not found, skipping service a5901276aed1; %%image_digest%%: image attribute \"digest\" is not supported, use one of name, short_name, tag or raw_name",
+		},
+		{
+			testName:    "unknown filter",
+			svc:         svc,
+			instance:    "a: %%label_app|reverse%%",
+			errorString: "%%label_app|reverse%%: unknown filter \"reverse\", supported filters are: default, lower, trim, upper",
+		},
+		{
+			testName:    "labels not supported by the service",
+			svc:         &svc.dummyService,
+			instance:    "a: %%label_app%%",
+			errorString: "%%label_app%%: labels are not supported for service a5901276aed1",
+		},
+		{
+			testName: "labels not supported by the service with default",
+			svc:      &svc.dummyService,
+			instance: "a: %%label_app|default:none%%",
+			out:      "a: none",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("case %d: %s", i, tc.testName), func(t *testing.T) {
+			tpl := integration.Config{
+				Name:                    "redis",
+				ADIdentifiers:           []string{"redis"},
+				Instances:               []integration.Data{integration.Data(tc.instance)},
+				IgnoreAutodiscoveryTags: true,
+			}
+			cfg, _, err := Resolve(tpl, tc.svc)
+			if tc.errorString != "" {
+				assert.EqualError(t, err, tc.errorString)
+
+				var tplErr *TemplateVarError
+				assert.True(t, errors.As(err, &tplErr))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.out, string(cfg.Instances[0]))
+			}
+		})
+	}
+}
@@ -67,6 +67,8 @@ type CommonInstanceConfig struct {
 	Service               string   `yaml:"service"`
 	Name                  string   `yaml:"name"`
 	Namespace             string   `yaml:"namespace"`
+	Priority              string   `yaml:"priority"`
+	RunTimeout            int      `yaml:"run_timeout"`
 }
 
 // CommonGlobalConfig holds the reserved fields for the yaml init_config data
@@ -97,6 +97,8 @@ func (l *ContainerListener) createContainerService(
 		ports:    ports,
 		pid:      container.PID,
 		hostname: container.Hostname,
+		image:    newContainerImage(containerImg),
+		labels:   container.Labels,
 	}
 
 	if findKubernetesInLabels(container.Labels) {
@@ -104,6 +106,7 @@ func (l *ContainerListener) createContainerService(
 		if err == nil {
 			svc.hosts = map[string]string{"pod": pod.IP}
 			svc.ready = pod.Ready
+			svc.annotations = pod.Annotations
 		} else {
 			log.Debugf("container %q belongs to a pod but was not found: %s", container.ID, err)
 		}
@@ -82,6 +82,7 @@ func TestCreateContainerService(t *testing.T) {
 						},
 						hosts:        map[string]string{},
 						creationTime: integration.After,
+						image:        &ContainerImage{RawName: "gcr.io/foobar:latest", ShortName: "foobar"},
 						ports:        []ContainerPort{},
 						ready:        true,
 					},
@@ -124,6 +125,7 @@ func TestCreateContainerService(t *testing.T) {
 							},
 						},
 						creationTime: integration.After,
+						image:        &ContainerImage{RawName: "foobar", ShortName: "foobar"},
 						ready:        true,
 					},
 				},
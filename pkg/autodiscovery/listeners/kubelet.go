@@ -108,6 +108,8 @@ func (l *KubeletListener) createPodService(
 		ports:         ports,
 		creationTime:  creationTime,
 		ready:         true,
+		labels:        pod.Labels,
+		annotations:   pod.Annotations,
 	}
 
 	svcID := buildSvcID(pod.GetID())
@@ -169,7 +171,10 @@ func (l *KubeletListener) createContainerService(
 			"namespace": pod.Namespace,
 			"pod_uid":   pod.ID,
 		},
-		hosts: map[string]string{"pod": pod.IP},
+		hosts:       map[string]string{"pod": pod.IP},
+		image:       newContainerImage(containerImg),
+		labels:      pod.Labels,
+		annotations: pod.Annotations,
 
 		// Exclude non-running containers (including init containers)
 		// from metrics collection but keep them for collecting logs.
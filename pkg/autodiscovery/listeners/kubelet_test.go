@@ -232,6 +232,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 						},
 						ports:        []ContainerPort{},
 						creationTime: integration.After,
+						image:        &ContainerImage{RawName: "gcr.io/foobar:latest", ShortName: "foobar"},
 						extraConfig: map[string]string{
 							"namespace": podNamespace,
 							"pod_name":  podName,
@@ -264,6 +265,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 						},
 						ports:           []ContainerPort{},
 						creationTime:    integration.After,
+						image:           &ContainerImage{RawName: "foobar", ShortName: "foobar"},
 						metricsExcluded: true,
 						extraConfig: map[string]string{
 							"namespace": podNamespace,
@@ -325,6 +327,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 							},
 						},
 						creationTime: integration.After,
+						image:        &ContainerImage{RawName: "foobar", ShortName: "foobar"},
 						extraConfig: map[string]string{
 							"namespace": podNamespace,
 							"pod_name":  podName,
@@ -358,6 +361,8 @@ func TestKubeletCreateContainerService(t *testing.T) {
 						},
 						ports:        []ContainerPort{},
 						creationTime: integration.After,
+						image:        &ContainerImage{RawName: "foobar", ShortName: "foobar"},
+						annotations:  podWithAnnotations.Annotations,
 						checkNames:   []string{"customcheck"},
 						extraConfig: map[string]string{
 							"namespace": podNamespace,
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !serverless
+
+package listeners
+
+import (
+	"reflect"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	processNameADPrefix = "process_name://"
+	systemdUnitADPrefix = "systemd_unit://"
+	processHost         = "127.0.0.1"
+)
+
+func init() {
+	Register("process", NewProcessListener)
+}
+
+// ProcessListener listens to the processes of the host listening on a port
+// through a subscription to the workloadmeta store.
+type ProcessListener struct {
+	workloadmetaListener
+}
+
+// NewProcessListener returns a new ProcessListener.
+func NewProcessListener() (ServiceListener, error) {
+	const name = "ad-processlistener"
+	l := &ProcessListener{}
+	f := workloadmeta.NewFilter(
+		[]workloadmeta.Kind{workloadmeta.KindProcess},
+		[]workloadmeta.Source{workloadmeta.SourceProcess},
+	)
+
+	var err error
+	l.workloadmetaListener, err = newWorkloadmetaListener(name, f, l.createProcessService)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *ProcessListener) createProcessService(
+	entity workloadmeta.Entity,
+	creationTime integration.CreationTime,
+) {
+	process := entity.(*workloadmeta.Process)
+
+	// containerized processes are discovered through their container
+	if process.ContainerID != "" || len(process.ListeningPorts) == 0 {
+		return
+	}
+
+	// the workers of a server inherit the listening sockets of their parent,
+	// only the parent is discovered
+	if parent, err := l.Store().GetProcess(process.PPID); err == nil && reflect.DeepEqual(parent.ListeningPorts, process.ListeningPorts) {
+		log.Debugf("process %d shares the ports of its parent %d, skipping", process.PID, process.PPID)
+		return
+	}
+
+	ports := make([]ContainerPort, 0, len(process.ListeningPorts))
+	for _, port := range process.ListeningPorts {
+		// a port can be listened on for TCP and UDP
+		if len(ports) > 0 && ports[len(ports)-1].Port == port.Port {
+			continue
+		}
+		ports = append(ports, ContainerPort{
+			Port: port.Port,
+			Name: port.Name,
+		})
+	}
+
+	svc := &service{
+		entity:        process,
+		creationTime:  creationTime,
+		adIdentifiers: computeProcessServiceIDs(process),
+		hosts:         map[string]string{"host": processHost},
+		ports:         ports,
+		pid:           process.PID,
+		ready:         true,
+	}
+
+	svcID := buildSvcID(process.GetID())
+	l.AddService(svcID, svc, "")
+}
+
+// computeProcessServiceIDs returns the AD identifiers of a process: its name
+// and the systemd unit it belongs to, if any
+func computeProcessServiceIDs(process *workloadmeta.Process) []string {
+	ids := []string{processNameADPrefix + process.Name}
+	if process.SystemdUnit != "" {
+		ids = append(ids, systemdUnitADPrefix+process.SystemdUnit)
+	}
+	return ids
+}
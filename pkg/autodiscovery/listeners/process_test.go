@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !serverless
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestCreateProcessService(t *testing.T) {
+	listeningPorts := []workloadmeta.ContainerPort{
+		{Port: 80, Protocol: "TCP"},
+		{Port: 443, Protocol: "TCP"},
+		{Port: 443, Protocol: "UDP"},
+	}
+
+	master := &workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindProcess,
+			ID:   "100",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "nginx",
+		},
+		PID:            100,
+		PPID:           1,
+		SystemdUnit:    "nginx.service",
+		ListeningPorts: listeningPorts,
+	}
+
+	worker := &workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindProcess,
+			ID:   "101",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "nginx",
+		},
+		PID:            101,
+		PPID:           100,
+		SystemdUnit:    "nginx.service",
+		ListeningPorts: listeningPorts,
+	}
+
+	tests := []struct {
+		name             string
+		process          *workloadmeta.Process
+		expectedServices map[string]wlmListenerSvc
+	}{
+		{
+			name:    "process listening on ports",
+			process: master,
+			expectedServices: map[string]wlmListenerSvc{
+				"process://100": {
+					service: &service{
+						entity: master,
+						adIdentifiers: []string{
+							"process_name://nginx",
+							"systemd_unit://nginx.service",
+						},
+						hosts: map[string]string{"host": "127.0.0.1"},
+						ports: []ContainerPort{
+							{Port: 80},
+							{Port: 443},
+						},
+						pid:          100,
+						creationTime: integration.After,
+						ready:        true,
+					},
+				},
+			},
+		},
+		{
+			name:             "worker sharing the ports of its parent",
+			process:          worker,
+			expectedServices: map[string]wlmListenerSvc{},
+		},
+		{
+			name: "process without listening ports",
+			process: &workloadmeta.Process{
+				EntityID: workloadmeta.EntityID{
+					Kind: workloadmeta.KindProcess,
+					ID:   "200",
+				},
+				PID: 200,
+			},
+			expectedServices: map[string]wlmListenerSvc{},
+		},
+		{
+			name: "containerized process",
+			process: &workloadmeta.Process{
+				EntityID: workloadmeta.EntityID{
+					Kind: workloadmeta.KindProcess,
+					ID:   "300",
+				},
+				PID:            300,
+				ContainerID:    containerID,
+				ListeningPorts: listeningPorts,
+			},
+			expectedServices: map[string]wlmListenerSvc{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, wlm := newProcessListener(t)
+			wlm.store.Set(master)
+
+			listener.createProcessService(tt.process, integration.After)
+
+			wlm.assertServices(tt.expectedServices)
+		})
+	}
+}
+
+func newProcessListener(t *testing.T) (*ProcessListener, *testWorkloadmetaListener) {
+	wlm := newTestWorkloadmetaListener(t)
+
+	return &ProcessListener{workloadmetaListener: wlm}, wlm
+}
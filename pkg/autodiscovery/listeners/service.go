@@ -26,11 +26,15 @@ type service struct {
 	ready           bool
 	checkNames      []string
 	extraConfig     map[string]string
+	image           *ContainerImage
+	labels          map[string]string
+	annotations     map[string]string
 	metricsExcluded bool
 	logsExcluded    bool
 }
 
 var _ Service = &service{}
+var _ MetadataService = &service{}
 
 // GetEntity returns the AD entity ID of the service.
 func (s *service) GetEntity() string {
@@ -39,6 +43,8 @@ func (s *service) GetEntity() string {
 		return containers.BuildEntityName(string(e.Runtime), e.ID)
 	case *workloadmeta.KubernetesPod:
 		return kubelet.PodUIDToEntityName(e.ID)
+	case *workloadmeta.Process:
+		return containers.BuildEntityName(string(e.Kind), e.ID)
 	default:
 		entityID := s.entity.GetID()
 		log.Errorf("cannot build AD entity ID for kind %q, ID %q", entityID.Kind, entityID.ID)
@@ -53,6 +59,8 @@ func (s *service) GetTaggerEntity() string {
 		return containers.BuildTaggerEntityName(e.ID)
 	case *workloadmeta.KubernetesPod:
 		return kubelet.PodUIDToTaggerEntityName(e.ID)
+	case *workloadmeta.Process:
+		return containers.BuildEntityName(string(e.Kind), e.ID)
 	default:
 		entityID := s.entity.GetID()
 		log.Errorf("cannot build AD entity ID for kind %q, ID %q", entityID.Kind, entityID.ID)
@@ -129,6 +137,36 @@ func (s *service) GetExtraConfig(key []byte) ([]byte, error) {
 	return []byte(result), nil
 }
 
+// GetImage returns the image of the service's container.
+func (s *service) GetImage(_ context.Context) (ContainerImage, error) {
+	if s.image == nil {
+		return ContainerImage{}, ErrNotSupported
+	}
+	return *s.image, nil
+}
+
+// GetLabels returns the labels of the service's container or pod.
+func (s *service) GetLabels(_ context.Context) (map[string]string, error) {
+	return s.labels, nil
+}
+
+// GetAnnotations returns the annotations of the service's pod.
+func (s *service) GetAnnotations(_ context.Context) (map[string]string, error) {
+	if s.annotations == nil {
+		return nil, ErrNotSupported
+	}
+	return s.annotations, nil
+}
+
+func newContainerImage(image workloadmeta.ContainerImage) *ContainerImage {
+	return &ContainerImage{
+		RawName:   image.RawName,
+		Name:      image.Name,
+		ShortName: image.ShortName,
+		Tag:       image.Tag,
+	}
+}
+
 // svcEqual checks that two Services are equal to each other by doing a deep
 // equality check on data returned by most of Service's methods. Methods not
 // checked are HasFilter and GetExtraConfig.
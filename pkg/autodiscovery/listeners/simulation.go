@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !serverless
+
+package listeners
+
+import (
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// simulationListener is a workloadmetaListener that records the services it's
+// given instead of sending them to autodiscovery.
+type simulationListener struct {
+	store    workloadmeta.Store
+	filters  *containerFilters
+	services map[string]Service
+}
+
+var _ workloadmetaListener = &simulationListener{}
+
+func (l *simulationListener) Listen(newSvc chan<- Service, delSvc chan<- Service) {}
+
+func (l *simulationListener) Stop() {}
+
+func (l *simulationListener) Store() workloadmeta.Store {
+	return l.store
+}
+
+func (l *simulationListener) AddService(svcID string, svc Service, parentSvcID string) {
+	l.services[svcID] = svc
+}
+
+func (l *simulationListener) IsExcluded(ft containers.FilterType, name, image, ns string) bool {
+	return l.filters.IsExcluded(ft, name, image, ns)
+}
+
+// SimulateServices returns the services the kubelet and container listeners
+// create for the given pods and containers of a store, without subscribing to
+// it. The containers of the pods are handled by the kubelet listener, the
+// other containers by the container listener.
+func SimulateServices(store workloadmeta.Store, pods []*workloadmeta.KubernetesPod, containers []*workloadmeta.Container) ([]Service, error) {
+	filters, err := newContainerFilters()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &simulationListener{
+		store:    store,
+		filters:  filters,
+		services: make(map[string]Service),
+	}
+	kubeletListener := &KubeletListener{workloadmetaListener: l}
+	containerListener := &ContainerListener{workloadmetaListener: l}
+
+	podContainers := make(map[string]struct{})
+	for _, pod := range pods {
+		for _, podContainer := range pod.Containers {
+			podContainers[podContainer.ID] = struct{}{}
+		}
+		kubeletListener.processPod(pod, integration.Before)
+	}
+
+	for _, container := range containers {
+		if _, found := podContainers[container.ID]; found {
+			continue
+		}
+		containerListener.createContainerService(container, integration.Before)
+	}
+
+	svcIDs := make([]string, 0, len(l.services))
+	for svcID := range l.services {
+		svcIDs = append(svcIDs, svcID)
+	}
+	sort.Strings(svcIDs)
+
+	services := make([]Service, 0, len(svcIDs))
+	for _, svcID := range svcIDs {
+		services = append(services, l.services[svcID])
+	}
+
+	return services, nil
+}
@@ -20,6 +20,14 @@ type ContainerPort struct {
 	Name string
 }
 
+// ContainerImage represents the image a Service runs.
+type ContainerImage struct {
+	RawName   string
+	Name      string
+	ShortName string
+	Tag       string
+}
+
 // Service represents an application we can run a check against.
 // It should be matched with a check template by the ConfigResolver using the
 // ADIdentifiers field.
@@ -39,6 +47,15 @@ type Service interface {
 	GetExtraConfig([]byte) ([]byte, error)               // Extra configuration values
 }
 
+// MetadataService is implemented by services that expose the image, labels
+// and annotations of their workload, used to resolve the %%image_*%%,
+// %%label_*%% and %%annotation_*%% template variables.
+type MetadataService interface {
+	GetImage(context.Context) (ContainerImage, error)          // image of the container
+	GetLabels(context.Context) (map[string]string, error)      // container or pod labels
+	GetAnnotations(context.Context) (map[string]string, error) // pod annotations
+}
+
 // ServiceListener monitors running services and triggers check (un)scheduling
 //
 // It holds a cache of running services, listens to new/killed services and
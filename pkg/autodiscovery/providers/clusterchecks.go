@@ -15,6 +15,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/clusteragent"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/hostinfo"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -29,6 +30,7 @@ type ClusterChecksConfigProvider struct {
 	lastChange     int64
 	identifier     string
 	flushedConfigs bool
+	nodeLabels     map[string]string
 }
 
 // NewClusterChecksConfigProvider returns a new ConfigProvider collecting
@@ -91,6 +93,7 @@ func (c *ClusterChecksConfigProvider) IsUpToDate(ctx context.Context) (bool, err
 
 	status := types.NodeStatus{
 		LastChange: c.lastChange,
+		Labels:     c.getNodeLabels(ctx),
 	}
 
 	reply, err := c.dcaClient.PostClusterCheckStatus(ctx, c.identifier, status)
@@ -113,6 +116,23 @@ func (c *ClusterChecksConfigProvider) IsUpToDate(ctx context.Context) (bool, err
 	return reply.IsUpToDate, nil
 }
 
+// getNodeLabels returns the labels of the node, used by the cluster-agent
+// to apply the dispatching constraints of the checks
+func (c *ClusterChecksConfigProvider) getNodeLabels(ctx context.Context) map[string]string {
+	if c.nodeLabels != nil || !config.IsFeaturePresent(config.Kubernetes) {
+		return c.nodeLabels
+	}
+
+	labels, err := hostinfo.GetNodeLabels(ctx)
+	if err != nil {
+		log.Debugf("Cannot get the node labels, will retry later: %v", err)
+		return nil
+	}
+
+	c.nodeLabels = labels
+	return c.nodeLabels
+}
+
 // Collect retrieves configurations the cluster-agent dispatched to this agent
 func (c *ClusterChecksConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
 	if c.dcaClient == nil {
@@ -35,6 +35,7 @@ type ContainerConfigProvider struct {
 	streaming         bool
 	containerCache    map[string]*workloadmeta.Container
 	containerFilter   *containers.Filter
+	configErrors      map[string]ErrorMsgSet
 	once              sync.Once
 }
 
@@ -64,11 +65,9 @@ func (d *ContainerConfigProvider) Collect(ctx context.Context) ([]integration.Co
 	})
 
 	d.Lock()
+	defer d.Unlock()
 	d.upToDate = true
-	d.Unlock()
 
-	d.RLock()
-	defer d.RUnlock()
 	return d.generateConfigs()
 }
 
@@ -161,6 +160,8 @@ func (d *ContainerConfigProvider) deleteContainer(containerID string) {
 }
 
 func (d *ContainerConfigProvider) generateConfigs() ([]integration.Config, error) {
+	adErrors := make(map[string]ErrorMsgSet)
+
 	var configs []integration.Config
 	for containerID, container := range d.containerCache {
 		containerEntityName := containers.BuildEntityName(string(container.Runtime), containerID)
@@ -168,6 +169,10 @@ func (d *ContainerConfigProvider) generateConfigs() ([]integration.Config, error
 
 		for _, err := range errors {
 			log.Errorf("Can't parse template for container %s: %s", containerID, err)
+			if _, found := adErrors[containerEntityName]; !found {
+				adErrors[containerEntityName] = make(ErrorMsgSet)
+			}
+			adErrors[containerEntityName][err.Error()] = struct{}{}
 		}
 
 		for idx := range c {
@@ -176,6 +181,9 @@ func (d *ContainerConfigProvider) generateConfigs() ([]integration.Config, error
 
 		configs = append(configs, c...)
 	}
+
+	d.configErrors = adErrors
+
 	return configs, nil
 }
 
@@ -183,7 +191,9 @@ func init() {
 	RegisterProvider(names.Container, NewContainerConfigProvider)
 }
 
-// GetConfigErrors is not implemented for the ContainerConfigProvider
+// GetConfigErrors returns a map of configuration errors for each container
 func (d *ContainerConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
-	return make(map[string]ErrorMsgSet)
+	d.RLock()
+	defer d.RUnlock()
+	return d.configErrors
 }
@@ -0,0 +1,210 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// directoryRefreshDelay is the delay between the first filesystem event of a batch
+// and the reload of the configurations, so that editors writing files in several
+// steps only trigger one reload
+const directoryRefreshDelay = 500 * time.Millisecond
+
+// DirectoryConfigProvider watches a directory laid out like conf.d and streams
+// the changes of its configuration files as they are written
+type DirectoryConfigProvider struct {
+	sync.RWMutex
+	dir       string
+	files     *FileConfigProvider
+	configs   map[string]integration.Config
+	collected chan struct{}
+	once      sync.Once
+}
+
+// NewDirectoryConfigProvider creates a new DirectoryConfigProvider watching the template_dir of the provider config
+func NewDirectoryConfigProvider(cfg config.ConfigurationProviders) (ConfigProvider, error) {
+	if cfg.TemplateDir == "" {
+		return nil, errors.New("template_dir must be set to the directory to watch")
+	}
+
+	dir := filepath.Clean(cfg.TemplateDir)
+	if dir == filepath.Clean(config.Datadog.GetString("confd_path")) {
+		log.Warnf("%s provider is watching %s, which is already loaded by the file provider: configurations will be scheduled twice", names.Directory, dir)
+	}
+
+	return &DirectoryConfigProvider{
+		dir:       dir,
+		files:     NewFileConfigProvider([]string{dir}),
+		configs:   make(map[string]integration.Config),
+		collected: make(chan struct{}),
+	}, nil
+}
+
+// String returns a string representation of the DirectoryConfigProvider
+func (p *DirectoryConfigProvider) String() string {
+	return names.Directory
+}
+
+// Collect reads the configuration files of the directory
+func (p *DirectoryConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	p.Lock()
+	defer p.Unlock()
+	defer p.once.Do(func() { close(p.collected) })
+
+	configs, err := p.files.Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.configs = configsByDigest(configs)
+	return configValues(p.configs), nil
+}
+
+// IsUpToDate always returns true as changes are streamed
+func (p *DirectoryConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// GetConfigErrors returns the errors encountered while parsing the configuration files
+func (p *DirectoryConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.RLock()
+	defer p.RUnlock()
+
+	configErrors := make(map[string]ErrorMsgSet, len(p.files.Errors))
+	for name, err := range p.files.Errors {
+		configErrors[name] = ErrorMsgSet{err: struct{}{}}
+	}
+	return configErrors
+}
+
+// Stream watches the directory and sends the changes of its configurations
+func (p *DirectoryConfigProvider) Stream(ctx context.Context) <-chan ConfigChanges {
+	ch := make(chan ConfigChanges)
+	go p.watch(ctx, ch)
+	return ch
+}
+
+func (p *DirectoryConfigProvider) watch(ctx context.Context, ch chan<- ConfigChanges) {
+	defer close(ch)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Errorf("%s provider: unable to watch %s, configuration changes won't be detected: %s", p, p.dir, err)
+		return
+	}
+	defer watcher.Close()
+
+	p.addWatches(watcher)
+
+	// changes are relative to the configurations returned by Collect
+	select {
+	case <-p.collected:
+	case <-ctx.Done():
+		return
+	}
+
+	// catch up with the changes made between Collect and the watches being added
+	if !sendChanges(ctx.Done(), ch, p.refresh(ctx)) {
+		return
+	}
+
+	var refresh <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			log.Tracef("%s provider: %s", p, event)
+			if event.Op&fsnotify.Create != 0 && isConfigDir(event.Name) {
+				if err := watcher.Add(event.Name); err != nil {
+					log.Warnf("%s provider: unable to watch %s: %s", p, event.Name, err)
+				}
+			}
+			if refresh == nil {
+				refresh = time.After(directoryRefreshDelay)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Warnf("%s provider: error watching %s: %s", p, p.dir, err)
+
+		case <-refresh:
+			refresh = nil
+			if !sendChanges(ctx.Done(), ch, p.refresh(ctx)) {
+				return
+			}
+		}
+	}
+}
+
+// addWatches watches the directory and its integration.d sub-directories
+func (p *DirectoryConfigProvider) addWatches(watcher *fsnotify.Watcher) {
+	if err := watcher.Add(p.dir); err != nil {
+		log.Warnf("%s provider: unable to watch %s: %s", p, p.dir, err)
+	}
+
+	entries, err := readDirPtr(p.dir)
+	if err != nil {
+		log.Warnf("%s provider: unable to list %s: %s", p, p.dir, err)
+		return
+	}
+	for _, entry := range entries {
+		path := filepath.Join(p.dir, entry.Name())
+		if entry.IsDir() && filepath.Ext(path) == ".d" {
+			if err := watcher.Add(path); err != nil {
+				log.Warnf("%s provider: unable to watch %s: %s", p, path, err)
+			}
+		}
+	}
+}
+
+// refresh reads the configuration files and returns the changes since the previous read
+func (p *DirectoryConfigProvider) refresh(ctx context.Context) ConfigChanges {
+	p.Lock()
+	defer p.Unlock()
+
+	configs, err := p.files.Collect(ctx)
+	if err != nil {
+		log.Warnf("%s provider: unable to read configurations from %s: %s", p, p.dir, err)
+		return ConfigChanges{}
+	}
+
+	latest := configsByDigest(configs)
+	changes := diffConfigs(p.configs, latest)
+	p.configs = latest
+	return changes
+}
+
+func isConfigDir(path string) bool {
+	if filepath.Ext(path) != ".d" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func init() {
+	RegisterProvider(names.Directory, NewDirectoryConfigProvider)
+}
@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func receiveChanges(t *testing.T, ch <-chan ConfigChanges) ConfigChanges {
+	select {
+	case changes := <-ch:
+		return changes
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no configuration change received")
+	}
+	return ConfigChanges{}
+}
+
+func TestNewDirectoryConfigProvider(t *testing.T) {
+	_, err := NewDirectoryConfigProvider(config.ConfigurationProviders{})
+	assert.Error(t, err)
+
+	p, err := NewDirectoryConfigProvider(config.ConfigurationProviders{TemplateDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.Implements(t, (*StreamingConfigProvider)(nil), p)
+}
+
+func TestDirectoryConfigProviderStream(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "foo.yaml"), []byte("instances:\n- a: 1\n"), 0644))
+
+	cp, err := NewDirectoryConfigProvider(config.ConfigurationProviders{TemplateDir: dir})
+	require.NoError(t, err)
+	p := cp.(*DirectoryConfigProvider)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := p.Stream(ctx)
+
+	configs, err := p.Collect(ctx)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	assert.Equal(t, "foo", configs[0].Name)
+
+	// new file in a new integration directory
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "bar.d"), 0755))
+	time.Sleep(100 * time.Millisecond) // let the watcher pick up the new directory
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bar.d", "conf.yaml"), []byte("instances:\n- b: 1\n"), 0644))
+
+	changes := receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "bar", changes.Schedule[0].Name)
+	assert.Empty(t, changes.Unschedule)
+
+	// modified file
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "foo.yaml"), []byte("instances:\n- a: 2\n"), 0644))
+
+	changes = receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+	require.Len(t, changes.Unschedule, 1)
+	assert.Equal(t, integration.Data("a: 2\n"), changes.Schedule[0].Instances[0])
+	assert.Equal(t, integration.Data("a: 1\n"), changes.Unschedule[0].Instances[0])
+
+	// removed file
+	require.NoError(t, os.Remove(filepath.Join(dir, "bar.d", "conf.yaml")))
+
+	changes = receiveChanges(t, ch)
+	assert.Empty(t, changes.Schedule)
+	require.Len(t, changes.Unschedule, 1)
+	assert.Equal(t, "bar", changes.Unschedule[0].Name)
+
+	// invalid file
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "baz.yaml"), []byte("init_config:\n"), 0644))
+	assert.Eventually(t, func() bool { return len(p.GetConfigErrors()["baz"]) == 1 }, 5*time.Second, 50*time.Millisecond)
+
+	cancel()
+	for range ch {
+	}
+}
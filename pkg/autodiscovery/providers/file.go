@@ -261,23 +261,30 @@ func (c *FileConfigProvider) collectDir(parentPath string, folder os.FileInfo) c
 
 // GetIntegrationConfigFromFile returns an instance of integration.Config if `fpath` points to a valid config file
 func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error) {
-	cf := configFormat{}
-	config := integration.Config{Name: name}
-
 	// Read file contents
 	// FIXME: ReadFile reads the entire file, possible security implications
 	yamlFile, err := readFilePtr(fpath)
 	if err != nil {
-		return config, err
+		return integration.Config{Name: name}, err
 	}
 
+	return parseIntegrationConfig(name, yamlFile, "file:"+fpath)
+}
+
+// parseIntegrationConfig returns an instance of integration.Config if `yamlFile` is a valid config file,
+// source is used as the config source and to report parsing warnings
+func parseIntegrationConfig(name string, yamlFile []byte, source string) (integration.Config, error) {
+	var err error
+	cf := configFormat{}
+	config := integration.Config{Name: name}
+
 	// Parse configuration
 	// Try UnmarshalStrict first, so we can warn about duplicated keys
 	if strictErr := yaml.UnmarshalStrict(yamlFile, &cf); strictErr != nil {
 		if err := yaml.Unmarshal(yamlFile, &cf); err != nil {
 			return config, err
 		}
-		log.Warnf("reading config file %v: %v\n", fpath, strictErr)
+		log.Warnf("reading config %v: %v\n", source, strictErr)
 	}
 
 	// If no valid instances were found & this is neither a metrics file, nor a logs file
@@ -329,7 +336,7 @@ func GetIntegrationConfigFromFile(name, fpath string) (integration.Config, error
 	// Interpolate env vars. Returns an error a variable wasn't subsituted, ignore it.
 	_ = configresolver.SubstituteTemplateEnvVars(&config)
 
-	config.Source = "file:" + fpath
+	config.Source = source
 
 	return config, err
 }
@@ -0,0 +1,315 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	httpProviderTimeout = 30 * time.Second
+	// httpProviderMaxBodySize caps the size of the payloads read from the endpoint
+	httpProviderMaxBodySize = 10 * 1024 * 1024
+)
+
+// HTTPConfigProvider pulls integration configurations from an HTTP(S) endpoint.
+//
+// The endpoint returns a YAML (or JSON) document mapping integration names to
+// configurations, each one in the same format as a conf.d file, or to a list of them:
+//
+//	nginx:
+//	  init_config:
+//	  instances:
+//	    - nginx_status_url: http://localhost:81/nginx_status/
+//
+// The ETag of the last payload is sent along requests so that the endpoint can
+// answer with a 304 Not Modified when configurations did not change.
+type HTTPConfigProvider struct {
+	sync.RWMutex
+	url          string
+	client       *http.Client
+	username     string
+	password     string
+	token        string
+	pollInterval time.Duration
+	etag         string
+	configs      map[string]integration.Config
+	errors       map[string]ErrorMsgSet
+	collected    chan struct{}
+	once         sync.Once
+}
+
+// NewHTTPConfigProvider creates a new HTTPConfigProvider pulling configurations from the template_url of the provider config
+func NewHTTPConfigProvider(cfg config.ConfigurationProviders) (ConfigProvider, error) {
+	u, err := url.Parse(cfg.TemplateURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "https":
+	case "http":
+		log.Warnf("%s provider: %s is not using https, configurations and credentials are sent in clear text", names.HTTP, cfg.TemplateURL)
+	default:
+		return nil, fmt.Errorf("template_url must be an http(s) URL, got %q", cfg.TemplateURL)
+	}
+
+	tlsConfig, err := httpProviderTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HTTPConfigProvider{
+		url: cfg.TemplateURL,
+		client: &http.Client{
+			Timeout: httpProviderTimeout,
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+		},
+		username:     cfg.Username,
+		password:     cfg.Password,
+		token:        cfg.Token,
+		pollInterval: GetPollInterval(cfg),
+		configs:      make(map[string]integration.Config),
+		errors:       make(map[string]ErrorMsgSet),
+		collected:    make(chan struct{}),
+	}, nil
+}
+
+func httpProviderTLSConfig(cfg config.ConfigurationProviders) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if cfg.CAFile != "" {
+		caCert, err := ioutil.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read ca_file: %s", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in ca_file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %s", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// String returns a string representation of the HTTPConfigProvider
+func (p *HTTPConfigProvider) String() string {
+	return names.HTTP
+}
+
+// Collect pulls the configurations from the endpoint
+func (p *HTTPConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+	p.Lock()
+	defer p.Unlock()
+	defer p.once.Do(func() { close(p.collected) })
+
+	if _, err := p.fetch(ctx); err != nil {
+		return nil, err
+	}
+	return configValues(p.configs), nil
+}
+
+// IsUpToDate always returns true as changes are streamed
+func (p *HTTPConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// GetConfigErrors returns the errors encountered while parsing the configurations of the last payload
+func (p *HTTPConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.RLock()
+	defer p.RUnlock()
+
+	configErrors := make(map[string]ErrorMsgSet, len(p.errors))
+	for name, set := range p.errors {
+		configErrors[name] = set
+	}
+	return configErrors
+}
+
+// Stream polls the endpoint and sends the changes of its configurations
+func (p *HTTPConfigProvider) Stream(ctx context.Context) <-chan ConfigChanges {
+	ch := make(chan ConfigChanges)
+	go p.poll(ctx, ch)
+	return ch
+}
+
+func (p *HTTPConfigProvider) poll(ctx context.Context, ch chan<- ConfigChanges) {
+	defer close(ch)
+
+	// changes are relative to the configurations returned by Collect
+	select {
+	case <-p.collected:
+	case <-ctx.Done():
+		return
+	}
+
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !sendChanges(ctx.Done(), ch, p.refresh(ctx)) {
+				return
+			}
+		}
+	}
+}
+
+// refresh pulls the configurations and returns the changes since the previous pull
+func (p *HTTPConfigProvider) refresh(ctx context.Context) ConfigChanges {
+	p.Lock()
+	defer p.Unlock()
+
+	previous := p.configs
+	modified, err := p.fetch(ctx)
+	if err != nil {
+		log.Warnf("%s provider: unable to pull configurations from %s: %s", p, p.url, err)
+		return ConfigChanges{}
+	}
+	if !modified {
+		log.Tracef("%s provider: configurations did not change", p)
+		return ConfigChanges{}
+	}
+	return diffConfigs(previous, p.configs)
+}
+
+// fetch pulls the configurations from the endpoint and stores them, it returns
+// false if the endpoint reported that they did not change since the last pull
+func (p *HTTPConfigProvider) fetch(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, httpProviderTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/yaml, application/json")
+	if p.etag != "" {
+		req.Header.Set("If-None-Match", p.etag)
+	}
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	} else if p.username != "" {
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return false, nil
+	case resp.StatusCode != http.StatusOK:
+		return false, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, httpProviderMaxBodySize+1))
+	if err != nil {
+		return false, err
+	}
+	if len(body) > httpProviderMaxBodySize {
+		return false, fmt.Errorf("payload exceeds %d bytes", httpProviderMaxBodySize)
+	}
+
+	configs, configErrors, err := parseHTTPConfigs(body, "http:"+p.url)
+	if err != nil {
+		return false, err
+	}
+
+	p.configs = configsByDigest(configs)
+	p.errors = configErrors
+	p.etag = resp.Header.Get("ETag")
+	return true, nil
+}
+
+// parseHTTPConfigs parses a payload mapping integration names to configurations,
+// invalid configurations are reported by integration name
+func parseHTTPConfigs(body []byte, source string) ([]integration.Config, map[string]ErrorMsgSet, error) {
+	payload := make(map[string]interface{})
+	if err := yaml.Unmarshal(body, &payload); err != nil {
+		return nil, nil, fmt.Errorf("invalid payload: %s", err)
+	}
+
+	integrationNames := make([]string, 0, len(payload))
+	for name := range payload {
+		integrationNames = append(integrationNames, name)
+	}
+	sort.Strings(integrationNames)
+
+	var configs []integration.Config
+	configErrors := make(map[string]ErrorMsgSet)
+	for _, name := range integrationNames {
+		var documents []interface{}
+		switch v := payload[name].(type) {
+		case []interface{}:
+			documents = v
+		default:
+			documents = []interface{}{v}
+		}
+
+		for _, document := range documents {
+			config, err := parseHTTPConfig(name, document, source)
+			if err != nil {
+				if _, found := configErrors[name]; !found {
+					configErrors[name] = make(ErrorMsgSet)
+				}
+				configErrors[name][err.Error()] = struct{}{}
+				continue
+			}
+			configs = append(configs, config)
+		}
+	}
+
+	return configs, configErrors, nil
+}
+
+func parseHTTPConfig(name string, document interface{}, source string) (integration.Config, error) {
+	if _, ok := document.(map[interface{}]interface{}); !ok {
+		return integration.Config{}, errors.New("configuration must be a mapping")
+	}
+
+	// at this point the Yaml was already parsed, no need to check the error
+	raw, _ := yaml.Marshal(document)
+	return parseIntegrationConfig(name, raw, source)
+}
+
+func init() {
+	RegisterProvider(names.HTTP, NewHTTPConfigProvider)
+}
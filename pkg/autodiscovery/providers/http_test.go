@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+type configServer struct {
+	sync.Mutex
+	payload  string
+	version  int
+	requests int
+	notMod   int
+}
+
+func (s *configServer) set(payload string) {
+	s.Lock()
+	defer s.Unlock()
+	s.payload = payload
+	s.version++
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	defer s.Unlock()
+	s.requests++
+
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	etag := fmt.Sprintf(`"v%d"`, s.version)
+	if r.Header.Get("If-None-Match") == etag {
+		s.notMod++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Write([]byte(s.payload)) //nolint:errcheck
+}
+
+func TestNewHTTPConfigProvider(t *testing.T) {
+	_, err := NewHTTPConfigProvider(config.ConfigurationProviders{TemplateURL: "ftp://example.com"})
+	assert.Error(t, err)
+
+	_, err = NewHTTPConfigProvider(config.ConfigurationProviders{TemplateURL: "https://example.com", CAFile: "does-not-exist"})
+	assert.Error(t, err)
+
+	p, err := NewHTTPConfigProvider(config.ConfigurationProviders{TemplateURL: "https://example.com"})
+	require.NoError(t, err)
+	assert.Implements(t, (*StreamingConfigProvider)(nil), p)
+}
+
+func TestHTTPConfigProviderRefresh(t *testing.T) {
+	server := &configServer{}
+	server.set(`
+nginx:
+  instances:
+  - nginx_status_url: http://localhost:81/nginx_status/
+redis:
+- instances:
+  - host: a
+- instances:
+  - host: b
+invalid:
+  init_config:
+`)
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	cp, err := NewHTTPConfigProvider(config.ConfigurationProviders{TemplateURL: ts.URL, Token: "secret"})
+	require.NoError(t, err)
+	p := cp.(*HTTPConfigProvider)
+
+	ctx := context.Background()
+	configs, err := p.Collect(ctx)
+	require.NoError(t, err)
+	assert.Len(t, configs, 3)
+	for _, c := range configs {
+		assert.Equal(t, "http:"+ts.URL, c.Source)
+	}
+	assert.Len(t, p.GetConfigErrors()["invalid"], 1)
+
+	// unchanged payload
+	changes := p.refresh(ctx)
+	assert.True(t, changes.IsEmpty())
+	assert.Equal(t, 1, server.notMod)
+
+	// one redis instance removed, nginx changed
+	server.set(`
+nginx:
+  instances:
+  - nginx_status_url: http://localhost:82/nginx_status/
+redis:
+- instances:
+  - host: a
+`)
+	changes = p.refresh(ctx)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "nginx", changes.Schedule[0].Name)
+	require.Len(t, changes.Unschedule, 2)
+	assert.Empty(t, p.GetConfigErrors())
+
+	// errors keep the previous configurations
+	server.set(`not: [valid`)
+	changes = p.refresh(ctx)
+	assert.True(t, changes.IsEmpty())
+	assert.Len(t, p.configs, 2)
+	assert.Equal(t, 4, server.requests)
+}
+
+func TestHTTPConfigProviderUnauthorized(t *testing.T) {
+	ts := httptest.NewServer(&configServer{})
+	defer ts.Close()
+
+	p, err := NewHTTPConfigProvider(config.ConfigurationProviders{TemplateURL: ts.URL})
+	require.NoError(t, err)
+
+	_, err = p.Collect(context.Background())
+	assert.Error(t, err)
+}
@@ -10,11 +10,13 @@ const (
 	Consul             = "consul"
 	Container          = "container"
 	CloudFoundryBBS    = "cloudfoundry-bbs"
+	Directory          = "directory"
 	ClusterChecks      = "cluster-checks"
 	ECS                = "ecs"
 	EndpointsChecks    = "endpoints-checks"
 	Etcd               = "etcd"
 	File               = "file"
+	HTTP               = "http"
 	Kubernetes         = "kubernetes"
 	KubeServices       = "kubernetes-services"
 	KubeEndpoints      = "kubernetes-endpoints"
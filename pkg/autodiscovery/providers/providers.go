@@ -58,3 +58,25 @@ type ConfigProvider interface {
 	IsUpToDate(context.Context) (bool, error)
 	GetConfigErrors() map[string]ErrorMsgSet
 }
+
+// ConfigChanges contains the configurations added and removed by a provider since its previous changes
+type ConfigChanges struct {
+	Schedule   []integration.Config
+	Unschedule []integration.Config
+}
+
+// IsEmpty returns whether there are no changes
+func (c ConfigChanges) IsEmpty() bool {
+	return len(c.Schedule) == 0 && len(c.Unschedule) == 0
+}
+
+// StreamingConfigProvider is a ConfigProvider that pushes the changes of its
+// configurations as they happen instead of being polled.
+//
+// Stream sends the changes made to the configurations returned by Collect,
+// until the context is cancelled. The returned channel is closed once the provider
+// stopped streaming.
+type StreamingConfigProvider interface {
+	ConfigProvider
+	Stream(context.Context) <-chan ConfigChanges
+}
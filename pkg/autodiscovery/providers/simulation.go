@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !serverless
+
+package providers
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// SimulateTemplates returns the templates the kubelet and container config
+// providers extract from the annotations of the given pods and the labels of the
+// given containers, without subscribing to the store. The errors found in the
+// annotations and labels are returned by pod and container.
+func SimulateTemplates(store workloadmeta.Store, pods []*workloadmeta.KubernetesPod, containers []*workloadmeta.Container) ([]integration.Config, map[string]ErrorMsgSet) {
+	kubeletProvider := &KubeletConfigProvider{
+		workloadmetaStore: store,
+		podCache:          make(map[string]*workloadmeta.KubernetesPod),
+	}
+	for _, pod := range pods {
+		kubeletProvider.podCache[pod.ID] = pod
+	}
+
+	containerProvider := &ContainerConfigProvider{
+		workloadmetaStore: store,
+		containerCache:    make(map[string]*workloadmeta.Container),
+	}
+	for _, container := range containers {
+		containerProvider.containerCache[container.ID] = container
+	}
+
+	var configs []integration.Config
+	errors := make(map[string]ErrorMsgSet)
+	for _, provider := range []interface {
+		String() string
+		generateConfigs() ([]integration.Config, error)
+		GetConfigErrors() map[string]ErrorMsgSet
+	}{kubeletProvider, containerProvider} {
+		c, _ := provider.generateConfigs()
+		for _, config := range c {
+			config.Provider = provider.String()
+			configs = append(configs, config)
+		}
+		for entity, errs := range provider.GetConfigErrors() {
+			errors[entity] = errs
+		}
+	}
+
+	return configs, errors
+}
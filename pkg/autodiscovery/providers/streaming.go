@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package providers
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// configsByDigest indexes configurations by digest, dropping JMX metric configurations
+// as they are not meant to be scheduled
+func configsByDigest(configs []integration.Config) map[string]integration.Config {
+	byDigest := make(map[string]integration.Config, len(configs))
+	for _, c := range configs {
+		if c.MetricConfig != nil {
+			continue
+		}
+		byDigest[c.Digest()] = c
+	}
+	return byDigest
+}
+
+// configValues returns the configurations of a configsByDigest index
+func configValues(byDigest map[string]integration.Config) []integration.Config {
+	configs := make([]integration.Config, 0, len(byDigest))
+	for _, c := range byDigest {
+		configs = append(configs, c)
+	}
+	return configs
+}
+
+// diffConfigs returns the changes turning the previous configurations into the latest ones
+func diffConfigs(previous, latest map[string]integration.Config) ConfigChanges {
+	var changes ConfigChanges
+	for digest, c := range previous {
+		if _, found := latest[digest]; !found {
+			changes.Unschedule = append(changes.Unschedule, c)
+		}
+	}
+	for digest, c := range latest {
+		if _, found := previous[digest]; !found {
+			changes.Schedule = append(changes.Schedule, c)
+		}
+	}
+	return changes
+}
+
+// sendChanges sends non-empty changes to the stream, it returns false if the context
+// was cancelled before the changes could be sent
+func sendChanges(done <-chan struct{}, ch chan<- ConfigChanges, changes ConfigChanges) bool {
+	if changes.IsEmpty() {
+		return true
+	}
+
+	select {
+	case ch <- changes:
+		return true
+	case <-done:
+		return false
+	}
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"context"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/scheduler"
+)
+
+// SimulationResult holds the outcome of an autodiscovery simulation
+type SimulationResult struct {
+	// Configs are the check and logs configs resolved from the templates, sorted
+	// by service and name
+	Configs []integration.Config
+	// ResolveWarnings are the errors of the templates that matched a service
+	// but couldn't be resolved against it, by service
+	ResolveWarnings map[string][]string
+}
+
+// simulationScheduler records the configs scheduled during a simulation
+type simulationScheduler struct {
+	configs []integration.Config
+}
+
+func (s *simulationScheduler) Schedule(configs []integration.Config) {
+	s.configs = append(s.configs, configs...)
+}
+
+func (s *simulationScheduler) Unschedule(configs []integration.Config) {}
+
+func (s *simulationScheduler) Stop() {}
+
+// Simulate resolves templates against services the way AutoConfig does, without
+// running any listener or config provider, and returns the configs that would be
+// scheduled. Configs that aren't templates are ignored.
+func Simulate(templates []integration.Config, services []listeners.Service) SimulationResult {
+	recorder := &simulationScheduler{}
+	ms := scheduler.NewMetaScheduler()
+	ms.Register("simulation", recorder)
+
+	ac := &AutoConfig{
+		store:     newStore(),
+		scheduler: ms,
+	}
+
+	ctx := context.Background()
+	for _, svc := range services {
+		ac.processNewService(ctx, svc)
+	}
+
+	for _, tpl := range templates {
+		if !tpl.IsTemplate() {
+			continue
+		}
+		ac.schedule(ac.processNewConfig(tpl))
+	}
+
+	result := SimulationResult{
+		ResolveWarnings: make(map[string][]string),
+	}
+
+	// services are scheduled as configs without name nor logs config for the
+	// logs agent to collect all containers, they aren't resolved templates
+	for _, config := range recorder.configs {
+		if config.Name == "" && len(config.LogsConfig) == 0 {
+			continue
+		}
+		result.Configs = append(result.Configs, config)
+	}
+	sort.SliceStable(result.Configs, func(i, j int) bool {
+		if result.Configs[i].Entity != result.Configs[j].Entity {
+			return result.Configs[i].Entity < result.Configs[j].Entity
+		}
+		return result.Configs[i].Name < result.Configs[j].Name
+	})
+
+	serviceEntities := make(map[string]struct{}, len(services))
+	for _, svc := range services {
+		serviceEntities[svc.GetEntity()] = struct{}{}
+	}
+	for svc, warnings := range errorStats.getServiceResolveWarnings() {
+		if _, found := serviceEntities[svc]; found {
+			result.ResolveWarnings[svc] = warnings
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,389 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !serverless
+
+package simulation
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// simulatedIPPrefix is the prefix of the IPs given to the pods and containers
+// that don't have one. It's the TEST-NET-1 documentation range, so that the
+// simulated IPs can't be mistaken for real ones.
+const simulatedIPPrefix = "192.0.2."
+
+// podTemplateKinds are the kinds of workload whose pods are described by a pod
+// template, by path to the template
+var podTemplateKinds = map[string][]string{
+	"Deployment":  {"spec", "template"},
+	"StatefulSet": {"spec", "template"},
+	"DaemonSet":   {"spec", "template"},
+	"ReplicaSet":  {"spec", "template"},
+	"Job":         {"spec", "template"},
+	"CronJob":     {"spec", "jobTemplate", "spec", "template"},
+}
+
+// LoadFiles loads the workloads described in the given files and merges them
+func LoadFiles(paths []string) (workloadmeta.EntitiesDump, error) {
+	b := newBuilder()
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return workloadmeta.EntitiesDump{}, err
+		}
+		if err := b.load(data); err != nil {
+			return workloadmeta.EntitiesDump{}, fmt.Errorf("unable to load %s: %w", path, err)
+		}
+	}
+	return b.dump, nil
+}
+
+// Load loads the workloads described in data. It can be a workloadmeta entities
+// dump in JSON, as printed by `agent workload-list --entities`, Kubernetes
+// manifests or a docker-compose file.
+func Load(data []byte) (workloadmeta.EntitiesDump, error) {
+	b := newBuilder()
+	if err := b.load(data); err != nil {
+		return workloadmeta.EntitiesDump{}, err
+	}
+	return b.dump, nil
+}
+
+// builder builds the entities of the loaded workloads
+type builder struct {
+	dump   workloadmeta.EntitiesDump
+	nextIP int
+}
+
+func newBuilder() *builder {
+	return &builder{nextIP: 1}
+}
+
+func (b *builder) load(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var dump workloadmeta.EntitiesDump
+		if err := json.Unmarshal(data, &dump); err != nil {
+			return fmt.Errorf("invalid workloadmeta entities dump: %w", err)
+		}
+		b.dump.Containers = append(b.dump.Containers, dump.Containers...)
+		b.dump.KubernetesPods = append(b.dump.KubernetesPods, dump.KubernetesPods...)
+		return nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc map[interface{}]interface{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if doc == nil {
+			continue
+		}
+
+		if _, found := doc["kind"]; found {
+			if err := b.addManifest(doc); err != nil {
+				return err
+			}
+		} else if services, found := doc["services"]; found {
+			if err := b.addComposeServices(services); err != nil {
+				return err
+			}
+		} else {
+			return fmt.Errorf("document is neither a Kubernetes manifest nor a docker-compose file")
+		}
+	}
+}
+
+// addManifest adds the pods of a Kubernetes manifest, other kinds of object are ignored
+func (b *builder) addManifest(doc map[interface{}]interface{}) error {
+	kind := getString(doc, "kind")
+	switch {
+	case kind == "List" || strings.HasSuffix(kind, "List"):
+		items, _ := doc["items"].([]interface{})
+		for _, item := range items {
+			if m, ok := item.(map[interface{}]interface{}); ok {
+				if err := b.addManifest(m); err != nil {
+					return err
+				}
+			}
+		}
+	case kind == "Pod":
+		return b.addPod(doc, getMap(doc, "metadata"), "")
+	default:
+		path, found := podTemplateKinds[kind]
+		if !found {
+			return nil
+		}
+		template := getMap(doc, path...)
+		if template == nil {
+			return fmt.Errorf("%s %q has no pod template", kind, getString(getMap(doc, "metadata"), "name"))
+		}
+
+		// pods created from a template are named after their owner and
+		// inherit its namespace
+		metadata := getMap(doc, "metadata")
+		podMetadata := getMap(template, "metadata")
+		if podMetadata == nil {
+			podMetadata = make(map[interface{}]interface{})
+		}
+		podMetadata["name"] = getString(metadata, "name") + "-0"
+		if getString(podMetadata, "namespace") == "" {
+			podMetadata["namespace"] = getString(metadata, "namespace")
+		}
+		return b.addPod(template, podMetadata, kind)
+	}
+	return nil
+}
+
+func (b *builder) addPod(pod, metadata map[interface{}]interface{}, ownerKind string) error {
+	name := getString(metadata, "name")
+	if name == "" {
+		return fmt.Errorf("pod has no name")
+	}
+	namespace := getString(metadata, "namespace")
+	if namespace == "" {
+		namespace = "default"
+	}
+	uid := getString(metadata, "uid")
+	if uid == "" {
+		uid = fmt.Sprintf("simulated-%s-%s", namespace, name)
+	}
+	ip := getString(pod, "status", "podIP")
+	if ip == "" {
+		ip = b.newIP()
+	}
+
+	kubePod := &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindKubernetesPod,
+			ID:   uid,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: getStringMap(metadata, "annotations"),
+			Labels:      getStringMap(metadata, "labels"),
+		},
+		Ready: true,
+		Phase: "Running",
+		IP:    ip,
+	}
+	if ownerKind != "" {
+		kubePod.Owners = []workloadmeta.KubernetesPodOwner{{Kind: ownerKind, Name: strings.TrimSuffix(name, "-0")}}
+	}
+
+	specContainers, _ := getMap(pod, "spec")["containers"].([]interface{})
+	for _, c := range specContainers {
+		specContainer, ok := c.(map[interface{}]interface{})
+		if !ok {
+			continue
+		}
+		containerName := getString(specContainer, "name")
+		image, err := workloadmeta.NewContainerImage(getString(specContainer, "image"))
+		if err != nil {
+			return fmt.Errorf("invalid image for container %q of pod %q: %w", containerName, name, err)
+		}
+
+		var ports []workloadmeta.ContainerPort
+		specPorts, _ := specContainer["ports"].([]interface{})
+		for _, p := range specPorts {
+			if port, ok := p.(map[interface{}]interface{}); ok {
+				ports = append(ports, workloadmeta.ContainerPort{
+					Name:     getString(port, "name"),
+					Port:     getInt(port, "containerPort"),
+					Protocol: getString(port, "protocol"),
+				})
+			}
+		}
+
+		container := &workloadmeta.Container{
+			EntityID: workloadmeta.EntityID{
+				Kind: workloadmeta.KindContainer,
+				ID:   fmt.Sprintf("%s-%s", uid, containerName),
+			},
+			EntityMeta: workloadmeta.EntityMeta{
+				Name: containerName,
+			},
+			Image:      image,
+			NetworkIPs: map[string]string{"pod": ip},
+			Ports:      ports,
+			Runtime:    workloadmeta.ContainerRuntimeContainerd,
+			State:      workloadmeta.ContainerState{Running: true},
+		}
+
+		kubePod.Containers = append(kubePod.Containers, workloadmeta.OrchestratorContainer{
+			ID:    container.ID,
+			Name:  containerName,
+			Image: image,
+		})
+		b.dump.Containers = append(b.dump.Containers, container)
+	}
+
+	b.dump.KubernetesPods = append(b.dump.KubernetesPods, kubePod)
+	return nil
+}
+
+// addComposeServices adds a container for each service of a docker-compose file
+func (b *builder) addComposeServices(services interface{}) error {
+	servicesMap, ok := services.(map[interface{}]interface{})
+	if !ok {
+		return fmt.Errorf("invalid docker-compose services")
+	}
+
+	names := make([]string, 0, len(servicesMap))
+	for name := range servicesMap {
+		names = append(names, fmt.Sprint(name))
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		service, _ := servicesMap[name].(map[interface{}]interface{})
+		containerName := getString(service, "container_name")
+		if containerName == "" {
+			containerName = name
+		}
+		image, err := workloadmeta.NewContainerImage(getString(service, "image"))
+		if err != nil {
+			return fmt.Errorf("invalid image for service %q: %w", name, err)
+		}
+
+		var ports []workloadmeta.ContainerPort
+		composePorts, _ := service["ports"].([]interface{})
+		for _, p := range composePorts {
+			if port, ok := parseComposePort(p); ok {
+				ports = append(ports, port)
+			}
+		}
+
+		b.dump.Containers = append(b.dump.Containers, &workloadmeta.Container{
+			EntityID: workloadmeta.EntityID{
+				Kind: workloadmeta.KindContainer,
+				ID:   "simulated-" + name,
+			},
+			EntityMeta: workloadmeta.EntityMeta{
+				Name:   containerName,
+				Labels: getLabels(service, "labels"),
+			},
+			Hostname:   name,
+			Image:      image,
+			NetworkIPs: map[string]string{"bridge": b.newIP()},
+			Ports:      ports,
+			Runtime:    workloadmeta.ContainerRuntimeDocker,
+			State:      workloadmeta.ContainerState{Running: true},
+		})
+	}
+
+	return nil
+}
+
+func (b *builder) newIP() string {
+	ip := simulatedIPPrefix + strconv.Itoa(b.nextIP)
+	b.nextIP++
+	return ip
+}
+
+// parseComposePort parses the container port of a docker-compose port, either in
+// the short syntax ("8080:80/tcp") or in the long one ({target: 80})
+func parseComposePort(p interface{}) (workloadmeta.ContainerPort, bool) {
+	if m, ok := p.(map[interface{}]interface{}); ok {
+		port := getInt(m, "target")
+		return workloadmeta.ContainerPort{Port: port, Protocol: getString(m, "protocol")}, port != 0
+	}
+
+	spec := fmt.Sprint(p)
+	protocol := ""
+	if idx := strings.LastIndex(spec, "/"); idx != -1 {
+		protocol = spec[idx+1:]
+		spec = spec[:idx]
+	}
+	if idx := strings.LastIndex(spec, ":"); idx != -1 {
+		spec = spec[idx+1:]
+	}
+	// ranges aren't expanded, only their first port is kept
+	spec = strings.SplitN(spec, "-", 2)[0]
+
+	port, err := strconv.Atoi(spec)
+	if err != nil {
+		return workloadmeta.ContainerPort{}, false
+	}
+	return workloadmeta.ContainerPort{Port: port, Protocol: protocol}, true
+}
+
+func getMap(m map[interface{}]interface{}, path ...string) map[interface{}]interface{} {
+	for _, key := range path {
+		if m == nil {
+			return nil
+		}
+		m, _ = m[key].(map[interface{}]interface{})
+	}
+	return m
+}
+
+func getString(m map[interface{}]interface{}, path ...string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	m = getMap(m, path[:len(path)-1]...)
+	if m == nil {
+		return ""
+	}
+	if v, found := m[path[len(path)-1]]; found && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+func getInt(m map[interface{}]interface{}, key string) int {
+	i, _ := strconv.Atoi(getString(m, key))
+	return i
+}
+
+func getStringMap(m map[interface{}]interface{}, key string) map[string]string {
+	values, _ := m[key].(map[interface{}]interface{})
+	if len(values) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(values))
+	for k, v := range values {
+		result[fmt.Sprint(k)] = fmt.Sprint(v)
+	}
+	return result
+}
+
+// getLabels returns the labels of a docker-compose service, given either as a
+// map or as a list of key=value
+func getLabels(m map[interface{}]interface{}, key string) map[string]string {
+	list, ok := m[key].([]interface{})
+	if !ok {
+		return getStringMap(m, key)
+	}
+	labels := make(map[string]string, len(list))
+	for _, l := range list {
+		parts := strings.SplitN(fmt.Sprint(l), "=", 2)
+		if len(parts) == 2 {
+			labels[parts[0]] = parts[1]
+		} else {
+			labels[parts[0]] = ""
+		}
+	}
+	return labels
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !serverless
+
+// Package simulation runs workloads through autodiscovery offline, to preview
+// the check and logs configs the agent would schedule for them.
+package simulation
+
+import (
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// ServiceResult holds the outcome of a simulation for a service
+type ServiceResult struct {
+	Entity string `json:"entity"`
+	// Configs are the resolved check and logs configs that would be scheduled
+	Configs []integration.Config `json:"configs"`
+	// ResolveWarnings are the errors of the templates matching the service that
+	// couldn't be resolved against it
+	ResolveWarnings []string `json:"resolve_warnings,omitempty"`
+}
+
+// Result holds the outcome of a simulation
+type Result struct {
+	// Services are the services created by the listeners, sorted by entity
+	Services []ServiceResult `json:"services"`
+	// TemplateErrors are the errors found in the pod annotations and container
+	// labels, by entity
+	TemplateErrors map[string][]string `json:"template_errors,omitempty"`
+}
+
+// Run runs the workloads of dump through the kubelet and container listeners
+// and config providers, and resolves the templates they provide, along with
+// the given file templates, against the services.
+func Run(dump workloadmeta.EntitiesDump, fileTemplates []integration.Config) (Result, error) {
+	store := workloadmeta.NewOfflineStore(dump)
+
+	services, err := listeners.SimulateServices(store, dump.KubernetesPods, dump.Containers)
+	if err != nil {
+		return Result{}, err
+	}
+
+	templates, templateErrors := providers.SimulateTemplates(store, dump.KubernetesPods, dump.Containers)
+	templates = append(fileTemplates, templates...)
+
+	simulation := autodiscovery.Simulate(templates, services)
+
+	configs := make(map[string][]integration.Config)
+	for _, config := range simulation.Configs {
+		configs[config.Entity] = append(configs[config.Entity], config)
+	}
+
+	result := Result{
+		Services: make([]ServiceResult, 0, len(services)),
+	}
+	for _, svc := range services {
+		entity := svc.GetEntity()
+		result.Services = append(result.Services, ServiceResult{
+			Entity:          entity,
+			Configs:         configs[entity],
+			ResolveWarnings: simulation.ResolveWarnings[entity],
+		})
+	}
+	sort.Slice(result.Services, func(i, j int) bool {
+		return result.Services[i].Entity < result.Services[j].Entity
+	})
+
+	if len(templateErrors) > 0 {
+		result.TemplateErrors = make(map[string][]string, len(templateErrors))
+		for entity, errs := range templateErrors {
+			for err := range errs {
+				result.TemplateErrors[entity] = append(result.TemplateErrors[entity], err)
+			}
+			sort.Strings(result.TemplateErrors[entity])
+		}
+	}
+
+	return result, nil
+}
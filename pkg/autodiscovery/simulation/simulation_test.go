@@ -0,0 +1,208 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build !serverless
+
+package simulation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const manifests = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: redis
+  namespace: cache
+spec:
+  template:
+    metadata:
+      labels:
+        app: redis
+      annotations:
+        ad.datadoghq.com/redis.check_names: '["redisdb"]'
+        ad.datadoghq.com/redis.init_configs: '[{}]'
+        ad.datadoghq.com/redis.instances: '[{"host": "%%host%%", "port": "%%port%%"}]'
+        ad.datadoghq.com/redis.logs: '[{"source": "redis"}]'
+    spec:
+      containers:
+      - name: redis
+        image: redis:6.2
+        ports:
+        - containerPort: 6379
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: redis
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  uid: web-uid
+  annotations:
+    ad.datadoghq.com/nginx.check_names: '["nginx"]'
+    ad.datadoghq.com/nginx.init_configs: '[{}]'
+    ad.datadoghq.com/nginx.instances: '[{"url": "http://%%host%%:%%port_http%%"}]'
+spec:
+  containers:
+  - name: nginx
+    image: nginx
+status:
+  podIP: 10.1.2.3
+`
+
+const compose = `
+version: "3"
+services:
+  db:
+    image: postgres:13
+    ports:
+    - "5432:5432"
+    labels:
+    - com.datadoghq.ad.check_names=["postgres"]
+    - com.datadoghq.ad.init_configs=[{}]
+    - com.datadoghq.ad.instances=[{"host":"%%host%%","port":"%%port%%"}]
+  broken:
+    image: busybox
+    labels:
+      com.datadoghq.ad.check_names: '["busybox"]'
+      com.datadoghq.ad.init_configs: '[{}]'
+      com.datadoghq.ad.instances: '[{'
+`
+
+func TestLoadManifests(t *testing.T) {
+	dump, err := Load([]byte(manifests))
+	require.NoError(t, err)
+
+	require.Len(t, dump.KubernetesPods, 2)
+	require.Len(t, dump.Containers, 2)
+
+	redis := dump.KubernetesPods[0]
+	assert.Equal(t, "redis-0", redis.Name)
+	assert.Equal(t, "cache", redis.Namespace)
+	assert.Equal(t, map[string]string{"app": "redis"}, redis.Labels)
+	assert.Equal(t, "192.0.2.1", redis.IP)
+	assert.Equal(t, []workloadmeta.KubernetesPodOwner{{Kind: "Deployment", Name: "redis"}}, redis.Owners)
+	require.Len(t, redis.Containers, 1)
+	assert.Equal(t, "redis", redis.Containers[0].Name)
+	assert.Equal(t, "redis", redis.Containers[0].Image.ShortName)
+	assert.Equal(t, "6.2", redis.Containers[0].Image.Tag)
+	assert.Equal(t, []workloadmeta.ContainerPort{{Port: 6379}}, dump.Containers[0].Ports)
+
+	web := dump.KubernetesPods[1]
+	assert.Equal(t, "web-uid", web.ID)
+	assert.Equal(t, "default", web.Namespace)
+	assert.Equal(t, "10.1.2.3", web.IP)
+}
+
+func TestLoadCompose(t *testing.T) {
+	dump, err := Load([]byte(compose))
+	require.NoError(t, err)
+
+	require.Empty(t, dump.KubernetesPods)
+	require.Len(t, dump.Containers, 2)
+
+	db := dump.Containers[1]
+	assert.Equal(t, "db", db.Name)
+	assert.Equal(t, workloadmeta.ContainerRuntimeDocker, db.Runtime)
+	assert.Equal(t, "postgres", db.Image.ShortName)
+	assert.Equal(t, []workloadmeta.ContainerPort{{Port: 5432}}, db.Ports)
+	assert.Equal(t, `["postgres"]`, db.Labels["com.datadoghq.ad.check_names"])
+	assert.Equal(t, map[string]string{"bridge": "192.0.2.2"}, db.NetworkIPs)
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, err := Load([]byte("foo: bar"))
+	assert.Error(t, err)
+
+	_, err = Load([]byte("{invalid"))
+	assert.Error(t, err)
+}
+
+func TestParseComposePort(t *testing.T) {
+	for _, tc := range []struct {
+		port     interface{}
+		expected workloadmeta.ContainerPort
+		ok       bool
+	}{
+		{port: "80", expected: workloadmeta.ContainerPort{Port: 80}, ok: true},
+		{port: "8080:80/udp", expected: workloadmeta.ContainerPort{Port: 80, Protocol: "udp"}, ok: true},
+		{port: "127.0.0.1:8080:80", expected: workloadmeta.ContainerPort{Port: 80}, ok: true},
+		{port: "3000-3005", expected: workloadmeta.ContainerPort{Port: 3000}, ok: true},
+		{port: map[interface{}]interface{}{"target": 443, "published": 8443}, expected: workloadmeta.ContainerPort{Port: 443}, ok: true},
+		{port: "invalid", ok: false},
+	} {
+		port, ok := parseComposePort(tc.port)
+		assert.Equal(t, tc.ok, ok, "%v", tc.port)
+		assert.Equal(t, tc.expected, port, "%v", tc.port)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dump, err := Load(append([]byte(manifests), []byte("---\n"+compose)...))
+	require.NoError(t, err)
+
+	fileTemplates := []integration.Config{
+		{
+			Name:          "redisdb",
+			ADIdentifiers: []string{"redis"},
+			Instances:     []integration.Data{integration.Data(`{"host": "%%host%%"}`)},
+			Provider:      names.File,
+			Source:        "file:/etc/datadog-agent/conf.d/redisdb.d/auto_conf.yaml",
+		},
+		{
+			Name:      "not_a_template",
+			Instances: []integration.Data{integration.Data(`{}`)},
+			Provider:  names.File,
+		},
+	}
+
+	result, err := Run(dump, fileTemplates)
+	require.NoError(t, err)
+
+	services := make(map[string]ServiceResult)
+	for _, svc := range result.Services {
+		services[svc.Entity] = svc
+	}
+
+	// the redisdb check of the annotations overrides the one of the file
+	redis := services["containerd://simulated-cache-redis-0-redis"]
+	require.Len(t, redis.Configs, 2)
+	assert.Equal(t, "", redis.Configs[0].Name)
+	assert.Equal(t, integration.Data(`[{"source":"redis"}]`), redis.Configs[0].LogsConfig)
+	assert.Equal(t, "redisdb", redis.Configs[1].Name)
+	assert.Equal(t, names.Kubernetes, redis.Configs[1].Provider)
+	assert.Contains(t, string(redis.Configs[1].Instances[0]), `"host":"192.0.2.1"`)
+	assert.Contains(t, string(redis.Configs[1].Instances[0]), `"port":"6379"`)
+	require.Len(t, redis.ResolveWarnings, 1)
+	assert.Contains(t, redis.ResolveWarnings[0], "another config is defined for the check redisdb")
+
+	// the port named http doesn't exist
+	nginx := services["containerd://web-uid-nginx"]
+	assert.Empty(t, nginx.Configs)
+	require.Len(t, nginx.ResolveWarnings, 1)
+	assert.Contains(t, nginx.ResolveWarnings[0], "port_http")
+
+	db := services["docker://simulated-db"]
+	require.Len(t, db.Configs, 1)
+	assert.Equal(t, "postgres", db.Configs[0].Name)
+	assert.Equal(t, names.Container, db.Configs[0].Provider)
+	assert.Contains(t, string(db.Configs[0].Instances[0]), `"host":"192.0.2.3"`)
+
+	assert.Contains(t, services, "kubernetes_pod://web-uid")
+	assert.Empty(t, services["kubernetes_pod://web-uid"].Configs)
+
+	require.Contains(t, result.TemplateErrors, "docker://simulated-broken")
+}
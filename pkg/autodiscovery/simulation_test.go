@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package autodiscovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/listeners"
+)
+
+func TestSimulate(t *testing.T) {
+	services := []listeners.Service{
+		&dummyService{ID: "b", ADIdentifiers: []string{"redis"}},
+		&dummyService{ID: "a", ADIdentifiers: []string{"redis", "nginx"}},
+		&dummyService{ID: "c", ADIdentifiers: []string{"nginx"}},
+	}
+	templates := []integration.Config{
+		{Name: "redisdb", ADIdentifiers: []string{"redis"}, Instances: []integration.Data{integration.Data("{}")}},
+		{ADIdentifiers: []string{"nginx"}, LogsConfig: integration.Data(`[{"source":"nginx"}]`)},
+		{Name: "nginx", ADIdentifiers: []string{"nginx"}, Instances: []integration.Data{integration.Data(`{"host":"%%host%%"}`)}},
+		{Name: "cpu", Instances: []integration.Data{integration.Data("{}")}},
+	}
+
+	result := Simulate(templates, services)
+
+	var scheduled []string
+	for _, config := range result.Configs {
+		scheduled = append(scheduled, config.Entity+"/"+config.Name)
+	}
+	assert.Equal(t, []string{"a/", "a/redisdb", "b/redisdb", "c/"}, scheduled)
+
+	// the nginx services have no host
+	require.Contains(t, result.ResolveWarnings, "a")
+	require.Contains(t, result.ResolveWarnings, "c")
+	assert.NotContains(t, result.ResolveWarnings, "b")
+	assert.Len(t, result.ResolveWarnings["c"], 1)
+}
@@ -6,13 +6,24 @@
 package autodiscovery
 
 import (
+	"sort"
 	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 )
 
+// resolveWarningKey identifies a resolve warning of a check
+type resolveWarningKey struct {
+	template string // digest of the template
+	source   string // source of the template, as identical templates can come from several sources
+	service  string // entity of the service the template was resolved against, empty for template-wide warnings
+	detail   string // distinguishes template-wide warnings, e.g. the AD identifier without service
+}
+
 // loaderErrorStats holds the error objects
 type acErrorStats struct {
-	config  map[string]string   // config file name -> error
-	resolve map[string][]string // config file name -> errors
+	config  map[string]string                       // config file name -> error
+	resolve map[string]map[resolveWarningKey]string // check name -> template and service -> error
 	m       sync.RWMutex
 }
 
@@ -20,7 +31,7 @@ type acErrorStats struct {
 func newAcErrorStats() *acErrorStats {
 	return &acErrorStats{
 		config:  make(map[string]string),
-		resolve: make(map[string][]string),
+		resolve: make(map[string]map[resolveWarningKey]string),
 	}
 }
 
@@ -53,31 +64,99 @@ func (es *acErrorStats) getConfigErrors() map[string]string {
 	return configCopy
 }
 
-// setResolveWarning will safely set the error for a check configuration file
-func (es *acErrorStats) setResolveWarning(checkName string, err string) {
+// setResolveWarning will safely set the error for a template, resolved against
+// a service if svc is not empty, replacing the previous error for the same
+// template, service and detail
+func (es *acErrorStats) setResolveWarning(tpl integration.Config, svc, detail, err string) {
+	es.m.Lock()
+	defer es.m.Unlock()
+
+	if _, found := es.resolve[tpl.Name]; !found {
+		es.resolve[tpl.Name] = make(map[resolveWarningKey]string)
+	}
+	es.resolve[tpl.Name][resolveWarningKey{template: tpl.Digest(), source: tpl.Source, service: svc, detail: detail}] = err
+}
+
+// removeResolveWarnings removes the errors of a template resolved against a service,
+// along with the template-wide errors since the template could be resolved
+func (es *acErrorStats) removeResolveWarnings(tpl integration.Config, svc string) {
+	es.m.Lock()
+	defer es.m.Unlock()
+
+	digest := tpl.Digest()
+	es.removeResolveWarningsLocked(tpl.Name, func(key resolveWarningKey) bool {
+		return key.template == digest && key.source == tpl.Source && (key.service == svc || key.service == "")
+	})
+}
+
+// removeTemplateResolveWarnings removes all the errors of a template
+func (es *acErrorStats) removeTemplateResolveWarnings(tpl integration.Config) {
 	es.m.Lock()
 	defer es.m.Unlock()
 
-	es.resolve[checkName] = append(es.resolve[checkName], err)
+	digest := tpl.Digest()
+	es.removeResolveWarningsLocked(tpl.Name, func(key resolveWarningKey) bool {
+		return key.template == digest && key.source == tpl.Source
+	})
 }
 
-// removeResolveWarnings removes the errors for a check config file
-func (es *acErrorStats) removeResolveWarnings(checkName string) {
+// removeServiceResolveWarnings removes the errors of all the templates resolved against a service
+func (es *acErrorStats) removeServiceResolveWarnings(svc string) {
 	es.m.Lock()
 	defer es.m.Unlock()
 
-	delete(es.resolve, checkName)
+	for checkName := range es.resolve {
+		es.removeResolveWarningsLocked(checkName, func(key resolveWarningKey) bool {
+			return key.service == svc
+		})
+	}
 }
 
-// getResolveWarnings will safely get the errors a check config file
+func (es *acErrorStats) removeResolveWarningsLocked(checkName string, match func(resolveWarningKey) bool) {
+	for key := range es.resolve[checkName] {
+		if match(key) {
+			delete(es.resolve[checkName], key)
+		}
+	}
+	if len(es.resolve[checkName]) == 0 {
+		delete(es.resolve, checkName)
+	}
+}
+
+// getResolveWarnings will safely get the errors of each check, sorted
 func (es *acErrorStats) getResolveWarnings() map[string][]string {
 	es.m.RLock()
 	defer es.m.RUnlock()
 
 	resolveCopy := make(map[string][]string)
 	for k, v := range es.resolve {
-		resolveCopy[k] = v
+		warnings := make([]string, 0, len(v))
+		for _, warning := range v {
+			warnings = append(warnings, warning)
+		}
+		sort.Strings(warnings)
+		resolveCopy[k] = warnings
 	}
 
 	return resolveCopy
 }
+
+// getServiceResolveWarnings will safely get the errors of the templates resolved
+// against a service, by service, sorted
+func (es *acErrorStats) getServiceResolveWarnings() map[string][]string {
+	es.m.RLock()
+	defer es.m.RUnlock()
+
+	warnings := make(map[string][]string)
+	for _, v := range es.resolve {
+		for key, warning := range v {
+			if key.service != "" {
+				warnings[key.service] = append(warnings[key.service], warning)
+			}
+		}
+	}
+	for _, w := range warnings {
+		sort.Strings(w)
+	}
+	return warnings
+}
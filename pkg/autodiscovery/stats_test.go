@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 )
 
 func TestNewAcErrorStats(t *testing.T) {
@@ -42,3 +44,28 @@ func TestGetConfigErrors(t *testing.T) {
 
 	assert.Len(t, err, 1)
 }
+
+func TestResolveWarnings(t *testing.T) {
+	s := newAcErrorStats()
+	tpl := integration.Config{Name: "redis", ADIdentifiers: []string{"redis"}, Source: "file:redis.yaml"}
+	other := integration.Config{Name: "redis", ADIdentifiers: []string{"redis"}, Source: "kubelet:pod"}
+
+	s.setResolveWarning(tpl, "", "redis", "no service")
+	s.setResolveWarning(tpl, "svc1", "", "error 1")
+	s.setResolveWarning(tpl, "svc1", "", "error 1 again")
+	s.setResolveWarning(tpl, "svc2", "", "error 2")
+	s.setResolveWarning(other, "svc1", "", "error other")
+	assert.Equal(t, map[string][]string{"redis": {"error 1 again", "error 2", "error other", "no service"}}, s.getResolveWarnings())
+
+	// resolving the template against svc2 clears its error and the template-wide ones
+	s.removeResolveWarnings(tpl, "svc2")
+	assert.Equal(t, map[string][]string{"redis": {"error 1 again", "error other"}}, s.getResolveWarnings())
+
+	s.removeServiceResolveWarnings("svc1")
+	assert.Empty(t, s.getResolveWarnings())
+
+	s.setResolveWarning(tpl, "svc1", "", "error 1")
+	s.setResolveWarning(other, "svc1", "", "error other")
+	s.removeTemplateResolveWarnings(other)
+	assert.Equal(t, map[string][]string{"redis": {"error 1"}}, s.getResolveWarnings())
+}
@@ -193,6 +193,12 @@ func (c *ControllerV1) generateTemplates() {
 		webhooks = append(webhooks, webhook)
 	}
 
+	// Tracing libraries injection
+	if config.Datadog.GetBool("admission_controller.auto_instrumentation.enabled") {
+		webhook := c.getWebhookSkeleton("lib", config.Datadog.GetString("admission_controller.auto_instrumentation.endpoint"))
+		webhooks = append(webhooks, webhook)
+	}
+
 	c.webhookTemplates = webhooks
 }
 
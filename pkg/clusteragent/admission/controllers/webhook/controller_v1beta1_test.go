@@ -313,7 +313,31 @@ func TestGenerateTemplatesV1beta1(t *testing.T) {
 				return []admiv1beta1.MutatingWebhook{webhookConfig, webhookTags}
 			},
 		},
+		{
+			name: "auto instrumentation, mutate labelled",
+			setupConfig: func() {
+				mockConfig.Set("admission_controller.mutate_unlabelled", false)
+				mockConfig.Set("admission_controller.inject_config.enabled", false)
+				mockConfig.Set("admission_controller.inject_tags.enabled", false)
+				mockConfig.Set("admission_controller.auto_instrumentation.enabled", true)
+				mockConfig.Set("admission_controller.namespace_selector_fallback", false)
+			},
+			configFunc: func() Config { return NewConfig(false, false) },
+			want: func() []admiv1beta1.MutatingWebhook {
+				webhook := webhook("datadog.webhook.lib", "/injectlib", &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"admission.datadoghq.com/enabled": "true",
+					},
+				}, nil)
+				return []admiv1beta1.MutatingWebhook{webhook}
+			},
+		},
 	}
+	defer func() {
+		mockConfig.Set("admission_controller.auto_instrumentation.enabled", false)
+		mockConfig.Set("admission_controller.inject_config.enabled", true)
+		mockConfig.Set("admission_controller.inject_tags.enabled", true)
+	}()
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.setupConfig()
@@ -0,0 +1,313 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+
+package mutate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/metrics"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// Pods select the library to inject with one of these annotations per language
+	// e.g. admission.datadoghq.com/java-lib.version: "v0.94.1"
+	libVersionAnnotationKeyFormat     = "admission.datadoghq.com/%s-lib.version"
+	customLibAnnotationKeyFormat      = "admission.datadoghq.com/%s-lib.custom-image"
+	libImageNameFormat                = "dd-lib-%s-init"
+	libInitContainerNameFormat        = "datadog-lib-%s-init"
+	libVolumeName                     = "datadog-auto-instrumentation"
+	libMountPath                      = "/datadog-lib"
+	javaToolOptionsEnvVarName         = "JAVA_TOOL_OPTIONS"
+	nodeOptionsEnvVarName             = "NODE_OPTIONS"
+	pythonPathEnvVarName              = "PYTHONPATH"
+	javaToolOptionsEnvVarValue        = " -javaagent:/datadog-lib/dd-java-agent.jar"
+	nodeOptionsEnvVarValue            = " --require=/datadog-lib/node_modules/dd-trace/init"
+	pythonPathEnvVarValue             = "/datadog-lib/"
+	autoInstrumentationConfigPrefix   = "admission_controller.auto_instrumentation."
+	autoInstrumentationRegistryConfig = autoInstrumentationConfigPrefix + "container_registry"
+	// Namespaces opt in the library injection with this label, or by being listed
+	// in admission_controller.auto_instrumentation.enabled_namespaces
+	autoInstrumentationNsLabelKey   = "admission.datadoghq.com/auto-instrumentation.enabled"
+	autoInstrumentationNsLabelValue = "true"
+	nsLabelsCacheKeyPrefix          = "auto_instrumentation_ns_labels"
+)
+
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+type language string
+
+const (
+	java   language = "java"
+	js     language = "js"
+	python language = "python"
+)
+
+// supportedLanguages are the languages whose tracing library can be injected
+var supportedLanguages = []language{java, js, python}
+
+// libInfo holds the information needed to inject the tracing library of a language
+type libInfo struct {
+	lang  language
+	image string
+}
+
+// envValFunc returns the value of a language env var given its current value
+type envValFunc func(string) string
+
+// libEnvVars are the env vars making the runtimes load the injected libraries
+var libEnvVars = map[language]struct {
+	name  string
+	value envValFunc
+}{
+	java: {
+		name:  javaToolOptionsEnvVarName,
+		value: func(predefinedVal string) string { return predefinedVal + javaToolOptionsEnvVarValue },
+	},
+	js: {
+		name:  nodeOptionsEnvVarName,
+		value: func(predefinedVal string) string { return predefinedVal + nodeOptionsEnvVarValue },
+	},
+	python: {
+		name: pythonPathEnvVarName,
+		value: func(predefinedVal string) string {
+			if predefinedVal == "" {
+				return pythonPathEnvVarValue
+			}
+			return pythonPathEnvVarValue + ":" + predefinedVal
+		},
+	},
+}
+
+// InjectAutoInstrumentation adds the init containers copying the tracing libraries
+// requested in the pod annotations, and configures the containers to load them
+func InjectAutoInstrumentation(rawPod []byte, ns string, dc dynamic.Interface) ([]byte, error) {
+	return mutate(rawPod, ns, injectAutoInstrumentation, dc)
+}
+
+// injectAutoInstrumentation injects the tracing libraries into a pod template if needed
+func injectAutoInstrumentation(pod *corev1.Pod, ns string, dc dynamic.Interface) error {
+	var injected bool
+	defer func() {
+		metrics.MutationAttempts.Inc(metrics.LibInjectionMutationType, strconv.FormatBool(injected))
+	}()
+
+	if pod == nil {
+		metrics.MutationErrors.Inc(metrics.LibInjectionMutationType, "nil pod")
+		return errors.New("cannot inject lib into nil pod")
+	}
+
+	if !isNsEnabledForAutoInstrumentation(ns, dc) {
+		return nil
+	}
+
+	libs := extractLibInfo(pod, config.Datadog.GetString(autoInstrumentationRegistryConfig))
+	if len(libs) == 0 {
+		return nil
+	}
+
+	if err := injectLibs(pod, libs); err != nil {
+		metrics.MutationErrors.Inc(metrics.LibInjectionMutationType, "env var from reference")
+		return err
+	}
+
+	injected = true
+	return nil
+}
+
+// isNsEnabledForAutoInstrumentation returns whether the namespace opted in the
+// library injection, either by being listed in the configuration or by being labelled.
+func isNsEnabledForAutoInstrumentation(ns string, dc dynamic.Interface) bool {
+	enabledNamespaces := config.Datadog.GetStringSlice(autoInstrumentationConfigPrefix + "enabled_namespaces")
+	for _, enabledNs := range enabledNamespaces {
+		if enabledNs == ns {
+			return true
+		}
+	}
+
+	if dc == nil {
+		return false
+	}
+
+	labels, err := getAndCacheNsLabels(ns, dc)
+	if err != nil {
+		log.Debugf("Cannot get the labels of namespace %s, not injecting libraries: %v", ns, err)
+		return false
+	}
+
+	return labels[autoInstrumentationNsLabelKey] == autoInstrumentationNsLabelValue
+}
+
+// getAndCacheNsLabels tries to fetch the labels of a namespace from cache before querying the api server
+func getAndCacheNsLabels(ns string, dc dynamic.Interface) (map[string]string, error) {
+	cacheKey := cache.BuildAgentKey(nsLabelsCacheKeyPrefix, ns)
+	if cached, hit := cache.Cache.Get(cacheKey); hit {
+		if labels, valid := cached.(map[string]string); valid {
+			return labels, nil
+		}
+		log.Debugf("Invalid labels for namespace %s, forcing a cache miss", ns)
+	}
+
+	obj, err := dc.Resource(namespacesGVR).Get(context.TODO(), ns, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	labels := obj.GetLabels()
+	cache.Cache.Set(cacheKey, labels, ownerCacheTTL)
+	return labels, nil
+}
+
+// extractLibInfo returns the libraries to inject, based on the pod annotations
+func extractLibInfo(pod *corev1.Pod, containerRegistry string) []libInfo {
+	libs := []libInfo{}
+	annotations := pod.GetAnnotations()
+	for _, lang := range supportedLanguages {
+		if image, found := annotations[fmt.Sprintf(customLibAnnotationKeyFormat, lang)]; found {
+			log.Debugf("Found %s library custom image %s for pod %s", lang, image, podString(pod))
+			libs = append(libs, libInfo{lang: lang, image: image})
+			continue
+		}
+
+		if version, found := annotations[fmt.Sprintf(libVersionAnnotationKeyFormat, lang)]; found {
+			image := fmt.Sprintf("%s/%s:%s", strings.TrimSuffix(containerRegistry, "/"), fmt.Sprintf(libImageNameFormat, lang), version)
+			log.Debugf("Found %s library version %s for pod %s", lang, version, podString(pod))
+			libs = append(libs, libInfo{lang: lang, image: image})
+		}
+	}
+
+	return libs
+}
+
+// injectLibs adds the shared volume and the init containers, and sets the env
+// vars of the containers to load the libraries
+func injectLibs(pod *corev1.Pod, libs []libInfo) error {
+	// Check that all the env vars can be set before mutating the pod
+	for _, lib := range libs {
+		envVar := libEnvVars[lib.lang]
+		for _, ctr := range pod.Spec.Containers {
+			for _, env := range ctr.Env {
+				if env.Name == envVar.name && env.ValueFrom != nil {
+					return fmt.Errorf("%s is defined from a reference in container %s, cannot inject the %s library", envVar.name, ctr.Name, lib.lang)
+				}
+			}
+		}
+	}
+
+	injectLibVolume(pod)
+
+	for _, lib := range libs {
+		injectLibInitContainer(pod, lib)
+
+		envVar := libEnvVars[lib.lang]
+		injectLibEnv(pod, envVar.name, envVar.value)
+	}
+
+	return nil
+}
+
+// injectLibVolume adds the volume holding the libraries to the pod, and mounts
+// it in all the containers
+func injectLibVolume(pod *corev1.Pod) {
+	volumeFound := false
+	for _, vol := range pod.Spec.Volumes {
+		if vol.Name == libVolumeName {
+			volumeFound = true
+			break
+		}
+	}
+
+	if !volumeFound {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+			Name: libVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+
+	volumeMount := corev1.VolumeMount{Name: libVolumeName, MountPath: libMountPath}
+	for i, ctr := range pod.Spec.Containers {
+		if containsVolumeMount(ctr.VolumeMounts, libVolumeName) {
+			continue
+		}
+		pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, volumeMount)
+	}
+}
+
+// injectLibInitContainer adds the init container copying the library of a
+// language into the shared volume
+func injectLibInitContainer(pod *corev1.Pod, lib libInfo) {
+	name := fmt.Sprintf(libInitContainerNameFormat, lib.lang)
+	for _, ctr := range pod.Spec.InitContainers {
+		if ctr.Name == name {
+			log.Debugf("Init container %s already exists in pod %s", name, podString(pod))
+			return
+		}
+	}
+
+	log.Debugf("Injecting init container %s with image %s into pod %s", name, lib.image, podString(pod))
+	pod.Spec.InitContainers = append(pod.Spec.InitContainers, corev1.Container{
+		Name:    name,
+		Image:   lib.image,
+		Command: []string{"sh", "copy-lib.sh", libMountPath},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      libVolumeName,
+				MountPath: libMountPath,
+			},
+		},
+	})
+}
+
+// injectLibEnv sets a language env var in all the containers, keeping the
+// value it may already have
+func injectLibEnv(pod *corev1.Pod, name string, value envValFunc) {
+	for i, ctr := range pod.Spec.Containers {
+		index := -1
+		for j, env := range ctr.Env {
+			if env.Name == name {
+				index = j
+				break
+			}
+		}
+
+		if index < 0 {
+			pod.Spec.Containers[i].Env = append(pod.Spec.Containers[i].Env, corev1.EnvVar{Name: name, Value: value("")})
+			continue
+		}
+
+		current := ctr.Env[index].Value
+		if strings.Contains(current, strings.TrimSpace(value(""))) {
+			log.Debugf("Ignoring container '%s' in pod %s: env var '%s' already loads the library", ctr.Name, podString(pod), name)
+			continue
+		}
+		pod.Spec.Containers[i].Env[index].Value = value(current)
+	}
+}
+
+// containsVolumeMount returns whether VolumeMount slice contains a volume mount with a given name
+func containsVolumeMount(volumeMounts []corev1.VolumeMount, name string) bool {
+	for _, mount := range volumeMounts {
+		if mount.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,278 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+
+package mutate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func Test_extractLibInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want []libInfo
+	}{
+		{
+			name: "no annotation",
+			pod:  fakePodWithAnnotations("pod", nil, fakeContainer("ctr")),
+			want: []libInfo{},
+		},
+		{
+			name: "java version",
+			pod: fakePodWithAnnotations("pod", map[string]string{
+				"admission.datadoghq.com/java-lib.version": "v0.94.1",
+			}, fakeContainer("ctr")),
+			want: []libInfo{{lang: java, image: "registry/dd-lib-java-init:v0.94.1"}},
+		},
+		{
+			name: "js custom image",
+			pod: fakePodWithAnnotations("pod", map[string]string{
+				"admission.datadoghq.com/js-lib.custom-image": "my-registry/dd-trace-js:latest",
+			}, fakeContainer("ctr")),
+			want: []libInfo{{lang: js, image: "my-registry/dd-trace-js:latest"}},
+		},
+		{
+			name: "custom image takes precedence",
+			pod: fakePodWithAnnotations("pod", map[string]string{
+				"admission.datadoghq.com/python-lib.version":      "v1.0.0",
+				"admission.datadoghq.com/python-lib.custom-image": "my-registry/dd-trace-py:latest",
+			}, fakeContainer("ctr")),
+			want: []libInfo{{lang: python, image: "my-registry/dd-trace-py:latest"}},
+		},
+		{
+			name: "several languages",
+			pod: fakePodWithAnnotations("pod", map[string]string{
+				"admission.datadoghq.com/python-lib.version": "v1.0.0",
+				"admission.datadoghq.com/java-lib.version":   "v0.94.1",
+				"admission.datadoghq.com/ruby-lib.version":   "v1.0.0",
+			}, fakeContainer("ctr")),
+			want: []libInfo{
+				{lang: java, image: "registry/dd-lib-java-init:v0.94.1"},
+				{lang: python, image: "registry/dd-lib-python-init:v1.0.0"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, extractLibInfo(tt.pod, "registry/"))
+		})
+	}
+}
+
+func fakeNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		TypeMeta:   metav1.TypeMeta{Kind: "Namespace", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+	}
+}
+
+func Test_isNsEnabledForAutoInstrumentation(t *testing.T) {
+	mockConfig := config.Mock()
+	defer mockConfig.Set("admission_controller.auto_instrumentation.enabled_namespaces", []string{})
+	defer cache.Cache.Flush()
+
+	dc := fake.NewSimpleDynamicClient(scheme,
+		fakeNamespace("labelled", map[string]string{"admission.datadoghq.com/auto-instrumentation.enabled": "true"}),
+		fakeNamespace("disabled", map[string]string{"admission.datadoghq.com/auto-instrumentation.enabled": "false"}),
+		fakeNamespace("default", nil),
+	)
+
+	// Namespaces are not opted in by default
+	mockConfig.Set("admission_controller.auto_instrumentation.enabled_namespaces", []string{})
+	assert.False(t, isNsEnabledForAutoInstrumentation("default", nil))
+	assert.False(t, isNsEnabledForAutoInstrumentation("default", dc))
+	assert.False(t, isNsEnabledForAutoInstrumentation("disabled", dc))
+	assert.False(t, isNsEnabledForAutoInstrumentation("unknown", dc))
+	assert.True(t, isNsEnabledForAutoInstrumentation("labelled", dc))
+
+	mockConfig.Set("admission_controller.auto_instrumentation.enabled_namespaces", []string{"apps", "jobs"})
+	assert.True(t, isNsEnabledForAutoInstrumentation("apps", nil))
+	assert.False(t, isNsEnabledForAutoInstrumentation("default", dc))
+	assert.True(t, isNsEnabledForAutoInstrumentation("labelled", dc))
+
+	// The labels are cached
+	actions := len(dc.Actions())
+	assert.True(t, isNsEnabledForAutoInstrumentation("labelled", dc))
+	assert.Len(t, dc.Actions(), actions)
+}
+
+func Test_injectLibEnv(t *testing.T) {
+	tests := []struct {
+		name string
+		lang language
+		env  []corev1.EnvVar
+		want []corev1.EnvVar
+	}{
+		{
+			name: "java, no env var",
+			lang: java,
+			want: []corev1.EnvVar{fakeEnvWithValue("JAVA_TOOL_OPTIONS", " -javaagent:/datadog-lib/dd-java-agent.jar")},
+		},
+		{
+			name: "java, predefined env var",
+			lang: java,
+			env:  []corev1.EnvVar{fakeEnvWithValue("JAVA_TOOL_OPTIONS", "-Xmx1g")},
+			want: []corev1.EnvVar{fakeEnvWithValue("JAVA_TOOL_OPTIONS", "-Xmx1g -javaagent:/datadog-lib/dd-java-agent.jar")},
+		},
+		{
+			name: "java, already injected",
+			lang: java,
+			env:  []corev1.EnvVar{fakeEnvWithValue("JAVA_TOOL_OPTIONS", "-Xmx1g -javaagent:/datadog-lib/dd-java-agent.jar")},
+			want: []corev1.EnvVar{fakeEnvWithValue("JAVA_TOOL_OPTIONS", "-Xmx1g -javaagent:/datadog-lib/dd-java-agent.jar")},
+		},
+		{
+			name: "js, predefined env var",
+			lang: js,
+			env:  []corev1.EnvVar{fakeEnvWithValue("NODE_OPTIONS", "--max-old-space-size=512")},
+			want: []corev1.EnvVar{fakeEnvWithValue("NODE_OPTIONS", "--max-old-space-size=512 --require=/datadog-lib/node_modules/dd-trace/init")},
+		},
+		{
+			name: "python, no env var",
+			lang: python,
+			want: []corev1.EnvVar{fakeEnvWithValue("PYTHONPATH", "/datadog-lib/")},
+		},
+		{
+			name: "python, predefined env var",
+			lang: python,
+			env:  []corev1.EnvVar{fakeEnvWithValue("PYTHONPATH", "/app")},
+			want: []corev1.EnvVar{fakeEnvWithValue("PYTHONPATH", "/datadog-lib/:/app")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := fakePodWithContainer("pod", corev1.Container{Name: "ctr", Env: tt.env})
+			envVar := libEnvVars[tt.lang]
+			injectLibEnv(pod, envVar.name, envVar.value)
+			assert.Equal(t, tt.want, pod.Spec.Containers[0].Env)
+		})
+	}
+}
+
+func Test_injectLibs(t *testing.T) {
+	pod := fakePodWithContainer("pod", fakeContainer("foo"), fakeContainer("bar"))
+	pod.Spec.InitContainers = []corev1.Container{{Name: "datadog-lib-python-init", Image: "custom"}}
+
+	err := injectLibs(pod, []libInfo{
+		{lang: java, image: "registry/dd-lib-java-init:v0.94.1"},
+		{lang: python, image: "registry/dd-lib-python-init:v1.0.0"},
+	})
+	require.NoError(t, err)
+
+	// Injecting twice doesn't change the pod
+	err = injectLibs(pod, []libInfo{{lang: java, image: "registry/dd-lib-java-init:v0.94.1"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, []corev1.Volume{
+		{
+			Name:         "datadog-auto-instrumentation",
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		},
+	}, pod.Spec.Volumes)
+
+	mount := corev1.VolumeMount{Name: "datadog-auto-instrumentation", MountPath: "/datadog-lib"}
+	assert.Equal(t, []corev1.Container{
+		{Name: "datadog-lib-python-init", Image: "custom"},
+		{
+			Name:         "datadog-lib-java-init",
+			Image:        "registry/dd-lib-java-init:v0.94.1",
+			Command:      []string{"sh", "copy-lib.sh", "/datadog-lib"},
+			VolumeMounts: []corev1.VolumeMount{mount},
+		},
+	}, pod.Spec.InitContainers)
+
+	for _, ctr := range pod.Spec.Containers {
+		assert.Equal(t, []corev1.VolumeMount{mount}, ctr.VolumeMounts)
+		assert.Contains(t, ctr.Env, fakeEnvWithValue("JAVA_TOOL_OPTIONS", " -javaagent:/datadog-lib/dd-java-agent.jar"))
+		assert.Contains(t, ctr.Env, fakeEnvWithValue("PYTHONPATH", "/datadog-lib/"))
+		assert.Len(t, ctr.Env, 4)
+	}
+
+	// Env vars defined from references cannot be updated
+	pod = fakePodWithContainer("pod", corev1.Container{
+		Name: "ctr",
+		Env: []corev1.EnvVar{
+			{
+				Name:      "NODE_OPTIONS",
+				ValueFrom: &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "node-options"}},
+			},
+		},
+	})
+	err = injectLibs(pod, []libInfo{{lang: js, image: "registry/dd-lib-js-init:v2.0.0"}})
+	assert.Error(t, err)
+	assert.Empty(t, pod.Spec.Volumes)
+	assert.Empty(t, pod.Spec.InitContainers)
+}
+
+func Test_injectAutoInstrumentation(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.auto_instrumentation.container_registry", "registry")
+	mockConfig.Set("admission_controller.auto_instrumentation.enabled_namespaces", []string{"apps"})
+	defer mockConfig.Set("admission_controller.auto_instrumentation.enabled_namespaces", []string{})
+
+	annotations := map[string]string{"admission.datadoghq.com/js-lib.version": "v2.0.0"}
+
+	assert.Error(t, injectAutoInstrumentation(nil, "apps", nil))
+
+	// Namespace not opted in
+	pod := fakePodWithAnnotations("pod", annotations, fakeContainer("ctr"))
+	require.NoError(t, injectAutoInstrumentation(pod, "default", nil))
+	assert.Empty(t, pod.Spec.InitContainers)
+
+	// No library requested
+	pod = fakePodWithAnnotations("pod", nil, fakeContainer("ctr"))
+	require.NoError(t, injectAutoInstrumentation(pod, "apps", nil))
+	assert.Empty(t, pod.Spec.InitContainers)
+
+	pod = fakePodWithAnnotations("pod", annotations, fakeContainer("ctr"))
+	require.NoError(t, injectAutoInstrumentation(pod, "apps", nil))
+	require.Len(t, pod.Spec.InitContainers, 1)
+	assert.Equal(t, "registry/dd-lib-js-init:v2.0.0", pod.Spec.InitContainers[0].Image)
+	assert.Contains(t, pod.Spec.Containers[0].Env, fakeEnvWithValue("NODE_OPTIONS", " --require=/datadog-lib/node_modules/dd-trace/init"))
+}
+
+func TestInjectAutoInstrumentation(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.auto_instrumentation.container_registry", "registry")
+
+	rawPod, err := json.Marshal(fakePodWithAnnotations("pod", map[string]string{
+		"admission.datadoghq.com/java-lib.version": "v0.94.1",
+	}, fakeContainer("ctr")))
+	require.NoError(t, err)
+
+	// Namespace not opted in
+	rawPatch, err := InjectAutoInstrumentation(rawPod, "default", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "[]", string(rawPatch))
+
+	mockConfig.Set("admission_controller.auto_instrumentation.enabled_namespaces", []string{"default"})
+	defer mockConfig.Set("admission_controller.auto_instrumentation.enabled_namespaces", []string{})
+
+	rawPatch, err = InjectAutoInstrumentation(rawPod, "default", nil)
+	require.NoError(t, err)
+
+	var patch []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rawPatch, &patch))
+
+	paths := []string{}
+	for _, op := range patch {
+		paths = append(paths, op["path"].(string))
+	}
+	assert.Contains(t, paths, "/spec/initContainers")
+	assert.Contains(t, paths, "/spec/volumes")
+	assert.Contains(t, paths, "/spec/containers/0/volumeMounts")
+	assert.Contains(t, paths, "/spec/containers/0/env/2")
+}
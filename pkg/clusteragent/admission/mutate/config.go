@@ -9,11 +9,14 @@ package mutate
 
 import (
 	"errors"
+	"fmt"
+	"path/filepath"
 	"strconv"
 
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/common"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/metrics"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	apiservercommon "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	corev1 "k8s.io/api/core/v1"
@@ -21,8 +24,18 @@ import (
 )
 
 const (
-	agentHostEnvVarName  = "DD_AGENT_HOST"
-	ddEntityIDEnvVarName = "DD_ENTITY_ID"
+	agentHostEnvVarName      = "DD_AGENT_HOST"
+	ddEntityIDEnvVarName     = "DD_ENTITY_ID"
+	traceURLEnvVarName       = "DD_TRACE_AGENT_URL"
+	dogstatsdURLEnvVarName   = "DD_DOGSTATSD_URL"
+	datadogVolumeName        = "datadog"
+	traceURLSocketFormat     = "unix://%s"
+	dogstatsdURLSocketFormat = "unix://%s"
+
+	// Supported config injection modes
+	hostIP  = "hostip"
+	service = "service"
+	socket  = "socket"
 )
 
 var (
@@ -48,15 +61,16 @@ var (
 )
 
 // InjectConfig adds the DD_AGENT_HOST and DD_ENTITY_ID env vars to the pod template if they don't exist
+// In socket mode, it mounts the agent sockets and sets DD_TRACE_AGENT_URL and DD_DOGSTATSD_URL instead of DD_AGENT_HOST
 func InjectConfig(rawPod []byte, ns string, dc dynamic.Interface) ([]byte, error) {
 	return mutate(rawPod, ns, injectConfig, dc)
 }
 
 // injectConfig injects DD_AGENT_HOST and DD_ENTITY_ID into a pod template if needed
 func injectConfig(pod *corev1.Pod, _ string, _ dynamic.Interface) error {
-	var injectedHost, injectedEntity bool
+	var injectedConfig, injectedEntity bool
 	defer func() {
-		metrics.MutationAttempts.Inc(metrics.ConfigMutationType, strconv.FormatBool(injectedHost || injectedEntity))
+		metrics.MutationAttempts.Inc(metrics.ConfigMutationType, strconv.FormatBool(injectedConfig || injectedEntity))
 	}()
 
 	if pod == nil {
@@ -64,14 +78,123 @@ func injectConfig(pod *corev1.Pod, _ string, _ dynamic.Interface) error {
 		return errors.New("cannot inject config into nil pod")
 	}
 
-	if shouldInjectConf(pod) {
-		injectedHost = injectEnv(pod, agentHostEnvVar)
-		injectedEntity = injectEnv(pod, ddEntityIDEnvVar)
+	if !shouldInjectConf(pod) {
+		return nil
+	}
+
+	switch mode := injectionMode(); mode {
+	case hostIP:
+		injectedConfig = injectEnv(pod, agentHostEnvVar)
+	case service:
+		injectedConfig = injectEnv(pod, agentServiceEnvVar())
+	case socket:
+		injectedConfig = injectSockets(pod)
+	default:
+		metrics.MutationErrors.Inc(metrics.ConfigMutationType, "unknown mode")
+		return fmt.Errorf("invalid injection mode %q", mode)
 	}
 
+	injectedEntity = injectEnv(pod, ddEntityIDEnvVar)
+
 	return nil
 }
 
+// injectionMode returns the configured config injection mode
+func injectionMode() string {
+	return config.Datadog.GetString("admission_controller.inject_config.mode")
+}
+
+// agentServiceEnvVar returns the DD_AGENT_HOST env var pointing to the
+// local agent service, that only routes to the node agent of the pod
+func agentServiceEnvVar() corev1.EnvVar {
+	return corev1.EnvVar{
+		Name:  agentHostEnvVarName,
+		Value: fmt.Sprintf("%s.%s.svc.cluster.local", config.Datadog.GetString("admission_controller.inject_config.local_service_name"), apiservercommon.GetMyNamespace()),
+	}
+}
+
+// injectSockets mounts the directories of the DogStatsD and trace agent
+// sockets, and sets DD_DOGSTATSD_URL and DD_TRACE_AGENT_URL to use them
+func injectSockets(pod *corev1.Pod) bool {
+	traceSocket := config.Datadog.GetString("admission_controller.inject_config.trace_agent_socket")
+	dogstatsdSocket := config.Datadog.GetString("admission_controller.inject_config.dogstatsd_socket")
+
+	injectedTrace := injectSocket(pod, traceSocket, datadogVolumeName+"-trace", traceURLEnvVarName, traceURLSocketFormat)
+	// Both sockets are usually in the same directory, which only needs to be mounted once
+	injectedDogstatsd := injectSocket(pod, dogstatsdSocket, datadogVolumeName+"-dogstatsd", dogstatsdURLEnvVarName, dogstatsdURLSocketFormat)
+
+	return injectedTrace || injectedDogstatsd
+}
+
+// injectSocket mounts the directory of a socket into the containers using a
+// hostPath volume, and sets the env var holding its URL
+func injectSocket(pod *corev1.Pod, socketPath, volumeName, envVarName, urlFormat string) bool {
+	dir := filepath.Dir(socketPath)
+	volumeName = injectHostPathVolume(pod, volumeName, dir)
+
+	injectedMount := injectVolumeMount(pod, corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: dir,
+		ReadOnly:  true,
+	})
+	injectedEnv := injectEnv(pod, corev1.EnvVar{
+		Name:  envVarName,
+		Value: fmt.Sprintf(urlFormat, socketPath),
+	})
+
+	return injectedMount || injectedEnv
+}
+
+// injectHostPathVolume adds a hostPath volume to the pod if none mounts the
+// given path yet, and returns the name of the volume mounting it
+func injectHostPathVolume(pod *corev1.Pod, name, path string) string {
+	for _, vol := range pod.Spec.Volumes {
+		if vol.HostPath != nil && vol.HostPath.Path == path {
+			return vol.Name
+		}
+	}
+
+	hostPathType := corev1.HostPathDirectoryOrCreate
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: name,
+		VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{
+				Path: path,
+				Type: &hostPathType,
+			},
+		},
+	})
+
+	return name
+}
+
+// injectVolumeMount mounts a volume into the containers, unless they already
+// use its name or its mount path
+func injectVolumeMount(pod *corev1.Pod, volumeMount corev1.VolumeMount) bool {
+	injected := false
+	podStr := podString(pod)
+	for i, ctr := range pod.Spec.Containers {
+		if containsMount(ctr.VolumeMounts, volumeMount) {
+			log.Debugf("Ignoring container '%s' in pod %s: volume mount '%s' already exist", ctr.Name, podStr, volumeMount.Name)
+			continue
+		}
+		pod.Spec.Containers[i].VolumeMounts = append(pod.Spec.Containers[i].VolumeMounts, volumeMount)
+		injected = true
+	}
+	return injected
+}
+
+// containsMount returns whether VolumeMount slice contains a mount of the
+// same volume or at the same path
+func containsMount(volumeMounts []corev1.VolumeMount, volumeMount corev1.VolumeMount) bool {
+	for _, mount := range volumeMounts {
+		if mount.Name == volumeMount.Name || mount.MountPath == volumeMount.MountPath {
+			return true
+		}
+	}
+	return false
+}
+
 // shouldInjectConf returns whether the config should be injected
 // based on the pod labels and the cluster agent config
 func shouldInjectConf(pod *corev1.Pod) bool {
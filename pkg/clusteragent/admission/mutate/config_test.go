@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -68,3 +69,103 @@ func Test_shouldInjectConf(t *testing.T) {
 		})
 	}
 }
+
+func Test_injectConfig(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.mutate_unlabelled", true)
+	defer mockConfig.Set("admission_controller.mutate_unlabelled", false)
+	defer mockConfig.Set("admission_controller.inject_config.mode", "hostip")
+
+	hostPathType := corev1.HostPathDirectoryOrCreate
+	tests := []struct {
+		name        string
+		mode        string
+		pod         *corev1.Pod
+		wantErr     bool
+		wantVolumes []corev1.Volume
+		wantMounts  []corev1.VolumeMount
+		wantEnvs    []corev1.EnvVar
+	}{
+		{
+			name:     "hostip mode",
+			mode:     "hostip",
+			pod:      fakePod("foo"),
+			wantEnvs: []corev1.EnvVar{agentHostEnvVar, ddEntityIDEnvVar},
+		},
+		{
+			name:     "service mode",
+			mode:     "service",
+			pod:      fakePod("foo"),
+			wantEnvs: []corev1.EnvVar{fakeEnvWithValue("DD_AGENT_HOST", "datadog.default.svc.cluster.local"), ddEntityIDEnvVar},
+		},
+		{
+			name: "socket mode",
+			mode: "socket",
+			pod:  fakePod("foo"),
+			wantVolumes: []corev1.Volume{
+				{
+					Name: "datadog-trace",
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{
+							Path: "/var/run/datadog",
+							Type: &hostPathType,
+						},
+					},
+				},
+			},
+			wantMounts: []corev1.VolumeMount{{Name: "datadog-trace", MountPath: "/var/run/datadog", ReadOnly: true}},
+			wantEnvs: []corev1.EnvVar{
+				fakeEnvWithValue("DD_TRACE_AGENT_URL", "unix:///var/run/datadog/apm.socket"),
+				fakeEnvWithValue("DD_DOGSTATSD_URL", "unix:///var/run/datadog/dsd.socket"),
+				ddEntityIDEnvVar,
+			},
+		},
+		{
+			name: "socket mode, directory already mounted",
+			mode: "socket",
+			pod: fakePodWithContainer("foo", corev1.Container{
+				Name:         "foo-container",
+				VolumeMounts: []corev1.VolumeMount{{Name: "sockets", MountPath: "/var/run/datadog"}},
+			}),
+			wantMounts: []corev1.VolumeMount{{Name: "sockets", MountPath: "/var/run/datadog"}},
+			wantVolumes: []corev1.Volume{
+				{
+					Name: "datadog-trace",
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{
+							Path: "/var/run/datadog",
+							Type: &hostPathType,
+						},
+					},
+				},
+			},
+			wantEnvs: []corev1.EnvVar{
+				fakeEnvWithValue("DD_TRACE_AGENT_URL", "unix:///var/run/datadog/apm.socket"),
+				fakeEnvWithValue("DD_DOGSTATSD_URL", "unix:///var/run/datadog/dsd.socket"),
+				ddEntityIDEnvVar,
+			},
+		},
+		{
+			name:    "unknown mode",
+			mode:    "foo",
+			pod:     fakePod("foo"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConfig.Set("admission_controller.inject_config.mode", tt.mode)
+
+			err := injectConfig(tt.pod, "", nil)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantVolumes, tt.pod.Spec.Volumes)
+			assert.Equal(t, tt.wantMounts, tt.pod.Spec.Containers[0].VolumeMounts)
+			assert.Equal(t, tt.wantEnvs, tt.pod.Spec.Containers[0].Env)
+		})
+	}
+}
@@ -57,6 +57,12 @@ func fakePodWithLabel(k, v string) *corev1.Pod {
 	}
 }
 
+func fakePodWithAnnotations(name string, annotations map[string]string, containers ...corev1.Container) *corev1.Pod {
+	pod := fakePodWithContainer(name, containers...)
+	pod.Annotations = annotations
+	return pod
+}
+
 func fakePodWithEnv(name, env string) *corev1.Pod {
 	return fakePodWithContainer(name, corev1.Container{Name: name + "-container", Env: []corev1.EnvVar{fakeEnv(env)}})
 }
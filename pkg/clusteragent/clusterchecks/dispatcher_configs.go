@@ -27,14 +27,19 @@ func (d *dispatcher) getState() (types.StateResponse, error) {
 	defer d.store.RUnlock()
 
 	response := types.StateResponse{
-		Warmup:   !d.store.active,
-		Dangling: makeConfigArray(d.store.danglingConfigs),
+		Warmup:             !d.store.active,
+		Dangling:           makeConfigArray(d.store.danglingConfigs),
+		Constraints:        d.constraints,
+		MaxChecksPerRunner: d.maxChecksPerRunner,
 	}
 	for _, node := range d.store.nodes {
+		node.RLock()
 		n := types.StateNodeResponse{
 			Name:    node.name,
+			Zone:    nodeZone(node.labels),
 			Configs: makeConfigArray(node.digestToConfig),
 		}
+		node.RUnlock()
 		response.Nodes = append(response.Nodes, n)
 	}
 
@@ -87,6 +92,7 @@ func (d *dispatcher) removeConfig(digest string) {
 	delete(d.store.digestToNode, digest)
 	delete(d.store.digestToConfig, digest)
 	delete(d.store.danglingConfigs, digest)
+	d.forgetConstraints(digest)
 
 	for k, v := range d.store.idToDigest {
 		if v == digest {
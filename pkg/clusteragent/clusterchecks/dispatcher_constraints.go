@@ -0,0 +1,256 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build clusterchecks
+
+package clusterchecks
+
+import (
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	zoneLabel           = "topology.kubernetes.io/zone"
+	legacyZoneLabel     = "failure-domain.beta.kubernetes.io/zone"
+	defaultConfigWeight = 1
+)
+
+// getDispatchingConstraints returns the dispatching constraints of the checks,
+// by check name, from the configuration
+func getDispatchingConstraints() map[string]types.DispatchingConstraints {
+	constraints := map[string]types.DispatchingConstraints{}
+	if err := config.Datadog.UnmarshalKey("cluster_checks.dispatching_constraints", &constraints); err != nil {
+		log.Errorf("Cannot parse the cluster checks dispatching constraints, they will be ignored: %v", err)
+		return map[string]types.DispatchingConstraints{}
+	}
+
+	for name, c := range constraints {
+		log.Debugf("Dispatching constraints for check %s: %+v", name, c)
+	}
+
+	return constraints
+}
+
+// configConstraintsOverride is the part of the init_config and of the instances
+// of a configuration overriding the dispatching constraints of its check
+type configConstraintsOverride struct {
+	DispatchingConstraints *types.DispatchingConstraints `yaml:"dispatching_constraints"`
+}
+
+// parseConfigConstraints returns the dispatching constraints set in a configuration, nil if
+// there is none. The constraints of an instance take precedence over the ones of init_config,
+// as the instances of a configuration are dispatched together, the first ones found are used.
+func parseConfigConstraints(config integration.Config) *types.DispatchingConstraints {
+	for _, instance := range config.Instances {
+		var override configConstraintsOverride
+		if err := yaml.Unmarshal(instance, &override); err != nil {
+			log.Debugf("Cannot parse the dispatching constraints of an instance of check %s: %v", config.Name, err)
+			continue
+		}
+		if override.DispatchingConstraints != nil {
+			return override.DispatchingConstraints
+		}
+	}
+
+	var override configConstraintsOverride
+	if err := yaml.Unmarshal(config.InitConfig, &override); err != nil {
+		log.Debugf("Cannot parse the dispatching constraints of the init_config of check %s: %v", config.Name, err)
+	}
+	return override.DispatchingConstraints
+}
+
+// getConstraints returns the dispatching constraints of a configuration: the ones set in
+// the configuration itself if any, the ones of its check name otherwise
+func (d *dispatcher) getConstraints(config integration.Config, digest string) types.DispatchingConstraints {
+	d.configConstraintsMutex.Lock()
+	constraints, found := d.configConstraints[digest]
+	if !found {
+		constraints = parseConfigConstraints(config)
+		d.configConstraints[digest] = constraints
+	}
+	d.configConstraintsMutex.Unlock()
+
+	if constraints != nil {
+		return *constraints
+	}
+	return d.constraints[config.Name]
+}
+
+// forgetConstraints removes the cached constraints of a configuration
+func (d *dispatcher) forgetConstraints(digest string) {
+	d.configConstraintsMutex.Lock()
+	delete(d.configConstraints, digest)
+	d.configConstraintsMutex.Unlock()
+}
+
+// nodeZone returns the zone of a node, based on its labels
+func nodeZone(labels map[string]string) string {
+	if zone, found := labels[zoneLabel]; found {
+		return zone
+	}
+	return labels[legacyZoneLabel]
+}
+
+// configWeight returns the weight of a configuration of a check, used
+// to compare the nodes when dispatching based on the number of checks
+func (d *dispatcher) configWeight(config integration.Config, digest string) int {
+	if c := d.getConstraints(config, digest); c.Weight > 0 {
+		return c.Weight
+	}
+	return defaultConfigWeight
+}
+
+// nodeLoad returns the sum of the weights of the configurations dispatched
+// to a node. The node lock is to be held by the caller.
+func (d *dispatcher) nodeLoad(node *nodeStore) int {
+	load := 0
+	for digest, config := range node.digestToConfig {
+		load += d.configWeight(config, digest)
+	}
+	return load
+}
+
+// isNodeAllowed returns whether a configuration of a check can run on a node
+// per the node selector, the anti-affinity and the maximum number of checks
+// per runner. The node lock is to be held by the caller.
+func (d *dispatcher) isNodeAllowed(node *nodeStore, config integration.Config, digest string) bool {
+	if node.name == "" {
+		// Dummy node holding the unscheduled configs
+		return false
+	}
+
+	constraints := d.getConstraints(config, digest)
+	for key, value := range constraints.NodeSelector {
+		if label, found := node.labels[key]; !found || label != value {
+			return false
+		}
+	}
+
+	_, alreadyOnNode := node.digestToConfig[digest]
+	if d.maxChecksPerRunner > 0 && !alreadyOnNode && len(node.digestToConfig) >= d.maxChecksPerRunner {
+		return false
+	}
+
+	for otherDigest, other := range node.digestToConfig {
+		if otherDigest == digest {
+			continue
+		}
+		// Anti-affinity is symmetric: a check avoiding another one
+		// also keeps the other check away from its nodes
+		if containsString(constraints.AntiAffinity, other.Name) || containsString(d.getConstraints(other, otherDigest).AntiAffinity, config.Name) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasAffinity returns whether a node runs one of the checks a check has
+// affinity with. The node lock is to be held by the caller.
+func (d *dispatcher) hasAffinity(node *nodeStore, config integration.Config, digest string) bool {
+	affinity := d.getConstraints(config, digest).Affinity
+	for otherDigest, other := range node.digestToConfig {
+		if otherDigest != digest && containsString(affinity, other.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// getAllowedNodes returns the nodes a configuration of a check can be
+// dispatched to. The nodes that do not satisfy the hard constraints are
+// excluded, then the nodes running checks the check has affinity with and
+// the nodes of the zones running the least instances of the check are
+// preferred, if any. The store lock is to be held by the caller.
+func (d *dispatcher) getAllowedNodes(config integration.Config, digest string) []*nodeStore {
+	allowed := []*nodeStore{}
+	preferred := []*nodeStore{}
+	for _, node := range d.store.nodes {
+		node.RLock()
+		if d.isNodeAllowed(node, config, digest) {
+			allowed = append(allowed, node)
+			if d.hasAffinity(node, config, digest) {
+				preferred = append(preferred, node)
+			}
+		}
+		node.RUnlock()
+	}
+
+	if len(preferred) > 0 {
+		allowed = preferred
+	}
+
+	if d.getConstraints(config, digest).SpreadZones {
+		allowed = d.filterLeastUsedZones(allowed, config.Name, digest)
+	}
+
+	return allowed
+}
+
+// filterLeastUsedZones keeps the nodes of the zones running the least
+// configurations of a check. The store lock is to be held by the caller.
+func (d *dispatcher) filterLeastUsedZones(nodes []*nodeStore, checkName, digest string) []*nodeStore {
+	checksPerZone := map[string]int{}
+	for _, node := range d.store.nodes {
+		node.RLock()
+		for otherDigest, config := range node.digestToConfig {
+			if otherDigest != digest && config.Name == checkName {
+				checksPerZone[nodeZone(node.labels)]++
+			}
+		}
+		node.RUnlock()
+	}
+
+	minCount := -1
+	for _, node := range nodes {
+		node.RLock()
+		count := checksPerZone[nodeZone(node.labels)]
+		node.RUnlock()
+		if minCount == -1 || count < minCount {
+			minCount = count
+		}
+	}
+
+	filtered := []*nodeStore{}
+	for _, node := range nodes {
+		node.RLock()
+		if checksPerZone[nodeZone(node.labels)] == minCount {
+			filtered = append(filtered, node)
+		}
+		node.RUnlock()
+	}
+
+	return filtered
+}
+
+// filterDiffMap keeps the nodes a configuration of a check can be moved to
+// in a rebalancing diff map
+func (d *dispatcher) filterDiffMap(diffMap map[string]int, config integration.Config, digest string) map[string]int {
+	d.store.RLock()
+	defer d.store.RUnlock()
+
+	filtered := make(map[string]int)
+	for _, node := range d.getAllowedNodes(config, digest) {
+		if diff, found := diffMap[node.name]; found {
+			filtered[node.name] = diff
+		}
+	}
+
+	return filtered
+}
+
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build clusterchecks
+
+package clusterchecks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func generateInstanceIntegration(name, instance string) integration.Config {
+	return integration.Config{
+		Name:         name,
+		ClusterCheck: true,
+		Instances:    []integration.Data{integration.Data("id: " + instance)},
+	}
+}
+
+func TestGetDispatchingConstraints(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("cluster_checks.dispatching_constraints", map[string]interface{}{
+		"postgres": map[string]interface{}{
+			"node_selector": map[string]interface{}{"pool": "monitoring"},
+			"anti_affinity": []interface{}{"postgres"},
+			"spread_zones":  true,
+			"weight":        3,
+		},
+	})
+	defer mockConfig.Set("cluster_checks.dispatching_constraints", nil)
+
+	assert.Equal(t, map[string]types.DispatchingConstraints{
+		"postgres": {
+			NodeSelector: map[string]string{"pool": "monitoring"},
+			AntiAffinity: []string{"postgres"},
+			SpreadZones:  true,
+			Weight:       3,
+		},
+	}, getDispatchingConstraints())
+}
+
+func TestDispatchingNodeSelector(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.constraints = map[string]types.DispatchingConstraints{
+		"postgres": {NodeSelector: map[string]string{"pool": "monitoring"}},
+	}
+
+	dispatcher.processNodeStatus("nodeA", "10.0.0.1", types.NodeStatus{Labels: map[string]string{"pool": "default"}})
+	dispatcher.processNodeStatus("nodeB", "10.0.0.2", types.NodeStatus{Labels: map[string]string{"pool": "monitoring"}})
+
+	// nodeB is the only node matching the selector, even when it's the busiest
+	dispatcher.addConfig(generateInstanceIntegration("http_check", "1"), "nodeB")
+	assert.Equal(t, "nodeB", dispatcher.getLeastBusyNode(generateInstanceIntegration("postgres", "1")))
+	assert.Equal(t, "nodeA", dispatcher.getLeastBusyNode(generateInstanceIntegration("http_check", "2")))
+
+	// Labels are kept when a node doesn't report them
+	dispatcher.processNodeStatus("nodeB", "10.0.0.2", types.NodeStatus{})
+	assert.Equal(t, "nodeB", dispatcher.getLeastBusyNode(generateInstanceIntegration("postgres", "1")))
+
+	// No node matching the selector
+	dispatcher.processNodeStatus("nodeB", "10.0.0.2", types.NodeStatus{Labels: map[string]string{}})
+	assert.Equal(t, "", dispatcher.getLeastBusyNode(generateInstanceIntegration("postgres", "1")))
+
+	requireNotLocked(t, dispatcher.store)
+}
+
+func TestDispatchingConfigConstraints(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.constraints = map[string]types.DispatchingConstraints{
+		"postgres": {NodeSelector: map[string]string{"pool": "monitoring"}},
+	}
+
+	dispatcher.processNodeStatus("nodeA", "10.0.0.1", types.NodeStatus{Labels: map[string]string{"pool": "default"}})
+	dispatcher.processNodeStatus("nodeB", "10.0.0.2", types.NodeStatus{Labels: map[string]string{"pool": "monitoring"}})
+	dispatcher.addConfig(generateInstanceIntegration("http_check", "1"), "nodeA")
+
+	// the constraints of init_config replace the ones of the check name
+	postgres := generateInstanceIntegration("postgres", "1")
+	postgres.InitConfig = integration.Data("dispatching_constraints:\n  node_selector:\n    pool: default")
+	assert.Equal(t, "nodeA", dispatcher.getLeastBusyNode(postgres))
+
+	// the constraints of an instance take precedence over the ones of init_config
+	postgres = generateInstanceIntegration("postgres", "2")
+	postgres.InitConfig = integration.Data("dispatching_constraints:\n  node_selector:\n    pool: default")
+	postgres.Instances = []integration.Data{integration.Data("id: 2\ndispatching_constraints:\n  anti_affinity: [http_check]")}
+	assert.Equal(t, "nodeB", dispatcher.getLeastBusyNode(postgres))
+
+	// the weight of a configuration is taken into account on its node
+	weighted := generateInstanceIntegration("redisdb", "1")
+	weighted.InitConfig = integration.Data("dispatching_constraints:\n  weight: 5")
+	dispatcher.addConfig(weighted, "nodeB")
+	assert.Equal(t, "nodeA", dispatcher.getLeastBusyNode(generateInstanceIntegration("http_check", "2")))
+
+	// the cached constraints are removed with the configuration
+	dispatcher.removeConfig(weighted.Digest())
+	assert.NotContains(t, dispatcher.configConstraints, weighted.Digest())
+
+	requireNotLocked(t, dispatcher.store)
+}
+
+func TestDispatchingAntiAffinity(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.constraints = map[string]types.DispatchingConstraints{
+		"postgres": {AntiAffinity: []string{"postgres"}},
+		"redis":    {AntiAffinity: []string{"mysql"}},
+	}
+
+	dispatcher.processNodeStatus("nodeA", "10.0.0.1", types.NodeStatus{})
+	dispatcher.processNodeStatus("nodeB", "10.0.0.2", types.NodeStatus{})
+
+	postgres1 := generateInstanceIntegration("postgres", "1")
+	dispatcher.add(postgres1)
+	postgres1Node := dispatcher.store.digestToNode[postgres1.Digest()]
+
+	postgres2 := generateInstanceIntegration("postgres", "2")
+	dispatcher.add(postgres2)
+	postgres2Node := dispatcher.store.digestToNode[postgres2.Digest()]
+	assert.NotEqual(t, postgres1Node, postgres2Node)
+
+	// Both nodes run a postgres instance
+	postgres3 := generateInstanceIntegration("postgres", "3")
+	dispatcher.add(postgres3)
+	assert.Contains(t, dispatcher.store.danglingConfigs, postgres3.Digest())
+
+	// An instance already dispatched can stay on its node
+	assert.Equal(t, postgres1Node, dispatcher.getLeastBusyNode(postgres1))
+
+	// Anti-affinity is symmetric
+	dispatcher.addConfig(generateInstanceIntegration("redis", "1"), "nodeA")
+	dispatcher.addConfig(generateInstanceIntegration("redis", "2"), "nodeA")
+	assert.Equal(t, "nodeB", dispatcher.getLeastBusyNode(generateInstanceIntegration("mysql", "1")))
+
+	requireNotLocked(t, dispatcher.store)
+}
+
+func TestDispatchingAffinity(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.constraints = map[string]types.DispatchingConstraints{
+		"pgbouncer": {Affinity: []string{"postgres"}},
+	}
+
+	dispatcher.processNodeStatus("nodeA", "10.0.0.1", types.NodeStatus{})
+	dispatcher.processNodeStatus("nodeB", "10.0.0.2", types.NodeStatus{})
+
+	// No node runs postgres, the least busy one is picked
+	dispatcher.addConfig(generateInstanceIntegration("http_check", "1"), "nodeA")
+	assert.Equal(t, "nodeB", dispatcher.getLeastBusyNode(generateInstanceIntegration("pgbouncer", "1")))
+
+	// The node running postgres is preferred
+	dispatcher.addConfig(generateInstanceIntegration("postgres", "1"), "nodeA")
+	assert.Equal(t, "nodeA", dispatcher.getLeastBusyNode(generateInstanceIntegration("pgbouncer", "1")))
+
+	requireNotLocked(t, dispatcher.store)
+}
+
+func TestDispatchingMaxChecksPerRunner(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.maxChecksPerRunner = 2
+
+	dispatcher.processNodeStatus("nodeA", "10.0.0.1", types.NodeStatus{})
+	dispatcher.addConfig(generateInstanceIntegration("http_check", "1"), "nodeA")
+	assert.Equal(t, "nodeA", dispatcher.getLeastBusyNode(generateInstanceIntegration("http_check", "2")))
+
+	dispatcher.addConfig(generateInstanceIntegration("http_check", "2"), "nodeA")
+	assert.Equal(t, "", dispatcher.getLeastBusyNode(generateInstanceIntegration("http_check", "3")))
+	assert.Equal(t, "nodeA", dispatcher.getLeastBusyNode(generateInstanceIntegration("http_check", "2")))
+
+	requireNotLocked(t, dispatcher.store)
+}
+
+func TestDispatchingSpreadZones(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.constraints = map[string]types.DispatchingConstraints{
+		"postgres": {SpreadZones: true},
+	}
+
+	dispatcher.processNodeStatus("nodeA1", "10.0.0.1", types.NodeStatus{Labels: map[string]string{zoneLabel: "zone-a"}})
+	dispatcher.processNodeStatus("nodeA2", "10.0.0.2", types.NodeStatus{Labels: map[string]string{zoneLabel: "zone-a"}})
+	dispatcher.processNodeStatus("nodeB", "10.0.0.3", types.NodeStatus{Labels: map[string]string{legacyZoneLabel: "zone-b"}})
+
+	// nodeB is the busiest node, but zone-b runs no postgres instance
+	dispatcher.addConfig(generateInstanceIntegration("postgres", "1"), "nodeA1")
+	dispatcher.addConfig(generateInstanceIntegration("http_check", "1"), "nodeB")
+	dispatcher.addConfig(generateInstanceIntegration("http_check", "2"), "nodeB")
+	assert.Equal(t, "nodeB", dispatcher.getLeastBusyNode(generateInstanceIntegration("postgres", "2")))
+
+	// Both zones run a postgres instance, the least busy node is picked
+	dispatcher.addConfig(generateInstanceIntegration("postgres", "2"), "nodeB")
+	assert.Equal(t, "nodeA2", dispatcher.getLeastBusyNode(generateInstanceIntegration("postgres", "3")))
+
+	state, err := dispatcher.getState()
+	require.NoError(t, err)
+	zones := map[string]string{}
+	for _, node := range state.Nodes {
+		zones[node.Name] = node.Zone
+	}
+	assert.Equal(t, map[string]string{"nodeA1": "zone-a", "nodeA2": "zone-a", "nodeB": "zone-b"}, zones)
+
+	requireNotLocked(t, dispatcher.store)
+}
+
+func TestDispatchingWeight(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.constraints = map[string]types.DispatchingConstraints{
+		"kafka_consumer": {Weight: 5},
+	}
+
+	dispatcher.addConfig(generateInstanceIntegration("kafka_consumer", "1"), "nodeA")
+	dispatcher.addConfig(generateInstanceIntegration("http_check", "1"), "nodeB")
+	dispatcher.addConfig(generateInstanceIntegration("http_check", "2"), "nodeB")
+	assert.Equal(t, "nodeB", dispatcher.getLeastBusyNode(generateInstanceIntegration("http_check", "3")))
+
+	requireNotLocked(t, dispatcher.store)
+}
+
+func TestRebalanceWithConstraints(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.constraints = map[string]types.DispatchingConstraints{
+		"postgres": {NodeSelector: map[string]string{"pool": "monitoring"}},
+	}
+
+	dispatcher.store.active = true
+	dispatcher.processNodeStatus("nodeA", "10.0.0.1", types.NodeStatus{Labels: map[string]string{"pool": "monitoring"}})
+	dispatcher.processNodeStatus("nodeB", "10.0.0.2", types.NodeStatus{})
+
+	postgres := generateInstanceIntegration("postgres", "1")
+	dispatcher.addConfig(postgres, "nodeA")
+	require.Len(t, dispatcher.store.idToDigest, 1)
+
+	var checkID string
+	for cid := range dispatcher.store.idToDigest {
+		checkID = string(cid)
+	}
+	dispatcher.store.nodes["nodeA"].clcRunnerStats = types.CLCRunnersStats{
+		checkID: types.CLCRunnerStats{AverageExecutionTime: 1000, MetricSamples: 1000, IsClusterCheck: true},
+	}
+
+	// nodeB doesn't match the node selector of postgres, the check stays on nodeA
+	assert.Empty(t, dispatcher.rebalance())
+	assert.Equal(t, "nodeA", dispatcher.store.digestToNode[postgres.Digest()])
+
+	requireNotLocked(t, dispatcher.store)
+}
+
+func TestRebalanceSkipsConstrainedChecks(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.constraints = map[string]types.DispatchingConstraints{
+		"postgres": {NodeSelector: map[string]string{"pool": "monitoring"}},
+	}
+
+	dispatcher.store.active = true
+	dispatcher.processNodeStatus("nodeA", "10.0.0.1", types.NodeStatus{Labels: map[string]string{"pool": "monitoring"}})
+	dispatcher.processNodeStatus("nodeB", "10.0.0.2", types.NodeStatus{})
+
+	postgres := generateInstanceIntegration("postgres", "1")
+	httpCheck := generateInstanceIntegration("http_check", "1")
+	dispatcher.addConfig(postgres, "nodeA")
+	dispatcher.addConfig(httpCheck, "nodeA")
+	require.Len(t, dispatcher.store.idToDigest, 2)
+
+	stats := types.CLCRunnersStats{}
+	for cid, digest := range dispatcher.store.idToDigest {
+		if digest == postgres.Digest() {
+			stats[string(cid)] = types.CLCRunnerStats{AverageExecutionTime: 1000, MetricSamples: 1000, IsClusterCheck: true}
+		} else {
+			stats[string(cid)] = types.CLCRunnerStats{AverageExecutionTime: 500, MetricSamples: 500, IsClusterCheck: true}
+		}
+	}
+	dispatcher.store.nodes["nodeA"].clcRunnerStats = stats
+
+	// postgres, the most weighted check, cannot move to nodeB, http_check is moved instead
+	moved := dispatcher.rebalance()
+	require.Len(t, moved, 1)
+	assert.Equal(t, "nodeB", moved[0].DestNodeName)
+	assert.Equal(t, "nodeA", dispatcher.store.digestToNode[postgres.Digest()])
+	assert.Equal(t, "nodeB", dispatcher.store.digestToNode[httpCheck.Digest()])
+
+	requireNotLocked(t, dispatcher.store)
+}
@@ -10,9 +10,11 @@ package clusterchecks
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util"
@@ -32,11 +34,18 @@ type dispatcher struct {
 	extraTags             []string
 	clcRunnersClient      clusteragent.CLCRunnerClientInterface
 	advancedDispatching   bool
+	constraints           map[string]types.DispatchingConstraints
+	maxChecksPerRunner    int
+
+	// configConstraints caches the constraints set in the configurations, by digest
+	configConstraints      map[string]*types.DispatchingConstraints
+	configConstraintsMutex sync.Mutex
 }
 
 func newDispatcher() *dispatcher {
 	d := &dispatcher{
-		store: newClusterStore(),
+		store:             newClusterStore(),
+		configConstraints: make(map[string]*types.DispatchingConstraints),
 	}
 	d.nodeExpirationSeconds = config.Datadog.GetInt64("cluster_checks.node_expiration_timeout")
 	d.extraTags = config.Datadog.GetStringSlice("cluster_checks.extra_tags")
@@ -52,6 +61,9 @@ func newDispatcher() *dispatcher {
 		d.extraTags = append(d.extraTags, fmt.Sprintf("kube_cluster_name:%s", clusterTagValue))
 	}
 
+	d.constraints = getDispatchingConstraints()
+	d.maxChecksPerRunner = config.Datadog.GetInt("cluster_checks.max_checks_per_runner")
+
 	d.advancedDispatching = config.Datadog.GetBool("cluster_checks.advanced_dispatching_enabled")
 	if !d.advancedDispatching {
 		return d
@@ -130,7 +142,7 @@ func (d *dispatcher) reschedule(configs []integration.Config) {
 
 // add stores and delegates a given configuration
 func (d *dispatcher) add(config integration.Config) {
-	target := d.getLeastBusyNode()
+	target := d.getLeastBusyNode(config)
 	if target == "" {
 		// If no node is found, store it in the danglingConfigs map for retrying later.
 		log.Warnf("No available node to dispatch %s:%s on, will retry later", config.Name, config.Digest())
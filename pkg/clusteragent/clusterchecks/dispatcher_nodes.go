@@ -51,6 +51,9 @@ func (d *dispatcher) processNodeStatus(nodeName, clientIP string, status types.N
 	defer node.Unlock()
 	node.lastStatus = status
 	node.heartbeat = timestampNow()
+	if status.Labels != nil {
+		node.labels = status.Labels
+	}
 
 	if node.lastConfigChange == status.LastChange {
 		// Node-agent is up to date
@@ -69,9 +72,10 @@ func (d *dispatcher) processNodeStatus(nodeName, clientIP string, status types.N
 }
 
 // getLeastBusyNode returns the name of the node that is assigned
-// the lowest number of checks. In case of equality, one is chosen
-// randomly, based on map iterations being randomized.
-func (d *dispatcher) getLeastBusyNode() string {
+// the lowest weighted number of checks, among the nodes satisfying
+// the dispatching constraints of the configuration. In case of equality,
+// one is chosen randomly, based on map iterations being randomized.
+func (d *dispatcher) getLeastBusyNode(config integration.Config) string {
 	var leastBusyNode string
 	minCheckCount := int(-1)
 	minBusyness := int(-1)
@@ -79,10 +83,8 @@ func (d *dispatcher) getLeastBusyNode() string {
 	d.store.RLock()
 	defer d.store.RUnlock()
 
-	for name, store := range d.store.nodes {
-		if name == "" {
-			continue
-		}
+	for _, store := range d.getAllowedNodes(config, config.Digest()) {
+		name := store.name
 		if d.advancedDispatching && store.busyness > defaultBusynessValue {
 			// dispatching based on clc runners stats
 			// only when advancedDispatching is true and
@@ -93,9 +95,12 @@ func (d *dispatcher) getLeastBusyNode() string {
 			}
 		} else {
 			// count-based round robin dispatching
-			if minCheckCount == -1 || len(store.digestToConfig) < minCheckCount {
+			store.RLock()
+			checkCount := d.nodeLoad(store)
+			store.RUnlock()
+			if minCheckCount == -1 || checkCount < minCheckCount {
 				leastBusyNode = name
-				minCheckCount = len(store.digestToConfig)
+				minCheckCount = checkCount
 			}
 		}
 	}
@@ -104,7 +104,7 @@ func (d *dispatcher) updateDiff(avg int) map[string]int {
 // A check Xi running on a node N is chosen to move to another node if it satisfies the following
 // Weight(Xi) >  Weight(Xj) (for each j != i, 0 <= j < len(weights))
 // where Weight(X) is the busyness value caused by running the check X.
-func (d *dispatcher) pickCheckToMove(nodeName string) (string, int, error) {
+func (d *dispatcher) pickCheckToMove(nodeName string, excluded map[string]struct{}) (string, int, error) {
 	d.store.RLock()
 	node, found := d.store.getNodeStore(nodeName)
 	d.store.RUnlock()
@@ -114,7 +114,7 @@ func (d *dispatcher) pickCheckToMove(nodeName string) (string, int, error) {
 		return "", -1, fmt.Errorf("node %s not found in store", nodeName)
 	}
 
-	return node.GetMostWeightedClusterCheck(busynessFunc)
+	return node.GetMostWeightedClusterCheck(busynessFunc, excluded)
 }
 
 // pickNode select the most appropriate node to receive a specific check.
@@ -196,16 +196,26 @@ func (d *dispatcher) rebalance() []types.RebalanceResponse {
 	sort.Sort(weights)
 
 	for _, nodeWeight := range weights {
+		// checks that cannot leave the node, the next candidates are tried instead
+		unmovable := map[string]struct{}{}
 		for diffMap[nodeWeight.nodeName] > 0 {
 			// try to move checks from a node only of the node busyness is above the average
 			sourceNodeName := nodeWeight.nodeName
-			checkID, checkWeight, err := d.pickCheckToMove(sourceNodeName)
+			checkID, checkWeight, err := d.pickCheckToMove(sourceNodeName, unmovable)
 			if err != nil {
 				log.Debugf("Cannot pick a check to move from node %s: %v", sourceNodeName, err)
 				break
 			}
 
-			destNodeName := pickNode(diffMap, sourceNodeName)
+			// only consider the nodes satisfying the dispatching constraints of the check
+			config, digest := d.getConfigAndDigest(checkID)
+			destNodeName := pickNode(d.filterDiffMap(diffMap, config, digest), sourceNodeName)
+			if destNodeName == "" {
+				log.Debugf("No node to move check %s to from node %s", checkID, sourceNodeName)
+				unmovable[checkID] = struct{}{}
+				continue
+			}
+
 			sourceDiff := diffMap[sourceNodeName]
 			destDiff := diffMap[destNodeName]
 
@@ -219,6 +229,7 @@ func (d *dispatcher) rebalance() []types.RebalanceResponse {
 				err = d.moveCheck(sourceNodeName, destNodeName, checkID)
 				if err != nil {
 					log.Debugf("Cannot move check %s: %v", checkID, err)
+					unmovable[checkID] = struct{}{}
 					continue
 				}
 
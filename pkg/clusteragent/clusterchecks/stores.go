@@ -87,6 +87,7 @@ type nodeStore struct {
 	lastConfigChange int64
 	digestToConfig   map[string]integration.Config
 	clientIP         string
+	labels           map[string]string
 	clcRunnerStats   types.CLCRunnersStats
 	busyness         int
 }
@@ -163,9 +164,9 @@ func (s *nodeStore) GetBusyness(busynessFunc func(stats types.CLCRunnerStats) in
 	return busyness
 }
 
-// GetMostWeightedClusterCheck returns the Cluster Check with the most weight on the node
+// GetMostWeightedClusterCheck returns the Cluster Check with the most weight on the node, ignoring the excluded checks
 // The nodeStore handles thread safety for this public method
-func (s *nodeStore) GetMostWeightedClusterCheck(busynessFunc func(stats types.CLCRunnerStats) int) (string, int, error) {
+func (s *nodeStore) GetMostWeightedClusterCheck(busynessFunc func(stats types.CLCRunnerStats) int, excluded map[string]struct{}) (string, int, error) {
 	s.RLock()
 	defer s.RUnlock()
 	if len(s.clcRunnerStats) == 0 {
@@ -176,6 +177,9 @@ func (s *nodeStore) GetMostWeightedClusterCheck(busynessFunc func(stats types.CL
 	checkID := ""
 	checkWeight := 0
 	for id, stats := range s.clcRunnerStats {
+		if _, skip := excluded[id]; skip {
+			continue
+		}
 		busyness := busynessFunc(stats)
 		if (busyness > checkWeight || firstItr) && stats.IsClusterCheck {
 			// Only consider Cluster Checks
@@ -11,7 +11,8 @@ import (
 
 // NodeStatus holds the status report from the node-agent
 type NodeStatus struct {
-	LastChange int64 `json:"last_change"`
+	LastChange int64             `json:"last_change"`
+	Labels     map[string]string `json:"labels,omitempty"` // Labels of the node, used by the dispatching constraints
 }
 
 // StatusResponse holds the DCA response for a status report
@@ -39,18 +40,38 @@ type ConfigResponse struct {
 
 // StateResponse holds the DCA response for a dispatching state query
 type StateResponse struct {
-	NotRunning string               `json:"not_running"` // Reason why not running, empty if leading
-	Warmup     bool                 `json:"warmup"`
-	Nodes      []StateNodeResponse  `json:"nodes"`
-	Dangling   []integration.Config `json:"dangling"`
+	NotRunning         string                            `json:"not_running"` // Reason why not running, empty if leading
+	Warmup             bool                              `json:"warmup"`
+	Nodes              []StateNodeResponse               `json:"nodes"`
+	Dangling           []integration.Config              `json:"dangling"`
+	Constraints        map[string]DispatchingConstraints `json:"constraints,omitempty"`
+	MaxChecksPerRunner int                               `json:"max_checks_per_runner,omitempty"`
 }
 
 // StateNodeResponse is a chunk of StateResponse
 type StateNodeResponse struct {
 	Name    string               `json:"name"`
+	Zone    string               `json:"zone,omitempty"`
 	Configs []integration.Config `json:"configs"`
 }
 
+// DispatchingConstraints holds the constraints applied when dispatching the
+// configurations of a check
+type DispatchingConstraints struct {
+	// NodeSelector restricts the check to the nodes having all these labels
+	NodeSelector map[string]string `mapstructure:"node_selector" json:"node_selector,omitempty" yaml:"node_selector"`
+	// Affinity makes the check prefer the nodes running one of these checks
+	Affinity []string `mapstructure:"affinity" json:"affinity,omitempty" yaml:"affinity"`
+	// AntiAffinity prevents the check from running on the nodes running one of
+	// these checks. Use the name of the check itself to spread its instances.
+	AntiAffinity []string `mapstructure:"anti_affinity" json:"anti_affinity,omitempty" yaml:"anti_affinity"`
+	// SpreadZones spreads the configurations of the check across the zones
+	SpreadZones bool `mapstructure:"spread_zones" json:"spread_zones,omitempty" yaml:"spread_zones"`
+	// Weight of a configuration of the check when dispatching based on the
+	// number of checks per node, 1 by default
+	Weight int `mapstructure:"weight" json:"weight,omitempty" yaml:"weight"`
+}
+
 // Stats holds statistics for the agent status command
 type Stats struct {
 	// Following
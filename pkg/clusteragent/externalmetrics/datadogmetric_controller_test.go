@@ -717,6 +717,7 @@ func TestFollower(t *testing.T) {
 		Valid:      true,
 		Value:      10.0,
 		UpdateTime: kubernetes.TimeWithoutWall(prevUpdateTime.UTC()),
+		DataTime:   kubernetes.TimeWithoutWall(prevUpdateTime.UTC()),
 		Error:      nil,
 	}
 	ddm.SetQueries("metric query0")
@@ -732,6 +733,7 @@ func TestFollower(t *testing.T) {
 		ExternalMetricName: "dd-metric-1",
 		Value:              10.0,
 		UpdateTime:         kubernetes.TimeWithoutWall(prevUpdateTime.UTC()),
+		DataTime:           kubernetes.TimeWithoutWall(prevUpdateTime.UTC()),
 		Error:              nil,
 	}
 	ddm.SetQueries("metric query1")
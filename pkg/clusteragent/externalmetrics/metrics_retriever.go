@@ -24,19 +24,31 @@ const (
 	metricRetrieverStoreID            string = "mr"
 )
 
+// MetricsBackend is implemented by the backends the DatadogMetrics values are retrieved from.
+// The Datadog API backend is the `autoscalers.Processor`.
+type MetricsBackend interface {
+	// QueryExternalMetric returns the latest point of each query. A global error is
+	// returned with no results when the backend cannot be queried at all.
+	QueryExternalMetric(queries []string) (map[string]autoscalers.Point, error)
+}
+
 type MetricsRetriever struct {
 	refreshPeriod int64
 	metricsMaxAge int64
-	processor     autoscalers.ProcessorInterface
+	gracePeriod   int64
+	backend       MetricsBackend
 	store         *DatadogMetricsInternalStore
 	isLeader      func() bool
 }
 
-func NewMetricsRetriever(refreshPeriod, metricsMaxAge int64, processor autoscalers.ProcessorInterface, isLeader func() bool, store *DatadogMetricsInternalStore) (*MetricsRetriever, error) {
+// NewMetricsRetriever returns a MetricsRetriever refreshing the active DatadogMetrics from the backend.
+// When the backend fails, the last known values keep being served for `gracePeriod` seconds after they become too old.
+func NewMetricsRetriever(refreshPeriod, metricsMaxAge, gracePeriod int64, backend MetricsBackend, isLeader func() bool, store *DatadogMetricsInternalStore) (*MetricsRetriever, error) {
 	return &MetricsRetriever{
 		refreshPeriod: refreshPeriod,
 		metricsMaxAge: metricsMaxAge,
-		processor:     processor,
+		gracePeriod:   gracePeriod,
+		backend:       backend,
 		store:         store,
 		isLeader:      isLeader,
 	}, nil
@@ -69,7 +81,7 @@ func (mr *MetricsRetriever) retrieveMetricsValues() {
 	queries := getUniqueQueries(datadogMetrics)
 	log.Debugf("Starting refreshing external metrics with: %d queries", len(queries))
 
-	results, err := mr.processor.QueryExternalMetric(queries)
+	results, err := mr.backend.QueryExternalMetric(queries)
 	globalError := false
 	// Check for global failure
 	if len(results) == 0 && err != nil {
@@ -88,46 +100,65 @@ func (mr *MetricsRetriever) retrieveMetricsValues() {
 		}
 
 		query := datadogMetric.Query()
+		maxAge := datadogMetric.MaxAge
+		if maxAge == 0 {
+			maxAge = time.Duration(mr.metricsMaxAge) * time.Second
+		}
+
 		if queryResult, found := results[query]; found {
-			log.Debugf("QueryResult from DD for %q: %v", query, queryResult)
+			log.Debugf("QueryResult from backend for %q: %v", query, queryResult)
 
 			if queryResult.Valid {
-				datadogMetricFromStore.Value = queryResult.Value
+				dataTime := time.Unix(queryResult.Timestamp, 0).UTC()
 
 				// If we get a valid but old metric, flag it as invalid
-				maxAge := datadogMetric.MaxAge
-				if maxAge == 0 {
-					maxAge = time.Duration(mr.metricsMaxAge) * time.Second
-				}
-
-				if time.Duration(currentTime.Unix()-queryResult.Timestamp)*time.Second <= maxAge {
+				if currentTime.Sub(dataTime) <= maxAge {
+					datadogMetricFromStore.Value = queryResult.Value
 					datadogMetricFromStore.Valid = true
+					datadogMetricFromStore.Stale = false
 					datadogMetricFromStore.Error = nil
-					datadogMetricFromStore.UpdateTime = time.Unix(queryResult.Timestamp, 0).UTC()
+					datadogMetricFromStore.UpdateTime = dataTime
+					datadogMetricFromStore.DataTime = dataTime
 				} else {
-					datadogMetricFromStore.Valid = false
-					datadogMetricFromStore.Error = fmt.Errorf(invalidMetricOutdatedErrorMessage, query)
-					datadogMetricFromStore.UpdateTime = currentTime
+					if dataTime.After(datadogMetricFromStore.DataTime) {
+						datadogMetricFromStore.Value = queryResult.Value
+						datadogMetricFromStore.DataTime = dataTime
+					}
+					mr.setError(datadogMetricFromStore, fmt.Errorf(invalidMetricOutdatedErrorMessage, query), maxAge, currentTime)
 				}
 			} else {
-				datadogMetricFromStore.Valid = false
-				datadogMetricFromStore.Error = fmt.Errorf(invalidMetricBackendErrorMessage, query)
-				datadogMetricFromStore.UpdateTime = currentTime
+				mr.setError(datadogMetricFromStore, fmt.Errorf(invalidMetricBackendErrorMessage, query), maxAge, currentTime)
 			}
 		} else {
-			datadogMetricFromStore.Valid = false
 			if globalError {
-				datadogMetricFromStore.Error = fmt.Errorf(invalidMetricGlobalErrorMessage)
+				mr.setError(datadogMetricFromStore, fmt.Errorf(invalidMetricGlobalErrorMessage), maxAge, currentTime)
 			} else {
-				datadogMetricFromStore.Error = fmt.Errorf(invalidMetricNoDataErrorMessage, query)
+				mr.setError(datadogMetricFromStore, fmt.Errorf(invalidMetricNoDataErrorMessage, query), maxAge, currentTime)
 			}
-			datadogMetricFromStore.UpdateTime = currentTime
 		}
 
 		mr.store.UnlockSet(datadogMetric.ID, *datadogMetricFromStore, metricRetrieverStoreID)
 	}
 }
 
+// setError flags the DatadogMetric as invalid, unless its last known value is still within
+// the grace period. In that case, the value keeps being served and is flagged as stale.
+func (mr *MetricsRetriever) setError(datadogMetric *model.DatadogMetricInternal, err error, maxAge time.Duration, currentTime time.Time) {
+	datadogMetric.Error = err
+	datadogMetric.UpdateTime = currentTime
+
+	gracePeriod := time.Duration(mr.gracePeriod) * time.Second
+	if gracePeriod > 0 && !datadogMetric.DataTime.IsZero() && (datadogMetric.Valid || datadogMetric.Stale) && currentTime.Sub(datadogMetric.DataTime) <= maxAge+gracePeriod {
+		log.Debugf("Serving last known value of DatadogMetric: %s from %v, err: %v", datadogMetric.ID, datadogMetric.DataTime, err)
+		datadogMetric.Valid = true
+		datadogMetric.Stale = true
+		return
+	}
+
+	datadogMetric.Valid = false
+	datadogMetric.Stale = false
+}
+
 func getUniqueQueries(datadogMetrics []model.DatadogMetricInternal) []string {
 	queries := make([]string, 0, len(datadogMetrics))
 	unique := make(map[string]struct{}, len(queries))
@@ -44,6 +44,7 @@ type ddmWithQuery struct {
 type metricsFixture struct {
 	desc         string
 	maxAge       int64
+	gracePeriod  int64
 	storeContent []ddmWithQuery
 	queryResults map[string]autoscalers.Point
 	queryError   error
@@ -65,7 +66,7 @@ func (f *metricsFixture) run(t *testing.T, testTime time.Time) {
 		points: f.queryResults,
 		err:    f.queryError,
 	}
-	metricsRetriever, err := NewMetricsRetriever(0, f.maxAge, &mockedProcessor, getIsLeaderFunction(true), &store)
+	metricsRetriever, err := NewMetricsRetriever(0, f.maxAge, f.gracePeriod, &mockedProcessor, getIsLeaderFunction(true), &store)
 	assert.Nil(t, err)
 	metricsRetriever.retrieveMetricsValues()
 
@@ -75,7 +76,7 @@ func (f *metricsFixture) run(t *testing.T, testTime time.Time) {
 
 		// Update time will be set to a value (as metricsRetriever uses time.Now()) that should be > testTime
 		// Thus, aligning updateTime to have a working comparison
-		if !expectedDatadogMetric.ddm.Valid && datadogMetric != nil && datadogMetric.Active {
+		if (!expectedDatadogMetric.ddm.Valid || expectedDatadogMetric.ddm.Stale) && datadogMetric != nil && datadogMetric.Active {
 			assert.Condition(t, func() bool { return datadogMetric.UpdateTime.After(expectedDatadogMetric.ddm.UpdateTime) })
 
 			alignedTime := time.Now().UTC()
@@ -139,6 +140,7 @@ func TestRetrieveMetricsBasic(t *testing.T) {
 						Active:     true,
 						Value:      10.0,
 						UpdateTime: defaultTestTime,
+						DataTime:   defaultTestTime,
 						Valid:      true,
 						Error:      nil,
 					},
@@ -150,6 +152,7 @@ func TestRetrieveMetricsBasic(t *testing.T) {
 						Active:     true,
 						Value:      11.0,
 						UpdateTime: defaultTestTime,
+						DataTime:   defaultTestTime,
 						Valid:      true,
 						Error:      nil,
 					},
@@ -218,6 +221,7 @@ func TestRetrieveMetricsErrorCases(t *testing.T) {
 						Active:     true,
 						Value:      10.0,
 						UpdateTime: defaultTestTime,
+						DataTime:   defaultTestTime,
 						Valid:      true,
 						Error:      nil,
 					},
@@ -225,11 +229,12 @@ func TestRetrieveMetricsErrorCases(t *testing.T) {
 				},
 				{
 					ddm: model.DatadogMetricInternal{
-						ID:     "metric1",
-						Active: true,
-						Value:  11.0,
-						Valid:  false,
-						Error:  fmt.Errorf(invalidMetricOutdatedErrorMessage, "query-metric1"),
+						ID:       "metric1",
+						Active:   true,
+						Value:    11.0,
+						Valid:    false,
+						Error:    fmt.Errorf(invalidMetricOutdatedErrorMessage, "query-metric1"),
+						DataTime: defaultPreviousUpdateTime,
 						// UpdateTime not set as it will not be compared directly
 					},
 					query: "query-metric1",
@@ -283,6 +288,7 @@ func TestRetrieveMetricsErrorCases(t *testing.T) {
 						Active:     true,
 						Value:      10.0,
 						UpdateTime: defaultTestTime,
+						DataTime:   defaultTestTime,
 						Valid:      true,
 						Error:      nil,
 						MaxAge:     20 * time.Second,
@@ -291,12 +297,13 @@ func TestRetrieveMetricsErrorCases(t *testing.T) {
 				},
 				{
 					ddm: model.DatadogMetricInternal{
-						ID:     "metric1",
-						Active: true,
-						Value:  11.0,
-						Valid:  false,
-						Error:  fmt.Errorf(invalidMetricOutdatedErrorMessage, "query-metric1"),
-						MaxAge: 5 * time.Second,
+						ID:       "metric1",
+						Active:   true,
+						Value:    11.0,
+						Valid:    false,
+						Error:    fmt.Errorf(invalidMetricOutdatedErrorMessage, "query-metric1"),
+						DataTime: defaultPreviousUpdateTime,
+						MaxAge:   5 * time.Second,
 						// UpdateTime not set as it will not be compared directly
 					},
 					query: "query-metric1",
@@ -350,6 +357,7 @@ func TestRetrieveMetricsErrorCases(t *testing.T) {
 						Active:     true,
 						Value:      10.0,
 						UpdateTime: defaultTestTime,
+						DataTime:   defaultTestTime,
 						Valid:      true,
 						Error:      nil,
 					},
@@ -464,6 +472,7 @@ func TestRetrieveMetricsErrorCases(t *testing.T) {
 						Active:     true,
 						Value:      10.0,
 						UpdateTime: defaultTestTime,
+						DataTime:   defaultTestTime,
 						Valid:      true,
 						Error:      nil,
 					},
@@ -543,6 +552,7 @@ func TestRetrieveMetricsNotActive(t *testing.T) {
 						Active:     true,
 						Value:      10.0,
 						UpdateTime: defaultTestTime,
+						DataTime:   defaultTestTime,
 						Valid:      true,
 						Error:      nil,
 					},
@@ -629,3 +639,170 @@ func TestRetrieveMetricsNotActive(t *testing.T) {
 		})
 	}
 }
+
+func TestRetrieveMetricsGracePeriod(t *testing.T) {
+	defaultTestTime := time.Now().Add(time.Duration(-1) * time.Second).UTC().Truncate(time.Second)
+	defaultPreviousUpdateTime := time.Now().Add(time.Duration(-11) * time.Second).UTC().Truncate(time.Second)
+	defaultOldUpdateTime := time.Now().Add(time.Duration(-60) * time.Second).UTC().Truncate(time.Second)
+
+	fixtures := []metricsFixture{
+		{
+			maxAge:      5,
+			gracePeriod: 30,
+			desc:        "Test last known values are served during the grace period on global error",
+			storeContent: []ddmWithQuery{
+				{
+					ddm: model.DatadogMetricInternal{
+						ID:         "metric0",
+						Active:     true,
+						Value:      1.0,
+						UpdateTime: defaultPreviousUpdateTime,
+						DataTime:   defaultPreviousUpdateTime,
+						Valid:      true,
+					},
+					query: "query-metric0",
+				},
+				{
+					ddm: model.DatadogMetricInternal{
+						ID:         "metric1",
+						Active:     true,
+						Value:      2.0,
+						UpdateTime: defaultOldUpdateTime,
+						DataTime:   defaultOldUpdateTime,
+						Valid:      true,
+					},
+					query: "query-metric1",
+				},
+				{
+					ddm: model.DatadogMetricInternal{
+						ID:         "metric2",
+						Active:     true,
+						Value:      3.0,
+						UpdateTime: defaultPreviousUpdateTime,
+						Valid:      false,
+					},
+					query: "query-metric2",
+				},
+			},
+			queryResults: map[string]autoscalers.Point{},
+			queryError:   fmt.Errorf("Backend error 500"),
+			expected: []ddmWithQuery{
+				{
+					ddm: model.DatadogMetricInternal{
+						ID:       "metric0",
+						Active:   true,
+						Value:    1.0,
+						DataTime: defaultPreviousUpdateTime,
+						Valid:    true,
+						Stale:    true,
+						Error:    fmt.Errorf(invalidMetricGlobalErrorMessage),
+						// UpdateTime not set as it will not be compared directly
+					},
+					query: "query-metric0",
+				},
+				{
+					ddm: model.DatadogMetricInternal{
+						ID:       "metric1",
+						Active:   true,
+						Value:    2.0,
+						DataTime: defaultOldUpdateTime,
+						Valid:    false,
+						Stale:    false,
+						Error:    fmt.Errorf(invalidMetricGlobalErrorMessage),
+						// UpdateTime not set as it will not be compared directly
+					},
+					query: "query-metric1",
+				},
+				{
+					ddm: model.DatadogMetricInternal{
+						ID:     "metric2",
+						Active: true,
+						Value:  3.0,
+						Valid:  false,
+						Error:  fmt.Errorf(invalidMetricGlobalErrorMessage),
+						// UpdateTime not set as it will not be compared directly
+					},
+					query: "query-metric2",
+				},
+			},
+		},
+		{
+			maxAge:      5,
+			gracePeriod: 30,
+			desc:        "Test outdated values are served during the grace period and stale metrics recover",
+			storeContent: []ddmWithQuery{
+				{
+					ddm: model.DatadogMetricInternal{
+						ID:         "metric0",
+						Active:     true,
+						Value:      1.0,
+						UpdateTime: defaultPreviousUpdateTime,
+						DataTime:   defaultOldUpdateTime,
+						Valid:      true,
+					},
+					query: "query-metric0",
+				},
+				{
+					ddm: model.DatadogMetricInternal{
+						ID:         "metric1",
+						Active:     true,
+						Value:      2.0,
+						UpdateTime: defaultPreviousUpdateTime,
+						DataTime:   defaultPreviousUpdateTime,
+						Valid:      true,
+						Stale:      true,
+						Error:      fmt.Errorf(invalidMetricGlobalErrorMessage),
+					},
+					query: "query-metric1",
+				},
+			},
+			queryResults: map[string]autoscalers.Point{
+				"query-metric0": {
+					Value:     10.0,
+					Timestamp: defaultPreviousUpdateTime.Unix(),
+					Valid:     true,
+				},
+				"query-metric1": {
+					Value:     11.0,
+					Timestamp: defaultTestTime.Unix(),
+					Valid:     true,
+				},
+			},
+			queryError: nil,
+			expected: []ddmWithQuery{
+				{
+					ddm: model.DatadogMetricInternal{
+						ID:       "metric0",
+						Active:   true,
+						Value:    10.0,
+						DataTime: defaultPreviousUpdateTime,
+						Valid:    true,
+						Stale:    true,
+						Error:    fmt.Errorf(invalidMetricOutdatedErrorMessage, "query-metric0"),
+						// UpdateTime not set as it will not be compared directly
+					},
+					query: "query-metric0",
+				},
+				{
+					ddm: model.DatadogMetricInternal{
+						ID:         "metric1",
+						Active:     true,
+						Value:      11.0,
+						UpdateTime: defaultTestTime,
+						DataTime:   defaultTestTime,
+						Valid:      true,
+						Stale:      false,
+						Error:      nil,
+					},
+					query: "query-metric1",
+				},
+			},
+		},
+	}
+
+	for i, fixture := range fixtures {
+		t.Run(fmt.Sprintf("#%d %s", i, fixture.desc), func(t *testing.T) {
+			fixture.run(t, defaultTestTime)
+		})
+	}
+}
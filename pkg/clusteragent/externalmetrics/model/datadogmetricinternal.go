@@ -24,6 +24,7 @@ import (
 // exported for testing purposes
 const (
 	DatadogMetricErrorConditionReason string = "Unable to fetch data from Datadog"
+	DatadogMetricStaleConditionReason string = "Unable to fetch data, serving last known value"
 )
 
 // DatadogMetricInternal is a flatten, easier to use, representation of `DatadogMetric` CRD
@@ -41,6 +42,11 @@ type DatadogMetricInternal struct {
 	UpdateTime           time.Time
 	Error                error
 	MaxAge               time.Duration
+	// DataTime is the timestamp of the last valid value received from the backend
+	DataTime time.Time
+	// Stale is true when the backend cannot provide a new value and the last
+	// known one is served during the grace period
+	Stale bool
 }
 
 // NewDatadogMetricInternal returns a `DatadogMetricInternal` object from a `DatadogMetric` CRD Object
@@ -62,19 +68,31 @@ func NewDatadogMetricInternal(id string, datadogMetric datadoghq.DatadogMetric)
 		internal.ExternalMetricName = datadogMetric.Spec.ExternalMetricName
 	}
 
+	var validTime time.Time
 	for _, condition := range datadogMetric.Status.Conditions {
 		switch {
 		case condition.Type == datadoghq.DatadogMetricConditionTypeValid && condition.Status == corev1.ConditionTrue:
 			internal.Valid = true
+			validTime = condition.LastTransitionTime.UTC()
 		case condition.Type == datadoghq.DatadogMetricConditionTypeActive && condition.Status == corev1.ConditionTrue:
 			internal.Active = true
 		case condition.Type == datadoghq.DatadogMetricConditionTypeUpdated && condition.Status == corev1.ConditionTrue:
 			internal.UpdateTime = condition.LastUpdateTime.UTC()
 		case condition.Type == datadoghq.DatadogMetricConditionTypeError && condition.Status == corev1.ConditionTrue:
 			internal.Error = errors.New(condition.Message)
+			internal.Stale = condition.Reason == DatadogMetricStaleConditionReason
 		}
 	}
 
+	// The time of the last valid value is the last update, or the transition time of the
+	// Valid condition when the last known value is served
+	internal.Stale = internal.Stale && internal.Valid
+	if internal.Stale {
+		internal.DataTime = validTime
+	} else if internal.Valid && internal.Error == nil {
+		internal.DataTime = internal.UpdateTime
+	}
+
 	internal.resolveQuery(internal.query)
 
 	// If UpdateTime is not set, it means it's a newly created DatadogMetric
@@ -179,10 +197,17 @@ func (d *DatadogMetricInternal) BuildStatus(currentStatus *datadoghq.DatadogMetr
 
 	activeCondition := d.newCondition(d.Active, updateTime, datadoghq.DatadogMetricConditionTypeActive, existingConditions[datadoghq.DatadogMetricConditionTypeActive])
 	validCondition := d.newCondition(d.Valid, updateTime, datadoghq.DatadogMetricConditionTypeValid, existingConditions[datadoghq.DatadogMetricConditionTypeValid])
+	if d.Stale && !d.DataTime.IsZero() {
+		// Keep the time of the last known value to restore it
+		validCondition.LastTransitionTime = metav1.NewTime(d.DataTime)
+	}
 	updatedCondition := d.newCondition(true, updateTime, datadoghq.DatadogMetricConditionTypeUpdated, existingConditions[datadoghq.DatadogMetricConditionTypeUpdated])
 	errorCondition := d.newCondition(d.Error != nil, updateTime, datadoghq.DatadogMetricConditionTypeError, existingConditions[datadoghq.DatadogMetricConditionTypeError])
 	if d.Error != nil {
 		errorCondition.Reason = DatadogMetricErrorConditionReason
+		if d.Stale {
+			errorCondition.Reason = DatadogMetricStaleConditionReason
+		}
 		errorCondition.Message = d.Error.Error()
 	}
 
@@ -206,11 +231,17 @@ func (d *DatadogMetricInternal) ToExternalMetricFormat(externalMetricName string
 		return nil, err
 	}
 
+	// Stale values are reported with the time of the data they come from
+	timestamp := d.UpdateTime
+	if d.Stale && !d.DataTime.IsZero() {
+		timestamp = d.DataTime
+	}
+
 	return &external_metrics.ExternalMetricValue{
 		MetricName:   externalMetricName,
 		MetricLabels: nil,
 		Value:        quantity,
-		Timestamp:    metav1.NewTime(d.UpdateTime),
+		Timestamp:    metav1.NewTime(timestamp),
 	}, nil
 }
 
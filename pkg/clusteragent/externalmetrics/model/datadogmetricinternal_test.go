@@ -228,3 +228,54 @@ func TestDatadogMetricInternal_resolveQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestDatadogMetricInternal_Stale(t *testing.T) {
+	dataTime := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	updateTime := time.Now().UTC().Truncate(time.Second)
+
+	ddm := DatadogMetricInternal{
+		ID:         "default/dd-metric-0",
+		Valid:      true,
+		Active:     true,
+		Value:      10.0,
+		UpdateTime: updateTime,
+		DataTime:   dataTime,
+		Stale:      true,
+		Error:      errors.New("Global error (all queries) from backend"),
+	}
+
+	// Stale metrics are served with the time of their data
+	externalMetric, err := ddm.ToExternalMetricFormat("dd-metric-0")
+	assert.NoError(t, err)
+	assert.Equal(t, dataTime, externalMetric.Timestamp.UTC())
+
+	status := ddm.BuildStatus(nil)
+	for _, condition := range status.Conditions {
+		if condition.Type == datadoghq.DatadogMetricConditionTypeError {
+			assert.Equal(t, DatadogMetricStaleConditionReason, condition.Reason)
+		}
+	}
+
+	// Staleness and the time of the data are restored from the status
+	restored := NewDatadogMetricInternal("default/dd-metric-0", datadoghq.DatadogMetric{Status: *status})
+	assert.True(t, restored.Valid)
+	assert.True(t, restored.Stale)
+	assert.Equal(t, dataTime, restored.DataTime)
+	externalMetric, err = restored.ToExternalMetricFormat("dd-metric-0")
+	assert.NoError(t, err)
+	assert.Equal(t, dataTime, externalMetric.Timestamp.UTC())
+
+	// The time of the data survives the next updates of the status
+	restored.UpdateTime = updateTime.Add(time.Minute)
+	restored = NewDatadogMetricInternal("default/dd-metric-0", datadoghq.DatadogMetric{Status: *restored.BuildStatus(status)})
+	assert.True(t, restored.Stale)
+	assert.Equal(t, dataTime, restored.DataTime)
+
+	// The time of the data is the update time of valid metrics without error
+	ddm.Stale = false
+	ddm.Error = nil
+	ddm.UpdateTime = dataTime
+	restored = NewDatadogMetricInternal("default/dd-metric-0", datadoghq.DatadogMetric{Status: *ddm.BuildStatus(nil)})
+	assert.False(t, restored.Stale)
+	assert.Equal(t, dataTime, restored.DataTime)
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	promapi "github.com/prometheus/client_golang/api"
+	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	utilserror "k8s.io/apimachinery/pkg/util/errors"
+
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/autoscalers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// prometheusBackend retrieves the DatadogMetrics values from a Prometheus-compatible query API.
+// The DatadogMetric queries are PromQL queries returning a single series.
+type prometheusBackend struct {
+	api     promv1.API
+	timeout time.Duration
+}
+
+func newPrometheusBackend(address string, timeout time.Duration) (*prometheusBackend, error) {
+	if address == "" {
+		return nil, errors.New("the Prometheus backend requires an URL")
+	}
+
+	client, err := promapi.NewClient(promapi.Config{Address: address})
+	if err != nil {
+		return nil, fmt.Errorf("invalid Prometheus URL %q: %v", address, err)
+	}
+
+	return &prometheusBackend{
+		api:     promv1.NewAPI(client),
+		timeout: timeout,
+	}, nil
+}
+
+// QueryExternalMetric runs the instant queries. Queries returning no data are not part of
+// the results, queries returning an error are invalid. The queries share the timeout of the
+// backend, and a global error is returned as soon as a query fails to reach the backend, or
+// if all the queries failed, as the backend is most likely unavailable.
+func (b *prometheusBackend) QueryExternalMetric(queries []string) (map[string]autoscalers.Point, error) {
+	results := make(map[string]autoscalers.Point, len(queries))
+	failedQueries := []string{}
+	errs := []error{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	now := time.Now()
+	for _, query := range queries {
+		point, found, err := b.query(ctx, query, now)
+		if err != nil {
+			if isConnectionError(err) {
+				return map[string]autoscalers.Point{}, fmt.Errorf("unable to reach the Prometheus backend: %v", err)
+			}
+			failedQueries = append(failedQueries, query)
+			errs = append(errs, fmt.Errorf("query %q failed: %v", query, err))
+			continue
+		}
+		if found {
+			results[query] = point
+		}
+	}
+
+	if len(errs) > 0 && len(errs) == len(queries) {
+		return map[string]autoscalers.Point{}, utilserror.NewAggregate(errs)
+	}
+
+	for _, query := range failedQueries {
+		results[query] = autoscalers.Point{Valid: false, Timestamp: now.Unix()}
+	}
+
+	return results, utilserror.NewAggregate(errs)
+}
+
+// isConnectionError returns whether err comes from the transport rather than from a response
+// of the backend, including the expiry of the timeout of the queries
+func isConnectionError(err error) bool {
+	var apiErr *promv1.Error
+	return !errors.As(err, &apiErr)
+}
+
+// query runs an instant query and returns its point, and whether there is one
+func (b *prometheusBackend) query(ctx context.Context, query string, ts time.Time) (autoscalers.Point, bool, error) {
+	value, warnings, err := b.api.Query(ctx, query, ts)
+	if err != nil {
+		return autoscalers.Point{}, false, err
+	}
+	for _, warning := range warnings {
+		log.Debugf("Warning from Prometheus for query %q: %s", query, warning)
+	}
+
+	switch v := value.(type) {
+	case model.Vector:
+		if len(v) == 0 {
+			return autoscalers.Point{}, false, nil
+		}
+		if len(v) > 1 {
+			log.Debugf("Query %q returned %d series, a single one is expected", query, len(v))
+			return autoscalers.Point{Valid: false, Timestamp: ts.Unix()}, true, nil
+		}
+		return newPoint(v[0].Value, v[0].Timestamp), true, nil
+	case *model.Scalar:
+		return newPoint(v.Value, v.Timestamp), true, nil
+	default:
+		log.Debugf("Query %q returned an unsupported result type: %s", query, value.Type())
+		return autoscalers.Point{Valid: false, Timestamp: ts.Unix()}, true, nil
+	}
+}
+
+func newPoint(value model.SampleValue, ts model.Time) autoscalers.Point {
+	f := float64(value)
+	return autoscalers.Point{
+		Value:     f,
+		Timestamp: ts.Unix(),
+		Valid:     !math.IsNaN(f) && !math.IsInf(f, 0),
+	}
+}
@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/autoscalers"
+)
+
+func TestPrometheusBackend(t *testing.T) {
+	responses := map[string]string{
+		"vector":   `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"nginx"},"value":[1617000000.5,"42.5"]}]}}`,
+		"scalar":   `{"status":"success","data":{"resultType":"scalar","result":[1617000000,"3"]}}`,
+		"empty":    `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		"multiple": `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"pod":"a"},"value":[1617000000,"1"]},{"metric":{"pod":"b"},"value":[1617000000,"2"]}]}}`,
+		"nan":      `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1617000000,"NaN"]}]}}`,
+	}
+
+	up := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		require.NoError(t, r.ParseForm())
+		response, found := responses[r.Form.Get("query")]
+		if !found {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"parse error"}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, response)
+	}))
+	defer server.Close()
+
+	_, err := newPrometheusBackend("", time.Second)
+	assert.Error(t, err)
+
+	backend, err := newPrometheusBackend(server.URL, time.Second)
+	require.NoError(t, err)
+
+	results, err := backend.QueryExternalMetric([]string{"vector", "scalar", "empty", "multiple", "nan", "invalid"})
+	assert.Error(t, err)
+	assert.Len(t, results, 5)
+	assert.Equal(t, autoscalers.Point{Value: 42.5, Timestamp: 1617000000, Valid: true}, results["vector"])
+	assert.Equal(t, autoscalers.Point{Value: 3, Timestamp: 1617000000, Valid: true}, results["scalar"])
+	assert.NotContains(t, results, "empty")
+	assert.False(t, results["multiple"].Valid)
+	assert.False(t, results["nan"].Valid)
+	assert.False(t, results["invalid"].Valid)
+
+	// Backend unavailable: global error
+	up = false
+	results, err = backend.QueryExternalMetric([]string{"vector", "scalar"})
+	assert.Error(t, err)
+	assert.Empty(t, results)
+}
+
+func TestPrometheusBackendUnreachable(t *testing.T) {
+	var requests int32
+	blocked := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-blocked
+	}))
+	defer server.Close()
+	defer close(blocked)
+
+	backend, err := newPrometheusBackend(server.URL, 100*time.Millisecond)
+	require.NoError(t, err)
+
+	// The queries share the timeout, the first one failing to reach the backend stops the others
+	start := time.Now()
+	results, err := backend.QueryExternalMetric([]string{"vector", "scalar", "empty"})
+	assert.Error(t, err)
+	assert.Empty(t, results)
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+	assert.LessOrEqual(t, atomic.LoadInt32(&requests), int32(1))
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"time"
 
 	"github.com/kubernetes-sigs/custom-metrics-apiserver/pkg/provider"
 	apierr "k8s.io/apimachinery/pkg/api/errors"
@@ -28,6 +29,8 @@ import (
 
 const (
 	autogenExpirationPeriodHours int64 = 3
+	datadogBackendName                 = "datadog"
+	prometheusBackendName              = "prometheus"
 )
 
 type datadogMetricProvider struct {
@@ -52,6 +55,7 @@ func NewDatadogMetricProvider(ctx context.Context, apiCl *apiserver.APIClient) (
 
 	refreshPeriod := config.Datadog.GetInt64("external_metrics_provider.refresh_period")
 	retrieverMetricsMaxAge := int64(math.Max(config.Datadog.GetFloat64("external_metrics_provider.max_age"), float64(3*rollup)))
+	gracePeriod := config.Datadog.GetInt64("external_metrics_provider.grace_period")
 	autogenNamespace := common.GetResourcesNamespace()
 
 	provider := &datadogMetricProvider{
@@ -61,12 +65,12 @@ func NewDatadogMetricProvider(ctx context.Context, apiCl *apiserver.APIClient) (
 	}
 
 	// Start MetricsRetriever, only leader will do refresh metrics
-	dogCl, err := autoscalers.NewDatadogClient()
+	backend, err := newMetricsBackend()
 	if err != nil {
-		return nil, fmt.Errorf("Unable to create DatadogMetricProvider as DatadogClient failed with: %v", err)
+		return nil, fmt.Errorf("Unable to create DatadogMetricProvider as metrics backend failed with: %v", err)
 	}
 
-	metricsRetriever, err := NewMetricsRetriever(refreshPeriod, retrieverMetricsMaxAge, autoscalers.NewProcessor(dogCl), le.IsLeader, &provider.store)
+	metricsRetriever, err := NewMetricsRetriever(refreshPeriod, retrieverMetricsMaxAge, gracePeriod, backend, le.IsLeader, &provider.store)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to create DatadogMetricProvider as MetricsRetriever failed with: %v", err)
 	}
@@ -97,6 +101,24 @@ func NewDatadogMetricProvider(ctx context.Context, apiCl *apiserver.APIClient) (
 	return provider, nil
 }
 
+// newMetricsBackend returns the configured backend to retrieve the DatadogMetrics values from
+func newMetricsBackend() (MetricsBackend, error) {
+	switch backend := config.Datadog.GetString("external_metrics_provider.backend"); backend {
+	case datadogBackendName:
+		dogCl, err := autoscalers.NewDatadogClient()
+		if err != nil {
+			return nil, fmt.Errorf("DatadogClient failed with: %v", err)
+		}
+		return autoscalers.NewProcessor(dogCl), nil
+	case prometheusBackendName:
+		log.Infof("Using the Prometheus backend, DatadogMetric queries are expected to be PromQL queries")
+		timeout := time.Duration(config.Datadog.GetInt64("external_metrics_provider.prometheus.timeout")) * time.Second
+		return newPrometheusBackend(config.Datadog.GetString("external_metrics_provider.prometheus.url"), timeout)
+	default:
+		return nil, fmt.Errorf("unknown backend %q, supported backends are %q and %q", backend, datadogBackendName, prometheusBackendName)
+	}
+}
+
 func (p *datadogMetricProvider) GetExternalMetric(namespace string, metricSelector labels.Selector, info provider.ExternalMetricInfo) (*external_metrics.ExternalMetricValueList, error) {
 	res, err := p.getExternalMetric(namespace, metricSelector, info)
 	if err != nil {
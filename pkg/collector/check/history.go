@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+// DefaultRunHistorySize is the default number of runs kept in the run history of a check
+const DefaultRunHistorySize = 20
+
+// RunResult holds the result of a single run of a check instance
+type RunResult struct {
+	Timestamp            int64             `json:"timestamp"`      // end of the run, unix timestamp in seconds
+	ExecutionTime        int64             `json:"execution_time"` // run duration in milliseconds
+	MetricSamples        int64             `json:"metric_samples"`
+	Events               int64             `json:"events"`
+	ServiceChecks        int64             `json:"service_checks"`
+	ServiceCheckStatuses map[string]string `json:"service_check_statuses,omitempty"` // last status submitted for each service check
+	Error                string            `json:"error,omitempty"`
+	Warnings             []string          `json:"warnings,omitempty"`
+	TimedOut             bool              `json:"timed_out,omitempty"`
+}
+
+// runHistory is a ring buffer of the last results of a check instance
+type runHistory struct {
+	results []RunResult
+	size    int
+	next    int
+}
+
+func newRunHistory(size int) runHistory {
+	if size < 0 {
+		size = 0
+	}
+	return runHistory{
+		results: make([]RunResult, 0, size),
+		size:    size,
+	}
+}
+
+// add adds a result to the history, evicting the oldest one if the history is full
+func (h *runHistory) add(result RunResult) {
+	if h.size == 0 {
+		return
+	}
+	if len(h.results) < h.size {
+		h.results = append(h.results, result)
+	} else {
+		h.results[h.next] = result
+	}
+	h.next = (h.next + 1) % h.size
+}
+
+// list returns the results of the history, from the oldest to the most recent
+func (h *runHistory) list() []RunResult {
+	results := make([]RunResult, 0, len(h.results))
+	if len(h.results) < h.size {
+		return append(results, h.results...)
+	}
+	results = append(results, h.results[h.next:]...)
+	return append(results, h.results[:h.next]...)
+}
@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// ErrRunTimeout is returned for the runs exceeding the run timeout of their check
+var ErrRunTimeout = errors.New("check run timed out")
+
+// Priority is the priority class of a check, critical checks are run by dedicated
+// workers so that they never wait behind other checks
+type Priority int
+
+// Priority classes
+const (
+	PriorityNormal Priority = iota
+	PriorityCritical
+)
+
+// ParsePriority parses the priority class of a check, an empty string is the normal priority
+func ParsePriority(s string) (Priority, error) {
+	switch s {
+	case "", "normal":
+		return PriorityNormal, nil
+	case "critical":
+		return PriorityCritical, nil
+	default:
+		return PriorityNormal, fmt.Errorf("unknown check priority %q, expected normal or critical", s)
+	}
+}
+
+// String returns the name of the priority class
+func (p Priority) String() string {
+	if p == PriorityCritical {
+		return "critical"
+	}
+	return "normal"
+}
+
+// SchedulingOptions holds the options of a check instance controlling how it's scheduled and run
+type SchedulingOptions struct {
+	Priority Priority
+	// RunTimeout is the maximum duration of a run, the default run timeout applies if zero
+	RunTimeout time.Duration
+}
+
+// SchedulableCheck is implemented by checks with scheduling options
+type SchedulableCheck interface {
+	Check
+	SchedulingOptions() SchedulingOptions
+}
+
+// ContextCheck is implemented by checks whose runs can be cancelled, the context is
+// cancelled when the run exceeds the run timeout of the check
+type ContextCheck interface {
+	Check
+	RunWithContext(ctx context.Context) error
+}
+
+// NewSchedulingOptions returns the scheduling options configured in the common options
+// of an instance, defaultPriority applies if the instance doesn't configure its priority
+func NewSchedulingOptions(commonOptions integration.CommonInstanceConfig, defaultPriority Priority) (SchedulingOptions, error) {
+	opts := SchedulingOptions{
+		Priority:   defaultPriority,
+		RunTimeout: time.Duration(commonOptions.RunTimeout) * time.Second,
+	}
+
+	if commonOptions.Priority != "" {
+		priority, err := ParsePriority(commonOptions.Priority)
+		if err != nil {
+			return opts, err
+		}
+		opts.Priority = priority
+	}
+
+	if commonOptions.RunTimeout < 0 {
+		return opts, fmt.Errorf("invalid run_timeout %d, it must be positive", commonOptions.RunTimeout)
+	}
+
+	return opts, nil
+}
+
+// GetSchedulingOptions returns the scheduling options of a check
+func GetSchedulingOptions(c Check) SchedulingOptions {
+	if s, ok := c.(SchedulableCheck); ok {
+		return s.SchedulingOptions()
+	}
+	return SchedulingOptions{}
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package check
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+type schedulableCheck struct {
+	StubCheck
+	opts SchedulingOptions
+}
+
+func (c *schedulableCheck) SchedulingOptions() SchedulingOptions { return c.opts }
+
+func TestParsePriority(t *testing.T) {
+	for s, expected := range map[string]Priority{"": PriorityNormal, "normal": PriorityNormal, "critical": PriorityCritical} {
+		p, err := ParsePriority(s)
+		require.NoError(t, err)
+		assert.Equal(t, expected, p)
+	}
+
+	_, err := ParsePriority("urgent")
+	assert.Error(t, err)
+
+	assert.Equal(t, "critical", PriorityCritical.String())
+	assert.Equal(t, "normal", PriorityNormal.String())
+}
+
+func TestNewSchedulingOptions(t *testing.T) {
+	opts, err := NewSchedulingOptions(integration.CommonInstanceConfig{}, PriorityCritical)
+	require.NoError(t, err)
+	assert.Equal(t, SchedulingOptions{Priority: PriorityCritical}, opts)
+
+	opts, err = NewSchedulingOptions(integration.CommonInstanceConfig{Priority: "normal", RunTimeout: 30}, PriorityCritical)
+	require.NoError(t, err)
+	assert.Equal(t, SchedulingOptions{Priority: PriorityNormal, RunTimeout: 30 * time.Second}, opts)
+
+	_, err = NewSchedulingOptions(integration.CommonInstanceConfig{Priority: "urgent"}, PriorityNormal)
+	assert.Error(t, err)
+
+	_, err = NewSchedulingOptions(integration.CommonInstanceConfig{RunTimeout: -1}, PriorityNormal)
+	assert.Error(t, err)
+}
+
+func TestGetSchedulingOptions(t *testing.T) {
+	assert.Equal(t, SchedulingOptions{}, GetSchedulingOptions(&StubCheck{}))
+
+	opts := SchedulingOptions{Priority: PriorityCritical, RunTimeout: time.Second}
+	assert.Equal(t, opts, GetSchedulingOptions(&schedulableCheck{opts: opts}))
+}
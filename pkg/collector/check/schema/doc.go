@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+/*
+Package schema validates check configurations against the schema declared by
+each check: the type of their keys, the required ones, the allowed values and
+the deprecated ones.
+
+Core checks register their schema from their init function with Register,
+other checks can ship a conf.yaml.schema file in their conf.d folder:
+
+	instances:
+	  url:
+	    type: string
+	    required: true
+	  collect_events:
+	    type: boolean
+	    deprecated: use `events` instead
+	  tag_cardinality:
+	    type: string
+	    enum: [low, orchestrator, high]
+*/
+package schema
@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package schema
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// FileName is the name of the schema files shipped along the configuration
+// examples of checks, in their conf.d folder: conf.d/<check>.d/conf.yaml.schema
+const FileName = "conf.yaml.schema"
+
+// Type is the type of the value of a configuration key
+type Type string
+
+// Supported types, an empty type accepts any value
+const (
+	TypeAny     Type = ""
+	TypeString  Type = "string"
+	TypeInteger Type = "integer"
+	TypeNumber  Type = "number"
+	TypeBoolean Type = "boolean"
+	TypeArray   Type = "array"
+	TypeObject  Type = "object"
+)
+
+// Field describes a key of the init_config or of the instances of a check
+type Field struct {
+	Type     Type          `yaml:"type"`
+	Required bool          `yaml:"required"`
+	Enum     []interface{} `yaml:"enum"`
+	// Deprecated is the deprecation notice of the key, the key is deprecated if it is not empty
+	Deprecated string `yaml:"deprecated"`
+}
+
+// Schema describes the configuration accepted by a check
+type Schema struct {
+	InitConfig map[string]Field `yaml:"init_config"`
+	Instances  map[string]Field `yaml:"instances"`
+	// AllowUnknownKeys disables the unknown key warnings, for checks accepting arbitrary keys
+	AllowUnknownKeys bool `yaml:"allow_unknown_keys"`
+}
+
+var (
+	registry   = make(map[string]*Schema)
+	registryMu sync.RWMutex
+)
+
+// Register registers the schema of a check, core checks register their schema
+// from their init function
+func Register(checkName string, s *Schema) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, found := registry[checkName]; found {
+		log.Debugf("Schema of check %s registered twice, overriding the previous one", checkName)
+	}
+	registry[checkName] = s
+}
+
+// Get returns the schema of a check, if any
+func Get(checkName string) (*Schema, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	s, found := registry[checkName]
+	return s, found
+}
+
+// Parse parses a YAML schema
+func Parse(data []byte) (*Schema, error) {
+	s := &Schema{}
+	if err := yaml.UnmarshalStrict(data, s); err != nil {
+		return nil, err
+	}
+
+	for section, fields := range map[string]map[string]Field{"init_config": s.InitConfig, "instances": s.Instances} {
+		for key, field := range fields {
+			switch field.Type {
+			case TypeAny, TypeString, TypeInteger, TypeNumber, TypeBoolean, TypeArray, TypeObject:
+			default:
+				return nil, fmt.Errorf("%s.%s: unknown type %q", section, key, field.Type)
+			}
+		}
+	}
+	return s, nil
+}
+
+// LoadFromConfd registers the schemas found in the conf.d folders of checks,
+// schemas registered by core checks take precedence over schema files
+func LoadFromConfd(paths []string) {
+	for _, path := range paths {
+		entries, err := ioutil.ReadDir(path)
+		if err != nil {
+			log.Debugf("Unable to look for check schemas in %s: %s", path, err)
+			continue
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() || filepath.Ext(entry.Name()) != ".d" {
+				continue
+			}
+			checkName := strings.TrimSuffix(entry.Name(), ".d")
+			if _, found := Get(checkName); found {
+				continue
+			}
+
+			schemaPath := filepath.Join(path, entry.Name(), FileName)
+			data, err := ioutil.ReadFile(schemaPath)
+			if err != nil {
+				if !os.IsNotExist(err) {
+					log.Warnf("Unable to read check schema %s: %s", schemaPath, err)
+				}
+				continue
+			}
+
+			s, err := Parse(data)
+			if err != nil {
+				log.Warnf("Invalid check schema %s: %s", schemaPath, err)
+				continue
+			}
+			log.Debugf("Loaded schema of check %s from %s", checkName, schemaPath)
+			Register(checkName, s)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+// Severity is the severity of a validation error
+type Severity string
+
+// Severities of validation errors, warnings don't prevent the check from working as configured
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Kind is the kind of a validation error
+type Kind string
+
+// Kinds of validation errors
+const (
+	KindInvalidYAML  Kind = "invalid_yaml"
+	KindUnknownKey   Kind = "unknown_key"
+	KindMissingKey   Kind = "missing_key"
+	KindInvalidType  Kind = "invalid_type"
+	KindInvalidValue Kind = "invalid_value"
+	KindDeprecated   Kind = "deprecated_key"
+)
+
+// maxSuggestionDistance is the maximum edit distance between an unknown key and
+// a known one for the latter to be suggested
+const maxSuggestionDistance = 2
+
+// commonInitConfigKeys are accepted in the init_config of every check
+var commonInitConfigKeys = map[string]struct{}{
+	"service": {},
+	"loader":  {},
+}
+
+// commonInstanceKeys are accepted in the instances of every check, see integration.CommonInstanceConfig
+var commonInstanceKeys = map[string]struct{}{
+	"min_collection_interval": {},
+	"empty_default_hostname":  {},
+	"tags":                    {},
+	"service":                 {},
+	"name":                    {},
+	"namespace":               {},
+	"loader":                  {},
+	"disable_generic_tags":    {},
+	"metric_patterns":         {},
+	"priority":                {},
+	"run_timeout":             {},
+}
+
+// Error is a structured validation error of a check configuration
+type Error struct {
+	Source   string   `json:"source,omitempty"`
+	Section  string   `json:"section"`
+	Key      string   `json:"key,omitempty"`
+	Kind     Kind     `json:"kind"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Error implements the error interface
+func (e Error) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("%s: %s", e.Section, e.Message)
+	}
+	return fmt.Sprintf("%s.%s: %s", e.Section, e.Key, e.Message)
+}
+
+// HasErrors returns whether some of the validation errors have the error severity
+func HasErrors(errs []Error) bool {
+	for _, e := range errs {
+		if e.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateConfig validates a check configuration against the schema registered for
+// the check, it returns false if the check has no schema
+func ValidateConfig(config integration.Config) ([]Error, bool) {
+	s, found := Get(config.Name)
+	if !found {
+		return nil, false
+	}
+
+	errs := s.Validate(config.InitConfig, config.Instances)
+	for i := range errs {
+		errs[i].Source = config.Source
+	}
+	return errs, true
+}
+
+// ValidateConfigs validates check configurations, it returns the validation errors by check name
+func ValidateConfigs(configs []integration.Config) map[string][]Error {
+	errs := make(map[string][]Error)
+	for _, config := range configs {
+		if !config.IsCheckConfig() || config.IsTemplate() {
+			continue
+		}
+		if configErrs, found := ValidateConfig(config); found && len(configErrs) > 0 {
+			errs[config.Name] = append(errs[config.Name], configErrs...)
+		}
+	}
+	return errs
+}
+
+// Validate validates the init_config and the instances of a check configuration
+func (s *Schema) Validate(initConfig integration.Data, instances []integration.Data) []Error {
+	var errs []Error
+	if len(initConfig) > 0 {
+		errs = append(errs, s.validateSection("init_config", initConfig, s.InitConfig, commonInitConfigKeys)...)
+	}
+	for i, instance := range instances {
+		errs = append(errs, s.validateSection(fmt.Sprintf("instances[%d]", i), instance, s.Instances, commonInstanceKeys)...)
+	}
+	return errs
+}
+
+func (s *Schema) validateSection(section string, data integration.Data, fields map[string]Field, commonKeys map[string]struct{}) []Error {
+	var errs []Error
+	newError := func(key string, kind Kind, severity Severity, format string, args ...interface{}) {
+		errs = append(errs, Error{Section: section, Key: key, Kind: kind, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		newError("", KindInvalidYAML, SeverityError, "invalid YAML: %s", err)
+		return errs
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := values[key]
+		field, found := fields[key]
+		if !found {
+			if _, common := commonKeys[key]; common || s.AllowUnknownKeys {
+				continue
+			}
+			if suggestion := closestKey(key, fields); suggestion != "" {
+				newError(key, KindUnknownKey, SeverityWarning, "unknown key, did you mean %q?", suggestion)
+			} else {
+				newError(key, KindUnknownKey, SeverityWarning, "unknown key")
+			}
+			continue
+		}
+
+		if field.Deprecated != "" {
+			newError(key, KindDeprecated, SeverityWarning, "deprecated key: %s", field.Deprecated)
+		}
+
+		// null values are handled as missing keys
+		if value == nil {
+			continue
+		}
+
+		if !field.Type.matches(value) {
+			newError(key, KindInvalidType, SeverityError, "expected a value of type %s, got %s", field.Type, typeOf(value))
+			continue
+		}
+
+		if len(field.Enum) > 0 && !inEnum(value, field.Enum) {
+			newError(key, KindInvalidValue, SeverityError, "invalid value %v, expected one of %v", value, field.Enum)
+		}
+	}
+
+	required := make([]string, 0)
+	for key, field := range fields {
+		if field.Required && values[key] == nil {
+			required = append(required, key)
+		}
+	}
+	sort.Strings(required)
+	for _, key := range required {
+		newError(key, KindMissingKey, SeverityError, "missing required key")
+	}
+
+	return errs
+}
+
+func (t Type) matches(value interface{}) bool {
+	switch t {
+	case TypeAny:
+		return true
+	case TypeString:
+		_, ok := value.(string)
+		return ok
+	case TypeInteger:
+		switch value.(type) {
+		case int, int64, uint64:
+			return true
+		}
+	case TypeNumber:
+		switch value.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+	case TypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case TypeArray:
+		_, ok := value.([]interface{})
+		return ok
+	case TypeObject:
+		_, ok := value.(map[interface{}]interface{})
+		return ok
+	}
+	return false
+}
+
+func typeOf(value interface{}) Type {
+	for _, t := range []Type{TypeString, TypeInteger, TypeNumber, TypeBoolean, TypeArray, TypeObject} {
+		if t.matches(value) {
+			return t
+		}
+	}
+	return Type(reflect.TypeOf(value).String())
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, allowed := range enum {
+		if reflect.DeepEqual(value, allowed) {
+			return true
+		}
+		// integers can be decoded in different types depending on their size
+		if TypeNumber.matches(value) && TypeNumber.matches(allowed) && fmt.Sprint(value) == fmt.Sprint(allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// closestKey returns the known key the closest to an unknown one, to help with typos
+func closestKey(key string, fields map[string]Field) string {
+	closest, closestDistance := "", maxSuggestionDistance+1
+	for candidate := range fields {
+		d := editDistance(key, candidate)
+		if d < closestDistance || (d == closestDistance && candidate < closest) {
+			closest, closestDistance = candidate, d
+		}
+	}
+	return closest
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package schema
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+)
+
+var testSchema = &Schema{
+	InitConfig: map[string]Field{
+		"timeout": {Type: TypeNumber},
+	},
+	Instances: map[string]Field{
+		"url":             {Type: TypeString, Required: true},
+		"collect_events":  {Type: TypeBoolean, Deprecated: "use `events` instead"},
+		"events":          {Type: TypeArray},
+		"port":            {Type: TypeInteger},
+		"tag_cardinality": {Type: TypeString, Enum: []interface{}{"low", "orchestrator", "high"}},
+		"version":         {Type: TypeInteger, Enum: []interface{}{1, 2}},
+		"headers":         {Type: TypeObject},
+		"anything":        {},
+	},
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		initConfig string
+		instance   string
+		expected   []Error
+	}{
+		{
+			name:     "valid",
+			instance: "url: http://localhost\nport: 8080\nevents: [a]\nheaders: {a: b}\nversion: 2\nanything: [1]\ntags: [a:b]\nmin_collection_interval: 30",
+		},
+		{
+			name:       "valid init_config",
+			initConfig: "timeout: 1.5\nservice: foo",
+			instance:   "url: http://localhost",
+		},
+		{
+			name:     "missing required key",
+			instance: "port: 8080",
+			expected: []Error{{Section: "instances[0]", Key: "url", Kind: KindMissingKey, Severity: SeverityError, Message: "missing required key"}},
+		},
+		{
+			name:     "null value",
+			instance: "url:",
+			expected: []Error{{Section: "instances[0]", Key: "url", Kind: KindMissingKey, Severity: SeverityError, Message: "missing required key"}},
+		},
+		{
+			name:     "invalid type",
+			instance: "url: http://localhost\nport: \"8080\"",
+			expected: []Error{{Section: "instances[0]", Key: "port", Kind: KindInvalidType, Severity: SeverityError, Message: "expected a value of type integer, got string"}},
+		},
+		{
+			name:     "invalid enum value",
+			instance: "url: http://localhost\ntag_cardinality: medium\nversion: 3",
+			expected: []Error{
+				{Section: "instances[0]", Key: "tag_cardinality", Kind: KindInvalidValue, Severity: SeverityError, Message: "invalid value medium, expected one of [low orchestrator high]"},
+				{Section: "instances[0]", Key: "version", Kind: KindInvalidValue, Severity: SeverityError, Message: "invalid value 3, expected one of [1 2]"},
+			},
+		},
+		{
+			name:     "unknown key with suggestion",
+			instance: "url: http://localhost\nprot: 8080\nfoo: bar",
+			expected: []Error{
+				{Section: "instances[0]", Key: "foo", Kind: KindUnknownKey, Severity: SeverityWarning, Message: "unknown key"},
+				{Section: "instances[0]", Key: "prot", Kind: KindUnknownKey, Severity: SeverityWarning, Message: `unknown key, did you mean "port"?`},
+			},
+		},
+		{
+			name:     "deprecated key",
+			instance: "url: http://localhost\ncollect_events: true",
+			expected: []Error{{Section: "instances[0]", Key: "collect_events", Kind: KindDeprecated, Severity: SeverityWarning, Message: "deprecated key: use `events` instead"}},
+		},
+		{
+			name:       "invalid init_config",
+			initConfig: "timeout: soon",
+			instance:   "url: http://localhost",
+			expected:   []Error{{Section: "init_config", Key: "timeout", Kind: KindInvalidType, Severity: SeverityError, Message: "expected a value of type number, got string"}},
+		},
+		{
+			name:     "invalid yaml",
+			instance: "- url",
+			expected: []Error{{Section: "instances[0]", Kind: KindInvalidYAML, Severity: SeverityError, Message: "invalid YAML: yaml: unmarshal errors:\n  line 1: cannot unmarshal !!seq into map[string]interface {}"}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := testSchema.Validate(integration.Data(tc.initConfig), []integration.Data{integration.Data(tc.instance)})
+			assert.Equal(t, tc.expected, errs)
+		})
+	}
+}
+
+func TestValidateAllowUnknownKeys(t *testing.T) {
+	s := &Schema{Instances: map[string]Field{"url": {Type: TypeString}}, AllowUnknownKeys: true}
+	assert.Empty(t, s.Validate(nil, []integration.Data{integration.Data("url: a\nfoo: bar")}))
+}
+
+func TestValidateConfigs(t *testing.T) {
+	Register("schema_test", testSchema)
+	defer func() {
+		registryMu.Lock()
+		delete(registry, "schema_test")
+		registryMu.Unlock()
+	}()
+
+	configs := []integration.Config{
+		{Name: "schema_test", Source: "file:/a.yaml", Instances: []integration.Data{integration.Data("url: a"), integration.Data("port: 1")}},
+		{Name: "schema_test", Source: "file:/b.yaml", Instances: []integration.Data{integration.Data("url: b")}},
+		{Name: "no_schema", Instances: []integration.Data{integration.Data("foo: bar")}},
+		{Name: "schema_test", ADIdentifiers: []string{"redis"}, Instances: []integration.Data{integration.Data("port: 1")}},
+	}
+
+	errs := ValidateConfigs(configs)
+	require.Len(t, errs, 1)
+	require.Len(t, errs["schema_test"], 1)
+	assert.Equal(t, "file:/a.yaml", errs["schema_test"][0].Source)
+	assert.Equal(t, "instances[1].url: missing required key", errs["schema_test"][0].Error())
+	assert.True(t, HasErrors(errs["schema_test"]))
+}
+
+func TestLoadFromConfd(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"schema_file.d":    "instances:\n  url:\n    type: string\n    required: true\n",
+		"schema_invalid.d": "instances:\n  url:\n    type: url\n",
+		"schema_unknown.d": "instancess: {}\n",
+		"schema_none.d":    "",
+	} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, name), 0755))
+		if content != "" {
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name, FileName), []byte(content), 0644))
+		}
+	}
+
+	LoadFromConfd([]string{dir, filepath.Join(dir, "does-not-exist")})
+
+	s, found := Get("schema_file")
+	require.True(t, found)
+	assert.Equal(t, Field{Type: TypeString, Required: true}, s.Instances["url"])
+
+	for _, name := range []string{"schema_invalid", "schema_unknown", "schema_none"} {
+		_, found = Get(name)
+		assert.False(t, found, name)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("port", "port"))
+	assert.Equal(t, 2, editDistance("prot", "port"))
+	assert.Equal(t, 1, editDistance("url", "urls"))
+	assert.Equal(t, 4, editDistance("", "port"))
+}
@@ -6,9 +6,11 @@
 package check
 
 import (
+	"errors"
 	"sync"
 	"time"
 
+	agentconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	telemetry_utils "github.com/DataDog/datadog-agent/pkg/telemetry/utils"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -18,6 +20,10 @@ import (
 const (
 	runCheckFailureTag = "fail"
 	runCheckSuccessTag = "ok"
+
+	// lateRunThreshold is the lateness above which a run is counted as late, runs are
+	// scheduled with a one second granularity
+	lateRunThreshold = time.Second
 )
 
 // EventPlatformNameTranslations contains human readable translations for event platform event types
@@ -41,6 +47,10 @@ var (
 		[]string{"check_name"}, "Service checks count")
 	tlmExecutionTime = telemetry.NewGauge("checks", "execution_time",
 		[]string{"check_name"}, "Check execution time")
+	tlmLateness = telemetry.NewGauge("checks", "lateness",
+		[]string{"check_name"}, "Delay between the scheduled time of a check run and its start")
+	tlmTimeouts = telemetry.NewCounter("checks", "timeouts",
+		[]string{"check_name"}, "Check runs that exceeded their run timeout")
 )
 
 // SenderStats contains statistics showing the count of various types of telemetry sent by a check sender
@@ -51,12 +61,15 @@ type SenderStats struct {
 	HistogramBuckets int64
 	// EventPlatformEvents tracks the number of events submitted for each eventType
 	EventPlatformEvents map[string]int64
+	// ServiceCheckStatuses tracks the last status submitted for each service check
+	ServiceCheckStatuses map[string]string
 }
 
 // NewSenderStats creates a new SenderStats
 func NewSenderStats() SenderStats {
 	return SenderStats{
-		EventPlatformEvents: make(map[string]int64),
+		EventPlatformEvents:  make(map[string]int64),
+		ServiceCheckStatuses: make(map[string]string),
 	}
 }
 
@@ -67,6 +80,10 @@ func (s SenderStats) Copy() (result SenderStats) {
 	for k, v := range s.EventPlatformEvents {
 		result.EventPlatformEvents[k] = v
 	}
+	result.ServiceCheckStatuses = make(map[string]string, len(s.ServiceCheckStatuses))
+	for k, v := range s.ServiceCheckStatuses {
+		result.ServiceCheckStatuses[k] = v
+	}
 	return result
 }
 
@@ -76,9 +93,11 @@ type Stats struct {
 	CheckVersion             string
 	CheckConfigSource        string
 	CheckID                  ID
+	CheckPriority            string
 	TotalRuns                uint64
 	TotalErrors              uint64
 	TotalWarnings            uint64
+	TotalTimeouts            uint64
 	MetricSamples            int64
 	Events                   int64
 	ServiceChecks            int64
@@ -94,8 +113,15 @@ type Stats struct {
 	LastError                string    // error that occurred in the last run, if any
 	LastWarnings             []string  // warnings that occurred in the last run, if any
 	UpdateTimestamp          int64     // latest update to this instance, unix timestamp in seconds
+	LatenessTimes            [32]int64 // circular buffer of recent run latenesses, the delay between the scheduled time of a run and its start
+	AverageLateness          int64     // average run lateness
+	MaxLateness              int64     // maximum run lateness in the circular buffer
+	LastLateness             int64     // most recent run lateness, provided for convenience
+	TotalLateRuns            uint64    // runs started more than a second after their scheduled time
 	m                        sync.Mutex
-	telemetry                bool // do we want telemetry on this Check
+	telemetry                bool       // do we want telemetry on this Check
+	scheduledRuns            uint64     // number of runs whose lateness was tracked
+	history                  runHistory // results of the last runs
 }
 
 // NewStats returns a new check stats instance
@@ -105,9 +131,11 @@ func NewStats(c Check) *Stats {
 		CheckName:                c.String(),
 		CheckVersion:             c.Version(),
 		CheckConfigSource:        c.ConfigSource(),
+		CheckPriority:            GetSchedulingOptions(c).Priority.String(),
 		telemetry:                telemetry_utils.IsCheckEnabled(c.String()),
 		EventPlatformEvents:      make(map[string]int64),
 		TotalEventPlatformEvents: make(map[string]int64),
+		history:                  newRunHistory(agentconfig.Datadog.GetInt("check_run_history_size")),
 	}
 
 	// We are interested in a check's run state values even when they are 0 so we
@@ -144,6 +172,12 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats S
 	cs.AverageExecutionTime = totalExecutionTime / int64(ringSize)
 	if err != nil {
 		cs.TotalErrors++
+		if errors.Is(err, ErrRunTimeout) {
+			cs.TotalTimeouts++
+			if cs.telemetry {
+				tlmTimeouts.Inc(cs.CheckName)
+			}
+		}
 		if cs.telemetry {
 			tlmRuns.Inc(cs.CheckName, runCheckFailureTag)
 		}
@@ -196,6 +230,65 @@ func (cs *Stats) Add(t time.Duration, err error, warnings []error, metricStats S
 		cs.TotalEventPlatformEvents[k] = cs.TotalEventPlatformEvents[k] + v
 		cs.EventPlatformEvents[k] = v
 	}
+
+	result := RunResult{
+		Timestamp:     cs.UpdateTimestamp,
+		ExecutionTime: tms,
+		MetricSamples: metricStats.MetricSamples,
+		Events:        metricStats.Events,
+		ServiceChecks: metricStats.ServiceChecks,
+		Error:         cs.LastError,
+		TimedOut:      errors.Is(err, ErrRunTimeout),
+	}
+	if len(metricStats.ServiceCheckStatuses) > 0 {
+		result.ServiceCheckStatuses = make(map[string]string, len(metricStats.ServiceCheckStatuses))
+		for k, v := range metricStats.ServiceCheckStatuses {
+			result.ServiceCheckStatuses[k] = v
+		}
+	}
+	if len(cs.LastWarnings) > 0 {
+		result.Warnings = append([]string(nil), cs.LastWarnings...)
+	}
+	cs.history.add(result)
+}
+
+// History returns the results of the last runs, from the oldest to the most recent
+func (cs *Stats) History() []RunResult {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+
+	return cs.history.list()
+}
+
+// AddLateness tracks the delay between the scheduled time of a run and its start
+func (cs *Stats) AddLateness(lateness time.Duration) {
+	cs.m.Lock()
+	defer cs.m.Unlock()
+
+	lms := lateness.Nanoseconds() / 1e6
+	cs.LastLateness = lms
+	cs.LatenessTimes[cs.scheduledRuns%uint64(len(cs.LatenessTimes))] = lms
+	cs.scheduledRuns++
+	if lateness > lateRunThreshold {
+		cs.TotalLateRuns++
+	}
+	if cs.telemetry {
+		tlmLateness.Set(float64(lms), cs.CheckName)
+	}
+
+	ringSize := cs.scheduledRuns
+	if ringSize > uint64(len(cs.LatenessTimes)) {
+		ringSize = uint64(len(cs.LatenessTimes))
+	}
+	var totalLateness, maxLateness int64
+	for i := uint64(0); i < ringSize; i++ {
+		totalLateness += cs.LatenessTimes[i]
+		if cs.LatenessTimes[i] > maxLateness {
+			maxLateness = cs.LatenessTimes[i]
+		}
+	}
+	cs.AverageLateness = totalLateness / int64(ringSize)
+	cs.MaxLateness = maxLateness
 }
 
 type aggStats struct {
@@ -6,9 +6,11 @@
 package check
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -138,3 +140,78 @@ func TestTranslateEventPlatformEventTypes(t *testing.T) {
 	assert.True(t, assert.ObjectsAreEqual(expected, result))
 	assert.EqualValues(t, expected, result)
 }
+
+func TestStatsLateness(t *testing.T) {
+	stats := NewStats(newMockCheck())
+	assert.Equal(t, "normal", stats.CheckPriority)
+
+	stats.AddLateness(100 * time.Millisecond)
+	stats.AddLateness(3 * time.Second)
+	stats.AddLateness(200 * time.Millisecond)
+
+	assert.Equal(t, int64(200), stats.LastLateness)
+	assert.Equal(t, int64(3000), stats.MaxLateness)
+	assert.Equal(t, int64(1100), stats.AverageLateness)
+	assert.Equal(t, uint64(1), stats.TotalLateRuns)
+
+	// the max is computed on the recent runs only
+	for i := 0; i < len(stats.LatenessTimes); i++ {
+		stats.AddLateness(10 * time.Millisecond)
+	}
+	assert.Equal(t, int64(10), stats.MaxLateness)
+	assert.Equal(t, int64(10), stats.AverageLateness)
+	assert.Equal(t, uint64(1), stats.TotalLateRuns)
+}
+
+func TestStatsTimeouts(t *testing.T) {
+	stats := NewStats(newMockCheck())
+
+	stats.Add(time.Second, fmt.Errorf("%w after 1s", ErrRunTimeout), nil, SenderStats{})
+	stats.Add(time.Second, fmt.Errorf("some error"), nil, SenderStats{})
+
+	assert.Equal(t, uint64(2), stats.TotalErrors)
+	assert.Equal(t, uint64(1), stats.TotalTimeouts)
+}
+
+func TestStatsHistory(t *testing.T) {
+	agentConfig.Datadog.Set("check_run_history_size", 3)
+	defer agentConfig.Datadog.Set("check_run_history_size", DefaultRunHistorySize)
+
+	stats := NewStats(newMockCheck())
+	assert.Empty(t, stats.History())
+
+	senderStats := NewSenderStats()
+	senderStats.MetricSamples = 10
+	senderStats.ServiceChecks = 1
+	senderStats.ServiceCheckStatuses["mock.can_connect"] = "OK"
+	stats.Add(10*time.Millisecond, nil, nil, senderStats)
+	stats.Add(20*time.Millisecond, fmt.Errorf("some error"), []error{fmt.Errorf("some warning")}, SenderStats{})
+
+	history := stats.History()
+	assert.Len(t, history, 2)
+	assert.Equal(t, int64(10), history[0].ExecutionTime)
+	assert.Equal(t, int64(10), history[0].MetricSamples)
+	assert.Equal(t, map[string]string{"mock.can_connect": "OK"}, history[0].ServiceCheckStatuses)
+	assert.Empty(t, history[0].Error)
+	assert.NotZero(t, history[0].Timestamp)
+	assert.Equal(t, "some error", history[1].Error)
+	assert.Equal(t, []string{"some warning"}, history[1].Warnings)
+	assert.False(t, history[1].TimedOut)
+
+	// the oldest runs are evicted
+	stats.Add(30*time.Millisecond, fmt.Errorf("%w after 1s", ErrRunTimeout), nil, SenderStats{})
+	stats.Add(40*time.Millisecond, nil, nil, SenderStats{})
+
+	history = stats.History()
+	assert.Len(t, history, 3)
+	assert.Equal(t, int64(20), history[0].ExecutionTime)
+	assert.Equal(t, int64(30), history[1].ExecutionTime)
+	assert.True(t, history[1].TimedOut)
+	assert.Equal(t, int64(40), history[2].ExecutionTime)
+
+	// the history can be disabled
+	agentConfig.Datadog.Set("check_run_history_size", 0)
+	stats = NewStats(newMockCheck())
+	stats.Add(10*time.Millisecond, nil, nil, SenderStats{})
+	assert.Empty(t, stats.History())
+}
@@ -40,7 +40,7 @@ type Collector struct {
 // NewCollector create a Collector instance and sets up the Python Environment
 func NewCollector(paths ...string) *Collector {
 	run := runner.NewRunner()
-	sched := scheduler.NewScheduler(run.GetChan())
+	sched := scheduler.NewScheduler(run.GetChan(), run.GetCriticalChan())
 
 	// let the runner some visibility into the scheduler
 	run.SetScheduler(sched)
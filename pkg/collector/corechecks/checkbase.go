@@ -37,13 +37,28 @@ import (
 //
 // If custom tags are set in the instance configuration, they will
 // be automatically appended to each send done by this check.
+//
+// Core system checks are scheduled with a critical priority unless
+// their instance configures another one.
 type CheckBase struct {
-	checkName      string
-	checkID        check.ID
-	latestWarnings []error
-	checkInterval  time.Duration
-	source         string
-	telemetry      bool
+	checkName         string
+	checkID           check.ID
+	latestWarnings    []error
+	checkInterval     time.Duration
+	schedulingOptions check.SchedulingOptions
+	source            string
+	telemetry         bool
+}
+
+// criticalChecks are the core checks scheduled with a critical priority by default
+var criticalChecks = map[string]struct{}{
+	"cpu":         {},
+	"memory":      {},
+	"load":        {},
+	"io":          {},
+	"uptime":      {},
+	"file_handle": {},
+	"winproc":     {},
 }
 
 // NewCheckBase returns a check base struct with a given check name
@@ -54,13 +69,21 @@ func NewCheckBase(name string) CheckBase {
 // NewCheckBaseWithInterval returns a check base struct with a given check name and interval
 func NewCheckBaseWithInterval(name string, defaultInterval time.Duration) CheckBase {
 	return CheckBase{
-		checkName:     name,
-		checkID:       check.ID(name),
-		checkInterval: defaultInterval,
-		telemetry:     telemetry_utils.IsCheckEnabled(name),
+		checkName:         name,
+		checkID:           check.ID(name),
+		checkInterval:     defaultInterval,
+		schedulingOptions: check.SchedulingOptions{Priority: defaultPriority(name)},
+		telemetry:         telemetry_utils.IsCheckEnabled(name),
 	}
 }
 
+func defaultPriority(checkName string) check.Priority {
+	if _, found := criticalChecks[checkName]; found {
+		return check.PriorityCritical
+	}
+	return check.PriorityNormal
+}
+
 // BuildID is to be called by the check's Config() method to generate
 // the unique check ID.
 func (c *CheckBase) BuildID(instance, initConfig integration.Data) {
@@ -118,6 +141,13 @@ func (c *CheckBase) CommonConfigure(instance integration.Data, source string) er
 		c.checkInterval = time.Duration(commonOptions.MinCollectionInterval) * time.Second
 	}
 
+	// Set the priority and run timeout of the check
+	c.schedulingOptions, err = check.NewSchedulingOptions(commonOptions, defaultPriority(c.checkName))
+	if err != nil {
+		log.Errorf("invalid instance section for check %s: %s", string(c.ID()), err)
+		return err
+	}
+
 	// Disable default hostname if specified
 	if commonOptions.EmptyDefaultHostname {
 		s, err := aggregator.GetSender(c.checkID)
@@ -192,6 +222,11 @@ func (c *CheckBase) Interval() time.Duration {
 	return c.checkInterval
 }
 
+// SchedulingOptions returns the priority and run timeout of the check
+func (c *CheckBase) SchedulingOptions() check.SchedulingOptions {
+	return c.schedulingOptions
+}
+
 // String returns the name of the check, the same for every instance
 func (c *CheckBase) String() string {
 	return c.checkName
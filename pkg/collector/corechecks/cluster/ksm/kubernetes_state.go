@@ -21,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
 	kubestatemetrics "github.com/DataDog/datadog-agent/pkg/kubestatemetrics/builder"
+	"github.com/DataDog/datadog-agent/pkg/kubestatemetrics/customresource"
 	ksmstore "github.com/DataDog/datadog-agent/pkg/kubestatemetrics/store"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes"
@@ -86,6 +87,22 @@ type KSMConfig struct {
 	//   namespace: kube_namespace
 	LabelsMapper map[string]string `yaml:"labels_mapper"`
 
+	// CustomResources defines the metrics to generate from custom resources.
+	// Example: Report the replicas and the conditions of the Database objects.
+	// custom_resources:
+	//   - group: example.com
+	//     version: v1
+	//     kind: Database
+	//     metrics:
+	//       - name: status.replicas
+	//         path: status.replicas
+	//       - name: status.condition
+	//         path: status.conditions
+	//         value_path: status
+	//         labels_from_path:
+	//           condition: type
+	CustomResources []customresource.Resource `yaml:"custom_resources"`
+
 	// Tags contains the list of tags to attach to every metric, event and service check emitted by this integration.
 	// Example:
 	// tags:
@@ -124,6 +141,11 @@ type KSMCheck struct {
 	cancel      context.CancelFunc
 	isCLCRunner bool
 	clusterName string
+
+	// customResourceMetrics maps the metric families of the custom resources to their Datadog names
+	customResourceMetrics map[string]string
+	// customResourceAggregators are the aggregators of the custom resources metric families
+	customResourceAggregators map[string]metricAggregator
 }
 
 // JoinsConfig contains the config parameters for label joins
@@ -184,6 +206,10 @@ func (k *KSMCheck) Configure(config, initConfig integration.Data, source string)
 
 	k.processLabelsAsTags()
 
+	if err := k.processCustomResources(); err != nil {
+		return err
+	}
+
 	// Prepare labels mapper
 	k.mergeLabelsMapper(defaultLabelsMapper)
 
@@ -267,6 +293,43 @@ func (k *KSMCheck) Configure(config, initConfig integration.Data, source string)
 	// Start the collection process
 	k.allStores = builder.BuildStores()
 
+	if len(k.instance.CustomResources) > 0 {
+		dynamicClient := c.DynamicCl
+		if dynamicClient == nil {
+			dynamicClient, err = apiserver.GetKubeDynamicClient(0) // No timeout for the reflectors, to allow long watch.
+			if err != nil {
+				return err
+			}
+		}
+
+		builder.WithDynamicClient(dynamicClient)
+
+		k.allStores = append(k.allStores, builder.BuildCustomResourceStores(k.instance.CustomResources)...)
+	}
+
+	return nil
+}
+
+// processCustomResources validates the custom resources definitions and
+// prepares the mapping and the aggregation of their metrics.
+func (k *KSMCheck) processCustomResources() error {
+	for _, resource := range k.instance.CustomResources {
+		if err := resource.Validate(); err != nil {
+			return err
+		}
+
+		for _, m := range resource.Metrics {
+			k.customResourceMetrics[resource.FamilyName(m)] = resource.DatadogName(m)
+		}
+
+		labelsFamily := resource.LabelsFamilyName()
+		k.customResourceAggregators[labelsFamily] = newCountObjectsAggregator(
+			resource.LabelName()+".count",
+			labelsFamily,
+			[]string{"namespace"},
+		)
+	}
+
 	return nil
 }
 
@@ -342,7 +405,8 @@ func (k *KSMCheck) processMetrics(sender aggregator.Sender, metrics map[string][
 	for _, metricsList := range metrics {
 		for _, metricFamily := range metricsList {
 			// First check for aggregator, because the check use _labels metrics to aggregate values.
-			if aggregator, found := metricAggregators[metricFamily.Name]; found {
+			aggregator, hasAggregator := k.metricAggregator(metricFamily.Name)
+			if hasAggregator {
 				for _, m := range metricFamily.ListMetrics {
 					aggregator.accumulate(m)
 				}
@@ -365,7 +429,14 @@ func (k *KSMCheck) processMetrics(sender aggregator.Sender, metrics map[string][
 				}
 				continue
 			}
-			if _, found := metricAggregators[metricFamily.Name]; found {
+			if ddname, found := k.customResourceMetrics[metricFamily.Name]; found {
+				for _, m := range metricFamily.ListMetrics {
+					hostname, tags := k.hostnameAndTags(m.Labels, labelJoiner, nil)
+					sender.Gauge(ksmMetricPrefix+ddname, m.Val, hostname, tags)
+				}
+				continue
+			}
+			if hasAggregator {
 				continue
 			}
 			if metadataMetricsRegex.MatchString(metricFamily.Name) {
@@ -381,6 +452,18 @@ func (k *KSMCheck) processMetrics(sender aggregator.Sender, metrics map[string][
 	for _, aggregator := range metricAggregators {
 		aggregator.flush(sender, k, labelJoiner)
 	}
+	for _, aggregator := range k.customResourceAggregators {
+		aggregator.flush(sender, k, labelJoiner)
+	}
+}
+
+// metricAggregator returns the aggregator of a metric family, if any
+func (k *KSMCheck) metricAggregator(name string) (metricAggregator, bool) {
+	if aggregator, found := metricAggregators[name]; found {
+		return aggregator, true
+	}
+	aggregator, found := k.customResourceAggregators[name]
+	return aggregator, found
 }
 
 // hostnameAndTags returns the tags and the hostname for a metric based on the metric labels and the check configuration
@@ -561,7 +644,8 @@ func (k *KSMCheck) processTelemetry(metrics map[string][]ksmstore.DDMetricsFam)
 
 	for name, list := range metrics {
 		isMetadataMetric := metadataMetricsRegex.MatchString(name)
-		if !isKnownMetric(name) && !isMetadataMetric {
+		_, isCustomResourceMetric := k.customResourceMetrics[name]
+		if !isKnownMetric(name) && !isCustomResourceMetric && !isMetadataMetric {
 			k.telemetry.incUnknown()
 			continue
 		}
@@ -620,10 +704,12 @@ func KubeStateMetricsFactoryWithParam(labelsMapper map[string]string, labelJoins
 
 func newKSMCheck(base core.CheckBase, instance *KSMConfig) *KSMCheck {
 	return &KSMCheck{
-		CheckBase:   base,
-		instance:    instance,
-		telemetry:   newTelemetryCache(),
-		isCLCRunner: config.IsCLCRunner(),
+		CheckBase:                 base,
+		instance:                  instance,
+		telemetry:                 newTelemetryCache(),
+		isCLCRunner:               config.IsCLCRunner(),
+		customResourceMetrics:     make(map[string]string),
+		customResourceAggregators: make(map[string]metricAggregator),
 	}
 }
 
@@ -15,6 +15,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/kubestatemetrics/customresource"
 	ksmstore "github.com/DataDog/datadog-agent/pkg/kubestatemetrics/store"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/kube-state-metrics/v2/pkg/allowdenylist"
@@ -470,6 +471,78 @@ func TestProcessMetrics(t *testing.T) {
 	}
 }
 
+func TestProcessCustomResourceMetrics(t *testing.T) {
+	config := &KSMConfig{
+		LabelsMapper: map[string]string{"namespace": "kube_namespace", "label_team": "team"},
+		LabelJoins: map[string]*JoinsConfig{
+			"kube_database_labels": {
+				LabelsToMatch: []string{"database", "namespace"},
+				LabelsToGet:   []string{"label_team"},
+			},
+		},
+		CustomResources: []customresource.Resource{
+			{
+				Group:   "example.com",
+				Version: "v1",
+				Kind:    "Database",
+				Metrics: []customresource.Metric{
+					{Name: "status.replicas", Path: "status.replicas"},
+				},
+			},
+		},
+	}
+
+	check := newKSMCheck(core.NewCheckBase(kubeStateMetricsCheckName), config)
+	assert.NoError(t, check.processCustomResources())
+
+	labelsFamily := ksmstore.DDMetricsFam{
+		Type: "*unstructured.Unstructured",
+		Name: "kube_database_labels",
+		ListMetrics: []ksmstore.DDMetric{
+			{
+				Labels: map[string]string{"database": "orders", "namespace": "default", "label_team": "payments"},
+				Val:    1,
+			},
+			{
+				Labels: map[string]string{"database": "users", "namespace": "default"},
+				Val:    1,
+			},
+		},
+	}
+	metrics := map[string][]ksmstore.DDMetricsFam{
+		"kube_database_status_replicas": {
+			{
+				Type: "*unstructured.Unstructured",
+				Name: "kube_database_status_replicas",
+				ListMetrics: []ksmstore.DDMetric{
+					{
+						Labels: map[string]string{"database": "orders", "namespace": "default"},
+						Val:    3,
+					},
+				},
+			},
+		},
+		"kube_database_labels": {labelsFamily},
+	}
+
+	mocked := mocksender.NewMockSender(check.ID())
+	mocked.SetupAcceptAll()
+
+	labelJoiner := newLabelJoiner(config.LabelJoins)
+	labelJoiner.insertFamily(labelsFamily)
+	check.processMetrics(mocked, metrics, labelJoiner)
+
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.database.status.replicas", 3, "", []string{"database:orders", "kube_namespace:default", "team:payments"})
+	mocked.AssertMetric(t, "Gauge", "kubernetes_state.database.count", 2, "", []string{"kube_namespace:default"})
+	mocked.AssertNumberOfCalls(t, "Gauge", 2)
+
+	assert.Error(t, (&KSMCheck{
+		instance:                  &KSMConfig{CustomResources: []customresource.Resource{{Version: "v1", Kind: "Database"}}},
+		customResourceMetrics:     make(map[string]string),
+		customResourceAggregators: make(map[string]metricAggregator),
+	}).processCustomResources())
+}
+
 func TestProcessTelemetry(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -48,6 +48,7 @@ const (
 type KubeASConfig struct {
 	CollectEvent             bool     `yaml:"collect_events"`
 	CollectOShiftQuotas      bool     `yaml:"collect_openshift_clusterquotas"`
+	CollectEventSignals      bool     `yaml:"collect_event_signals"`
 	FilteredEventTypes       []string `yaml:"filtered_event_types"`
 	EventCollectionTimeoutMs int      `yaml:"kubernetes_event_read_timeout_ms"`
 	MaxEventCollection       int      `yaml:"max_events_per_run"`
@@ -71,12 +72,14 @@ type KubeASCheck struct {
 	ac              *apiserver.APIClient
 	oshiftAPILevel  apiserver.OpenShiftAPILevel
 	providerIDCache *cache.Cache
+	eventSignals    *eventSignals
 }
 
 func (c *KubeASConfig) parse(data []byte) error {
 	// default values
 	c.CollectEvent = config.Datadog.GetBool("collect_kubernetes_events")
 	c.CollectOShiftQuotas = true
+	c.CollectEventSignals = true
 	c.ResyncPeriodEvents = defaultResyncPeriodInSecond
 	c.UseComponentStatus = true
 
@@ -88,6 +91,7 @@ func NewKubeASCheck(base core.CheckBase, instance *KubeASConfig) *KubeASCheck {
 		CheckBase:       base,
 		instance:        instance,
 		providerIDCache: cache.New(defaultCacheExpire, defaultCachePurge),
+		eventSignals:    newEventSignals(),
 	}
 }
 
@@ -205,8 +209,8 @@ func (k *KubeASCheck) Run() error {
 		}
 	}
 
-	// Running the event collection.
-	if !k.instance.CollectEvent {
+	// Running the event collection, the events are also needed to derive the event signals.
+	if !k.instance.CollectEvent && !k.instance.CollectEventSignals {
 		return nil
 	}
 
@@ -217,9 +221,15 @@ func (k *KubeASCheck) Run() error {
 	}
 
 	// Process the events to have a Datadog format.
-	err = k.processEvents(sender, events)
-	if err != nil {
-		k.Warnf("Could not submit new event %s", err.Error()) //nolint:errcheck
+	if k.instance.CollectEvent {
+		err = k.processEvents(sender, events)
+		if err != nil {
+			k.Warnf("Could not submit new event %s", err.Error()) //nolint:errcheck
+		}
+	}
+
+	if k.instance.CollectEventSignals {
+		k.eventSignalsCheck(sender, events)
 	}
 	return nil
 }
@@ -253,6 +263,30 @@ func (k *KubeASCheck) eventCollectionCheck() (newEvents []*v1.Event, err error)
 	return newEvents, nil
 }
 
+// eventSignalsCheck submits the signals derived from the events. The events
+// already accounted for are shared through the ConfigMap with the next leaders.
+func (k *KubeASCheck) eventSignalsCheck(sender aggregator.Sender, events []*v1.Event) {
+	token, _, err := k.ac.GetTokenFromConfigmap(eventSignalsTokenKey)
+	if err != nil {
+		k.Warnf("Could not retrieve the event signals from the ConfigMap, only relying on the local ones: %s", err.Error()) //nolint:errcheck
+	} else if token != "" {
+		if err := k.eventSignals.load(token); err != nil {
+			log.Errorf("Event signals stored in the ConfigMap are incorrect. Will only rely on the local ones: %s", err)
+		}
+	}
+
+	k.eventSignals.process(sender, events, time.Now())
+
+	token, err = k.eventSignals.dump()
+	if err != nil {
+		k.Warnf("Could not serialize the event signals: %s", err.Error()) //nolint:errcheck
+		return
+	}
+	if err := k.ac.UpdateTokenInConfigmap(eventSignalsTokenKey, token, time.Now()); err != nil {
+		k.Warnf("Could not store the event signals in the ConfigMap: %s", err.Error()) //nolint:errcheck
+	}
+}
+
 func (k *KubeASCheck) parseComponentStatus(sender aggregator.Sender, componentsStatus *v1.ComponentStatusList) error {
 	for _, component := range componentsStatus.Items {
 		if component.ObjectMeta.Name == "" {
@@ -0,0 +1,277 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+
+package kubernetesapiserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	eventSignalsTokenKey = "event_signals"
+
+	jobFailedMetric            = "kubernetes_apiserver.job.failed"
+	cronJobMissedMetric        = "kubernetes_apiserver.cronjob.missed_schedules"
+	nodeConditionMetric        = "kubernetes_apiserver.node.condition_transitions"
+	podEvictedMetric           = "kubernetes_apiserver.pod.evictions"
+	nodeConditionServiceCheck  = "kubernetes_apiserver.node.condition"
+	nodeConditionMemory        = "MemoryPressure"
+	nodeConditionDisk          = "DiskPressure"
+	nodeConditionReady         = "Ready"
+	evictionReasonOther        = "other"
+	evictionReasonTaint        = "taint"
+	evictionReasonStorageLimit = "ephemeral_storage_limit"
+
+	// Kubernetes events are garbage collected after an hour by default,
+	// the events we have not seen for twice that long can't come back.
+	eventSignalsRetention = 2 * time.Hour
+)
+
+// nodeTransition is a node condition change reported by a Kubernetes event.
+type nodeTransition struct {
+	condition string
+	// healthy is true when the node recovered from the condition, false when it
+	// entered it. The Ready condition is healthy when true, pressure ones when
+	// false.
+	healthy bool
+}
+
+// nodeTransitions maps the reasons of the node events emitted by the kubelet
+// and the node controller to the condition transition they report.
+var nodeTransitions = map[string]nodeTransition{
+	"NodeHasInsufficientMemory": {condition: nodeConditionMemory, healthy: false},
+	"NodeHasSufficientMemory":   {condition: nodeConditionMemory, healthy: true},
+	"NodeHasDiskPressure":       {condition: nodeConditionDisk, healthy: false},
+	"NodeHasNoDiskPressure":     {condition: nodeConditionDisk, healthy: true},
+	"NodeNotReady":              {condition: nodeConditionReady, healthy: false},
+	"NodeReady":                 {condition: nodeConditionReady, healthy: true},
+}
+
+var (
+	// jobFailureReasons are the reasons of the events emitted by the job
+	// controller when a Job fails.
+	jobFailureReasons = map[string]struct{}{
+		"BackoffLimitExceeded": {},
+		"DeadlineExceeded":     {},
+	}
+
+	// cronJobMissedReasons are the reasons of the events emitted by the cronjob
+	// controller when a CronJob misses a schedule.
+	cronJobMissedReasons = map[string]struct{}{
+		"MissSchedule":       {},
+		"TooManyMissedTimes": {},
+	}
+
+	// lowOnResourceRegexp extracts the starved resource from the message of the
+	// evictions triggered by the kubelet on node pressure.
+	lowOnResourceRegexp = regexp.MustCompile(`low on resource: ([\w-]+)`)
+)
+
+// seenEvent is what we remember of a Kubernetes event to only account for its
+// new occurrences.
+type seenEvent struct {
+	Count         int32 `json:"c"`
+	LastTimestamp int64 `json:"t"`
+}
+
+// eventSignals derives metrics and service checks from the Kubernetes events.
+// The events are updated in place when they occur again, so it keeps the count
+// of the events it already accounted for. This state is stored in the token
+// ConfigMap, so that a new leader doesn't report them again.
+type eventSignals struct {
+	seen map[string]seenEvent
+}
+
+func newEventSignals() *eventSignals {
+	return &eventSignals{
+		seen: make(map[string]seenEvent),
+	}
+}
+
+// load replaces the state with the one stored in token.
+func (s *eventSignals) load(token string) error {
+	seen := make(map[string]seenEvent)
+	if err := json.Unmarshal([]byte(token), &seen); err != nil {
+		return err
+	}
+	s.seen = seen
+	return nil
+}
+
+// dump returns the state to store in the token ConfigMap.
+func (s *eventSignals) dump() (string, error) {
+	token, err := json.Marshal(s.seen)
+	if err != nil {
+		return "", err
+	}
+	return string(token), nil
+}
+
+// process submits the signals of the events, and forgets the events that
+// cannot be updated anymore.
+func (s *eventSignals) process(sender aggregator.Sender, events []*v1.Event, now time.Time) {
+	signalEvents := make([]*v1.Event, 0, len(events))
+	for _, event := range events {
+		if isSignalEvent(event) {
+			signalEvents = append(signalEvents, event)
+		}
+	}
+
+	// Node conditions are reported as service checks, the last transition
+	// must be submitted last.
+	sort.SliceStable(signalEvents, func(i, j int) bool {
+		return signalEvents[i].LastTimestamp.Before(&signalEvents[j].LastTimestamp)
+	})
+
+	for _, event := range signalEvents {
+		occurrences := s.newOccurrences(event)
+		if occurrences == 0 {
+			continue
+		}
+		submitSignal(sender, event, occurrences)
+	}
+
+	for uid, seen := range s.seen {
+		if now.Sub(time.Unix(seen.LastTimestamp, 0)) > eventSignalsRetention {
+			delete(s.seen, uid)
+		}
+	}
+}
+
+// newOccurrences returns how many times the event occurred since we last saw
+// it, and records it as seen.
+func (s *eventSignals) newOccurrences(event *v1.Event) int32 {
+	count := event.Count
+	if count == 0 {
+		count = 1
+	}
+
+	uid := string(event.UID)
+	if uid == "" {
+		uid = fmt.Sprintf("%s/%s", event.Namespace, event.Name)
+	}
+
+	previous := s.seen[uid]
+	s.seen[uid] = seenEvent{
+		Count:         count,
+		LastTimestamp: eventLastTimestamp(event).Unix(),
+	}
+
+	if count <= previous.Count {
+		return 0
+	}
+	return count - previous.Count
+}
+
+func isSignalEvent(event *v1.Event) bool {
+	switch event.InvolvedObject.Kind {
+	case kubernetes.JobKind:
+		_, found := jobFailureReasons[event.Reason]
+		return found
+	case kubernetes.CronJobKind:
+		_, found := cronJobMissedReasons[event.Reason]
+		return found
+	case "Node":
+		_, found := nodeTransitions[event.Reason]
+		return found
+	case kubernetes.PodKind:
+		// The taint manager also emits an event when it cancels an eviction
+		return event.Reason == "Evicted" || (event.Reason == "TaintManagerEviction" && strings.HasPrefix(event.Message, "Marking for deletion"))
+	}
+	return false
+}
+
+func submitSignal(sender aggregator.Sender, event *v1.Event, occurrences int32) {
+	obj := event.InvolvedObject
+
+	switch obj.Kind {
+	case kubernetes.JobKind:
+		tags := []string{
+			fmt.Sprintf("%s:%s", kubernetes.JobTagName, obj.Name),
+			fmt.Sprintf("%s:%s", kubernetes.NamespaceTagName, obj.Namespace),
+			fmt.Sprintf("reason:%s", strings.ToLower(event.Reason)),
+		}
+		if cronJob := kubernetes.ParseCronJobForJob(obj.Name); cronJob != "" {
+			tags = append(tags, fmt.Sprintf("%s:%s", kubernetes.CronJobTagName, cronJob))
+		}
+		sender.Count(jobFailedMetric, float64(occurrences), "", tags)
+
+	case kubernetes.CronJobKind:
+		tags := []string{
+			fmt.Sprintf("%s:%s", kubernetes.CronJobTagName, obj.Name),
+			fmt.Sprintf("%s:%s", kubernetes.NamespaceTagName, obj.Namespace),
+			fmt.Sprintf("reason:%s", strings.ToLower(event.Reason)),
+		}
+		sender.Count(cronJobMissedMetric, float64(occurrences), "", tags)
+
+	case "Node":
+		transition := nodeTransitions[event.Reason]
+		status := metrics.ServiceCheckCritical
+		if transition.healthy {
+			status = metrics.ServiceCheckOK
+		}
+		tags := []string{
+			fmt.Sprintf("node:%s", obj.Name),
+			fmt.Sprintf("condition:%s", transition.condition),
+		}
+		sender.ServiceCheck(nodeConditionServiceCheck, status, "", tags, event.Message)
+		sender.Count(nodeConditionMetric, float64(occurrences), "", append(tags, fmt.Sprintf("status:%s", strings.ToLower(status.String()))))
+
+	case kubernetes.PodKind:
+		tags := []string{
+			fmt.Sprintf("%s:%s", kubernetes.NamespaceTagName, obj.Namespace),
+			fmt.Sprintf("eviction_reason:%s", evictionReason(event)),
+		}
+		if event.Source.Host != "" {
+			tags = append(tags, fmt.Sprintf("node:%s", event.Source.Host))
+		}
+		sender.Count(podEvictedMetric, float64(occurrences), "", tags)
+
+	default:
+		log.Debugf("No signal for the events of %s objects", obj.Kind)
+	}
+}
+
+// evictionReason returns why a pod was evicted, based on the event emitted by
+// the kubelet or the taint manager.
+func evictionReason(event *v1.Event) string {
+	if event.Reason == "TaintManagerEviction" {
+		return evictionReasonTaint
+	}
+
+	if match := lowOnResourceRegexp.FindStringSubmatch(event.Message); match != nil {
+		return "low_on_" + strings.ReplaceAll(match[1], "-", "_")
+	}
+
+	if strings.Contains(event.Message, "ephemeral local storage usage exceeds") || strings.Contains(event.Message, "EmptyDir volume") {
+		return evictionReasonStorageLimit
+	}
+
+	return evictionReasonOther
+}
+
+func eventLastTimestamp(event *v1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.FirstTimestamp.Time
+}
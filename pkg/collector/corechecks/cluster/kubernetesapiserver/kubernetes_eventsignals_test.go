@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+
+package kubernetesapiserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func createSignalEvent(uid string, count int32, namespace, objname, objkind, hostname, reason, message string, timestamp int64) *v1.Event {
+	event := createEvent(count, namespace, objname, objkind, "", "", hostname, reason, message, v1.EventTypeWarning, timestamp)
+	event.UID = types.UID(uid)
+	return event
+}
+
+func TestEventSignals(t *testing.T) {
+	now := time.Unix(709662600, 0)
+
+	jobFailed := createSignalEvent("1", 1, "default", "backup-27391680", "Job", "", "BackoffLimitExceeded", "Job has reached the specified backoff limit", 709662500)
+	cronJobMissed := createSignalEvent("2", 3, "default", "backup", "CronJob", "", "MissSchedule", "Missed scheduled time to start a job", 709662500)
+	nodeNotReady := createSignalEvent("3", 1, "", "node-1", "Node", "", "NodeNotReady", "Node node-1 status is now: NodeNotReady", 709662400)
+	nodeReady := createSignalEvent("4", 1, "", "node-1", "Node", "", "NodeReady", "Node node-1 status is now: NodeReady", 709662500)
+	evicted := createSignalEvent("5", 1, "default", "redis-0", "Pod", "node-2", "Evicted", "The node was low on resource: ephemeral-storage.", 709662500)
+	taintEvicted := createSignalEvent("6", 1, "default", "redis-1", "Pod", "", "TaintManagerEviction", "Marking for deletion Pod default/redis-1", 709662500)
+	taintCancelled := createSignalEvent("7", 1, "default", "redis-1", "Pod", "", "TaintManagerEviction", "Cancelling deletion of Pod default/redis-1", 709662500)
+	scheduled := createSignalEvent("8", 1, "default", "redis-2", "Pod", "", "Scheduled", "Successfully assigned default/redis-2 to node-2", 709662500)
+
+	signals := newEventSignals()
+	mocked := mocksender.NewMockSender("event-signals")
+	mocked.SetupAcceptAll()
+
+	// nodeReady comes first to check that transitions are submitted in order
+	signals.process(mocked, []*v1.Event{nodeReady, jobFailed, cronJobMissed, nodeNotReady, evicted, taintEvicted, taintCancelled, scheduled}, now)
+
+	mocked.AssertMetric(t, "Count", jobFailedMetric, 1, "", []string{"kube_job:backup-27391680", "kube_namespace:default", "reason:backofflimitexceeded", "kube_cronjob:backup"})
+	mocked.AssertMetric(t, "Count", cronJobMissedMetric, 3, "", []string{"kube_cronjob:backup", "kube_namespace:default", "reason:missschedule"})
+	mocked.AssertMetric(t, "Count", nodeConditionMetric, 1, "", []string{"node:node-1", "condition:Ready", "status:critical"})
+	mocked.AssertMetric(t, "Count", nodeConditionMetric, 1, "", []string{"node:node-1", "condition:Ready", "status:ok"})
+	mocked.AssertMetric(t, "Count", podEvictedMetric, 1, "", []string{"kube_namespace:default", "eviction_reason:low_on_ephemeral_storage", "node:node-2"})
+	mocked.AssertMetric(t, "Count", podEvictedMetric, 1, "", []string{"kube_namespace:default", "eviction_reason:taint"})
+	mocked.AssertNumberOfCalls(t, "Count", 6)
+
+	serviceChecks := []metrics.ServiceCheckStatus{}
+	for _, call := range mocked.Calls {
+		if call.Method == "ServiceCheck" {
+			serviceChecks = append(serviceChecks, call.Arguments.Get(1).(metrics.ServiceCheckStatus))
+		}
+	}
+	assert.Equal(t, []metrics.ServiceCheckStatus{metrics.ServiceCheckCritical, metrics.ServiceCheckOK}, serviceChecks)
+
+	// Only the new occurrences of the events are accounted for, including
+	// after the state went through the ConfigMap.
+	token, err := signals.dump()
+	require.NoError(t, err)
+
+	newLeaderSignals := newEventSignals()
+	require.NoError(t, newLeaderSignals.load(token))
+
+	cronJobMissedAgain := createSignalEvent("2", 5, "default", "backup", "CronJob", "", "MissSchedule", "Missed scheduled time to start a job", 709662600)
+
+	mocked = mocksender.NewMockSender("event-signals")
+	mocked.SetupAcceptAll()
+
+	newLeaderSignals.process(mocked, []*v1.Event{jobFailed, cronJobMissedAgain, nodeNotReady, nodeReady, evicted}, now)
+
+	mocked.AssertMetric(t, "Count", cronJobMissedMetric, 2, "", []string{"kube_cronjob:backup", "kube_namespace:default", "reason:missschedule"})
+	mocked.AssertNumberOfCalls(t, "Count", 1)
+	mocked.AssertNotCalled(t, "ServiceCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	// The events that cannot be updated anymore are forgotten
+	newLeaderSignals.process(mocked, nil, now.Add(eventSignalsRetention+time.Minute))
+	assert.Empty(t, newLeaderSignals.seen)
+}
+
+func TestEvictionReason(t *testing.T) {
+	for _, tc := range []struct {
+		reason   string
+		message  string
+		expected string
+	}{
+		{
+			reason:   "Evicted",
+			message:  "The node was low on resource: memory. Container redis was using 1Gi, which exceeds its request of 0.",
+			expected: "low_on_memory",
+		},
+		{
+			reason:   "Evicted",
+			message:  "Pod ephemeral local storage usage exceeds the total limit of containers 1Gi.",
+			expected: evictionReasonStorageLimit,
+		},
+		{
+			reason:   "Evicted",
+			message:  "Usage of EmptyDir volume \"cache\" exceeds the limit \"1Gi\".",
+			expected: evictionReasonStorageLimit,
+		},
+		{
+			reason:   "TaintManagerEviction",
+			message:  "Marking for deletion Pod default/redis-0",
+			expected: evictionReasonTaint,
+		},
+		{
+			reason:   "Evicted",
+			message:  "Something unexpected",
+			expected: evictionReasonOther,
+		},
+	} {
+		t.Run(tc.expected, func(t *testing.T) {
+			event := &v1.Event{Reason: tc.reason, Message: tc.message}
+			assert.Equal(t, tc.expected, evictionReason(event))
+		})
+	}
+}
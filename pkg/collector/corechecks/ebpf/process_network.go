@@ -0,0 +1,290 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// FIXME: we require the `cgo` build tag because of this dep relationship:
+// github.com/DataDog/datadog-agent/pkg/process/net depends on `github.com/DataDog/agent-payload/v5/process`,
+// which has a hard dependency on `github.com/DataDog/zstd_0`, which requires CGO.
+// Should be removed once `github.com/DataDog/agent-payload/v5/process` can be imported with CGO disabled.
+// +build cgo
+// +build linux
+
+package ebpf
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	dd_config "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/network"
+	process_net "github.com/DataDog/datadog-agent/pkg/process/net"
+	process_util "github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	processNetworkCheckName = "process_network"
+
+	processNetworkContainerCacheValidity = 10 * time.Second
+	unknownProcessName                   = "unknown"
+)
+
+// ProcessNetworkConfig is the config of the process network check
+type ProcessNetworkConfig struct {
+	CollectServiceMetrics bool   `yaml:"collect_service_metrics"`
+	TagCardinality        string `yaml:"tag_cardinality"`
+}
+
+var processNetworkSchema = &schema.Schema{
+	Instances: map[string]schema.Field{
+		"collect_service_metrics": {Type: schema.TypeBoolean},
+		"tag_cardinality":         {Type: schema.TypeString, Enum: []interface{}{"low", "orchestrator", "high"}},
+	},
+}
+
+// ProcessNetworkCheck reports the network throughput and connection rates of processes and services,
+// computed from the connections tracked by system-probe
+type ProcessNetworkCheck struct {
+	core.CheckBase
+	instance    *ProcessNetworkConfig
+	cardinality collectors.TagCardinality
+	lastRun     time.Time
+
+	// overridden in tests
+	containers  network.ContainerIDResolver
+	processName func(pid int32) string
+	entityTags  func(entityID string, cardinality collectors.TagCardinality) ([]string, error)
+}
+
+// processNetworkKey identifies the processes which connections are aggregated together
+type processNetworkKey struct {
+	name        string
+	containerID string
+}
+
+type processNetworkStats struct {
+	bytesSent      uint64
+	bytesRecv      uint64
+	opened         uint64
+	closed         uint64
+	retransmits    uint64
+	failedConnects uint64
+}
+
+func (s *processNetworkStats) add(o *processNetworkStats) {
+	s.bytesSent += o.bytesSent
+	s.bytesRecv += o.bytesRecv
+	s.opened += o.opened
+	s.closed += o.closed
+	s.retransmits += o.retransmits
+	s.failedConnects += o.failedConnects
+}
+
+func init() {
+	core.RegisterCheck(processNetworkCheckName, ProcessNetworkFactory)
+	schema.Register(processNetworkCheckName, processNetworkSchema)
+}
+
+// ProcessNetworkFactory is exported for integration testing
+func ProcessNetworkFactory() check.Check {
+	return &ProcessNetworkCheck{
+		CheckBase:   core.NewCheckBase(processNetworkCheckName),
+		instance:    &ProcessNetworkConfig{},
+		processName: readProcessName,
+		entityTags:  tagger.Tag,
+	}
+}
+
+// Parse parses the check configuration
+func (c *ProcessNetworkConfig) Parse(data []byte) error {
+	// default values
+	c.CollectServiceMetrics = true
+	c.TagCardinality = collectors.LowCardinalityString
+
+	return yaml.Unmarshal(data, c)
+}
+
+// Configure parses the check configuration and init the check
+func (c *ProcessNetworkCheck) Configure(config, initConfig integration.Data, source string) error {
+	// TODO: Remove that hard-code and put it somewhere else
+	process_net.SetSystemProbePath(dd_config.Datadog.GetString("system_probe_config.sysprobe_socket"))
+
+	err := c.CommonConfigure(config, source)
+	if err != nil {
+		return err
+	}
+
+	if err := c.instance.Parse(config); err != nil {
+		return err
+	}
+
+	c.cardinality, err = collectors.StringToTagCardinality(c.instance.TagCardinality)
+	if err != nil {
+		return err
+	}
+
+	c.containers, err = network.NewContainerResolver(process_util.HostProc(), processNetworkContainerCacheValidity)
+	if err != nil {
+		log.Infof("container tags will not be added to process network metrics: %s", err)
+	}
+	return nil
+}
+
+// Run executes the check
+func (c *ProcessNetworkCheck) Run() error {
+	sysProbeUtil, err := process_net.GetRemoteSystemProbeUtil()
+	if err != nil {
+		return err
+	}
+
+	// each check instance registers as its own system-probe client,
+	// so that it gets the connection deltas since its previous run
+	conns, err := sysProbeUtil.GetConnections(string(c.ID()))
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	lastRun := c.lastRun
+	c.lastRun = now
+	// the first run only registers the client, there's no interval to compute rates over yet
+	if lastRun.IsZero() {
+		return nil
+	}
+
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	c.submit(sender, conns.Conns, now.Sub(lastRun))
+	sender.Commit()
+	return nil
+}
+
+func (c *ProcessNetworkCheck) submit(sender aggregator.Sender, conns []*model.Connection, interval time.Duration) {
+	seconds := interval.Seconds()
+	if seconds <= 0 {
+		return
+	}
+
+	byProcess := c.aggregate(conns)
+	byService := make(map[string]*processNetworkStats)
+	for key, stats := range byProcess {
+		tags := []string{"process_name:" + key.name}
+		if key.containerID != "" {
+			containerTags, err := c.entityTags(containers.BuildTaggerEntityName(key.containerID), c.cardinality)
+			if err != nil {
+				log.Debugf("Error collecting tags for container %s: %s", key.containerID, err)
+			}
+			tags = append(tags, containerTags...)
+		}
+		submitProcessNetworkStats(sender, "network.process.", stats, seconds, tags)
+
+		if !c.instance.CollectServiceMetrics {
+			continue
+		}
+		if service := serviceFromTags(tags); service != "" {
+			if _, ok := byService[service]; !ok {
+				byService[service] = &processNetworkStats{}
+			}
+			byService[service].add(stats)
+		}
+	}
+
+	for service, stats := range byService {
+		submitProcessNetworkStats(sender, "network.service.", stats, seconds, []string{"service:" + service})
+	}
+}
+
+func (c *ProcessNetworkCheck) aggregate(conns []*model.Connection) map[processNetworkKey]*processNetworkStats {
+	names := make(map[int32]string)
+	byProcess := make(map[processNetworkKey]*processNetworkStats)
+	for _, conn := range conns {
+		name, ok := names[conn.Pid]
+		if !ok {
+			name = c.processName(conn.Pid)
+			names[conn.Pid] = name
+		}
+
+		key := processNetworkKey{name: name}
+		if c.containers != nil {
+			key.containerID = c.containers.ContainerID(uint32(conn.Pid))
+		}
+
+		stats, ok := byProcess[key]
+		if !ok {
+			stats = &processNetworkStats{}
+			byProcess[key] = stats
+		}
+
+		stats.bytesSent += conn.LastBytesSent
+		stats.bytesRecv += conn.LastBytesReceived
+		if conn.Type != model.ConnectionType_tcp {
+			continue
+		}
+		stats.opened += uint64(conn.LastTcpEstablished)
+		stats.closed += uint64(conn.LastTcpClosed)
+		stats.retransmits += uint64(conn.LastRetransmits)
+		if isFailedConnect(conn) {
+			stats.failedConnects++
+		}
+	}
+	return byProcess
+}
+
+// isFailedConnect returns whether the connection is an outgoing TCP connection that
+// was closed without completing its handshake. Connections established during a previous
+// interval are told apart by the data they exchanged or their RTT, which is only measured
+// once the handshake completes.
+func isFailedConnect(conn *model.Connection) bool {
+	return conn.Direction == model.ConnectionDirection_outgoing &&
+		conn.LastTcpClosed > 0 &&
+		conn.LastTcpEstablished == 0 &&
+		conn.LastBytesSent == 0 &&
+		conn.LastBytesReceived == 0 &&
+		conn.Rtt == 0
+}
+
+func submitProcessNetworkStats(sender aggregator.Sender, prefix string, stats *processNetworkStats, seconds float64, tags []string) {
+	sender.Gauge(prefix+"bytes_sent", float64(stats.bytesSent)/seconds, "", tags)
+	sender.Gauge(prefix+"bytes_rcvd", float64(stats.bytesRecv)/seconds, "", tags)
+	sender.Gauge(prefix+"connections.opened", float64(stats.opened)/seconds, "", tags)
+	sender.Gauge(prefix+"connections.closed", float64(stats.closed)/seconds, "", tags)
+	sender.Gauge(prefix+"tcp.retransmits", float64(stats.retransmits)/seconds, "", tags)
+	sender.Gauge(prefix+"tcp.failed_connects", float64(stats.failedConnects)/seconds, "", tags)
+}
+
+func serviceFromTags(tags []string) string {
+	for _, t := range tags {
+		if strings.HasPrefix(t, "service:") {
+			return strings.TrimPrefix(t, "service:")
+		}
+	}
+	return ""
+}
+
+func readProcessName(pid int32) string {
+	comm, err := ioutil.ReadFile(process_util.HostProc(strconv.Itoa(int(pid)), "comm"))
+	if err != nil {
+		return unknownProcessName
+	}
+	if name := strings.TrimSpace(string(comm)); name != "" {
+		return name
+	}
+	return unknownProcessName
+}
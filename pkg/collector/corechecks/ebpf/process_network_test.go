@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build cgo
+// +build linux
+
+package ebpf
+
+import (
+	"testing"
+	"time"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+)
+
+type staticContainers map[uint32]string
+
+func (s staticContainers) ContainerID(pid uint32) string {
+	return s[pid]
+}
+
+func newTestProcessNetworkCheck(collectServiceMetrics bool) *ProcessNetworkCheck {
+	c := ProcessNetworkFactory().(*ProcessNetworkCheck)
+	c.instance.CollectServiceMetrics = collectServiceMetrics
+	c.containers = staticContainers{2: "abc", 3: "abc"}
+	c.processName = func(pid int32) string {
+		switch pid {
+		case 1:
+			return "curl"
+		case 2, 3:
+			return "nginx"
+		}
+		return unknownProcessName
+	}
+	c.entityTags = func(entityID string, _ collectors.TagCardinality) ([]string, error) {
+		if entityID == "container_id://abc" {
+			return []string{"service:web", "image_name:nginx"}, nil
+		}
+		return nil, nil
+	}
+	return c
+}
+
+func TestProcessNetworkSubmit(t *testing.T) {
+	c := newTestProcessNetworkCheck(true)
+	sender := mocksender.NewMockSender(c.ID())
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	conns := []*model.Connection{
+		{Pid: 1, Type: model.ConnectionType_tcp, Direction: model.ConnectionDirection_outgoing, LastBytesSent: 100, LastBytesReceived: 200, LastTcpEstablished: 1, LastRetransmits: 2},
+		// failed connect
+		{Pid: 1, Type: model.ConnectionType_tcp, Direction: model.ConnectionDirection_outgoing, LastTcpClosed: 1},
+		{Pid: 2, Type: model.ConnectionType_tcp, Direction: model.ConnectionDirection_incoming, LastBytesSent: 40, LastTcpEstablished: 1, LastTcpClosed: 1},
+		{Pid: 3, Type: model.ConnectionType_udp, Direction: model.ConnectionDirection_outgoing, LastBytesSent: 60, LastBytesReceived: 20, LastTcpClosed: 1},
+	}
+	c.submit(sender, conns, 10*time.Second)
+
+	curlTags := []string{"process_name:curl"}
+	sender.AssertMetric(t, "Gauge", "network.process.bytes_sent", 10, "", curlTags)
+	sender.AssertMetric(t, "Gauge", "network.process.bytes_rcvd", 20, "", curlTags)
+	sender.AssertMetric(t, "Gauge", "network.process.connections.opened", 0.1, "", curlTags)
+	sender.AssertMetric(t, "Gauge", "network.process.connections.closed", 0.1, "", curlTags)
+	sender.AssertMetric(t, "Gauge", "network.process.tcp.retransmits", 0.2, "", curlTags)
+	sender.AssertMetric(t, "Gauge", "network.process.tcp.failed_connects", 0.1, "", curlTags)
+
+	// both nginx processes run in the same container and are aggregated together,
+	// UDP connections are only taken into account for throughput
+	nginxTags := []string{"process_name:nginx", "service:web", "image_name:nginx"}
+	sender.AssertMetric(t, "Gauge", "network.process.bytes_sent", 10, "", nginxTags)
+	sender.AssertMetric(t, "Gauge", "network.process.bytes_rcvd", 2, "", nginxTags)
+	sender.AssertMetric(t, "Gauge", "network.process.connections.opened", 0.1, "", nginxTags)
+	sender.AssertMetric(t, "Gauge", "network.process.connections.closed", 0.1, "", nginxTags)
+	sender.AssertMetric(t, "Gauge", "network.process.tcp.failed_connects", 0, "", nginxTags)
+
+	serviceTags := []string{"service:web"}
+	sender.AssertMetric(t, "Gauge", "network.service.bytes_sent", 10, "", serviceTags)
+	sender.AssertMetric(t, "Gauge", "network.service.connections.opened", 0.1, "", serviceTags)
+	sender.AssertNumberOfCalls(t, "Gauge", 18)
+}
+
+func TestIsFailedConnect(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		conn   *model.Connection
+		failed bool
+	}{
+		{
+			name:   "refused",
+			conn:   &model.Connection{Direction: model.ConnectionDirection_outgoing, LastTcpClosed: 1},
+			failed: true,
+		},
+		{
+			name:   "timed out",
+			conn:   &model.Connection{Direction: model.ConnectionDirection_outgoing, LastTcpClosed: 1, LastRetransmits: 5},
+			failed: true,
+		},
+		{
+			name: "incoming",
+			conn: &model.Connection{Direction: model.ConnectionDirection_incoming, LastTcpClosed: 1},
+		},
+		{
+			name: "established and closed",
+			conn: &model.Connection{Direction: model.ConnectionDirection_outgoing, LastTcpEstablished: 1, LastTcpClosed: 1},
+		},
+		{
+			name: "long-lived only sending data",
+			conn: &model.Connection{Direction: model.ConnectionDirection_outgoing, LastTcpClosed: 1, LastBytesSent: 1024, Rtt: 300},
+		},
+		{
+			name: "long-lived idle",
+			conn: &model.Connection{Direction: model.ConnectionDirection_outgoing, LastTcpClosed: 1, Rtt: 300},
+		},
+		{
+			name: "still open",
+			conn: &model.Connection{Direction: model.ConnectionDirection_outgoing},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.failed, isFailedConnect(tc.conn))
+		})
+	}
+}
+
+func TestProcessNetworkSubmitWithoutServiceMetrics(t *testing.T) {
+	c := newTestProcessNetworkCheck(false)
+	sender := mocksender.NewMockSender(c.ID())
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+
+	conns := []*model.Connection{
+		{Pid: 2, Type: model.ConnectionType_tcp, LastBytesSent: 40},
+	}
+	c.submit(sender, conns, 10*time.Second)
+
+	sender.AssertMetric(t, "Gauge", "network.process.bytes_sent", 4, "", []string{"process_name:nginx", "service:web", "image_name:nginx"})
+	sender.AssertNotCalled(t, "Gauge", "network.service.bytes_sent", mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertNumberOfCalls(t, "Gauge", 6)
+}
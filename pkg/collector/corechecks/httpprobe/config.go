@@ -0,0 +1,172 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package httpprobe
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
+)
+
+const (
+	defaultTimeout            = 10 * time.Second
+	defaultStatusCodePattern  = `(1|2|3)\d\d`
+	defaultMaxRedirects       = 10
+	defaultDaysWarning        = 14
+	defaultDaysCritical       = 7
+	defaultMaxContentReadSize = 1024 * 1024
+)
+
+type instanceConfig struct {
+	Name                       string            `yaml:"name"`
+	URL                        string            `yaml:"url"`
+	Method                     string            `yaml:"method"`
+	Data                       string            `yaml:"data"`
+	Headers                    map[string]string `yaml:"headers"`
+	Username                   string            `yaml:"username"`
+	Password                   string            `yaml:"password"`
+	Timeout                    float64           `yaml:"timeout"`
+	HTTPResponseStatusCode     string            `yaml:"http_response_status_code"`
+	ContentMatch               string            `yaml:"content_match"`
+	ReverseContentMatch        bool              `yaml:"reverse_content_match"`
+	FollowRedirects            *bool             `yaml:"follow_redirects"`
+	MaxRedirects               int               `yaml:"max_redirects"`
+	TLSVerify                  *bool             `yaml:"tls_verify"`
+	TLSCACert                  string            `yaml:"tls_ca_cert"`
+	TLSCert                    string            `yaml:"tls_cert"`
+	TLSPrivateKey              string            `yaml:"tls_private_key"`
+	TLSServerName              string            `yaml:"tls_server_name"`
+	CheckCertificateExpiration *bool             `yaml:"check_certificate_expiration"`
+	DaysWarning                int               `yaml:"days_warning"`
+	DaysCritical               int               `yaml:"days_critical"`
+}
+
+var httpProbeSchema = &schema.Schema{
+	Instances: map[string]schema.Field{
+		"name":                         {Type: schema.TypeString},
+		"url":                          {Type: schema.TypeString, Required: true},
+		"method":                       {Type: schema.TypeString},
+		"data":                         {Type: schema.TypeString},
+		"headers":                      {Type: schema.TypeObject},
+		"username":                     {Type: schema.TypeString},
+		"password":                     {Type: schema.TypeString},
+		"timeout":                      {Type: schema.TypeNumber},
+		"http_response_status_code":    {Type: schema.TypeString},
+		"content_match":                {Type: schema.TypeString},
+		"reverse_content_match":        {Type: schema.TypeBoolean},
+		"follow_redirects":             {Type: schema.TypeBoolean},
+		"max_redirects":                {Type: schema.TypeInteger},
+		"tls_verify":                   {Type: schema.TypeBoolean},
+		"tls_ca_cert":                  {Type: schema.TypeString},
+		"tls_cert":                     {Type: schema.TypeString},
+		"tls_private_key":              {Type: schema.TypeString},
+		"tls_server_name":              {Type: schema.TypeString},
+		"check_certificate_expiration": {Type: schema.TypeBoolean},
+		"days_warning":                 {Type: schema.TypeInteger},
+		"days_critical":                {Type: schema.TypeInteger},
+	},
+}
+
+// config is the parsed configuration of an instance
+type config struct {
+	instanceConfig
+
+	timeout                    time.Duration
+	statusCode                 *regexp.Regexp
+	contentMatch               *regexp.Regexp
+	followRedirects            bool
+	tlsVerify                  bool
+	checkCertificateExpiration bool
+	tags                       []string
+}
+
+func parseConfig(data []byte) (*config, error) {
+	c := &config{}
+	if err := yaml.Unmarshal(data, &c.instanceConfig); err != nil {
+		return nil, err
+	}
+
+	if c.URL == "" {
+		return nil, errors.New("url must be set")
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid url %s: the scheme must be http or https", c.URL)
+	}
+
+	if c.Method == "" {
+		c.Method = http.MethodGet
+	}
+	c.Method = strings.ToUpper(c.Method)
+
+	c.timeout = defaultTimeout
+	if c.Timeout > 0 {
+		c.timeout = time.Duration(c.Timeout * float64(time.Second))
+	}
+
+	if c.HTTPResponseStatusCode == "" {
+		c.HTTPResponseStatusCode = defaultStatusCodePattern
+	}
+	// the status code must match the whole pattern
+	if c.statusCode, err = regexp.Compile("^(" + c.HTTPResponseStatusCode + ")$"); err != nil {
+		return nil, fmt.Errorf("invalid http_response_status_code: %w", err)
+	}
+
+	if c.ContentMatch != "" {
+		if c.contentMatch, err = regexp.Compile(c.ContentMatch); err != nil {
+			return nil, fmt.Errorf("invalid content_match: %w", err)
+		}
+	} else if c.ReverseContentMatch {
+		return nil, errors.New("reverse_content_match requires content_match")
+	}
+
+	c.followRedirects = c.FollowRedirects == nil || *c.FollowRedirects
+	if c.MaxRedirects <= 0 {
+		c.MaxRedirects = defaultMaxRedirects
+	}
+	c.tlsVerify = c.TLSVerify == nil || *c.TLSVerify
+	c.checkCertificateExpiration = c.CheckCertificateExpiration == nil || *c.CheckCertificateExpiration
+	if c.DaysWarning <= 0 {
+		c.DaysWarning = defaultDaysWarning
+	}
+	if c.DaysCritical <= 0 {
+		c.DaysCritical = defaultDaysCritical
+	}
+	if c.DaysCritical > c.DaysWarning {
+		return nil, errors.New("days_critical must be lower than days_warning")
+	}
+
+	c.tags = []string{"url:" + c.URL}
+	if c.Name != "" {
+		c.tags = append(c.tags, "instance:"+c.Name)
+	}
+
+	return c, nil
+}
+
+// tlsConfig returns the TLS configuration of the requests
+func (c *config) tlsConfig() (*tls.Config, error) {
+	return httputils.NewTLSConfig(httputils.TLSOptions{
+		Verify:     c.tlsVerify,
+		ServerName: c.TLSServerName,
+		CACert:     c.TLSCACert,
+		Cert:       c.TLSCert,
+		PrivateKey: c.TLSPrivateKey,
+	})
+}
@@ -0,0 +1,266 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package httpprobe
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	httpProbeCheckName = "http_probe"
+
+	canConnectServiceCheck = "http_probe.can_connect"
+	sslCertServiceCheck    = "http_probe.ssl_cert"
+)
+
+// for testing purpose
+var timeNow = time.Now
+
+// Check probes an HTTP(S) endpoint
+type Check struct {
+	core.CheckBase
+	config *config
+	client *http.Client
+}
+
+// timings holds the duration of the phases of the last hop of a request,
+// a phase that didn't happen, like DNS for an IP, is left at zero
+type timings struct {
+	hopStart  time.Time
+	dnsStart  time.Time
+	dns       time.Duration
+	connStart time.Time
+	connect   time.Duration
+	tlsStart  time.Time
+	tls       time.Duration
+	ttfb      time.Duration
+}
+
+func (t *timings) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		// a new connection is requested for each hop of the redirects
+		GetConn:              func(string) { *t = timings{hopStart: timeNow()} },
+		DNSStart:             func(httptrace.DNSStartInfo) { t.dnsStart = timeNow() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.dns = timeNow().Sub(t.dnsStart) },
+		ConnectStart:         func(string, string) { t.connStart = timeNow() },
+		ConnectDone:          func(string, string, error) { t.connect = timeNow().Sub(t.connStart) },
+		TLSHandshakeStart:    func() { t.tlsStart = timeNow() },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.tls = timeNow().Sub(t.tlsStart) },
+		GotFirstResponseByte: func() { t.ttfb = timeNow().Sub(t.hopStart) },
+	}
+}
+
+// Configure parses the check configuration and builds the HTTP client
+func (c *Check) Configure(data integration.Data, initConfig integration.Data, source string) error {
+	// Must be called before CommonConfigure that uses checkID
+	c.BuildID(data, initConfig)
+
+	if err := c.CommonConfigure(data, source); err != nil {
+		return err
+	}
+
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := cfg.tlsConfig()
+	if err != nil {
+		return err
+	}
+
+	c.config = cfg
+	c.client = &http.Client{
+		Timeout: cfg.timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+			// every run times a new connection
+			DisableKeepAlives: true,
+		},
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !cfg.followRedirects {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > cfg.MaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", cfg.MaxRedirects)
+			}
+			return nil
+		},
+	}
+	return nil
+}
+
+// Run executes the check
+func (c *Check) Run() error {
+	return c.RunWithContext(context.Background())
+}
+
+// RunWithContext executes the check, the probe is aborted when ctx is cancelled
+func (c *Check) RunWithContext(ctx context.Context) error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	status, message := c.probe(ctx, sender)
+
+	canConnect := 0.0
+	if status == metrics.ServiceCheckOK {
+		canConnect = 1
+	}
+	sender.Gauge("http_probe.can_connect", canConnect, "", c.config.tags)
+	sender.ServiceCheck(canConnectServiceCheck, status, "", c.config.tags, message)
+
+	sender.Commit()
+	return nil
+}
+
+// probe sends the request, submits its metrics and returns the status of the probe
+func (c *Check) probe(ctx context.Context, sender aggregator.Sender) (metrics.ServiceCheckStatus, string) {
+	var body io.Reader
+	if c.config.Data != "" {
+		body = strings.NewReader(c.config.Data)
+	}
+	req, err := http.NewRequestWithContext(ctx, c.config.Method, c.config.URL, body)
+	if err != nil {
+		return metrics.ServiceCheckCritical, err.Error()
+	}
+	for k, v := range c.config.Headers {
+		if strings.EqualFold(k, "host") {
+			req.Host = v
+			continue
+		}
+		req.Header.Set(k, v)
+	}
+	if c.config.Username != "" {
+		req.SetBasicAuth(c.config.Username, c.config.Password)
+	}
+
+	t := &timings{}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), t.trace()))
+
+	redirects := 0
+	start := timeNow()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		c.submitCertificateError(sender, err)
+		return metrics.ServiceCheckCritical, fmt.Sprintf("unable to reach %s: %s", c.config.URL, err)
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, defaultMaxContentReadSize))
+	if err != nil {
+		return metrics.ServiceCheckCritical, fmt.Sprintf("unable to read the response of %s: %s", c.config.URL, err)
+	}
+	responseTime := timeNow().Sub(start)
+
+	for r := resp.Request; r != nil && r.Response != nil; r = r.Response.Request {
+		redirects++
+	}
+
+	tags := append(append([]string{}, c.config.tags...), "status_code:"+strconv.Itoa(resp.StatusCode))
+	sender.Gauge("http_probe.response_time", responseTime.Seconds(), "", tags)
+	sender.Gauge("http_probe.ttfb", t.ttfb.Seconds(), "", tags)
+	if t.dns > 0 {
+		sender.Gauge("http_probe.dns_time", t.dns.Seconds(), "", tags)
+	}
+	if t.connect > 0 {
+		sender.Gauge("http_probe.connect_time", t.connect.Seconds(), "", tags)
+	}
+	if t.tls > 0 {
+		sender.Gauge("http_probe.tls_time", t.tls.Seconds(), "", tags)
+	}
+	sender.Gauge("http_probe.redirects", float64(redirects), "", tags)
+	sender.Gauge("http_probe.content_length", float64(len(content)), "", tags)
+
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		c.submitCertificateExpiration(sender, resp.TLS.PeerCertificates[0])
+	}
+
+	if !c.config.statusCode.MatchString(strconv.Itoa(resp.StatusCode)) {
+		return metrics.ServiceCheckCritical, fmt.Sprintf("unexpected status code %d, expected %s", resp.StatusCode, c.config.HTTPResponseStatusCode)
+	}
+
+	if c.config.contentMatch != nil {
+		matched := c.config.contentMatch.Match(content)
+		if matched && c.config.ReverseContentMatch {
+			return metrics.ServiceCheckCritical, fmt.Sprintf("content matched %q", c.config.ContentMatch)
+		}
+		if !matched && !c.config.ReverseContentMatch {
+			return metrics.ServiceCheckCritical, fmt.Sprintf("content didn't match %q", c.config.ContentMatch)
+		}
+	}
+
+	return metrics.ServiceCheckOK, ""
+}
+
+// submitCertificateExpiration submits the number of days left before the expiration
+// of the certificate of the endpoint
+func (c *Check) submitCertificateExpiration(sender aggregator.Sender, cert *x509.Certificate) {
+	if !c.config.checkCertificateExpiration {
+		return
+	}
+
+	daysLeft := cert.NotAfter.Sub(timeNow()).Hours() / 24
+	sender.Gauge("http_probe.ssl.days_left", daysLeft, "", c.config.tags)
+
+	status := metrics.ServiceCheckOK
+	message := ""
+	switch {
+	case daysLeft < 0:
+		status = metrics.ServiceCheckCritical
+		message = fmt.Sprintf("the certificate expired on %s", cert.NotAfter.Format(time.RFC3339))
+	case daysLeft < float64(c.config.DaysCritical):
+		status = metrics.ServiceCheckCritical
+		message = fmt.Sprintf("the certificate expires in %.1f days, on %s", daysLeft, cert.NotAfter.Format(time.RFC3339))
+	case daysLeft < float64(c.config.DaysWarning):
+		status = metrics.ServiceCheckWarning
+		message = fmt.Sprintf("the certificate expires in %.1f days, on %s", daysLeft, cert.NotAfter.Format(time.RFC3339))
+	}
+	sender.ServiceCheck(sslCertServiceCheck, status, "", c.config.tags, message)
+}
+
+// submitCertificateError submits the certificate service check when a request
+// fails because the certificate of the endpoint expired
+func (c *Check) submitCertificateError(sender aggregator.Sender, err error) {
+	if !c.config.checkCertificateExpiration {
+		return
+	}
+
+	var certErr x509.CertificateInvalidError
+	if errors.As(err, &certErr) && certErr.Reason == x509.Expired {
+		sender.ServiceCheck(sslCertServiceCheck, metrics.ServiceCheckCritical, "", c.config.tags, certErr.Error())
+	}
+}
+
+func factory() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBase(httpProbeCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(httpProbeCheckName, factory)
+	schema.Register(httpProbeCheckName, httpProbeSchema)
+}
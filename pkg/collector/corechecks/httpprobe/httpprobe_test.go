@@ -0,0 +1,232 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package httpprobe
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func newServer(t *testing.T, tlsServer bool) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status": "healthy"}`)
+	})
+	mux.HandleFunc("/error", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/redirect2", http.StatusFound)
+	})
+	mux.HandleFunc("/redirect2", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/post", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+
+	var ts *httptest.Server
+	if tlsServer {
+		ts = httptest.NewTLSServer(mux)
+	} else {
+		ts = httptest.NewServer(mux)
+	}
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func runCheck(t *testing.T, instance string) (*Check, *mocksender.MockSender) {
+	c := factory().(*Check)
+	require.NoError(t, c.Configure([]byte(instance), []byte("{}"), "test"))
+
+	sender := mocksender.NewMockSender(c.ID())
+	sender.SetupAcceptAll()
+
+	require.NoError(t, c.Run())
+	return c, sender
+}
+
+func TestProbeOK(t *testing.T) {
+	ts := newServer(t, false)
+	u := ts.URL + "/ok"
+	_, sender := runCheck(t, fmt.Sprintf("url: %s\nname: api\ncontent_match: healthy", u))
+
+	tags := []string{"url:" + u, "instance:api"}
+	sender.AssertServiceCheck(t, canConnectServiceCheck, metrics.ServiceCheckOK, "", tags, "")
+	sender.AssertMetric(t, "Gauge", "http_probe.can_connect", 1, "", tags)
+	sender.AssertMetric(t, "Gauge", "http_probe.redirects", 0, "", append(tags, "status_code:200"))
+	sender.AssertMetric(t, "Gauge", "http_probe.content_length", 21, "", append(tags, "status_code:200"))
+	sender.AssertMetricTaggedWith(t, "Gauge", "http_probe.response_time", []string{"status_code:200"})
+	sender.AssertMetricTaggedWith(t, "Gauge", "http_probe.ttfb", []string{"status_code:200"})
+	sender.AssertMetricTaggedWith(t, "Gauge", "http_probe.connect_time", []string{"status_code:200"})
+	sender.AssertNotCalled(t, "Gauge", "http_probe.tls_time", mock.Anything, mock.Anything, mock.Anything)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestProbeFailures(t *testing.T) {
+	ts := newServer(t, false)
+
+	for _, tc := range []struct {
+		name     string
+		instance string
+		message  string
+	}{
+		{
+			name:     "status code",
+			instance: fmt.Sprintf("url: %s/error", ts.URL),
+			message:  `unexpected status code 500, expected (1|2|3)\d\d`,
+		},
+		{
+			name:     "content match",
+			instance: fmt.Sprintf("url: %s/ok\ncontent_match: degraded", ts.URL),
+			message:  `content didn't match "degraded"`,
+		},
+		{
+			name:     "reverse content match",
+			instance: fmt.Sprintf("url: %s/ok\ncontent_match: healthy\nreverse_content_match: true", ts.URL),
+			message:  `content matched "healthy"`,
+		},
+		{
+			name:     "method and headers",
+			instance: fmt.Sprintf("url: %s/post", ts.URL),
+			message:  `unexpected status code 400, expected (1|2|3)\d\d`,
+		},
+		{
+			name:     "redirects not followed",
+			instance: fmt.Sprintf("url: %s/redirect\nfollow_redirects: false\nhttp_response_status_code: 200", ts.URL),
+			message:  `unexpected status code 302, expected 200`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, sender := runCheck(t, tc.instance)
+			sender.AssertServiceCheck(t, canConnectServiceCheck, metrics.ServiceCheckCritical, "", c.config.tags, tc.message)
+			sender.AssertMetric(t, "Gauge", "http_probe.can_connect", 0, "", c.config.tags)
+		})
+	}
+
+	c, sender := runCheck(t, fmt.Sprintf("url: %s/post\nmethod: post\ndata: '{}'\nheaders:\n  X-Token: secret", ts.URL))
+	sender.AssertServiceCheck(t, canConnectServiceCheck, metrics.ServiceCheckOK, "", c.config.tags, "")
+}
+
+func TestProbeRedirects(t *testing.T) {
+	ts := newServer(t, false)
+
+	c, sender := runCheck(t, fmt.Sprintf("url: %s/redirect\nhttp_response_status_code: 200", ts.URL))
+	sender.AssertServiceCheck(t, canConnectServiceCheck, metrics.ServiceCheckOK, "", c.config.tags, "")
+	sender.AssertMetric(t, "Gauge", "http_probe.redirects", 2, "", append(c.config.tags, "status_code:200"))
+
+	c, sender = runCheck(t, fmt.Sprintf("url: %s/redirect\nmax_redirects: 1", ts.URL))
+	sender.AssertServiceCheck(t, canConnectServiceCheck, metrics.ServiceCheckCritical, "", c.config.tags, fmt.Sprintf(`unable to reach %s/redirect: Get "/ok": stopped after 1 redirects`, ts.URL))
+}
+
+func TestProbeConnectionError(t *testing.T) {
+	ts := newServer(t, false)
+	ts.Close()
+
+	c, sender := runCheck(t, fmt.Sprintf("url: %s/ok\ntimeout: 1", ts.URL))
+	sender.AssertCalled(t, "ServiceCheck", canConnectServiceCheck, metrics.ServiceCheckCritical, "", c.config.tags, mock.AnythingOfType("string"))
+	sender.AssertNotCalled(t, "Gauge", "http_probe.response_time", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestProbeCertificate(t *testing.T) {
+	ts := newServer(t, true)
+	notAfter := ts.Certificate().NotAfter
+	defer func() { timeNow = time.Now }()
+
+	for _, tc := range []struct {
+		name     string
+		now      time.Time
+		status   metrics.ServiceCheckStatus
+		daysLeft float64
+	}{
+		{name: "ok", now: notAfter.Add(-30 * 24 * time.Hour), status: metrics.ServiceCheckOK, daysLeft: 30},
+		{name: "warning", now: notAfter.Add(-10 * 24 * time.Hour), status: metrics.ServiceCheckWarning, daysLeft: 10},
+		{name: "critical", now: notAfter.Add(-2 * 24 * time.Hour), status: metrics.ServiceCheckCritical, daysLeft: 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			timeNow = func() time.Time { return tc.now }
+			c, sender := runCheck(t, fmt.Sprintf("url: %s/ok\ntls_verify: false", ts.URL))
+
+			sender.AssertServiceCheck(t, canConnectServiceCheck, metrics.ServiceCheckOK, "", c.config.tags, "")
+			sender.AssertCalled(t, "ServiceCheck", sslCertServiceCheck, tc.status, "", c.config.tags, mock.AnythingOfType("string"))
+			sender.AssertMetric(t, "Gauge", "http_probe.ssl.days_left", tc.daysLeft, "", c.config.tags)
+		})
+	}
+	timeNow = time.Now
+
+	// the certificate of the test server isn't trusted
+	c, sender := runCheck(t, fmt.Sprintf("url: %s/ok", ts.URL))
+	sender.AssertCalled(t, "ServiceCheck", canConnectServiceCheck, metrics.ServiceCheckCritical, "", c.config.tags, mock.AnythingOfType("string"))
+	sender.AssertNotCalled(t, "ServiceCheck", sslCertServiceCheck, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCertificateError(t *testing.T) {
+	c := factory().(*Check)
+	require.NoError(t, c.Configure([]byte("url: https://localhost"), []byte("{}"), "test"))
+	sender := mocksender.NewMockSender(c.ID())
+	sender.SetupAcceptAll()
+
+	certErr := x509.CertificateInvalidError{Cert: &x509.Certificate{}, Reason: x509.Expired}
+	c.submitCertificateError(sender, &url.Error{Op: "Get", URL: "https://localhost", Err: certErr})
+	sender.AssertServiceCheck(t, sslCertServiceCheck, metrics.ServiceCheckCritical, "", c.config.tags, certErr.Error())
+}
+
+func TestRunWithCancelledContext(t *testing.T) {
+	ts := newServer(t, false)
+	c := factory().(*Check)
+	require.NoError(t, c.Configure([]byte(fmt.Sprintf("url: %s/ok", ts.URL)), []byte("{}"), "test"))
+	sender := mocksender.NewMockSender(c.ID())
+	sender.SetupAcceptAll()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, c.RunWithContext(ctx))
+	sender.AssertCalled(t, "ServiceCheck", canConnectServiceCheck, metrics.ServiceCheckCritical, "", c.config.tags, mock.AnythingOfType("string"))
+}
+
+func TestParseConfig(t *testing.T) {
+	for _, tc := range []struct {
+		instance string
+		err      string
+	}{
+		{instance: "name: foo", err: "url must be set"},
+		{instance: "url: ftp://localhost", err: "the scheme must be http or https"},
+		{instance: "url: http://localhost\nhttp_response_status_code: '('", err: "invalid http_response_status_code"},
+		{instance: "url: http://localhost\ncontent_match: '('", err: "invalid content_match"},
+		{instance: "url: http://localhost\nreverse_content_match: true", err: "reverse_content_match requires content_match"},
+		{instance: "url: http://localhost\ndays_warning: 5\ndays_critical: 10", err: "days_critical must be lower than days_warning"},
+	} {
+		_, err := parseConfig([]byte(tc.instance))
+		require.Error(t, err, tc.instance)
+		assert.Contains(t, err.Error(), tc.err)
+	}
+
+	c, err := parseConfig([]byte("url: http://localhost\nmethod: post\ntimeout: 0.5\nhttp_response_status_code: 2\\d\\d"))
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPost, c.Method)
+	assert.Equal(t, 500*time.Millisecond, c.timeout)
+	assert.True(t, c.statusCode.MatchString("204"))
+	assert.False(t, c.statusCode.MatchString("2040"))
+	assert.False(t, c.statusCode.MatchString("301"))
+	assert.True(t, c.followRedirects)
+	assert.True(t, c.tlsVerify)
+	assert.Equal(t, []string{"url:http://localhost"}, c.tags)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/shirou/gopsutil/net"
@@ -85,6 +86,14 @@ type networkInstanceConfig struct {
 
 type networkInitConfig struct{}
 
+var networkSchema = &schema.Schema{
+	Instances: map[string]schema.Field{
+		"collect_connection_state": {Type: schema.TypeBoolean},
+		"excluded_interfaces":      {Type: schema.TypeArray},
+		"excluded_interface_re":    {Type: schema.TypeString},
+	},
+}
+
 type networkConfig struct {
 	instance networkInstanceConfig
 	initConf networkInitConfig
@@ -311,4 +320,5 @@ func networkFactory() check.Check {
 
 func init() {
 	core.RegisterCheck(networkCheckName, networkFactory)
+	schema.Register(networkCheckName, networkSchema)
 }
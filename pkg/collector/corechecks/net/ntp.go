@@ -19,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
 	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
@@ -60,6 +61,18 @@ type ntpInstanceConfig struct {
 
 type ntpInitConfig struct{}
 
+var ntpSchema = &schema.Schema{
+	Instances: map[string]schema.Field{
+		"offset_threshold":          {Type: schema.TypeInteger},
+		"host":                      {Type: schema.TypeString},
+		"hosts":                     {Type: schema.TypeArray},
+		"port":                      {Type: schema.TypeInteger},
+		"timeout":                   {Type: schema.TypeInteger},
+		"version":                   {Type: schema.TypeInteger, Enum: []interface{}{1, 2, 3, 4}},
+		"use_local_defined_servers": {Type: schema.TypeBoolean},
+	},
+}
+
 type ntpConfig struct {
 	instance ntpInstanceConfig
 	initConf ntpInitConfig
@@ -242,4 +255,5 @@ func ntpFactory() check.Check {
 
 func init() {
 	core.RegisterCheck(ntpCheckName, ntpFactory)
+	schema.Register(ntpCheckName, ntpSchema)
 }
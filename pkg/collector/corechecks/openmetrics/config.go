@@ -0,0 +1,256 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
+)
+
+const (
+	defaultTimeout            = 10 * time.Second
+	defaultMaxReturnedMetrics = 2000
+	defaultBearerTokenPath    = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// metric types accepted in type_overrides
+const (
+	typeGauge   = "gauge"
+	typeCounter = "counter"
+	typeUntyped = "untyped"
+)
+
+// instanceConfig holds the instance options, they follow the ones of the
+// openmetrics Python check
+type instanceConfig struct {
+	URL                               string            `yaml:"prometheus_url"`
+	Namespace                         string            `yaml:"namespace"`
+	Metrics                           []interface{}     `yaml:"metrics"`
+	Prefix                            string            `yaml:"prometheus_metrics_prefix"`
+	HealthServiceCheck                *bool             `yaml:"health_service_check"`
+	LabelsMapper                      map[string]string `yaml:"labels_mapper"`
+	TypeOverrides                     map[string]string `yaml:"type_overrides"`
+	SendHistogramsBuckets             *bool             `yaml:"send_histograms_buckets"`
+	SendDistributionBuckets           bool              `yaml:"send_distribution_buckets"`
+	SendMonotonicCounter              *bool             `yaml:"send_monotonic_counter"`
+	SendDistributionCountsAsMonotonic bool              `yaml:"send_distribution_counts_as_monotonic"`
+	SendDistributionSumsAsMonotonic   bool              `yaml:"send_distribution_sums_as_monotonic"`
+	ExcludeLabels                     []string          `yaml:"exclude_labels"`
+	IgnoreMetrics                     []string          `yaml:"ignore_metrics"`
+	BearerTokenAuth                   bool              `yaml:"bearer_token_auth"`
+	BearerTokenPath                   string            `yaml:"bearer_token_path"`
+	Username                          string            `yaml:"username"`
+	Password                          string            `yaml:"password"`
+	TLSVerify                         *bool             `yaml:"tls_verify"`
+	TLSCert                           string            `yaml:"tls_cert"`
+	TLSPrivateKey                     string            `yaml:"tls_private_key"`
+	TLSCACert                         string            `yaml:"tls_ca_cert"`
+	Headers                           map[string]string `yaml:"headers"`
+	ExtraHeaders                      map[string]string `yaml:"extra_headers"`
+	Timeout                           int               `yaml:"timeout"`
+	MaxReturnedMetrics                int               `yaml:"max_returned_metrics"`
+}
+
+var openmetricsSchema = &schema.Schema{
+	Instances: map[string]schema.Field{
+		"prometheus_url":                        {Type: schema.TypeString, Required: true},
+		"namespace":                             {Type: schema.TypeString},
+		"metrics":                               {Type: schema.TypeArray},
+		"prometheus_metrics_prefix":             {Type: schema.TypeString},
+		"health_service_check":                  {Type: schema.TypeBoolean},
+		"labels_mapper":                         {Type: schema.TypeObject},
+		"type_overrides":                        {Type: schema.TypeObject},
+		"send_histograms_buckets":               {Type: schema.TypeBoolean},
+		"send_distribution_buckets":             {Type: schema.TypeBoolean},
+		"send_monotonic_counter":                {Type: schema.TypeBoolean},
+		"send_distribution_counts_as_monotonic": {Type: schema.TypeBoolean},
+		"send_distribution_sums_as_monotonic":   {Type: schema.TypeBoolean},
+		"exclude_labels":                        {Type: schema.TypeArray},
+		"ignore_metrics":                        {Type: schema.TypeArray},
+		"bearer_token_auth":                     {Type: schema.TypeBoolean},
+		"bearer_token_path":                     {Type: schema.TypeString},
+		"username":                              {Type: schema.TypeString},
+		"password":                              {Type: schema.TypeString},
+		"tls_verify":                            {Type: schema.TypeBoolean},
+		"tls_cert":                              {Type: schema.TypeString},
+		"tls_private_key":                       {Type: schema.TypeString},
+		"tls_ca_cert":                           {Type: schema.TypeString},
+		"headers":                               {Type: schema.TypeObject},
+		"extra_headers":                         {Type: schema.TypeObject},
+		"timeout":                               {Type: schema.TypeInteger},
+		"max_returned_metrics":                  {Type: schema.TypeInteger},
+	},
+	// the instances generated by the prometheus config providers hold options of
+	// the Python check that aren't supported
+	AllowUnknownKeys: true,
+}
+
+// config is the parsed configuration of an instance
+type config struct {
+	instanceConfig
+
+	healthServiceCheck      bool
+	sendHistogramsBuckets   bool
+	sendMonotonicCounter    bool
+	tlsVerify               bool
+	timeout                 time.Duration
+	maxReturnedMetrics      int
+	headers                 map[string]string
+	metricNames             map[string]string // raw metric name to submitted name
+	metricWildcards         []*regexp.Regexp
+	ignoredMetrics          map[string]struct{}
+	ignoredMetricsWildcards []*regexp.Regexp
+	excludedLabels          map[string]struct{}
+}
+
+func parseConfig(data []byte) (*config, error) {
+	c := &config{}
+	if err := yaml.Unmarshal(data, &c.instanceConfig); err != nil {
+		return nil, err
+	}
+
+	if c.URL == "" {
+		return nil, errors.New("prometheus_url must be set")
+	}
+	if len(c.Metrics) == 0 {
+		return nil, errors.New("metrics must be set, use [\"*\"] to collect all the metrics")
+	}
+
+	c.healthServiceCheck = boolOrDefault(c.HealthServiceCheck, true)
+	c.sendHistogramsBuckets = boolOrDefault(c.SendHistogramsBuckets, true)
+	c.sendMonotonicCounter = boolOrDefault(c.SendMonotonicCounter, true)
+	c.tlsVerify = boolOrDefault(c.TLSVerify, true)
+
+	c.timeout = defaultTimeout
+	if c.Timeout > 0 {
+		c.timeout = time.Duration(c.Timeout) * time.Second
+	}
+	c.maxReturnedMetrics = defaultMaxReturnedMetrics
+	if c.MaxReturnedMetrics > 0 {
+		c.maxReturnedMetrics = c.MaxReturnedMetrics
+	}
+	if c.BearerTokenAuth && c.BearerTokenPath == "" {
+		c.BearerTokenPath = defaultBearerTokenPath
+	}
+
+	c.headers = make(map[string]string, len(c.Headers)+len(c.ExtraHeaders))
+	for k, v := range c.Headers {
+		c.headers[k] = v
+	}
+	for k, v := range c.ExtraHeaders {
+		c.headers[k] = v
+	}
+
+	c.metricNames = make(map[string]string)
+	for _, m := range c.Metrics {
+		switch metric := m.(type) {
+		case string:
+			if err := c.addMetric(metric, metric); err != nil {
+				return nil, err
+			}
+		case map[interface{}]interface{}:
+			for raw, name := range metric {
+				if err := c.addMetric(fmt.Sprint(raw), fmt.Sprint(name)); err != nil {
+					return nil, err
+				}
+			}
+		default:
+			return nil, fmt.Errorf("invalid metrics entry %v, expected a metric name or a mapping of metric names", m)
+		}
+	}
+
+	c.ignoredMetrics = make(map[string]struct{})
+	for _, metric := range c.IgnoreMetrics {
+		if strings.Contains(metric, "*") {
+			c.ignoredMetricsWildcards = append(c.ignoredMetricsWildcards, wildcardRegexp(metric))
+		} else {
+			c.ignoredMetrics[metric] = struct{}{}
+		}
+	}
+
+	c.excludedLabels = make(map[string]struct{}, len(c.ExcludeLabels))
+	for _, label := range c.ExcludeLabels {
+		c.excludedLabels[label] = struct{}{}
+	}
+
+	for metric, metricType := range c.TypeOverrides {
+		switch metricType {
+		case typeGauge, typeCounter, typeUntyped:
+		default:
+			return nil, fmt.Errorf("invalid type override %q for metric %s, only gauge, counter and untyped are supported", metricType, metric)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *config) addMetric(raw, name string) error {
+	if strings.Contains(raw, "*") {
+		if raw != name {
+			return fmt.Errorf("metric %s can't be renamed, wildcards can't be renamed", raw)
+		}
+		c.metricWildcards = append(c.metricWildcards, wildcardRegexp(raw))
+		return nil
+	}
+	c.metricNames[raw] = name
+	return nil
+}
+
+// metricName returns the name a raw metric is submitted as, without the
+// namespace, and false if the metric isn't collected
+func (c *config) metricName(raw string) (string, bool) {
+	raw = strings.TrimPrefix(raw, c.Prefix)
+
+	if _, found := c.ignoredMetrics[raw]; found {
+		return "", false
+	}
+	for _, re := range c.ignoredMetricsWildcards {
+		if re.MatchString(raw) {
+			return "", false
+		}
+	}
+
+	if name, found := c.metricNames[raw]; found {
+		return name, true
+	}
+	for _, re := range c.metricWildcards {
+		if re.MatchString(raw) {
+			return raw, true
+		}
+	}
+	return "", false
+}
+
+// fullName prefixes a metric name with the namespace
+func (c *config) fullName(name string) string {
+	if c.Namespace == "" {
+		return name
+	}
+	return c.Namespace + "." + name
+}
+
+// wildcardRegexp compiles a metric name pattern where * matches any characters
+func wildcardRegexp(pattern string) *regexp.Regexp {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(parts, ".*") + "$")
+}
+
+func boolOrDefault(b *bool, defaultValue bool) bool {
+	if b == nil {
+		return defaultValue
+	}
+	return *b
+}
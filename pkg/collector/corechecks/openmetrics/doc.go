@@ -0,0 +1,13 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+/*
+Package openmetrics provides a core check scraping OpenMetrics and Prometheus
+endpoints, in the text and protobuf exposition formats.
+
+It accepts the instances of the openmetrics Python check generated by the
+prometheus config providers, so it can replace it on hosts running many of them.
+*/
+package openmetrics
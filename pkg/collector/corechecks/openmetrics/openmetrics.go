@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	// CheckName is the name of the check
+	CheckName = "openmetrics_core"
+
+	healthServiceCheck = "prometheus.health"
+)
+
+// Check scrapes an OpenMetrics or Prometheus endpoint
+type Check struct {
+	core.CheckBase
+	config *config
+	client *http.Client
+}
+
+// Configure parses the check configuration and builds the HTTP client
+func (c *Check) Configure(data integration.Data, initConfig integration.Data, source string) error {
+	// Must be called before CommonConfigure that uses checkID
+	c.BuildID(data, initConfig)
+
+	if err := c.CommonConfigure(data, source); err != nil {
+		return err
+	}
+
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return err
+	}
+	client, err := newHTTPClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	c.config = cfg
+	c.client = client
+	return nil
+}
+
+// Run executes the check
+func (c *Check) Run() error {
+	return c.RunWithContext(context.Background())
+}
+
+// RunWithContext executes the check, the scrape is aborted when ctx is cancelled
+func (c *Check) RunWithContext(ctx context.Context) error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	families, err := scrape(ctx, c.client, c.config)
+	if err != nil {
+		err = fmt.Errorf("unable to scrape %s: %w", c.config.URL, err)
+		c.submitHealth(sender, metrics.ServiceCheckCritical, err.Error())
+		sender.Commit()
+		return err
+	}
+	c.submitHealth(sender, metrics.ServiceCheckOK, "")
+
+	submitted := newSubmitter(c.config, sender).submit(families)
+	if submitted > c.config.maxReturnedMetrics {
+		c.Warnf("Check %s exceeded the limit of %d metrics, sending %d of %d. Filter the metrics or raise max_returned_metrics.", c.ID(), c.config.maxReturnedMetrics, c.config.maxReturnedMetrics, submitted) //nolint:errcheck
+	}
+
+	sender.Commit()
+	return nil
+}
+
+func (c *Check) submitHealth(sender aggregator.Sender, status metrics.ServiceCheckStatus, message string) {
+	if !c.config.healthServiceCheck {
+		return
+	}
+	sender.ServiceCheck(c.config.fullName(healthServiceCheck), status, "", []string{"endpoint:" + c.config.URL}, message)
+}
+
+func factory() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBase(CheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(CheckName, factory)
+	schema.Register(CheckName, openmetricsSchema)
+}
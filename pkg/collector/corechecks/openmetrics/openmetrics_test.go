@@ -0,0 +1,261 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const promText = `# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027
+http_requests_total{method="post",code="400"} 3
+# TYPE temperature gauge
+temperature{room="kitchen"} 21.5
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.1"} 10
+request_duration_seconds_bucket{le="0.5"} 15
+request_duration_seconds_bucket{le="+Inf"} 17
+request_duration_seconds_sum 4.2
+request_duration_seconds_count 17
+# TYPE rpc_duration_seconds summary
+rpc_duration_seconds{quantile="0.5"} 0.05
+rpc_duration_seconds{quantile="0.99"} NaN
+rpc_duration_seconds_sum 12
+rpc_duration_seconds_count 100
+untyped_metric 7
+`
+
+const openmetricsText = `# TYPE http_requests counter
+# HELP http_requests The total number of HTTP requests.
+http_requests_total{method="get"} 12 # {trace_id="abc"} 1.0
+http_requests_created{method="get"} 1.6e9
+# TYPE build info
+build_info{version="1.2.3"} 1
+# TYPE memory_bytes gauge
+# UNIT memory_bytes bytes
+memory_bytes 1024
+# EOF
+`
+
+func serve(t *testing.T, contentType string, body []byte) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Accept"), "application/vnd.google.protobuf")
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body) //nolint:errcheck
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func runCheck(t *testing.T, instance string) (*Check, *mocksender.MockSender, error) {
+	c := factory().(*Check)
+	require.NoError(t, c.Configure([]byte(instance), []byte("{}"), "test"))
+
+	sender := mocksender.NewMockSender(c.ID())
+	sender.SetupAcceptAll()
+
+	return c, sender, c.Run()
+}
+
+func TestRunText(t *testing.T) {
+	ts := serve(t, "text/plain; version=0.0.4", []byte(promText))
+	instance := fmt.Sprintf(`
+prometheus_url: %s
+namespace: app
+metrics:
+  - http_requests_total: requests
+  - temperature
+  - request_duration_seconds
+  - rpc_duration_seconds
+  - untyped_*
+labels_mapper:
+  method: http_method
+exclude_labels:
+  - code
+`, ts.URL)
+
+	_, sender, err := runCheck(t, instance)
+	require.NoError(t, err)
+
+	sender.AssertServiceCheck(t, "app.prometheus.health", metrics.ServiceCheckOK, "", []string{"endpoint:" + ts.URL}, "")
+	sender.AssertMetric(t, "MonotonicCount", "app.requests", 1027, "", []string{"http_method:post"})
+	sender.AssertMetric(t, "MonotonicCount", "app.requests", 3, "", []string{"http_method:post"})
+	sender.AssertMetric(t, "Gauge", "app.temperature", 21.5, "", []string{"room:kitchen"})
+	sender.AssertMetric(t, "Gauge", "app.request_duration_seconds.count", 17, "", []string{})
+	sender.AssertMetric(t, "Gauge", "app.request_duration_seconds.sum", 4.2, "", []string{})
+	sender.AssertMetric(t, "Gauge", "app.request_duration_seconds.count", 10, "", []string{"upper_bound:0.1"})
+	sender.AssertMetric(t, "Gauge", "app.request_duration_seconds.count", 17, "", []string{"upper_bound:inf"})
+	sender.AssertMetric(t, "Gauge", "app.rpc_duration_seconds.quantile", 0.05, "", []string{"quantile:0.5"})
+	sender.AssertNotCalled(t, "Gauge", "app.rpc_duration_seconds.quantile", 0.0, "", []string{"quantile:0.99"})
+	sender.AssertMetric(t, "Gauge", "app.rpc_duration_seconds.count", 100, "", []string{})
+	sender.AssertMetric(t, "Gauge", "app.untyped_metric", 7, "", []string{})
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestRunDistributionBuckets(t *testing.T) {
+	ts := serve(t, "text/plain; version=0.0.4", []byte(promText))
+	instance := fmt.Sprintf(`
+prometheus_url: %s
+namespace: app
+metrics: [request_duration_seconds]
+send_distribution_buckets: true
+send_distribution_counts_as_monotonic: true
+`, ts.URL)
+
+	_, sender, err := runCheck(t, instance)
+	require.NoError(t, err)
+
+	sender.AssertMetric(t, "MonotonicCount", "app.request_duration_seconds.count", 17, "", []string{})
+	sender.AssertMetric(t, "Gauge", "app.request_duration_seconds.sum", 4.2, "", []string{})
+	sender.AssertHistogramBucket(t, "HistogramBucket", "app.request_duration_seconds", 10, 0, 0.1, true, "", []string{"lower_bound:0", "upper_bound:0.1"}, false)
+	sender.AssertHistogramBucket(t, "HistogramBucket", "app.request_duration_seconds", 5, 0.1, 0.5, true, "", []string{"lower_bound:0.1", "upper_bound:0.5"}, false)
+	sender.AssertHistogramBucket(t, "HistogramBucket", "app.request_duration_seconds", 2, 0.5, 0.5, true, "", []string{"lower_bound:0.5", "upper_bound:inf"}, false)
+}
+
+func TestRunTypeOverridesAndPrefix(t *testing.T) {
+	ts := serve(t, "text/plain; version=0.0.4", []byte(promText))
+	instance := fmt.Sprintf(`
+prometheus_url: %s
+prometheus_metrics_prefix: untyped_
+metrics: ["*"]
+ignore_metrics: ["http_*", "request_*", "rpc_*"]
+type_overrides:
+  untyped_metric: counter
+  temperature: untyped
+send_monotonic_counter: false
+health_service_check: false
+`, ts.URL)
+
+	_, sender, err := runCheck(t, instance)
+	require.NoError(t, err)
+
+	// counters are sent as gauges without send_monotonic_counter
+	sender.AssertMetric(t, "Gauge", "metric", 7, "", []string{})
+	sender.AssertMetric(t, "Gauge", "temperature", 21.5, "", []string{"room:kitchen"})
+	sender.AssertNotCalled(t, "MonotonicCount", "http_requests_total", 1027.0, "", []string{"code:200", "method:post"})
+	sender.AssertNotCalled(t, "ServiceCheck", "prometheus.health", metrics.ServiceCheckOK, "", []string{"endpoint:" + ts.URL}, "")
+}
+
+func TestRunOpenMetrics(t *testing.T) {
+	ts := serve(t, "application/openmetrics-text; version=1.0.0; charset=utf-8", []byte(openmetricsText))
+	instance := fmt.Sprintf(`
+prometheus_url: %s
+namespace: app
+metrics: ["*"]
+`, ts.URL)
+
+	_, sender, err := runCheck(t, instance)
+	require.NoError(t, err)
+
+	sender.AssertMetric(t, "MonotonicCount", "app.http_requests_total", 12, "", []string{"method:get"})
+	sender.AssertMetric(t, "Gauge", "app.build_info", 1, "", []string{"version:1.2.3"})
+	sender.AssertMetric(t, "Gauge", "app.memory_bytes", 1024, "", []string{})
+	sender.AssertNotCalled(t, "Gauge", "app.http_requests_created", 1.6e9, "", []string{"method:get"})
+}
+
+func TestRunProtobuf(t *testing.T) {
+	var buf strings.Builder
+	encoder := expfmt.NewEncoder(&buf, expfmt.FmtProtoDelim)
+	require.NoError(t, encoder.Encode(&dto.MetricFamily{
+		Name: proto.String("queue_size"),
+		Type: dto.MetricType_GAUGE.Enum(),
+		Metric: []*dto.Metric{{
+			Label: []*dto.LabelPair{{Name: proto.String("queue"), Value: proto.String("default")}},
+			Gauge: &dto.Gauge{Value: proto.Float64(42)},
+		}},
+	}))
+	ts := serve(t, string(expfmt.FmtProtoDelim), []byte(buf.String()))
+	instance := fmt.Sprintf(`
+prometheus_url: %s
+namespace: app
+metrics: [queue_size]
+`, ts.URL)
+
+	_, sender, err := runCheck(t, instance)
+	require.NoError(t, err)
+
+	sender.AssertMetric(t, "Gauge", "app.queue_size", 42, "", []string{"queue:default"})
+}
+
+func TestRunMaxReturnedMetrics(t *testing.T) {
+	ts := serve(t, "text/plain; version=0.0.4", []byte(promText))
+	instance := fmt.Sprintf(`
+prometheus_url: %s
+metrics: ["*"]
+max_returned_metrics: 2
+`, ts.URL)
+
+	c, sender, err := runCheck(t, instance)
+	require.NoError(t, err)
+
+	sender.AssertNumberOfCalls(t, "MonotonicCount", 2)
+	sender.AssertNumberOfCalls(t, "Gauge", 0)
+	assert.Len(t, c.GetWarnings(), 1)
+}
+
+func TestRunError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+	instance := fmt.Sprintf(`
+prometheus_url: %s
+namespace: app
+metrics: ["*"]
+`, ts.URL)
+
+	_, sender, err := runCheck(t, instance)
+	require.Error(t, err)
+
+	sender.AssertServiceCheck(t, "app.prometheus.health", metrics.ServiceCheckCritical, "", []string{"endpoint:" + ts.URL}, err.Error())
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
+
+func TestParseConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		instance string
+		err      string
+	}{
+		{name: "missing url", instance: `metrics: ["*"]`, err: "prometheus_url must be set"},
+		{name: "missing metrics", instance: `prometheus_url: http://localhost`, err: "metrics must be set"},
+		{name: "renamed wildcard", instance: "prometheus_url: http://localhost\nmetrics: [{\"foo_*\": bar}]", err: "wildcards can't be renamed"},
+		{name: "invalid override", instance: "prometheus_url: http://localhost\nmetrics: [foo]\ntype_overrides: {foo: histogram}", err: "invalid type override"},
+		{name: "invalid metrics entry", instance: "prometheus_url: http://localhost\nmetrics: [[foo]]", err: "invalid metrics entry"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parseConfig([]byte(tc.instance))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+		})
+	}
+
+	c, err := parseConfig([]byte("prometheus_url: http://localhost\nmetrics: [foo, {bar: baz}, \"qux_*\"]\nignore_metrics: [qux_ignored]\nbearer_token_auth: true"))
+	require.NoError(t, err)
+	assert.Equal(t, defaultBearerTokenPath, c.BearerTokenPath)
+	assert.Equal(t, defaultTimeout, c.timeout)
+	assert.True(t, c.tlsVerify)
+
+	for raw, expected := range map[string]string{"foo": "foo", "bar": "baz", "qux_1": "qux_1", "qux_ignored": "", "other": ""} {
+		name, collected := c.metricName(raw)
+		assert.Equal(t, expected != "", collected, raw)
+		assert.Equal(t, expected, name, raw)
+	}
+}
@@ -0,0 +1,205 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
+)
+
+// acceptHeader prefers the protobuf format, which is the cheapest to parse
+const acceptHeader = `application/vnd.google.protobuf;proto=io.prometheus.client.MetricFamily;encoding=delimited;q=0.7,application/openmetrics-text;version=1.0.0;q=0.6,text/plain;version=0.0.4;q=0.5,*/*;q=0.1`
+
+const openmetricsMediaType = "application/openmetrics-text"
+
+// newHTTPClient returns the client scraping the endpoint of an instance
+func newHTTPClient(c *config) (*http.Client, error) {
+	tlsConfig, err := httputils.NewTLSConfig(httputils.TLSOptions{
+		Verify:     c.tlsVerify,
+		CACert:     c.TLSCACert,
+		Cert:       c.TLSCert,
+		PrivateKey: c.TLSPrivateKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout: c.timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+// scrape fetches and parses the metric families exposed by the endpoint
+func scrape(ctx context.Context, client *http.Client, c *config) ([]*dto.MetricFamily, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", acceptHeader)
+	for k, v := range c.headers {
+		req.Header.Set(k, v)
+	}
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+	if c.BearerTokenPath != "" {
+		// the token is read on each run as it can be rotated
+		token, err := ioutil.ReadFile(c.BearerTokenPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	return parse(resp.Body, resp.Header.Get("Content-Type"))
+}
+
+// parse parses metric families in the format of the given content type
+func parse(r io.Reader, contentType string) ([]*dto.MetricFamily, error) {
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	format := expfmt.ResponseFormat(header)
+
+	if format == expfmt.FmtProtoDelim {
+		var families []*dto.MetricFamily
+		decoder := expfmt.NewDecoder(r, format)
+		for {
+			family := &dto.MetricFamily{}
+			if err := decoder.Decode(family); err != nil {
+				if err == io.EOF {
+					return families, nil
+				}
+				return nil, err
+			}
+			families = append(families, family)
+		}
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil && mediaType == openmetricsMediaType {
+		var err error
+		if r, err = normalizeOpenMetrics(r); err != nil {
+			return nil, err
+		}
+	}
+
+	var parser expfmt.TextParser
+	familiesByName, err := parser.TextToMetricFamilies(r)
+	if err != nil {
+		return nil, err
+	}
+	families := make([]*dto.MetricFamily, 0, len(familiesByName))
+	for _, family := range familiesByName {
+		families = append(families, family)
+	}
+	return families, nil
+}
+
+// normalizeOpenMetrics rewrites the OpenMetrics text format to the Prometheus
+// one: counter families are named after their _total samples, _created samples,
+// units and exemplars are dropped, and the types unknown to the Prometheus
+// format are left untyped.
+func normalizeOpenMetrics(r io.Reader) (io.Reader, error) {
+	var out bytes.Buffer
+	counters := make(map[string]struct{})
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "#") {
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				// # EOF and other comments
+				continue
+			}
+			switch fields[1] {
+			case "TYPE":
+				if len(fields) < 4 {
+					continue
+				}
+				switch fields[3] {
+				case "counter":
+					counters[fields[2]] = struct{}{}
+					fmt.Fprintf(&out, "# TYPE %s_total counter\n", fields[2])
+				case "gauge", "histogram", "summary", "untyped":
+					out.WriteString(line + "\n")
+				}
+				// info, stateset, gaugehistogram and unknown families are left untyped
+			case "HELP":
+				if _, found := counters[fields[2]]; found {
+					out.WriteString(strings.Replace(line, fields[2], fields[2]+"_total", 1) + "\n")
+				} else {
+					out.WriteString(line + "\n")
+				}
+			}
+			continue
+		}
+
+		name := line
+		if idx := strings.IndexAny(line, "{ "); idx != -1 {
+			name = line[:idx]
+		}
+		if strings.HasSuffix(name, "_created") {
+			if _, found := counters[strings.TrimSuffix(name, "_created")]; found {
+				continue
+			}
+		}
+
+		// exemplars follow the value after a #, outside of the label values
+		if idx := exemplarIndex(line); idx != -1 {
+			line = strings.TrimRight(line[:idx], " ")
+		}
+		out.WriteString(line + "\n")
+	}
+
+	return &out, scanner.Err()
+}
+
+// exemplarIndex returns the index of the exemplar of a sample line, -1 if there is none
+func exemplarIndex(line string) int {
+	inQuotes := false
+	escaped := false
+	for i, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == '#' && !inQuotes:
+			return i
+		}
+	}
+	return -1
+}
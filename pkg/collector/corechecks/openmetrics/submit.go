@@ -0,0 +1,198 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package openmetrics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+)
+
+// submitter submits the samples of metric families, up to the limit of samples
+// of the instance
+type submitter struct {
+	config *config
+	sender aggregator.Sender
+	count  int
+}
+
+func newSubmitter(c *config, sender aggregator.Sender) *submitter {
+	return &submitter{config: c, sender: sender}
+}
+
+// submit submits the samples of the collected families, in name order so that
+// the limit of samples always drops the same ones, and returns the number of
+// samples, including the ones over the limit
+func (s *submitter) submit(families []*dto.MetricFamily) int {
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].GetName() < families[j].GetName()
+	})
+
+	for _, family := range families {
+		name, collected := s.config.metricName(family.GetName())
+		if !collected {
+			continue
+		}
+		name = s.config.fullName(name)
+
+		metricType := family.GetType()
+		if override, found := s.config.TypeOverrides[family.GetName()]; found {
+			switch override {
+			case typeGauge:
+				metricType = dto.MetricType_GAUGE
+			case typeCounter:
+				metricType = dto.MetricType_COUNTER
+			case typeUntyped:
+				metricType = dto.MetricType_UNTYPED
+			}
+		}
+
+		for _, metric := range family.GetMetric() {
+			tags := s.tags(metric.GetLabel())
+			switch {
+			case metric.Histogram != nil && metricType == dto.MetricType_HISTOGRAM:
+				s.submitHistogram(name, metric.Histogram, tags)
+			case metric.Summary != nil && metricType == dto.MetricType_SUMMARY:
+				s.submitSummary(name, metric.Summary, tags)
+			case metricType == dto.MetricType_COUNTER && s.config.sendMonotonicCounter:
+				s.monotonicCount(name, scalarValue(metric), tags)
+			default:
+				// gauges, untyped metrics, counters sent as gauges and
+				// samples of overridden types
+				s.gauge(name, scalarValue(metric), tags)
+			}
+		}
+	}
+
+	return s.count
+}
+
+func (s *submitter) submitHistogram(name string, histogram *dto.Histogram, tags []string) {
+	s.countOrMonotonic(name+".count", float64(histogram.GetSampleCount()), tags, s.config.SendDistributionCountsAsMonotonic)
+	s.countOrMonotonic(name+".sum", histogram.GetSampleSum(), tags, s.config.SendDistributionSumsAsMonotonic)
+
+	if !s.config.sendHistogramsBuckets {
+		return
+	}
+
+	if s.config.SendDistributionBuckets {
+		s.submitDistributionBuckets(name, histogram.GetBucket(), tags)
+		return
+	}
+
+	for _, bucket := range histogram.GetBucket() {
+		bucketTags := append(copyTags(tags), "upper_bound:"+formatBound(bucket.GetUpperBound()))
+		s.countOrMonotonic(name+".count", float64(bucket.GetCumulativeCount()), bucketTags, s.config.SendDistributionCountsAsMonotonic)
+	}
+}
+
+// submitDistributionBuckets submits the buckets of a histogram as distribution
+// buckets, which are not cumulative
+func (s *submitter) submitDistributionBuckets(name string, buckets []*dto.Bucket, tags []string) {
+	lowerBound := 0.0
+	var previousCount uint64
+	for _, bucket := range buckets {
+		upperBound := bucket.GetUpperBound()
+		if math.IsInf(upperBound, 1) {
+			// the values of the +Inf bucket are counted at its lower bound
+			upperBound = lowerBound
+		}
+		count := bucket.GetCumulativeCount() - previousCount
+		if !s.reserve() {
+			return
+		}
+		bucketTags := append(copyTags(tags), "lower_bound:"+formatBound(lowerBound), "upper_bound:"+formatBound(bucket.GetUpperBound()))
+		s.sender.HistogramBucket(name, int64(count), lowerBound, upperBound, true, "", bucketTags, false)
+
+		lowerBound = bucket.GetUpperBound()
+		previousCount = bucket.GetCumulativeCount()
+	}
+}
+
+func (s *submitter) submitSummary(name string, summary *dto.Summary, tags []string) {
+	s.countOrMonotonic(name+".count", float64(summary.GetSampleCount()), tags, s.config.SendDistributionCountsAsMonotonic)
+	s.countOrMonotonic(name+".sum", summary.GetSampleSum(), tags, s.config.SendDistributionSumsAsMonotonic)
+
+	for _, quantile := range summary.GetQuantile() {
+		if math.IsNaN(quantile.GetValue()) {
+			continue
+		}
+		quantileTags := append(copyTags(tags), "quantile:"+formatBound(quantile.GetQuantile()))
+		s.gauge(name+".quantile", quantile.GetValue(), quantileTags)
+	}
+}
+
+func (s *submitter) countOrMonotonic(name string, value float64, tags []string, monotonic bool) {
+	if monotonic {
+		s.monotonicCount(name, value, tags)
+	} else {
+		s.gauge(name, value, tags)
+	}
+}
+
+func (s *submitter) gauge(name string, value float64, tags []string) {
+	if math.IsNaN(value) || !s.reserve() {
+		return
+	}
+	s.sender.Gauge(name, value, "", tags)
+}
+
+func (s *submitter) monotonicCount(name string, value float64, tags []string) {
+	if math.IsNaN(value) || !s.reserve() {
+		return
+	}
+	s.sender.MonotonicCount(name, value, "", tags)
+}
+
+// reserve counts a sample and returns whether it's within the limit
+func (s *submitter) reserve() bool {
+	s.count++
+	return s.count <= s.config.maxReturnedMetrics
+}
+
+// tags returns the tags of the labels of a sample, renamed by labels_mapper
+func (s *submitter) tags(labels []*dto.LabelPair) []string {
+	tags := make([]string, 0, len(labels))
+	for _, label := range labels {
+		name := label.GetName()
+		if _, excluded := s.config.excludedLabels[name]; excluded {
+			continue
+		}
+		if mapped, found := s.config.LabelsMapper[name]; found {
+			name = mapped
+		}
+		tags = append(tags, name+":"+label.GetValue())
+	}
+	return tags
+}
+
+func scalarValue(metric *dto.Metric) float64 {
+	switch {
+	case metric.Gauge != nil:
+		return metric.Gauge.GetValue()
+	case metric.Counter != nil:
+		return metric.Counter.GetValue()
+	case metric.Untyped != nil:
+		return metric.Untyped.GetValue()
+	default:
+		return math.NaN()
+	}
+}
+
+func formatBound(f float64) string {
+	if math.IsInf(f, 1) {
+		return "inf"
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func copyTags(tags []string) []string {
+	return append(make([]string, 0, len(tags)+2), tags...)
+}
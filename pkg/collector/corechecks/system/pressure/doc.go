@@ -0,0 +1,11 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+/*
+Package pressure provides a Linux core check reporting resource saturation:
+the system-wide Pressure Stall Information of the kernel, and the pressure,
+CPU throttling, memory events and IO of the cgroups of the containers.
+*/
+package pressure
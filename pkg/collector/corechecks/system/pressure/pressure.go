@@ -0,0 +1,236 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+// +build linux
+
+package pressure
+
+import (
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/cgroups"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const pressureCheckName = "pressure"
+
+// cgroupLister lists the cgroups of the containers, it's implemented by cgroups.Reader
+type cgroupLister interface {
+	RefreshCgroups(cacheValidity time.Duration) error
+	ListCgroups() []cgroups.Cgroup
+}
+
+// for testing purpose
+var (
+	getPressureStats = cgroups.GetPressureStats
+	newCgroupLister  = func(procPath string) (cgroupLister, error) {
+		var hostPrefix string
+		if strings.HasPrefix(procPath, "/host") {
+			hostPrefix = "/host"
+		}
+		return cgroups.NewReader(
+			cgroups.WithCgroupV1BaseController("freezer"),
+			cgroups.WithProcPath(procPath),
+			cgroups.WithHostPrefix(hostPrefix),
+			cgroups.WithReaderFilter(cgroups.ContainerFilter),
+		)
+	}
+)
+
+type instanceConfig struct {
+	CollectSystemPressure *bool `yaml:"collect_system_pressure"`
+	CollectContainers     *bool `yaml:"collect_containers"`
+}
+
+var pressureSchema = &schema.Schema{
+	Instances: map[string]schema.Field{
+		"collect_system_pressure": {Type: schema.TypeBoolean},
+		"collect_containers":      {Type: schema.TypeBoolean},
+	},
+}
+
+// throttlingSample holds the CPU throttling counters of a cgroup read on the previous run
+type throttlingSample struct {
+	elapsed   uint64
+	throttled uint64
+}
+
+// Check reports the pressure stall information of the system and of the cgroups of the containers
+type Check struct {
+	core.CheckBase
+	procPath              string
+	collectSystemPressure bool
+	cgroups               cgroupLister
+
+	// throttling are the CPU throttling counters of the previous run, by container ID
+	throttling map[string]throttlingSample
+}
+
+// Configure parses the check configuration and initializes the cgroup reader
+func (c *Check) Configure(data integration.Data, initConfig integration.Data, source string) error {
+	if err := c.CheckBase.Configure(data, initConfig, source); err != nil {
+		return err
+	}
+
+	var conf instanceConfig
+	if err := yaml.Unmarshal(data, &conf); err != nil {
+		return err
+	}
+
+	c.procPath = config.Datadog.GetString("container_proc_root")
+	c.collectSystemPressure = conf.CollectSystemPressure == nil || *conf.CollectSystemPressure
+	c.throttling = make(map[string]throttlingSample)
+
+	if conf.CollectContainers == nil || *conf.CollectContainers {
+		lister, err := newCgroupLister(c.procPath)
+		if err != nil {
+			c.Warnf("Unable to read the cgroups, the metrics of the containers are disabled: %s", err) //nolint:errcheck
+		} else {
+			c.cgroups = lister
+		}
+	}
+
+	return nil
+}
+
+// Run reports the pressure stall information of the system and the cgroup metrics of the containers
+func (c *Check) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	if c.collectSystemPressure {
+		stats, err := getPressureStats(c.procPath)
+		if err != nil {
+			log.Debugf("Unable to read the pressure stall information, PSI may be disabled in the kernel: %s", err)
+		} else {
+			submitPSI(sender, "system.pressure.cpu.some", stats.CPUSome, nil)
+			submitPSI(sender, "system.pressure.cpu.full", stats.CPUFull, nil)
+			submitPSI(sender, "system.pressure.memory.some", stats.MemorySome, nil)
+			submitPSI(sender, "system.pressure.memory.full", stats.MemoryFull, nil)
+			submitPSI(sender, "system.pressure.io.some", stats.IOSome, nil)
+			submitPSI(sender, "system.pressure.io.full", stats.IOFull, nil)
+		}
+	}
+
+	if c.cgroups != nil {
+		if err := c.cgroups.RefreshCgroups(0); err != nil {
+			c.Warnf("Unable to list the cgroups of the containers: %s", err) //nolint:errcheck
+		} else {
+			c.submitContainers(sender)
+		}
+	}
+
+	sender.Commit()
+	return nil
+}
+
+func (c *Check) submitContainers(sender aggregator.Sender) {
+	throttling := make(map[string]throttlingSample)
+
+	for _, cg := range c.cgroups.ListCgroups() {
+		containerID := cg.Identifier()
+		// containers are told apart by their high cardinality tags, so that the
+		// counters of the containers of a same workload don't share a context
+		tags, err := tagger.Tag(containers.BuildTaggerEntityName(containerID), collectors.HighCardinality)
+		if err != nil {
+			log.Debugf("Unable to get the tags of container %s: %s", containerID, err)
+			continue
+		}
+		if len(tags) == 0 {
+			log.Debugf("No tags found for container %s, skipping it", containerID)
+			continue
+		}
+
+		cpu := cgroups.CPUStats{}
+		if err := cg.GetCPUStats(&cpu); err != nil {
+			log.Debugf("Unable to read the CPU stats of container %s: %s", containerID, err)
+		} else {
+			submitPSI(sender, "cgroup.cpu.pressure.some", cpu.PSISome, tags)
+			submitCounter(sender, "cgroup.cpu.elapsed_periods", cpu.ElapsedPeriods, tags)
+			submitCounter(sender, "cgroup.cpu.throttled.periods", cpu.ThrottledPeriods, tags)
+			submitCounter(sender, "cgroup.cpu.throttled.time", cpu.ThrottledTime, tags)
+
+			if cpu.ElapsedPeriods != nil && cpu.ThrottledPeriods != nil {
+				sample := throttlingSample{elapsed: *cpu.ElapsedPeriods, throttled: *cpu.ThrottledPeriods}
+				if prev, found := c.throttling[containerID]; found && sample.elapsed > prev.elapsed && sample.throttled >= prev.throttled {
+					ratio := float64(sample.throttled-prev.throttled) / float64(sample.elapsed-prev.elapsed)
+					sender.Gauge("cgroup.cpu.throttled.ratio", ratio, "", tags)
+				}
+				throttling[containerID] = sample
+			}
+		}
+
+		memory := cgroups.MemoryStats{}
+		if err := cg.GetMemoryStats(&memory); err != nil {
+			log.Debugf("Unable to read the memory stats of container %s: %s", containerID, err)
+		} else {
+			submitPSI(sender, "cgroup.memory.pressure.some", memory.PSISome, tags)
+			submitPSI(sender, "cgroup.memory.pressure.full", memory.PSIFull, tags)
+			submitCounter(sender, "cgroup.memory.events.high", memory.HighEvents, tags)
+			submitCounter(sender, "cgroup.memory.events.max", memory.MaxEvents, tags)
+			submitCounter(sender, "cgroup.memory.events.oom", memory.OOMEvents, tags)
+			submitCounter(sender, "cgroup.memory.events.oom_kill", memory.OOMKiilEvents, tags)
+		}
+
+		io := cgroups.IOStats{}
+		if err := cg.GetIOStats(&io); err != nil {
+			log.Debugf("Unable to read the IO stats of container %s: %s", containerID, err)
+		} else {
+			submitPSI(sender, "cgroup.io.pressure.some", io.PSISome, tags)
+			submitPSI(sender, "cgroup.io.pressure.full", io.PSIFull, tags)
+			submitCounter(sender, "cgroup.io.read_bytes", io.ReadBytes, tags)
+			submitCounter(sender, "cgroup.io.write_bytes", io.WriteBytes, tags)
+			submitCounter(sender, "cgroup.io.read_operations", io.ReadOperations, tags)
+			submitCounter(sender, "cgroup.io.write_operations", io.WriteOperations, tags)
+		}
+	}
+
+	c.throttling = throttling
+}
+
+// submitPSI submits the averages and the total stall time of a pressure stall information line,
+// the stall time is reported in microseconds
+func submitPSI(sender aggregator.Sender, prefix string, psi cgroups.PSIStats, tags []string) {
+	if psi.Avg10 != nil {
+		sender.Gauge(prefix+".avg10", *psi.Avg10, "", tags)
+	}
+	if psi.Avg60 != nil {
+		sender.Gauge(prefix+".avg60", *psi.Avg60, "", tags)
+	}
+	if psi.Avg300 != nil {
+		sender.Gauge(prefix+".avg300", *psi.Avg300, "", tags)
+	}
+	submitCounter(sender, prefix+".total", psi.Total, tags)
+}
+
+func submitCounter(sender aggregator.Sender, name string, value *uint64, tags []string) {
+	if value != nil {
+		sender.MonotonicCount(name, float64(*value), "", tags)
+	}
+}
+
+func pressureFactory() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBase(pressureCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(pressureCheckName, pressureFactory)
+	schema.Register(pressureCheckName, pressureSchema)
+}
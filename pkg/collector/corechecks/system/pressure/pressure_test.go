@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+// +build linux
+
+package pressure
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/tagger/local"
+	"github.com/DataDog/datadog-agent/pkg/util/cgroups"
+)
+
+func uint64Ptr(v uint64) *uint64 {
+	return &v
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+type fakeCgroup struct {
+	id     string
+	cpu    cgroups.CPUStats
+	memory cgroups.MemoryStats
+	io     *cgroups.IOStats
+}
+
+func (f *fakeCgroup) Identifier() string                          { return f.id }
+func (f *fakeCgroup) GetParent() (cgroups.Cgroup, error)          { return nil, errors.New("no parent") }
+func (f *fakeCgroup) GetStats(*cgroups.Stats) error               { return errors.New("not implemented") }
+func (f *fakeCgroup) GetPIDStats(*cgroups.PIDStats) error         { return errors.New("not implemented") }
+func (f *fakeCgroup) GetCPUStats(stats *cgroups.CPUStats) error   { *stats = f.cpu; return nil }
+func (f *fakeCgroup) GetMemoryStats(s *cgroups.MemoryStats) error { *s = f.memory; return nil }
+func (f *fakeCgroup) GetIOStats(stats *cgroups.IOStats) error {
+	if f.io == nil {
+		return &cgroups.ControllerNotFoundError{Controller: "io"}
+	}
+	*stats = *f.io
+	return nil
+}
+
+type fakeLister struct {
+	cgroups []cgroups.Cgroup
+}
+
+func (f *fakeLister) RefreshCgroups(time.Duration) error { return nil }
+func (f *fakeLister) ListCgroups() []cgroups.Cgroup      { return f.cgroups }
+
+func TestPressureCheck(t *testing.T) {
+	oldGetPressureStats, oldNewCgroupLister := getPressureStats, newCgroupLister
+	oldTagger := tagger.GetDefaultTagger()
+	defer func() {
+		getPressureStats, newCgroupLister = oldGetPressureStats, oldNewCgroupLister
+		tagger.SetDefaultTagger(oldTagger)
+	}()
+
+	getPressureStats = func(string) (*cgroups.PressureStats, error) {
+		return &cgroups.PressureStats{
+			CPUSome: cgroups.PSIStats{Avg10: float64Ptr(1.5), Avg60: float64Ptr(1), Avg300: float64Ptr(0.5), Total: uint64Ptr(1000)},
+			IOFull:  cgroups.PSIStats{Avg10: float64Ptr(20), Total: uint64Ptr(5000)},
+		}, nil
+	}
+
+	cg := &fakeCgroup{
+		id: "abc",
+		cpu: cgroups.CPUStats{
+			ElapsedPeriods:   uint64Ptr(100),
+			ThrottledPeriods: uint64Ptr(10),
+			ThrottledTime:    uint64Ptr(1e9),
+			PSISome:          cgroups.PSIStats{Avg10: float64Ptr(30)},
+		},
+		memory: cgroups.MemoryStats{
+			HighEvents:    uint64Ptr(4),
+			OOMKiilEvents: uint64Ptr(1),
+			PSIFull:       cgroups.PSIStats{Avg60: float64Ptr(2)},
+		},
+	}
+	unknown := &fakeCgroup{id: "unknown"}
+	untagged := &fakeCgroup{id: "untagged", cpu: cgroups.CPUStats{ElapsedPeriods: uint64Ptr(100)}}
+	newCgroupLister = func(string) (cgroupLister, error) {
+		return &fakeLister{cgroups: []cgroups.Cgroup{cg, unknown, untagged}}, nil
+	}
+
+	fakeTagger := local.NewFakeTagger()
+	fakeTagger.SetTags("container_id://abc", "fake", []string{"image_name:nginx"}, nil, []string{"container_id:abc"}, nil)
+	fakeTagger.SetError("container_id://unknown", collectors.HighCardinality, errors.New("unknown container"))
+	tagger.SetDefaultTagger(fakeTagger)
+
+	check := pressureFactory().(*Check)
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Configure(nil, nil, "test"))
+	require.NoError(t, check.Run())
+
+	// containers are tagged with their high cardinality tags
+	tags := []string{"image_name:nginx", "container_id:abc"}
+	sender.AssertMetric(t, "Gauge", "system.pressure.cpu.some.avg10", 1.5, "", nil)
+	sender.AssertMetric(t, "Gauge", "system.pressure.cpu.some.avg300", 0.5, "", nil)
+	sender.AssertMetric(t, "MonotonicCount", "system.pressure.cpu.some.total", 1000, "", nil)
+	sender.AssertMetric(t, "Gauge", "system.pressure.io.full.avg10", 20, "", nil)
+	sender.AssertNotCalled(t, "Gauge", "system.pressure.memory.some.avg10", 0.0, "", []string(nil))
+	sender.AssertMetric(t, "Gauge", "cgroup.cpu.pressure.some.avg10", 30, "", tags)
+	sender.AssertMetric(t, "MonotonicCount", "cgroup.cpu.throttled.periods", 10, "", tags)
+	sender.AssertMetric(t, "MonotonicCount", "cgroup.cpu.throttled.time", 1e9, "", tags)
+	sender.AssertMetric(t, "MonotonicCount", "cgroup.memory.events.high", 4, "", tags)
+	sender.AssertMetric(t, "MonotonicCount", "cgroup.memory.events.oom_kill", 1, "", tags)
+	sender.AssertMetric(t, "Gauge", "cgroup.memory.pressure.full.avg60", 2, "", tags)
+	sender.AssertNotCalled(t, "Gauge", "cgroup.cpu.throttled.ratio", 0.0, "", tags)
+	sender.AssertNumberOfCalls(t, "MonotonicCount", 7)
+
+	// 20 periods elapsed since the previous run, 5 of which were throttled
+	cg.cpu.ElapsedPeriods = uint64Ptr(120)
+	cg.cpu.ThrottledPeriods = uint64Ptr(15)
+	cg.io = &cgroups.IOStats{ReadBytes: uint64Ptr(2048), PSISome: cgroups.PSIStats{Avg10: float64Ptr(3)}}
+
+	sender = mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	sender.AssertMetric(t, "Gauge", "cgroup.cpu.throttled.ratio", 0.25, "", tags)
+	sender.AssertMetric(t, "MonotonicCount", "cgroup.io.read_bytes", 2048, "", tags)
+	sender.AssertMetric(t, "Gauge", "cgroup.io.pressure.some.avg10", 3, "", tags)
+}
+
+func TestPressureCheckDisabled(t *testing.T) {
+	oldGetPressureStats, oldNewCgroupLister := getPressureStats, newCgroupLister
+	defer func() { getPressureStats, newCgroupLister = oldGetPressureStats, oldNewCgroupLister }()
+
+	getPressureStats = func(string) (*cgroups.PressureStats, error) {
+		t.Fatal("the system pressure must not be collected")
+		return nil, nil
+	}
+	newCgroupLister = func(string) (cgroupLister, error) {
+		t.Fatal("the cgroups must not be collected")
+		return nil, nil
+	}
+
+	check := pressureFactory().(*Check)
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Configure([]byte("collect_system_pressure: false\ncollect_containers: false"), nil, "test"))
+	require.NoError(t, check.Run())
+	sender.AssertNumberOfCalls(t, "Gauge", 0)
+	sender.AssertNumberOfCalls(t, "Commit", 1)
+}
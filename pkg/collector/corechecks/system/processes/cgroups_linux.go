@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+// +build linux
+
+package processes
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/containers/providers"
+)
+
+// for testing purpose
+var (
+	readCgroups       = readProcCgroups
+	containerIDForPID = providersContainerIDForPID
+)
+
+// readProcCgroups returns the cgroup paths of a process, read from /proc/<pid>/cgroup
+func readProcCgroups(pid int32) ([]string, error) {
+	f, err := os.Open(util.HostProc(strconv.Itoa(int(pid)), "cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cgroups []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) == 3 {
+			cgroups = append(cgroups, parts[2])
+		}
+	}
+	return cgroups, scanner.Err()
+}
+
+func providersContainerIDForPID(pid int32) (string, error) {
+	return providers.ContainerImpl().ContainerIDForPID(int(pid))
+}
@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+// +build !linux
+
+package processes
+
+import "errors"
+
+// for testing purpose
+var (
+	readCgroups       = readProcCgroups
+	containerIDForPID = noContainerIDForPID
+)
+
+func readProcCgroups(pid int32) ([]string, error) {
+	return nil, errors.New("cgroups are only supported on Linux")
+}
+
+// noContainerIDForPID doesn't tag the processes by container outside of Linux
+func noContainerIDForPID(pid int32) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processes
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+)
+
+type instanceConfig struct {
+	Name           string   `yaml:"name"`
+	ProcessNames   []string `yaml:"process_names"`
+	CmdlinePattern string   `yaml:"cmdline_pattern"`
+	User           string   `yaml:"user"`
+	CgroupPattern  string   `yaml:"cgroup_pattern"`
+	TagByContainer *bool    `yaml:"tag_by_container"`
+}
+
+var processesSchema = &schema.Schema{
+	Instances: map[string]schema.Field{
+		"name":             {Type: schema.TypeString, Required: true},
+		"process_names":    {Type: schema.TypeArray},
+		"cmdline_pattern":  {Type: schema.TypeString},
+		"user":             {Type: schema.TypeString},
+		"cgroup_pattern":   {Type: schema.TypeString},
+		"tag_by_container": {Type: schema.TypeBoolean},
+	},
+}
+
+// config is the parsed configuration of an instance
+type config struct {
+	instanceConfig
+
+	selector       *selector
+	tagByContainer bool
+	tags           []string
+}
+
+func parseConfig(data []byte) (*config, error) {
+	c := &config{}
+	if err := yaml.Unmarshal(data, &c.instanceConfig); err != nil {
+		return nil, err
+	}
+
+	if c.Name == "" {
+		return nil, errors.New("name must be set")
+	}
+
+	s, err := newSelector(c.instanceConfig)
+	if err != nil {
+		return nil, err
+	}
+	c.selector = s
+
+	c.tagByContainer = c.TagByContainer == nil || *c.TagByContainer
+	c.tags = []string{"process_name:" + c.Name}
+
+	return c, nil
+}
+
+// selector selects the processes of an instance, a process is selected when
+// it matches all the configured criteria
+type selector struct {
+	names   map[string]struct{}
+	cmdline *regexp.Regexp
+	// uid is set when the configured user is numeric, the user name otherwise
+	uid      int32
+	hasUID   bool
+	username string
+	cgroup   *regexp.Regexp
+}
+
+func newSelector(c instanceConfig) (*selector, error) {
+	s := &selector{}
+
+	if len(c.ProcessNames) > 0 {
+		s.names = make(map[string]struct{}, len(c.ProcessNames))
+		for _, name := range c.ProcessNames {
+			s.names[name] = struct{}{}
+		}
+	}
+
+	if c.CmdlinePattern != "" {
+		re, err := regexp.Compile(c.CmdlinePattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cmdline_pattern: %w", err)
+		}
+		s.cmdline = re
+	}
+
+	if c.User != "" {
+		if uid, err := strconv.ParseInt(c.User, 10, 32); err == nil {
+			s.uid = int32(uid)
+			s.hasUID = true
+		} else {
+			s.username = c.User
+		}
+	}
+
+	if c.CgroupPattern != "" {
+		re, err := regexp.Compile(c.CgroupPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cgroup_pattern: %w", err)
+		}
+		s.cgroup = re
+	}
+
+	if s.names == nil && s.cmdline == nil && c.User == "" && s.cgroup == nil {
+		return nil, errors.New("at least one of process_names, cmdline_pattern, user or cgroup_pattern must be set")
+	}
+
+	return s, nil
+}
+
+// matches returns whether a process is selected, the cgroups of the process
+// are only read when the other criteria match
+func (s *selector) matches(p *procutil.Process, lookupUser func(uid int32) string) bool {
+	if s.names != nil {
+		if _, found := s.names[p.Name]; !found {
+			return false
+		}
+	}
+
+	if s.cmdline != nil && !s.cmdline.MatchString(strings.Join(p.Cmdline, " ")) {
+		return false
+	}
+
+	if s.hasUID || s.username != "" {
+		if !s.matchesUser(p, lookupUser) {
+			return false
+		}
+	}
+
+	if s.cgroup != nil {
+		cgroups, err := readCgroups(p.Pid)
+		if err != nil {
+			return false
+		}
+		found := false
+		for _, cgroup := range cgroups {
+			if s.cgroup.MatchString(cgroup) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *selector) matchesUser(p *procutil.Process, lookupUser func(uid int32) string) bool {
+	// the user name is only collected on Windows, where processes have no uid
+	if p.Username != "" {
+		return s.username != "" && (p.Username == s.username || strings.HasSuffix(p.Username, `\`+s.username))
+	}
+	if len(p.Uids) == 0 {
+		return false
+	}
+	if s.hasUID {
+		return p.Uids[0] == s.uid
+	}
+	return lookupUser(p.Uids[0]) == s.username
+}
@@ -0,0 +1,11 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+/*
+Package processes provides a core check monitoring the resources used by
+groups of processes selected by name, command line, user or cgroup, the
+processes running in containers are tagged with the tags of their container.
+*/
+package processes
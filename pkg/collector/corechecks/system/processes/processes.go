@@ -0,0 +1,380 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processes
+
+import (
+	"fmt"
+	"os/user"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	processesCheckName = "process_core"
+
+	upServiceCheck = "process.up"
+)
+
+// for testing purpose
+var (
+	timeNow  = time.Now
+	newProbe = func() procutil.Probe {
+		return procutil.NewProcessProbe(procutil.WithPermission(true))
+	}
+	lookupUsername = func(uid int32) string {
+		u, err := user.LookupId(strconv.Itoa(int(uid)))
+		if err != nil {
+			return ""
+		}
+		return u.Username
+	}
+)
+
+// processKey identifies a process across runs, pids can be reused
+type processKey struct {
+	pid        int32
+	createTime int64
+}
+
+// sameProcess returns whether two keys of a pid are the same process, the creation time
+// is 0 when it couldn't be read, in which case the process is assumed to be the same
+func sameProcess(a, b processKey) bool {
+	return a.pid == b.pid && (a.createTime == b.createTime || a.createTime == 0 || b.createTime == 0)
+}
+
+// byPID indexes the keys of processes by pid
+func byPID(processes map[processKey]string) map[int32]processKey {
+	keys := make(map[int32]processKey, len(processes))
+	for key := range processes {
+		keys[key.pid] = key
+	}
+	return keys
+}
+
+// processSample holds the counters of a process read on the previous run
+type processSample struct {
+	cpuTime   float64
+	timestamp time.Time
+	io        *procutil.IOCountersStat
+}
+
+// Check monitors the resources used by the processes selected by an instance
+type Check struct {
+	core.CheckBase
+	config *config
+	probe  procutil.Probe
+
+	// processes maps the selected processes of the previous run to their group,
+	// nil before the first run
+	processes map[processKey]string
+	samples   map[processKey]processSample
+	usernames map[int32]string
+}
+
+// group aggregates the resources of the selected processes of a container,
+// or of the host for the processes not running in a container
+type group struct {
+	tags       []string
+	number     int
+	started    int
+	restarts   int
+	cpuPct     float64
+	hasCPU     bool
+	rss        uint64
+	vms        uint64
+	fds        int64
+	hasFDs     bool
+	threads    int64
+	io         procutil.IOCountersStat
+	hasIO      bool
+	ioDelta    procutil.IOCountersStat
+	hasIODelta bool
+}
+
+// Configure parses the check configuration and initializes the process probe
+func (c *Check) Configure(data integration.Data, initConfig integration.Data, source string) error {
+	// Must be called before CommonConfigure that uses checkID
+	c.BuildID(data, initConfig)
+
+	if err := c.CommonConfigure(data, source); err != nil {
+		return err
+	}
+
+	cfg, err := parseConfig(data)
+	if err != nil {
+		return err
+	}
+	c.config = cfg
+	c.usernames = make(map[int32]string)
+	if c.probe == nil {
+		c.probe = newProbe()
+	}
+
+	return nil
+}
+
+// Run collects the resources of the selected processes
+func (c *Check) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	now := timeNow()
+	procs, err := c.probe.ProcessesByPID(now, false)
+	if err != nil {
+		sender.ServiceCheck(upServiceCheck, metrics.ServiceCheckUnknown, "", c.config.tags, err.Error())
+		sender.Commit()
+		return fmt.Errorf("unable to list the processes: %w", err)
+	}
+
+	// stats are only collected for the selected processes
+	var selected []*procutil.Process
+	var pids []int32
+	for _, p := range procs {
+		if c.config.selector.matches(p, c.username) {
+			selected = append(selected, p)
+			pids = append(pids, p.Pid)
+		}
+	}
+
+	var statsByPID map[int32]*procutil.Stats
+	if len(pids) > 0 {
+		statsByPID, err = c.probe.StatsForPIDs(pids, now)
+		if err != nil {
+			log.Warnf("Unable to collect the stats of the processes selected by %s: %s", c.ID(), err)
+		}
+	}
+
+	prevKeys := byPID(c.processes)
+	groups := make(map[string]*group)
+	processes := make(map[processKey]string, len(selected))
+	samples := make(map[processKey]processSample)
+	for _, p := range selected {
+		groupKey, g := c.groupFor(groups, p.Pid)
+		stats := statsByPID[p.Pid]
+
+		key := processKey{pid: p.Pid}
+		if stats != nil {
+			key.createTime = stats.CreateTime
+		} else if p.Stats != nil {
+			key.createTime = p.Stats.CreateTime
+		}
+		prevKey, known := prevKeys[p.Pid]
+		known = known && sameProcess(key, prevKey)
+		if known && key.createTime == 0 {
+			key = prevKey
+		}
+		processes[key] = groupKey
+		g.number++
+		if c.processes != nil && !known {
+			g.started++
+		}
+
+		if stats == nil {
+			continue
+		}
+		prev, seen := c.samples[key]
+		sample := processSample{timestamp: now}
+		g.addStats(stats, prev, seen, now, &sample)
+		samples[key] = sample
+	}
+	c.countRestarts(groups, processes)
+	c.processes = processes
+	c.samples = samples
+
+	cpus := float64(runtime.NumCPU())
+	for _, g := range groups {
+		g.submit(sender, cpus)
+	}
+
+	if len(selected) == 0 {
+		sender.Gauge("system.processes.number", 0, "", c.config.tags)
+		sender.ServiceCheck(upServiceCheck, metrics.ServiceCheckCritical, "", c.config.tags, "No matching process found")
+	} else {
+		sender.ServiceCheck(upServiceCheck, metrics.ServiceCheckOK, "", c.config.tags, "")
+	}
+
+	sender.Commit()
+	return nil
+}
+
+// countRestarts counts the processes that replaced a process of their group since the
+// previous run, so that the processes started when a group scales up aren't counted
+func (c *Check) countRestarts(groups map[string]*group, processes map[processKey]string) {
+	if c.processes == nil {
+		return
+	}
+
+	keys := byPID(processes)
+	stopped := make(map[string]int)
+	for key, groupKey := range c.processes {
+		if current, running := keys[key.pid]; !running || !sameProcess(key, current) {
+			stopped[groupKey]++
+		}
+	}
+
+	for groupKey, g := range groups {
+		g.restarts = g.started
+		if stopped[groupKey] < g.restarts {
+			g.restarts = stopped[groupKey]
+		}
+	}
+}
+
+// groupFor returns the group of a process and its key, creating it if needed
+func (c *Check) groupFor(groups map[string]*group, pid int32) (string, *group) {
+	containerID := ""
+	if c.config.tagByContainer {
+		cID, err := containerIDForPID(pid)
+		if err != nil {
+			log.Debugf("Unable to get the container of process %d: %s", pid, err)
+		}
+		containerID = cID
+	}
+
+	if g, found := groups[containerID]; found {
+		return containerID, g
+	}
+
+	tags := append([]string{}, c.config.tags...)
+	if containerID != "" {
+		// containers are told apart by their high cardinality tags, so that
+		// the groups of the containers of a same workload don't share a context
+		containerTags, err := tagger.Tag(containers.BuildTaggerEntityName(containerID), collectors.HighCardinality)
+		if err != nil {
+			log.Debugf("Unable to get the tags of container %s: %s", containerID, err)
+		}
+		tags = append(tags, containerTags...)
+		sort.Strings(tags)
+	}
+	g := &group{tags: tags}
+	groups[containerID] = g
+	return containerID, g
+}
+
+// username returns the cached name of a user
+func (c *Check) username(uid int32) string {
+	name, found := c.usernames[uid]
+	if !found {
+		name = lookupUsername(uid)
+		c.usernames[uid] = name
+	}
+	return name
+}
+
+func (g *group) addStats(stats *procutil.Stats, prev processSample, seen bool, now time.Time, sample *processSample) {
+	if stats.CPUTime != nil {
+		sample.cpuTime = stats.CPUTime.User + stats.CPUTime.System
+		if seen {
+			if elapsed := now.Sub(prev.timestamp).Seconds(); elapsed > 0 && sample.cpuTime >= prev.cpuTime {
+				g.cpuPct += (sample.cpuTime - prev.cpuTime) / elapsed * 100
+				g.hasCPU = true
+			}
+		}
+	}
+
+	if stats.MemInfo != nil {
+		g.rss += stats.MemInfo.RSS
+		g.vms += stats.MemInfo.VMS
+	}
+
+	// negative values mean the agent isn't allowed to read them
+	if stats.OpenFdCount >= 0 {
+		g.fds += int64(stats.OpenFdCount)
+		g.hasFDs = true
+	}
+	g.threads += int64(stats.NumThreads)
+
+	if stats.IOStat != nil && stats.IOStat.ReadBytes >= 0 {
+		io := *stats.IOStat
+		sample.io = &io
+		g.io.ReadCount += io.ReadCount
+		g.io.WriteCount += io.WriteCount
+		g.io.ReadBytes += io.ReadBytes
+		g.io.WriteBytes += io.WriteBytes
+		g.hasIO = true
+
+		if seen && prev.io != nil {
+			g.ioDelta.ReadCount += positive(io.ReadCount - prev.io.ReadCount)
+			g.ioDelta.WriteCount += positive(io.WriteCount - prev.io.WriteCount)
+			g.ioDelta.ReadBytes += positive(io.ReadBytes - prev.io.ReadBytes)
+			g.ioDelta.WriteBytes += positive(io.WriteBytes - prev.io.WriteBytes)
+			g.hasIODelta = true
+		}
+	}
+}
+
+func (g *group) submit(sender aggregator.Sender, cpus float64) {
+	sender.Gauge("system.processes.number", float64(g.number), "", g.tags)
+	sender.Count("system.processes.restarts", float64(g.restarts), "", g.tags)
+
+	if g.hasCPU {
+		sender.Gauge("system.processes.cpu.pct", g.cpuPct, "", g.tags)
+		sender.Gauge("system.processes.cpu.normalized_pct", g.cpuPct/cpus, "", g.tags)
+	}
+
+	sender.Gauge("system.processes.mem.rss", float64(g.rss), "", g.tags)
+	sender.Gauge("system.processes.mem.vms", float64(g.vms), "", g.tags)
+	sender.Gauge("system.processes.threads", float64(g.threads), "", g.tags)
+	if g.hasFDs {
+		sender.Gauge("system.processes.open_file_descriptors", float64(g.fds), "", g.tags)
+	}
+
+	if g.hasIO {
+		sender.Gauge("system.processes.ioread_count", float64(g.io.ReadCount), "", g.tags)
+		sender.Gauge("system.processes.iowrite_count", float64(g.io.WriteCount), "", g.tags)
+		sender.Gauge("system.processes.ioread_bytes", float64(g.io.ReadBytes), "", g.tags)
+		sender.Gauge("system.processes.iowrite_bytes", float64(g.io.WriteBytes), "", g.tags)
+	}
+	if g.hasIODelta {
+		sender.Count("system.processes.ioread_count.count", float64(g.ioDelta.ReadCount), "", g.tags)
+		sender.Count("system.processes.iowrite_count.count", float64(g.ioDelta.WriteCount), "", g.tags)
+		sender.Count("system.processes.ioread_bytes.count", float64(g.ioDelta.ReadBytes), "", g.tags)
+		sender.Count("system.processes.iowrite_bytes.count", float64(g.ioDelta.WriteBytes), "", g.tags)
+	}
+}
+
+// positive returns the difference of two counters, a counter reset gives zero
+func positive(delta int64) int64 {
+	if delta < 0 {
+		return 0
+	}
+	return delta
+}
+
+// Cancel closes the process probe
+func (c *Check) Cancel() {
+	if c.probe != nil {
+		c.probe.Close()
+	}
+	c.CommonCancel()
+}
+
+func processesFactory() check.Check {
+	return &Check{
+		CheckBase: core.NewCheckBase(processesCheckName),
+	}
+}
+
+func init() {
+	core.RegisterCheck(processesCheckName, processesFactory)
+	schema.Register(processesCheckName, processesSchema)
+}
@@ -0,0 +1,263 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package processes
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/local"
+)
+
+type fakeProbe struct {
+	procs     map[int32]*procutil.Process
+	err       error
+	statsPIDs []int32
+}
+
+func (f *fakeProbe) Close() {}
+
+func (f *fakeProbe) StatsForPIDs(pids []int32, now time.Time) (map[int32]*procutil.Stats, error) {
+	f.statsPIDs = append([]int32{}, pids...)
+	stats := make(map[int32]*procutil.Stats, len(pids))
+	for _, pid := range pids {
+		if p, found := f.procs[pid]; found {
+			stats[pid] = p.Stats
+		}
+	}
+	return stats, nil
+}
+
+func (f *fakeProbe) ProcessesByPID(now time.Time, collectStats bool) (map[int32]*procutil.Process, error) {
+	if collectStats {
+		return nil, fmt.Errorf("stats must only be collected for the selected processes")
+	}
+	return f.procs, f.err
+}
+
+func (f *fakeProbe) StatsWithPermByPID(pids []int32) (map[int32]*procutil.StatsWithPerm, error) {
+	return nil, nil
+}
+
+func newProcess(pid int32, name string, cmdline []string, uid int32, cpu float64, io int64) *procutil.Process {
+	return &procutil.Process{
+		Pid:     pid,
+		Name:    name,
+		Cmdline: cmdline,
+		Uids:    []int32{uid},
+		Stats: &procutil.Stats{
+			CreateTime:  int64(pid) * 1000,
+			OpenFdCount: 10,
+			NumThreads:  4,
+			CPUTime:     &procutil.CPUTimesStat{User: cpu, System: cpu},
+			MemInfo:     &procutil.MemoryInfoStat{RSS: 1000, VMS: 2000},
+			IOStat:      &procutil.IOCountersStat{ReadCount: io, WriteCount: io, ReadBytes: io * 10, WriteBytes: io * 10},
+		},
+	}
+}
+
+func setup(t *testing.T, probe *fakeProbe, cgroups map[int32][]string, containersByPID map[int32]string) {
+	oldProbe, oldCgroups, oldContainerIDForPID, oldLookupUsername, oldTimeNow := newProbe, readCgroups, containerIDForPID, lookupUsername, timeNow
+	oldTagger := tagger.GetDefaultTagger()
+	t.Cleanup(func() {
+		newProbe, readCgroups, containerIDForPID, lookupUsername, timeNow = oldProbe, oldCgroups, oldContainerIDForPID, oldLookupUsername, oldTimeNow
+		tagger.SetDefaultTagger(oldTagger)
+	})
+
+	newProbe = func() procutil.Probe { return probe }
+	readCgroups = func(pid int32) ([]string, error) {
+		if c, found := cgroups[pid]; found {
+			return c, nil
+		}
+		return nil, fmt.Errorf("no cgroup for %d", pid)
+	}
+	containerIDForPID = func(pid int32) (string, error) { return containersByPID[pid], nil }
+	lookupUsername = func(uid int32) string {
+		if uid == 33 {
+			return "www-data"
+		}
+		return "root"
+	}
+
+	fakeTagger := local.NewFakeTagger()
+	fakeTagger.SetTags("container_id://abc", "fake", []string{"image_name:nginx"}, nil, nil, nil)
+	tagger.SetDefaultTagger(fakeTagger)
+}
+
+func TestParseConfig(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{name: "no name", config: "process_names: [nginx]", err: "name must be set"},
+		{name: "no selector", config: "name: nginx", err: "at least one of"},
+		{name: "invalid cmdline pattern", config: "name: nginx\ncmdline_pattern: '('", err: "invalid cmdline_pattern"},
+		{name: "invalid cgroup pattern", config: "name: nginx\ncgroup_pattern: '['", err: "invalid cgroup_pattern"},
+		{name: "valid", config: "name: nginx\nuser: '33'"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := parseConfig([]byte(tc.config))
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, c.selector.hasUID)
+			assert.Equal(t, int32(33), c.selector.uid)
+			assert.True(t, c.tagByContainer)
+			assert.Equal(t, []string{"process_name:nginx"}, c.tags)
+		})
+	}
+}
+
+func TestSelector(t *testing.T) {
+	setup(t, &fakeProbe{}, map[int32][]string{1: {"/system.slice/nginx.service"}}, nil)
+	nginx := newProcess(1, "nginx", []string{"nginx:", "worker", "process"}, 33, 0, 0)
+	other := newProcess(2, "nginx", []string{"nginx:", "master", "process"}, 0, 0, 0)
+
+	for _, tc := range []struct {
+		name     string
+		config   string
+		expected []bool
+	}{
+		{name: "names", config: "process_names: [nginx, apache2]", expected: []bool{true, true}},
+		{name: "cmdline", config: "cmdline_pattern: 'worker process$'", expected: []bool{true, false}},
+		{name: "uid", config: "user: '0'", expected: []bool{false, true}},
+		{name: "username", config: "user: www-data", expected: []bool{true, false}},
+		{name: "cgroup", config: "cgroup_pattern: 'nginx\\.service$'", expected: []bool{true, false}},
+		{name: "all criteria", config: "process_names: [nginx]\nuser: root\ncmdline_pattern: master", expected: []bool{false, true}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := parseConfig([]byte("name: test\n" + tc.config))
+			require.NoError(t, err)
+			check := &Check{usernames: map[int32]string{}}
+			assert.Equal(t, tc.expected[0], c.selector.matches(nginx, check.username))
+			assert.Equal(t, tc.expected[1], c.selector.matches(other, check.username))
+		})
+	}
+}
+
+func TestRun(t *testing.T) {
+	probe := &fakeProbe{procs: map[int32]*procutil.Process{
+		1: newProcess(1, "nginx", []string{"nginx"}, 0, 1, 5),
+		2: newProcess(2, "nginx", []string{"nginx"}, 0, 2, 5),
+		3: newProcess(3, "nginx", []string{"nginx"}, 0, 3, 5),
+		4: newProcess(4, "bash", []string{"bash"}, 0, 3, 5),
+	}}
+	setup(t, probe, nil, map[int32]string{3: "abc"})
+
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+
+	check := processesFactory().(*Check)
+	require.NoError(t, check.Configure([]byte("name: nginx\nprocess_names: [nginx]"), nil, "test"))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+	assert.ElementsMatch(t, []int32{1, 2, 3}, probe.statsPIDs)
+
+	hostTags := []string{"process_name:nginx"}
+	containerTags := []string{"image_name:nginx", "process_name:nginx"}
+	sender.AssertMetric(t, "Gauge", "system.processes.number", 2, "", hostTags)
+	sender.AssertMetric(t, "Gauge", "system.processes.number", 1, "", containerTags)
+	sender.AssertMetric(t, "Gauge", "system.processes.mem.rss", 2000, "", hostTags)
+	sender.AssertMetric(t, "Gauge", "system.processes.open_file_descriptors", 20, "", hostTags)
+	sender.AssertMetric(t, "Gauge", "system.processes.threads", 4, "", containerTags)
+	sender.AssertMetric(t, "Gauge", "system.processes.ioread_bytes", 100, "", hostTags)
+	sender.AssertMetric(t, "Count", "system.processes.restarts", 0, "", hostTags)
+	sender.AssertNotCalled(t, "Gauge", "system.processes.cpu.pct", 0.0, "", hostTags)
+	sender.AssertServiceCheck(t, upServiceCheck, metrics.ServiceCheckOK, "", hostTags, "")
+
+	// process 2 restarted and process 1 used a cpu second over 10 seconds
+	now = now.Add(10 * time.Second)
+	probe.procs[1] = newProcess(1, "nginx", []string{"nginx"}, 0, 1.5, 8)
+	probe.procs[2] = newProcess(2, "nginx", []string{"nginx"}, 0, 0, 0)
+	probe.procs[2].Stats.CreateTime++
+
+	sender = mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	sender.AssertMetric(t, "Count", "system.processes.restarts", 1, "", hostTags)
+	sender.AssertMetric(t, "Count", "system.processes.restarts", 0, "", containerTags)
+	sender.AssertMetric(t, "Gauge", "system.processes.cpu.pct", 10, "", hostTags)
+	sender.AssertMetric(t, "Gauge", "system.processes.cpu.pct", 0, "", containerTags)
+	sender.AssertMetric(t, "Count", "system.processes.ioread_bytes.count", 30, "", hostTags)
+	sender.AssertMetric(t, "Count", "system.processes.ioread_count.count", 3, "", hostTags)
+
+	// a new process scaling the group up isn't a restart
+	now = now.Add(10 * time.Second)
+	probe.procs[5] = newProcess(5, "nginx", []string{"nginx"}, 0, 0, 0)
+
+	sender = mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	sender.AssertMetric(t, "Gauge", "system.processes.number", 3, "", hostTags)
+	sender.AssertMetric(t, "Count", "system.processes.restarts", 0, "", hostTags)
+
+	// a process whose creation time can't be read isn't a restart
+	now = now.Add(10 * time.Second)
+	probe.procs[1] = newProcess(1, "nginx", []string{"nginx"}, 0, 2, 8)
+	probe.procs[1].Stats.CreateTime = 0
+
+	sender = mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	sender.AssertMetric(t, "Count", "system.processes.restarts", 0, "", hostTags)
+	sender.AssertMetric(t, "Gauge", "system.processes.cpu.pct", 10, "", hostTags)
+
+	// nor is it once its creation time can be read again
+	now = now.Add(10 * time.Second)
+	probe.procs[1] = newProcess(1, "nginx", []string{"nginx"}, 0, 2, 8)
+
+	sender = mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	sender.AssertMetric(t, "Count", "system.processes.restarts", 0, "", hostTags)
+}
+
+func TestRunNoMatch(t *testing.T) {
+	setup(t, &fakeProbe{procs: map[int32]*procutil.Process{
+		1: newProcess(1, "bash", []string{"bash"}, 0, 1, 5),
+	}}, nil, nil)
+
+	check := processesFactory().(*Check)
+	require.NoError(t, check.Configure([]byte("name: nginx\nprocess_names: [nginx]\ntags: [foo:bar]"), nil, "test"))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	require.NoError(t, check.Run())
+
+	tags := []string{"process_name:nginx"}
+	sender.AssertMetric(t, "Gauge", "system.processes.number", 0, "", tags)
+	sender.AssertServiceCheck(t, upServiceCheck, metrics.ServiceCheckCritical, "", tags, "No matching process found")
+}
+
+func TestRunProbeError(t *testing.T) {
+	setup(t, &fakeProbe{err: fmt.Errorf("no procfs")}, nil, nil)
+
+	check := processesFactory().(*Check)
+	require.NoError(t, check.Configure([]byte("name: nginx\nprocess_names: [nginx]"), nil, "test"))
+
+	sender := mocksender.NewMockSender(check.ID())
+	sender.SetupAcceptAll()
+	assert.Error(t, check.Run())
+	sender.AssertServiceCheck(t, upServiceCheck, metrics.ServiceCheckUnknown, "", []string{"process_name:nginx"}, "no procfs")
+}
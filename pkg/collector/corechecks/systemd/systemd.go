@@ -19,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metadata/inventories"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	systemdutil "github.com/DataDog/datadog-agent/pkg/util/systemd"
 	"github.com/coreos/go-systemd/dbus"
 	"gopkg.in/yaml.v2"
 
@@ -174,7 +175,7 @@ type systemdStats interface {
 type defaultSystemdStats struct{}
 
 func (s *defaultSystemdStats) PrivateSocketConnection(privateSocket string) (*dbus.Conn, error) {
-	return NewSystemdConnection(privateSocket)
+	return systemdutil.NewSystemdConnection(privateSocket)
 }
 
 func (s *defaultSystemdStats) SystemBusSocketConnection() (*dbus.Conn, error) {
@@ -43,6 +43,7 @@ type PythonCheck struct {
 	class        *C.rtloader_pyobject_t
 	ModuleName   string
 	interval     time.Duration
+	scheduling   check.SchedulingOptions
 	lastWarnings []error
 	source       string
 	telemetry    bool // whether or not the telemetry is enabled for this check
@@ -226,6 +227,14 @@ func (c *PythonCheck) Configure(data integration.Data, initConfig integration.Da
 		c.interval = time.Duration(commonOptions.MinCollectionInterval) * time.Second
 	}
 
+	// Set the priority and run timeout of the check
+	scheduling, err := check.NewSchedulingOptions(commonOptions, check.PriorityNormal)
+	if err != nil {
+		log.Errorf("invalid instance section for check %s: %s", string(c.id), err)
+		return err
+	}
+	c.scheduling = scheduling
+
 	// Disable default hostname if specified
 	if commonOptions.EmptyDefaultHostname {
 		s, err := aggregator.GetSender(c.id)
@@ -310,6 +319,11 @@ func (c *PythonCheck) Interval() time.Duration {
 	return c.interval
 }
 
+// SchedulingOptions returns the priority and run timeout of the check
+func (c *PythonCheck) SchedulingOptions() check.SchedulingOptions {
+	return c.scheduling
+}
+
 // ID returns the ID of the check
 func (c *PythonCheck) ID() check.ID {
 	return c.id
@@ -119,13 +119,25 @@ func AddCheckStats(
 	mStats check.SenderStats,
 ) {
 
-	var s *check.Stats
-
 	checkStats.statsLock.Lock()
 	defer checkStats.statsLock.Unlock()
 
 	log.Tracef("Adding stats for %s", string(c.ID()))
 
+	getOrCreateCheckStats(c).Add(execTime, err, warnings, mStats)
+}
+
+// AddCheckLateness adds the lateness of a run to the check's expvars
+func AddCheckLateness(c check.Check, lateness time.Duration) {
+	checkStats.statsLock.Lock()
+	defer checkStats.statsLock.Unlock()
+
+	getOrCreateCheckStats(c).AddLateness(lateness)
+}
+
+// getOrCreateCheckStats returns the stats of a check, creating them if needed.
+// The stats lock must be held.
+func getOrCreateCheckStats(c check.Check) *check.Stats {
 	checkName := check.IDToCheckName(c.ID())
 	stats, found := checkStats.stats[checkName]
 	if !found {
@@ -133,13 +145,13 @@ func AddCheckStats(
 		checkStats.stats[checkName] = stats
 	}
 
-	s, found = stats[c.ID()]
+	s, found := stats[c.ID()]
 	if !found {
 		s = check.NewStats(c)
 		stats[c.ID()] = s
 	}
 
-	s.Add(execTime, err, warnings, mStats)
+	return s
 }
 
 // RemoveCheckStats removes a check from the check stats map
@@ -184,6 +196,23 @@ func CheckStats(id check.ID) (*check.Stats, bool) {
 	return check, true
 }
 
+// CheckHistory returns the run history of the instances of a check, it accepts either
+// the name of the check or the ID of one of its instances
+func CheckHistory(nameOrID string) map[check.ID][]check.RunResult {
+	checkStats.statsLock.RLock()
+	defer checkStats.statsLock.RUnlock()
+
+	history := make(map[check.ID][]check.RunResult)
+	checkName := check.IDToCheckName(check.ID(nameOrID))
+	for id, s := range checkStats.stats[checkName] {
+		if checkName == nameOrID || string(id) == nameOrID {
+			history[id] = s.History()
+		}
+	}
+
+	return history
+}
+
 // Functions relating to running checks state map (`runningChecksStats`)
 
 // SetRunningStats sets the start time of a running check
@@ -334,6 +334,29 @@ func TestExpvarsGetChecksStatsClone(t *testing.T) {
 	assert.Equal(t, numCheckInstances, len(getCheckStatsExpvarMap(t)["testcheck1"]))
 }
 
+func TestExpvarsCheckHistory(t *testing.T) {
+	setUp()
+
+	AddCheckStats(newTestCheck("testcheck:1"), 10*time.Millisecond, nil, []error{}, check.SenderStats{})
+	AddCheckStats(newTestCheck("testcheck:1"), 20*time.Millisecond, fmt.Errorf("myerror"), []error{}, check.SenderStats{})
+	AddCheckStats(newTestCheck("testcheck:2"), 30*time.Millisecond, nil, []error{}, check.SenderStats{})
+	AddCheckStats(newTestCheck("othercheck:1"), 40*time.Millisecond, nil, []error{}, check.SenderStats{})
+
+	history := CheckHistory("testcheck")
+	require.Len(t, history, 2)
+	require.Len(t, history["testcheck:1"], 2)
+	assert.Equal(t, int64(10), history["testcheck:1"][0].ExecutionTime)
+	assert.Equal(t, "myerror", history["testcheck:1"][1].Error)
+	require.Len(t, history["testcheck:2"], 1)
+
+	history = CheckHistory("testcheck:2")
+	require.Len(t, history, 1)
+	assert.Equal(t, int64(30), history["testcheck:2"][0].ExecutionTime)
+
+	assert.Empty(t, CheckHistory("testcheck:3"))
+	assert.Empty(t, CheckHistory("unknown"))
+}
+
 func TestExpvarsRunningStats(t *testing.T) {
 	loc, err := time.LoadLocation("America/New_York")
 	require.Nil(t, err)
@@ -42,9 +42,11 @@ type Runner struct {
 
 	id                  int                           // Globally unique identifier for the Runner
 	workers             map[int]*worker.Worker        // Workers currrently under this Runner's management
+	criticalWorkers     map[int]*worker.Worker        // Workers dedicated to critical checks
 	workersLock         sync.Mutex                    // Lock to prevent concurrent worker changes
 	isStaticWorkerCount bool                          // Flag indicating if numWorkers is dynamically updated
 	pendingChecksChan   chan check.Check              // The channel where checks come from
+	criticalChecksChan  chan check.Check              // The channel where critical checks come from
 	checksTracker       *tracker.RunningChecksTracker // Tracker in charge of maintaining the running check list
 	scheduler           *scheduler.Scheduler          // Scheduler runner operates on
 	schedulerLock       sync.RWMutex                  // Lock around operations on the scheduler
@@ -58,8 +60,10 @@ func NewRunner() *Runner {
 		id:                  int(atomic.AddUint64(&runnerIDGenerator, 1)),
 		isRunning:           1,
 		workers:             make(map[int]*worker.Worker),
+		criticalWorkers:     make(map[int]*worker.Worker),
 		isStaticWorkerCount: numWorkers != 0,
 		pendingChecksChan:   make(chan check.Check),
+		criticalChecksChan:  make(chan check.Check),
 		checksTracker:       tracker.NewRunningChecksTracker(),
 	}
 
@@ -68,10 +72,29 @@ func NewRunner() *Runner {
 	}
 
 	r.ensureMinWorkers(numWorkers)
+	r.addCriticalWorkers(config.Datadog.GetInt("check_runners_critical"))
 
 	return r
 }
 
+// addCriticalWorkers adds workers dedicated to critical checks, so that they never
+// wait for a worker busy running other checks
+func (r *Runner) addCriticalWorkers(numWorkers int) {
+	r.workersLock.Lock()
+	defer r.workersLock.Unlock()
+
+	for idx := 0; idx < numWorkers; idx++ {
+		worker, err := r.newWorker(true)
+		if err == nil {
+			r.criticalWorkers[worker.ID] = worker
+		}
+	}
+
+	if numWorkers > 0 {
+		log.Infof("Runner %d added %d workers dedicated to critical checks", r.id, len(r.criticalWorkers))
+	}
+}
+
 // EnsureMinWorkers increases the number of workers to match the
 // `desiredNumWorkers` parameter
 func (r *Runner) ensureMinWorkers(desiredNumWorkers int) {
@@ -86,7 +109,7 @@ func (r *Runner) ensureMinWorkers(desiredNumWorkers int) {
 
 	workersToAdd := desiredNumWorkers - currentWorkers
 	for idx := 0; idx < workersToAdd; idx++ {
-		worker, err := r.newWorker()
+		worker, err := r.newWorker(false)
 		if err == nil {
 			r.workers[worker.ID] = worker
 		}
@@ -105,18 +128,25 @@ func (r *Runner) AddWorker() {
 	r.workersLock.Lock()
 	defer r.workersLock.Unlock()
 
-	worker, err := r.newWorker()
+	worker, err := r.newWorker(false)
 	if err == nil {
 		r.workers[worker.ID] = worker
 	}
 }
 
-// addWorker adds a new worker running in a separate goroutine
-func (r *Runner) newWorker() (*worker.Worker, error) {
+// newWorker adds a new worker running in a separate goroutine, dedicated workers
+// only run critical checks
+func (r *Runner) newWorker(dedicatedToCriticalChecks bool) (*worker.Worker, error) {
+	pendingChecksChan := r.pendingChecksChan
+	if dedicatedToCriticalChecks {
+		pendingChecksChan = nil
+	}
+
 	worker, err := worker.NewWorker(
 		r.id,
 		int(atomic.AddUint64(&workerIDGenerator, 1)),
-		r.pendingChecksChan,
+		pendingChecksChan,
+		r.criticalChecksChan,
 		r.checksTracker,
 		r.ShouldAddCheckStats,
 	)
@@ -139,6 +169,7 @@ func (r *Runner) removeWorker(id int) {
 	defer r.workersLock.Unlock()
 
 	delete(r.workers, id)
+	delete(r.criticalWorkers, id)
 }
 
 // UpdateNumWorkers checks if the current number of workers is reasonable,
@@ -177,6 +208,7 @@ func (r *Runner) Stop() {
 
 	log.Infof("Runner %d is shutting down...", r.id)
 	close(r.pendingChecksChan)
+	close(r.criticalChecksChan)
 
 	wg := sync.WaitGroup{}
 
@@ -224,6 +256,11 @@ func (r *Runner) GetChan() chan<- check.Check {
 	return r.pendingChecksChan
 }
 
+// GetCriticalChan returns a write-only version of the critical checks channel
+func (r *Runner) GetCriticalChan() chan<- check.Check {
+	return r.criticalChecksChan
+}
+
 // SetScheduler sets the scheduler for the runner
 func (r *Runner) SetScheduler(s *scheduler.Scheduler) {
 	r.schedulerLock.Lock()
@@ -102,7 +102,7 @@ func newCheck(t *testing.T, id string, doErr bool, runFunc func(check.ID)) *test
 }
 
 func newScheduler() *scheduler.Scheduler {
-	return scheduler.NewScheduler(nil)
+	return scheduler.NewScheduler(nil, nil)
 }
 
 func assertAsyncWorkerCount(t *testing.T, count int) {
@@ -141,6 +141,7 @@ func testSetUp(t *testing.T) {
 	assertAsyncWorkerCount(t, 0)
 	expvars.Reset()
 	config.Datadog.Set("hostname", "myhost")
+	config.Datadog.Set("check_runners_critical", "0")
 }
 
 // Tests
@@ -449,3 +450,33 @@ func TestRunnerShouldAddCheckStats(t *testing.T) {
 	// If there's a scheduler with scheduled check, add the stats
 	require.True(t, r.ShouldAddCheckStats(testCheck.ID()))
 }
+
+func TestRunnerCriticalWorkers(t *testing.T) {
+	testSetUp(t)
+	config.Datadog.Set("check_runners", "1")
+	config.Datadog.Set("check_runners_critical", "1")
+	defer config.Datadog.Set("check_runners_critical", "0")
+
+	blockedCheck := newCheck(t, "blockedcheck:123", false, nil)
+	blockedCheck.RunLock.Lock()
+	criticalCheck := newCheck(t, "criticalcheck:123", false, nil)
+
+	r := NewRunner()
+	require.NotNil(t, r)
+	defer r.Stop()
+
+	assertAsyncWorkerCount(t, 2)
+
+	// Block the only regular worker
+	r.GetChan() <- blockedCheck
+	<-blockedCheck.StartedChan()
+
+	// The dedicated worker runs critical checks anyway
+	r.GetCriticalChan() <- criticalCheck
+	<-criticalCheck.StartedChan()
+	assertAsyncBool(t, func() bool { return criticalCheck.RunCount() == 1 }, true)
+	assert.Equal(t, 0, blockedCheck.RunCount())
+
+	blockedCheck.RunLock.Unlock()
+	assertAsyncBool(t, func() bool { return blockedCheck.RunCount() == 1 }, true)
+}
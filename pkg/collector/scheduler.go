@@ -13,6 +13,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
 	"github.com/DataDog/datadog-agent/pkg/collector/loaders"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -42,6 +43,9 @@ func init() {
 	schedulerErrs.Set("RunErrors", expvar.Func(func() interface{} {
 		return errorStats.getRunErrors()
 	}))
+	schedulerErrs.Set("ValidationErrors", expvar.Func(func() interface{} {
+		return errorStats.getValidationErrors()
+	}))
 }
 
 // CheckScheduler is the check scheduler
@@ -89,6 +93,7 @@ func (s *CheckScheduler) Unschedule(configs []integration.Config) {
 		}
 		// unschedule all the possible checks corresponding to this config
 		digest := config.Digest()
+		errorStats.removeValidationErrors(config.Name, digest)
 		ids := s.configToChecks[digest]
 		stopped := map[check.ID]struct{}{}
 		for _, id := range ids {
@@ -144,6 +149,19 @@ func (s *CheckScheduler) getChecks(config integration.Config) ([]check.Check, er
 	checks := []check.Check{}
 	numLoaders := len(s.loaders)
 
+	// validation issues are reported but don't prevent the check from being loaded,
+	// the check itself is the authority on its configuration
+	if validationErrors, found := schema.ValidateConfig(config); found {
+		for _, e := range validationErrors {
+			if e.Severity == schema.SeverityError {
+				log.Errorf("Invalid configuration for check '%s' (%s): %s", config.Name, config.Source, e)
+			} else {
+				log.Warnf("Configuration issue for check '%s' (%s): %s", config.Name, config.Source, e)
+			}
+		}
+		errorStats.setValidationErrors(config.Name, config.Digest(), validationErrors)
+	}
+
 	initConfig := commonInitConfig{}
 	err := yaml.Unmarshal(config.InitConfig, &initConfig)
 	if err != nil {
@@ -264,3 +282,8 @@ func (s *CheckScheduler) GetChecksFromConfigs(configs []integration.Config, popu
 func GetLoaderErrors() map[string]map[string]string {
 	return errorStats.getLoaderErrors()
 }
+
+// GetValidationErrors returns the check configuration validation errors
+func GetValidationErrors() map[string][]schema.Error {
+	return errorStats.getValidationErrors()
+}
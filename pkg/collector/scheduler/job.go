@@ -7,10 +7,12 @@ package scheduler
 
 import (
 	"fmt"
+	"math/rand"
 	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -58,27 +60,47 @@ func (jb *jobBucket) removeJob(id check.ID) bool {
 // scheduled at a certain interval.
 type jobQueue struct {
 	interval            time.Duration
-	stop                chan bool // to stop this queue
-	stopped             chan bool // signals that this queue has stopped
+	priority            check.Priority
+	pipe                chan<- check.Check // the pipe the checks of this queue are posted to
+	stop                chan bool          // to stop this queue
+	stopped             chan bool          // signals that this queue has stopped
 	buckets             []*jobBucket
 	bucketTicker        *time.Ticker
 	lastTick            time.Time
 	sparseStep          uint
 	currentBucketIdx    uint
 	schedulingBucketIdx uint
+	firstRuns           map[check.ID]*time.Timer // timers enqueuing the first run of the checks added with a start jitter
+	dueFirstRuns        map[check.ID]dueFirstRun // first runs that expired while the queue wasn't running
 	running             bool
+	done                chan struct{} // closed once the queue stops, to cancel the pending first runs
 	health              *health.Handle
 	mu                  sync.RWMutex // to protect critical sections in struct's fields
 }
 
+// dueFirstRun is the first run of a check whose delay expired before the queue ran
+type dueFirstRun struct {
+	check check.Check
+	due   time.Time
+}
+
 // newJobQueue creates a new jobQueue instance
-func newJobQueue(interval time.Duration) *jobQueue {
+func newJobQueue(interval time.Duration, priority check.Priority, pipe chan<- check.Check) *jobQueue {
+	healthName := fmt.Sprintf("collector-queue-%vs", interval.Seconds())
+	if priority != check.PriorityNormal {
+		healthName = fmt.Sprintf("collector-queue-%s-%vs", priority, interval.Seconds())
+	}
+
 	jq := &jobQueue{
 		interval:     interval,
+		priority:     priority,
+		pipe:         pipe,
 		stop:         make(chan bool),
 		stopped:      make(chan bool),
-		health:       health.RegisterLiveness(fmt.Sprintf("collector-queue-%vs", interval.Seconds())),
+		health:       health.RegisterLiveness(healthName),
 		bucketTicker: time.NewTicker(time.Second),
+		firstRuns:    make(map[check.ID]*time.Timer),
+		dueFirstRuns: make(map[check.ID]dueFirstRun),
 	}
 
 	var nb int
@@ -113,20 +135,76 @@ func newJobQueue(interval time.Duration) *jobQueue {
 	return jq
 }
 
-// addJob is a convenience method to add a check to a queue
-func (jq *jobQueue) addJob(c check.Check) {
+// addJob is a convenience method to add a check to a queue, the first run of the check
+// is randomly delayed by up to startJitter (and at most the interval of the queue).
+// The jitter only applies to the first run, which is enqueued on its own once the delay
+// expires; the following runs follow the bucket the check is scheduled to.
+func (jq *jobQueue) addJob(c check.Check, startJitter time.Duration) {
 	jq.mu.Lock()
 	defer jq.mu.Unlock()
 
 	// Checks scheduled to buckets scheduled with sparse round-robin
 	jq.buckets[jq.schedulingBucketIdx].addJob(c)
 	jq.schedulingBucketIdx = (jq.schedulingBucketIdx + jq.sparseStep) % uint(len(jq.buckets))
+
+	if startJitter > jq.interval {
+		startJitter = jq.interval
+	}
+	if startJitter > 0 {
+		delay := time.Duration(rand.Int63n(int64(startJitter) + 1))
+		jq.firstRuns[c.ID()] = time.AfterFunc(delay, func() { jq.enqueueFirstRun(c) })
+	}
+}
+
+// enqueueFirstRun posts the first run of a check added with a start jitter to the pipe,
+// unless the check was removed or the queue stopped meanwhile. If the queue isn't running
+// yet, the first run is posted once it runs.
+func (jq *jobQueue) enqueueFirstRun(c check.Check) {
+	jq.mu.Lock()
+	if _, found := jq.firstRuns[c.ID()]; !found {
+		jq.mu.Unlock()
+		return
+	}
+	done := jq.done
+	if done == nil {
+		jq.dueFirstRuns[c.ID()] = dueFirstRun{check: c, due: time.Now()}
+		jq.mu.Unlock()
+		return
+	}
+	delete(jq.firstRuns, c.ID())
+	jq.mu.Unlock()
+
+	jq.postFirstRun(c, time.Now(), done)
+}
+
+// postFirstRun posts the first run of a check due at the given time to the pipe,
+// unless the queue stops meanwhile
+func (jq *jobQueue) postFirstRun(c check.Check, due time.Time, done <-chan struct{}) {
+	select {
+	case jq.pipe <- c:
+		expvars.AddCheckLateness(c, time.Since(due))
+	case <-done:
+	}
+}
+
+// isFirstRunDelayed returns whether the first run of a check is still delayed by its start jitter
+func (jq *jobQueue) isFirstRunDelayed(id check.ID) bool {
+	jq.mu.RLock()
+	defer jq.mu.RUnlock()
+
+	_, found := jq.firstRuns[id]
+	return found
 }
 
 func (jq *jobQueue) removeJob(id check.ID) error {
 	jq.mu.Lock()
 	defer jq.mu.Unlock()
 
+	if timer, found := jq.firstRuns[id]; found {
+		timer.Stop()
+		delete(jq.firstRuns, id)
+		delete(jq.dueFirstRuns, id)
+	}
 	for _, bucket := range jq.buckets {
 		if found := bucket.removeJob(id); found {
 			return nil
@@ -149,6 +227,7 @@ func (jq *jobQueue) stats() map[string]interface{} {
 
 	return map[string]interface{}{
 		"Interval": jq.interval / time.Second,
+		"Priority": jq.priority.String(),
 		"Buckets":  nBuckets,
 		"Size":     nJobs,
 	}
@@ -158,12 +237,28 @@ func (jq *jobQueue) stats() map[string]interface{} {
 // execution pipeline.
 // Not blocking, runs in a new goroutine.
 func (jq *jobQueue) run(s *Scheduler) {
+	jq.mu.Lock()
+	done := make(chan struct{})
+	jq.done = done
+	// post the first runs that expired before the queue ran
+	for id, firstRun := range jq.dueFirstRuns {
+		delete(jq.firstRuns, id)
+		delete(jq.dueFirstRuns, id)
+		go jq.postFirstRun(firstRun.check, firstRun.due, done)
+	}
+	jq.mu.Unlock()
 
 	go func() {
 		log.Debugf("Job queue is running...")
 		for jq.process(s) {
 			// empty
 		}
+
+		jq.mu.Lock()
+		jq.done = nil
+		jq.mu.Unlock()
+		close(done)
+
 		jq.stopped <- true
 	}()
 }
@@ -196,18 +291,24 @@ func (jq *jobQueue) process(s *Scheduler) bool {
 		log.Tracef("Jobs in bucket: %v", jobs)
 
 		for _, check := range jobs {
-			if !s.IsCheckScheduled(check.ID()) {
+			if !s.IsCheckScheduled(check.ID()) || jq.isFirstRunDelayed(check.ID()) {
 				continue
 			}
 
 			select {
 			// blocking, we'll be here as long as it takes
-			case s.checksPipe <- check:
+			case jq.pipe <- check:
 			case <-jq.stop:
 				jq.health.Deregister() //nolint:errcheck
 				return false
 			}
 
+			// the check is picked up by a worker as soon as it's posted, the delay since
+			// the tick is the time it waited for a worker
+			if s.IsCheckScheduled(check.ID()) {
+				expvars.AddCheckLateness(check, time.Since(t))
+			}
+
 			select {
 			// we were able to schedule a check so we're not stuck, therefore poll the health chan
 			case <-jq.health.C:
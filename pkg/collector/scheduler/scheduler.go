@@ -12,6 +12,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -37,16 +38,20 @@ func init() {
 }
 
 // Scheduler keeps things rolling.
-// More docs to come...
+// Checks are grouped in queues by interval and priority class, critical checks are posted
+// to a dedicated pipe so that they don't wait behind other checks.
 type Scheduler struct {
-	running          uint32                      // Flag to see if the scheduler is running
-	checksPipe       chan<- check.Check          // The pipe the Runner pops the checks from, initially set to nil
-	done             chan bool                   // Guard for the main loop
-	halted           chan bool                   // Used to internally communicate all queues are done
-	started          chan bool                   // Used to internally communicate the queues are up
-	jobQueues        map[time.Duration]*jobQueue // We have one scheduling queue for every interval
-	tlmTrackedChecks map[check.ID]string         // Keep track of the checks that are tracked with telemetry
-	mu               sync.Mutex                  // To protect critical sections in struct's fields
+	running            uint32                      // Flag to see if the scheduler is running
+	checksPipe         chan<- check.Check          // The pipe the Runner pops the checks from, initially set to nil
+	criticalChecksPipe chan<- check.Check          // The pipe the Runner pops the critical checks from, checksPipe is used if nil
+	done               chan bool                   // Guard for the main loop
+	halted             chan bool                   // Used to internally communicate all queues are done
+	started            chan bool                   // Used to internally communicate the queues are up
+	jobQueues          map[time.Duration]*jobQueue // We have one scheduling queue for every interval
+	criticalJobQueues  map[time.Duration]*jobQueue // And one for every interval of critical checks
+	startJitter        time.Duration               // Maximum delay randomly added to the first run of checks
+	tlmTrackedChecks   map[check.ID]string         // Keep track of the checks that are tracked with telemetry
+	mu                 sync.Mutex                  // To protect critical sections in struct's fields
 
 	checkToQueue map[check.ID]*jobQueue // Keep track of what is the queue for any Check
 	// To protect checkToQueue. Using mu would create a deadlock when stopping the Scheduler. 'jobQueue' is calling
@@ -60,18 +65,26 @@ type Scheduler struct {
 }
 
 // NewScheduler create a Scheduler and returns a pointer to it.
-func NewScheduler(checksPipe chan<- check.Check) *Scheduler {
+// Critical checks are posted to criticalChecksPipe, or to checksPipe if it's nil.
+func NewScheduler(checksPipe chan<- check.Check, criticalChecksPipe chan<- check.Check) *Scheduler {
+	if criticalChecksPipe == nil {
+		criticalChecksPipe = checksPipe
+	}
+
 	return &Scheduler{
-		checksPipe:       checksPipe,
-		done:             make(chan bool),
-		halted:           make(chan bool),
-		started:          make(chan bool),
-		jobQueues:        make(map[time.Duration]*jobQueue),
-		checkToQueue:     make(map[check.ID]*jobQueue),
-		tlmTrackedChecks: make(map[check.ID]string),
-		running:          0,
-		cancelOneTime:    make(chan bool),
-		wgOneTime:        sync.WaitGroup{},
+		checksPipe:         checksPipe,
+		criticalChecksPipe: criticalChecksPipe,
+		done:               make(chan bool),
+		halted:             make(chan bool),
+		started:            make(chan bool),
+		jobQueues:          make(map[time.Duration]*jobQueue),
+		criticalJobQueues:  make(map[time.Duration]*jobQueue),
+		startJitter:        time.Duration(config.Datadog.GetInt("check_start_jitter")) * time.Second,
+		checkToQueue:       make(map[check.ID]*jobQueue),
+		tlmTrackedChecks:   make(map[check.ID]string),
+		running:            0,
+		cancelOneTime:      make(chan bool),
+		wgOneTime:          sync.WaitGroup{},
 	}
 }
 
@@ -88,25 +101,31 @@ func (s *Scheduler) Enter(check check.Check) error {
 		return fmt.Errorf("Schedule interval must be greater than %v or 0", minAllowedInterval)
 	}
 
-	log.Infof("Scheduling check %v with an interval of %v", check, check.Interval())
+	priority := getPriority(check)
+	log.Infof("Scheduling check %v with an interval of %v and a %s priority", check, check.Interval(), priority)
 
 	// sync when accessing `jobQueues` and `check2queue`
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, ok := s.jobQueues[check.Interval()]; !ok {
-		s.jobQueues[check.Interval()] = newJobQueue(check.Interval())
-		s.startQueue(s.jobQueues[check.Interval()])
+	queues, pipe := s.jobQueues, s.checksPipe
+	if isCritical(priority) {
+		queues, pipe = s.criticalJobQueues, s.criticalChecksPipe
+	}
+
+	if _, ok := queues[check.Interval()]; !ok {
+		queues[check.Interval()] = newJobQueue(check.Interval(), priority, pipe)
+		s.startQueue(queues[check.Interval()])
 		if check.IsTelemetryEnabled() {
 			tlmQueuesCount.Inc()
 		}
 		schedulerQueuesCount.Add(1)
 	}
-	s.jobQueues[check.Interval()].addJob(check)
+	queues[check.Interval()].addJob(check, s.startJitter)
 
 	// map each check to the Job Queue it was assigned to
 	s.checkToQueueMutex.Lock()
-	s.checkToQueue[check.ID()] = s.jobQueues[check.Interval()]
+	s.checkToQueue[check.ID()] = queues[check.Interval()]
 	s.checkToQueueMutex.Unlock()
 
 	schedulerChecksEntered.Add(1)
@@ -224,8 +243,8 @@ func (s *Scheduler) stopQueues() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	log.Debugf("Stopping %v queue(s)", len(s.jobQueues))
-	for _, q := range s.jobQueues {
+	log.Debugf("Stopping %v queue(s)", len(s.jobQueues)+len(s.criticalJobQueues))
+	for _, q := range s.allQueues() {
 		// check that the queue is actually running or this blocks
 		// while posting to the channel
 		if q.running {
@@ -243,11 +262,23 @@ func (s *Scheduler) startQueues() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, q := range s.jobQueues {
+	for _, q := range s.allQueues() {
 		s.startQueue(q)
 	}
 }
 
+// allQueues returns the queues of every priority class
+func (s *Scheduler) allQueues() []*jobQueue {
+	queues := make([]*jobQueue, 0, len(s.jobQueues)+len(s.criticalJobQueues))
+	for _, q := range s.criticalJobQueues {
+		queues = append(queues, q)
+	}
+	for _, q := range s.jobQueues {
+		queues = append(queues, q)
+	}
+	return queues
+}
+
 // startQueue starts a queue (non-blocking operation) if it's not running yet
 func (s *Scheduler) startQueue(q *jobQueue) {
 	if !q.running {
@@ -263,10 +294,15 @@ func (s *Scheduler) enqueueOnce(check check.Check) {
 	log.Infof("Scheduling check %v for one-time execution", check)
 	s.wgOneTime.Add(1)
 
+	pipe := s.checksPipe
+	if isCritical(getPriority(check)) {
+		pipe = s.criticalChecksPipe
+	}
+
 	go func(cancelOneTime <-chan bool) {
 		defer s.wgOneTime.Done()
 		select {
-		case s.checksPipe <- check:
+		case pipe <- check:
 		case <-cancelOneTime:
 		}
 	}(s.cancelOneTime)
@@ -279,9 +315,18 @@ func expQueues(s *Scheduler) func() interface{} {
 	return func() interface{} {
 		queues := make([]map[string]interface{}, 0)
 
-		for _, queue := range s.jobQueues {
+		for _, queue := range s.allQueues() {
 			queues = append(queues, queue.stats())
 		}
 		return queues
 	}
 }
+
+// getPriority returns the priority class of a check
+func getPriority(c check.Check) check.Priority {
+	return check.GetSchedulingOptions(c).Priority
+}
+
+func isCritical(p check.Priority) bool {
+	return p == check.PriorityCritical
+}
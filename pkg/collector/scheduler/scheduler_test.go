@@ -6,6 +6,7 @@
 package scheduler
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -39,12 +40,12 @@ func resetMinAllowedInterval() {
 }
 
 func getScheduler() *Scheduler {
-	return NewScheduler(make(chan<- check.Check))
+	return NewScheduler(make(chan<- check.Check), nil)
 }
 
 func TestNewScheduler(t *testing.T) {
 	c := make(chan<- check.Check)
-	s := NewScheduler(c)
+	s := NewScheduler(c, nil)
 
 	assert.Equal(t, c, s.checksPipe)
 	assert.Equal(t, len(s.jobQueues), 0)
@@ -55,7 +56,7 @@ func TestEnter(t *testing.T) {
 	c := &TestCheck{}
 	ch := make(chan check.Check)
 	stop := make(chan bool)
-	s := NewScheduler(ch)
+	s := NewScheduler(ch, nil)
 
 	// consume the enqueued checks
 	go consume(ch, stop)
@@ -108,7 +109,7 @@ func TestCancel(t *testing.T) {
 		stop <- true
 	}()
 
-	s := NewScheduler(c)
+	s := NewScheduler(c, nil)
 	defer s.Stop()
 
 	s.Enter(chk)
@@ -148,7 +149,7 @@ func TestStop(t *testing.T) {
 func TestStopCancelsProducers(t *testing.T) {
 	ch := make(chan check.Check)
 	stop := make(chan bool)
-	s := NewScheduler(ch)
+	s := NewScheduler(ch, nil)
 
 	// consume the enqueued checks
 	go consume(ch, stop)
@@ -196,3 +197,127 @@ func TestStopOneTimeSchedule(t *testing.T) {
 	// sleep to make the runtime schedule the hanging goroutines, if there are any
 	time.Sleep(time.Millisecond)
 }
+
+type TestCriticalCheck struct {
+	TestCheck
+}
+
+func (c *TestCriticalCheck) SchedulingOptions() check.SchedulingOptions {
+	return check.SchedulingOptions{Priority: check.PriorityCritical}
+}
+
+func TestEnterCritical(t *testing.T) {
+	ch := make(chan check.Check, 1)
+	criticalCh := make(chan check.Check, 1)
+	s := NewScheduler(ch, criticalCh)
+
+	c := &TestCriticalCheck{TestCheck{intl: 1 * time.Second}}
+	assert.Nil(t, s.Enter(c))
+	assert.Len(t, s.jobQueues, 0)
+	assert.Len(t, s.criticalJobQueues, 1)
+	assert.Equal(t, check.PriorityCritical, s.criticalJobQueues[c.intl].priority)
+
+	// one-time critical checks are posted to the critical pipe
+	c.intl = 0
+	assert.Nil(t, s.Enter(c))
+	select {
+	case enqueued := <-criticalCh:
+		assert.Equal(t, c, enqueued)
+	case <-time.After(time.Second):
+		assert.Fail(t, "the critical check wasn't posted to the critical pipe")
+	}
+	assert.Len(t, ch, 0)
+}
+
+func TestEnterCriticalWithoutCriticalPipe(t *testing.T) {
+	ch := make(chan check.Check, 1)
+	s := NewScheduler(ch, nil)
+
+	assert.Nil(t, s.Enter(&TestCriticalCheck{TestCheck{intl: 0}}))
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the critical check wasn't posted to the checks pipe")
+	}
+}
+
+func TestStartJitter(t *testing.T) {
+	pipe := make(chan check.Check, 50)
+	jq := newJobQueue(5*time.Second, check.PriorityNormal, pipe)
+	noJitter := newJobQueue(5*time.Second, check.PriorityNormal, nil)
+	// the first runs are only enqueued while the queue is running
+	jq.done = make(chan struct{})
+	defer close(jq.done)
+
+	jitter := time.Second
+	start := time.Now()
+	for i := 0; i < 50; i++ {
+		jq.addJob(&TestJobCheck{id: fmt.Sprint(i)}, jitter)
+		noJitter.addJob(&TestJobCheck{id: fmt.Sprint(i)}, 0)
+	}
+	removed := check.ID("49")
+	assert.NoError(t, jq.removeJob(removed))
+	assert.NoError(t, noJitter.removeJob(removed))
+
+	// the jitter doesn't change the buckets of the checks
+	for i, bucket := range jq.buckets {
+		assert.Equal(t, noJitter.buckets[i].jobs, bucket.jobs)
+	}
+	assert.False(t, noJitter.isFirstRunDelayed(check.ID("0")))
+
+	// the first runs are enqueued once their random delay expires, which falls within the jitter
+	for i := 0; i < 49; i++ {
+		select {
+		case c := <-pipe:
+			delay := time.Since(start)
+			assert.NotEqual(t, removed, c.ID())
+			assert.LessOrEqual(t, int64(delay), int64(jitter+200*time.Millisecond), "check %s", c.ID())
+			assert.False(t, jq.isFirstRunDelayed(c.ID()))
+		case <-time.After(2 * jitter):
+			assert.FailNow(t, "first runs were not enqueued within the jitter")
+		}
+	}
+
+	// the first run of a removed check is cancelled
+	select {
+	case c := <-pipe:
+		assert.Failf(t, "unexpected first run", "check %s", c.ID())
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestStartJitterBeforeRun(t *testing.T) {
+	pipe := make(chan check.Check, 2)
+	s := NewScheduler(pipe, nil)
+	jq := newJobQueue(5*time.Second, check.PriorityNormal, pipe)
+
+	jq.addJob(&TestJobCheck{id: "0"}, 10*time.Millisecond)
+	jq.addJob(&TestJobCheck{id: "1"}, 10*time.Millisecond)
+	assert.NoError(t, jq.removeJob(check.ID("1")))
+
+	// the first run expires before the queue runs, it stays delayed
+	time.Sleep(100 * time.Millisecond)
+	assert.True(t, jq.isFirstRunDelayed(check.ID("0")))
+	assert.Empty(t, pipe)
+
+	// and is enqueued as soon as the queue runs
+	jq.run(s)
+	defer func() {
+		jq.stop <- true
+		<-jq.stopped
+	}()
+
+	select {
+	case c := <-pipe:
+		assert.Equal(t, check.ID("0"), c.ID())
+	case <-time.After(500 * time.Millisecond):
+		assert.FailNow(t, "the first run was not enqueued once the queue ran")
+	}
+	assert.False(t, jq.isFirstRunDelayed(check.ID("0")))
+
+	select {
+	case c := <-pipe:
+		assert.Failf(t, "unexpected first run", "check %s", c.ID())
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -6,23 +6,27 @@
 package collector
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
 )
 
 // collectorErrors holds the error objects
 type collectorErrors struct {
-	loader map[string]map[string]string // check Name -> loader -> error
-	run    map[check.ID]string          // check ID -> error
-	m      sync.RWMutex
+	loader     map[string]map[string]string         // check Name -> loader -> error
+	run        map[check.ID]string                  // check ID -> error
+	validation map[string]map[string][]schema.Error // check Name -> config digest -> errors
+	m          sync.RWMutex
 }
 
 // newCollectorErrors returns an instance holding autoconfig errors stats
 func newCollectorErrors() *collectorErrors {
 	return &collectorErrors{
-		loader: make(map[string]map[string]string),
-		run:    make(map[check.ID]string),
+		loader:     make(map[string]map[string]string),
+		run:        make(map[check.ID]string),
+		validation: make(map[string]map[string][]schema.Error),
 	}
 }
 
@@ -76,3 +80,54 @@ func (ce *collectorErrors) getRunErrors() map[check.ID]string {
 
 	return runCopy
 }
+
+// setValidationErrors replaces the validation errors of a check configuration
+func (ce *collectorErrors) setValidationErrors(checkName string, digest string, errs []schema.Error) {
+	ce.m.Lock()
+	defer ce.m.Unlock()
+
+	if len(errs) == 0 {
+		ce.removeValidationErrorsUnlocked(checkName, digest)
+		return
+	}
+	if _, found := ce.validation[checkName]; !found {
+		ce.validation[checkName] = make(map[string][]schema.Error)
+	}
+	ce.validation[checkName][digest] = errs
+}
+
+// removeValidationErrors removes the validation errors of a check configuration (usually when unscheduled)
+func (ce *collectorErrors) removeValidationErrors(checkName string, digest string) {
+	ce.m.Lock()
+	defer ce.m.Unlock()
+
+	ce.removeValidationErrorsUnlocked(checkName, digest)
+}
+
+func (ce *collectorErrors) removeValidationErrorsUnlocked(checkName string, digest string) {
+	delete(ce.validation[checkName], digest)
+	if len(ce.validation[checkName]) == 0 {
+		delete(ce.validation, checkName)
+	}
+}
+
+// getValidationErrors returns the validation errors of every check configuration, by check name
+func (ce *collectorErrors) getValidationErrors() map[string][]schema.Error {
+	ce.m.RLock()
+	defer ce.m.RUnlock()
+
+	errorsCopy := make(map[string][]schema.Error)
+	for checkName, configs := range ce.validation {
+		digests := make([]string, 0, len(configs))
+		for digest := range configs {
+			digests = append(digests, digest)
+		}
+		sort.Strings(digests)
+
+		for _, digest := range digests {
+			errorsCopy[checkName] = append(errorsCopy[checkName], configs[digest]...)
+		}
+	}
+
+	return errorsCopy
+}
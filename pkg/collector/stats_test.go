@@ -9,6 +9,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
 )
 
 func TestNewCollectorErrors(t *testing.T) {
@@ -40,3 +42,22 @@ func TestGetLoaderErrors(t *testing.T) {
 	errs := ce.getLoaderErrors()
 	assert.Len(t, errs, 1)
 }
+
+func TestValidationErrors(t *testing.T) {
+	ce := newCollectorErrors()
+	ce.setValidationErrors("aCheck", "digest1", []schema.Error{{Section: "instances[0]", Key: "a", Message: "first"}})
+	ce.setValidationErrors("aCheck", "digest2", []schema.Error{{Section: "instances[0]", Key: "b", Message: "second"}})
+	ce.setValidationErrors("anotherCheck", "digest3", nil)
+
+	errs := ce.getValidationErrors()
+	assert.Len(t, errs, 1)
+	assert.Len(t, errs["aCheck"], 2)
+	assert.Equal(t, "first", errs["aCheck"][0].Message)
+
+	// revalidated configs replace their errors
+	ce.setValidationErrors("aCheck", "digest1", nil)
+	assert.Len(t, ce.getValidationErrors()["aCheck"], 1)
+
+	ce.removeValidationErrors("aCheck", "digest2")
+	assert.Empty(t, ce.getValidationErrors())
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner/expvars"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner/tracker"
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -28,7 +29,8 @@ const (
 )
 
 // Worker is an object that encapsulates the logic to manage a loop of processing
-// checks over the provided `PendingCheckChan`
+// checks over the provided `PendingCheckChan` and `CriticalChecksChan`, critical
+// checks being processed first
 type Worker struct {
 	ID   int
 	Name string
@@ -36,6 +38,7 @@ type Worker struct {
 	checksTracker           *tracker.RunningChecksTracker
 	getDefaultSenderFunc    func() (aggregator.Sender, error)
 	pendingChecksChan       chan check.Check
+	criticalChecksChan      chan check.Check
 	runnerID                int
 	shouldAddCheckStatsFunc func(id check.ID) bool
 	utilizationTracker      UtilizationTracker
@@ -46,6 +49,7 @@ func NewWorker(
 	runnerID int,
 	ID int,
 	pendingChecksChan chan check.Check,
+	criticalChecksChan chan check.Check,
 	checksTracker *tracker.RunningChecksTracker,
 	shouldAddCheckStatsFunc func(id check.ID) bool,
 ) (*Worker, error) {
@@ -54,7 +58,8 @@ func NewWorker(
 		return nil, fmt.Errorf("worker cannot initialize using a nil checksTracker")
 	}
 
-	if pendingChecksChan == nil {
+	// a worker dedicated to critical checks has no pendingChecksChan
+	if pendingChecksChan == nil && criticalChecksChan == nil {
 		return nil, fmt.Errorf("worker cannot initialize using a nil pendingChecksChan")
 	}
 
@@ -66,6 +71,7 @@ func NewWorker(
 		runnerID,
 		ID,
 		pendingChecksChan,
+		criticalChecksChan,
 		checksTracker,
 		shouldAddCheckStatsFunc,
 		aggregator.GetDefaultSender,
@@ -81,6 +87,7 @@ func newWorkerWithOptions(
 	runnerID int,
 	ID int,
 	pendingChecksChan chan check.Check,
+	criticalChecksChan chan check.Check,
 	checksTracker *tracker.RunningChecksTracker,
 	shouldAddCheckStatsFunc func(id check.ID) bool,
 	getDefaultSenderFunc func() (aggregator.Sender, error),
@@ -103,6 +110,7 @@ func newWorkerWithOptions(
 		Name:                    workerName,
 		checksTracker:           checksTracker,
 		pendingChecksChan:       pendingChecksChan,
+		criticalChecksChan:      criticalChecksChan,
 		runnerID:                runnerID,
 		shouldAddCheckStatsFunc: shouldAddCheckStatsFunc,
 		getDefaultSenderFunc:    getDefaultSenderFunc,
@@ -123,7 +131,12 @@ func (w *Worker) Run() {
 		}
 	}()
 
-	for check := range w.pendingChecksChan {
+	for {
+		check, ok := w.nextCheck()
+		if !ok {
+			break
+		}
+
 		checkLogger := CheckLogger{Check: check}
 		longRunning := check.Interval() == 0
 
@@ -143,14 +156,20 @@ func (w *Worker) Run() {
 		w.utilizationTracker.CheckStarted(longRunning)
 
 		// Run the check
-		var checkErr error
-		checkErr = check.Run()
+		timedOut, checkErr := w.runCheck(check, longRunning, checkStartTime)
 
 		w.utilizationTracker.CheckFinished()
 
-		expvars.DeleteRunningStats(check.ID())
+		if !timedOut {
+			expvars.DeleteRunningStats(check.ID())
+		}
 
-		checkWarnings := check.GetWarnings()
+		// The warnings and stats of a run that timed out are collected once it actually
+		// returns, reading them now would race with the run
+		var checkWarnings []error
+		if !timedOut {
+			checkWarnings = check.GetWarnings()
+		}
 
 		// Use the default sender for the service checks
 		sender, err := w.getDefaultSenderFunc()
@@ -178,14 +197,17 @@ func (w *Worker) Run() {
 			sender.Commit()
 		}
 
-		// Remove the check from the running list
-		w.checksTracker.DeleteCheck(check.ID())
+		// Remove the check from the running list, checks that timed out are removed
+		// once their run actually returns
+		if !timedOut {
+			w.checksTracker.DeleteCheck(check.ID())
+		}
 
 		// Publish statistics about this run
 		expvars.AddRunningCheckCount(-1)
 		expvars.AddRunsCount(1)
 
-		if !longRunning || len(checkWarnings) != 0 || checkErr != nil {
+		if !timedOut && (!longRunning || len(checkWarnings) != 0 || checkErr != nil) {
 			// If the scheduler isn't assigned (it should), just add stats
 			// otherwise only do so if the check is in the scheduler
 			if w.shouldAddCheckStatsFunc(check.ID()) {
@@ -199,3 +221,92 @@ func (w *Worker) Run() {
 
 	log.Debugf("Runner %d, worker %d: Finished processing checks.", w.runnerID, w.ID)
 }
+
+// nextCheck waits for the next check to process, critical checks first. It returns false
+// once the channels are closed.
+func (w *Worker) nextCheck() (check.Check, bool) {
+	pending, critical := w.pendingChecksChan, w.criticalChecksChan
+
+	select {
+	case c, ok := <-critical:
+		if ok {
+			return c, true
+		}
+		critical = nil
+	default:
+	}
+
+	// receiving from a nil channel blocks, closed channels are set to nil
+	for pending != nil || critical != nil {
+		select {
+		case c, ok := <-critical:
+			if ok {
+				return c, true
+			}
+			critical = nil
+		case c, ok := <-pending:
+			if ok {
+				return c, true
+			}
+			pending = nil
+		}
+	}
+	return nil, false
+}
+
+// runCheck runs a check within its run timeout and returns whether the run timed out.
+// Checks implementing check.ContextCheck are cancelled when they time out, others can't
+// be interrupted and keep running in the background until they return. The stats of
+// a run that timed out are added once it returns.
+func (w *Worker) runCheck(c check.Check, longRunning bool, startTime time.Time) (bool, error) {
+	timeout := check.GetSchedulingOptions(c).RunTimeout
+	if timeout == 0 {
+		timeout = time.Duration(config.Datadog.GetInt("check_run_timeout")) * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	run := func() error {
+		if cc, ok := c.(check.ContextCheck); ok {
+			return cc.RunWithContext(ctx)
+		}
+		return c.Run()
+	}
+
+	// long running checks are not expected to return
+	if longRunning || timeout <= 0 {
+		return false, run()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- run()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return false, err
+	case <-timer.C:
+		if _, ok := c.(check.ContextCheck); ok {
+			log.Warnf("Check %s exceeded its run timeout of %s, cancelling it", c, timeout)
+		} else {
+			log.Warnf("Check %s exceeded its run timeout of %s, it can't be cancelled and keeps running in the background", c, timeout)
+		}
+
+		timeoutErr := fmt.Errorf("%w after %s", check.ErrRunTimeout, timeout)
+		go func() {
+			<-done
+			if w.shouldAddCheckStatsFunc(c.ID()) {
+				sStats, _ := c.GetSenderStats()
+				expvars.AddCheckStats(c, time.Since(startTime), timeoutErr, c.GetWarnings(), sStats)
+			}
+			expvars.DeleteRunningStats(c.ID())
+			w.checksTracker.DeleteCheck(c.ID())
+		}()
+		return true, timeoutErr
+	}
+}
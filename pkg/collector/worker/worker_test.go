@@ -6,6 +6,7 @@
 package worker
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -116,16 +117,16 @@ func TestWorkerInit(t *testing.T) {
 	pendingChecksChan := make(chan check.Check, 1)
 	mockShouldAddStatsFunc := func(id check.ID) bool { return true }
 
-	_, err := NewWorker(1, 2, nil, checksTracker, mockShouldAddStatsFunc)
+	_, err := NewWorker(1, 2, nil, nil, checksTracker, mockShouldAddStatsFunc)
 	require.NotNil(t, err)
 
-	_, err = NewWorker(1, 2, pendingChecksChan, nil, mockShouldAddStatsFunc)
+	_, err = NewWorker(1, 2, pendingChecksChan, nil, nil, mockShouldAddStatsFunc)
 	require.NotNil(t, err)
 
-	_, err = NewWorker(1, 2, pendingChecksChan, checksTracker, nil)
+	_, err = NewWorker(1, 2, pendingChecksChan, nil, checksTracker, nil)
 	require.NotNil(t, err)
 
-	worker, err := NewWorker(1, 2, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	worker, err := NewWorker(1, 2, pendingChecksChan, nil, checksTracker, mockShouldAddStatsFunc)
 	assert.Nil(t, err)
 	assert.NotNil(t, worker)
 }
@@ -144,7 +145,7 @@ func TestWorkerInitExpvarStats(t *testing.T) {
 		go func(idx int) {
 			defer wg.Done()
 
-			worker, err := NewWorker(1, idx, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+			worker, err := NewWorker(1, idx, pendingChecksChan, nil, checksTracker, mockShouldAddStatsFunc)
 			assert.Nil(t, err)
 
 			worker.Run()
@@ -166,7 +167,7 @@ func TestWorkerName(t *testing.T) {
 
 	for _, id := range []int{1, 100, 500} {
 		expectedName := fmt.Sprintf("worker_%d", id)
-		worker, err := NewWorker(1, id, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+		worker, err := NewWorker(1, id, pendingChecksChan, nil, checksTracker, mockShouldAddStatsFunc)
 		assert.Nil(t, err)
 		assert.NotNil(t, worker)
 
@@ -218,7 +219,7 @@ func TestWorker(t *testing.T) {
 	pendingChecksChan <- testCheck1
 	close(pendingChecksChan)
 
-	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	worker, err := NewWorker(100, 200, pendingChecksChan, nil, checksTracker, mockShouldAddStatsFunc)
 	require.Nil(t, err)
 
 	wg.Add(1)
@@ -270,6 +271,7 @@ func TestWorkerUtilizationExpvars(t *testing.T) {
 		1,
 		2,
 		pendingChecksChan,
+		nil,
 		checksTracker,
 		mockShouldAddStatsFunc,
 		func() (aggregator.Sender, error) { return nil, nil },
@@ -344,7 +346,7 @@ func TestWorkerErrorAndWarningHandling(t *testing.T) {
 	}
 	close(pendingChecksChan)
 
-	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	worker, err := NewWorker(100, 200, pendingChecksChan, nil, checksTracker, mockShouldAddStatsFunc)
 	require.Nil(t, err)
 	AssertAsyncWorkerCount(t, 0)
 
@@ -389,7 +391,7 @@ func TestWorkerConcurrentCheckScheduling(t *testing.T) {
 	pendingChecksChan <- testCheck
 	close(pendingChecksChan)
 
-	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, mockShouldAddStatsFunc)
+	worker, err := NewWorker(100, 200, pendingChecksChan, nil, checksTracker, mockShouldAddStatsFunc)
 	require.Nil(t, err)
 
 	worker.Run()
@@ -443,7 +445,7 @@ func TestWorkerStatsAddition(t *testing.T) {
 	pendingChecksChan <- squelchedStatsCheck
 	close(pendingChecksChan)
 
-	worker, err := NewWorker(100, 200, pendingChecksChan, checksTracker, shouldAddStatsFunc)
+	worker, err := NewWorker(100, 200, pendingChecksChan, nil, checksTracker, shouldAddStatsFunc)
 	require.Nil(t, err)
 
 	worker.Run()
@@ -488,6 +490,7 @@ func TestWorkerServiceCheckSending(t *testing.T) {
 		100,
 		200,
 		pendingChecksChan,
+		nil,
 		checksTracker,
 		mockShouldAddStatsFunc,
 		func() (aggregator.Sender, error) {
@@ -559,6 +562,7 @@ func TestWorkerSenderNil(t *testing.T) {
 		100,
 		200,
 		pendingChecksChan,
+		nil,
 		checksTracker,
 		mockShouldAddStatsFunc,
 		func() (aggregator.Sender, error) {
@@ -599,6 +603,7 @@ func TestWorkerServiceCheckSendingLongRunningTasks(t *testing.T) {
 		100,
 		200,
 		pendingChecksChan,
+		nil,
 		checksTracker,
 		mockShouldAddStatsFunc,
 		func() (aggregator.Sender, error) {
@@ -617,3 +622,121 @@ func TestWorkerServiceCheckSendingLongRunningTasks(t *testing.T) {
 	mockSender.AssertNumberOfCalls(t, "Commit", 0)
 	mockSender.AssertNumberOfCalls(t, "ServiceCheck", 0)
 }
+
+type slowCheck struct {
+	testCheck
+	timeout time.Duration
+}
+
+func (c *slowCheck) SchedulingOptions() check.SchedulingOptions {
+	return check.SchedulingOptions{RunTimeout: c.timeout}
+}
+
+type cancellableCheck struct {
+	slowCheck
+	cancelled chan struct{}
+}
+
+func (c *cancellableCheck) RunWithContext(ctx context.Context) error {
+	<-ctx.Done()
+	close(c.cancelled)
+	return ctx.Err()
+}
+
+func TestWorkerCriticalChecksFirst(t *testing.T) {
+	expvars.Reset()
+	config.Datadog.Set("hostname", "myhost")
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	criticalChecksChan := make(chan check.Check, 10)
+	mockShouldAddStatsFunc := func(id check.ID) bool { return true }
+
+	var m sync.Mutex
+	var order []string
+	runFunc := func(id check.ID) {
+		m.Lock()
+		defer m.Unlock()
+		order = append(order, string(id))
+	}
+
+	pendingChecksChan <- newCheck(t, "normal:1", false, runFunc)
+	pendingChecksChan <- newCheck(t, "normal:2", false, runFunc)
+	criticalChecksChan <- newCheck(t, "critical:1", false, runFunc)
+	close(pendingChecksChan)
+	close(criticalChecksChan)
+
+	worker, err := NewWorker(100, 200, pendingChecksChan, criticalChecksChan, checksTracker, mockShouldAddStatsFunc)
+	require.Nil(t, err)
+
+	worker.Run()
+
+	require.Len(t, order, 3)
+	assert.Equal(t, "critical:1", order[0])
+}
+
+func TestWorkerRunTimeout(t *testing.T) {
+	expvars.Reset()
+	config.Datadog.Set("hostname", "myhost")
+
+	checksTracker := tracker.NewRunningChecksTracker()
+	pendingChecksChan := make(chan check.Check, 10)
+	mockShouldAddStatsFunc := func(id check.ID) bool { return true }
+
+	release := make(chan struct{})
+	blocking := &slowCheck{
+		testCheck: testCheck{t: t, id: "blocking:1", runFunc: func(check.ID) { <-release }},
+		timeout:   50 * time.Millisecond,
+	}
+	cancellable := &cancellableCheck{
+		slowCheck: slowCheck{testCheck: testCheck{t: t, id: "cancellable:1"}, timeout: 50 * time.Millisecond},
+		cancelled: make(chan struct{}),
+	}
+
+	pendingChecksChan <- blocking
+	pendingChecksChan <- cancellable
+	close(pendingChecksChan)
+
+	worker, err := NewWorker(100, 200, pendingChecksChan, nil, checksTracker, mockShouldAddStatsFunc)
+	require.Nil(t, err)
+
+	done := make(chan struct{})
+	go func() {
+		worker.Run()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "the worker was blocked by a check exceeding its timeout")
+	}
+
+	select {
+	case <-cancellable.cancelled:
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "the run of the check was not cancelled")
+	}
+
+	// the stats of a run that timed out are added once it returns
+	assertTimedOut := func(c check.Check) {
+		var stats *check.Stats
+		require.Eventually(t, func() bool {
+			var found bool
+			stats, found = expvars.CheckStats(c.ID())
+			return found
+		}, 5*time.Second, 10*time.Millisecond)
+		assert.Equal(t, uint64(1), stats.TotalTimeouts)
+		assert.Contains(t, stats.LastError, "check run timed out after 50ms")
+	}
+	assertTimedOut(cancellable)
+	_, found := expvars.CheckStats(blocking.ID())
+	assert.False(t, found)
+
+	// checks that timed out are tracked as running until their run returns
+	assert.False(t, checksTracker.AddCheck(blocking))
+	close(release)
+	assertTimedOut(blocking)
+	assert.Eventually(t, func() bool { _, running := checksTracker.Check(blocking.ID()); return !running }, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool { _, running := checksTracker.Check(cancellable.ID()); return !running }, 5*time.Second, 10*time.Millisecond)
+}
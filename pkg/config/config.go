@@ -226,6 +226,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("enable_metadata_collection", true)
 	config.BindEnvAndSetDefault("enable_gohai", true)
 	config.BindEnvAndSetDefault("check_runners", int64(4))
+	config.BindEnvAndSetDefault("check_runners_critical", int64(1))
+	config.BindEnvAndSetDefault("check_run_timeout", 0)
+	config.BindEnvAndSetDefault("check_start_jitter", 0)
+	config.BindEnvAndSetDefault("check_run_history_size", 20)
 	config.BindEnvAndSetDefault("auth_token_file_path", "")
 	config.BindEnv("bind_host")
 	config.BindEnvAndSetDefault("ipc_address", "localhost")
@@ -372,6 +376,12 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("histogram_percentiles", []string{"0.95"})
 	config.BindEnvAndSetDefault("aggregator_stop_timeout", 2)
 	config.BindEnvAndSetDefault("aggregator_buffer_size", 100)
+	config.BindEnvAndSetDefault("tag_cardinality_budget.enabled", false)
+	config.BindEnvAndSetDefault("tag_cardinality_budget.max_values_per_key", 1000)
+	config.BindEnvAndSetDefault("tag_cardinality_budget.key_limits", map[string]int{})
+	config.BindEnvAndSetDefault("tag_cardinality_budget.action", "drop")
+	config.BindEnvAndSetDefault("tag_cardinality_budget.window", 3600) // in seconds
+	config.BindEnvAndSetDefault("tag_cardinality_budget.hash_buckets", 16)
 	config.BindEnvAndSetDefault("basic_telemetry_add_container_tags", false) // configure adding the agent container tags to the basic agent telemetry metrics (e.g. `datadog.agent.running`)
 	// Serializer
 	config.BindEnvAndSetDefault("enable_stream_payload_serialization", true)
@@ -527,6 +537,14 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("containerd_namespace", "k8s.io")
 	config.BindEnvAndSetDefault("container_env_as_tags", map[string]string{})
 	config.BindEnvAndSetDefault("container_labels_as_tags", map[string]string{})
+	config.BindEnv("tag_rules") // User-defined tag extraction rules evaluated on the containers and pods
+	config.SetEnvKeyTransformer("tag_rules", func(in string) interface{} {
+		var rules []map[string]interface{}
+		if err := json.Unmarshal([]byte(in), &rules); err != nil {
+			log.Warnf(`"tag_rules" can not be parsed: %v`, err)
+		}
+		return rules
+	})
 
 	// Kubernetes
 	config.BindEnvAndSetDefault("kubernetes_kubelet_host", "")
@@ -555,6 +573,7 @@ func InitConfig(config Config) {
 
 	config.BindEnvAndSetDefault("prometheus_scrape.enabled", false)           // Enables the prometheus config provider
 	config.BindEnvAndSetDefault("prometheus_scrape.service_endpoints", false) // Enables Service Endpoints checks in the prometheus config provider
+	config.BindEnvAndSetDefault("prometheus_scrape.use_core_check", false)    // Schedules the openmetrics_core check instead of the openmetrics Python check
 	config.BindEnv("prometheus_scrape.checks")                                // Defines any extra prometheus/openmetrics check configurations to be handled by the prometheus config provider
 	config.SetEnvKeyTransformer("prometheus_scrape.checks", func(in string) interface{} {
 		var promChecks []*types.PrometheusCheck
@@ -564,6 +583,20 @@ func InitConfig(config Config) {
 		return promChecks
 	})
 
+	// Workloadmeta processes and systemd units of the host
+	config.BindEnvAndSetDefault("workloadmeta.process_collection.enabled", false)
+	config.BindEnvAndSetDefault("workloadmeta.process_collection.interval", 30) // in seconds
+	config.BindEnvAndSetDefault("workloadmeta.process_collection.include_containerized", false)
+	config.BindEnvAndSetDefault("workloadmeta.systemd_collection.enabled", false)
+	config.BindEnvAndSetDefault("workloadmeta.systemd_collection.private_socket", "")
+
+	// Workloadmeta snapshots, rehydrating the store when the agent restarts
+	config.BindEnvAndSetDefault("workloadmeta.snapshot.enabled", false)
+	config.BindEnvAndSetDefault("workloadmeta.snapshot.path", "")
+	config.BindEnvAndSetDefault("workloadmeta.snapshot.interval", 60)  // in seconds
+	config.BindEnvAndSetDefault("workloadmeta.snapshot.max_age", 3600) // in seconds
+	config.BindEnvAndSetDefault("workloadmeta.snapshot.expire", 120)   // in seconds
+
 	// SNMP
 	config.SetKnown("snmp_listener.discovery_interval")
 	config.SetKnown("snmp_listener.allowed_failures")
@@ -810,6 +843,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("kubernetes_informers_resync_period", 60*5)               // value in seconds. Default to 5 minutes
 	config.BindEnvAndSetDefault("external_metrics_provider.config", map[string]string{})  // list of options that can be used to configure the external metrics server
 	config.BindEnvAndSetDefault("external_metrics_provider.local_copy_refresh_rate", 30)  // value in seconds
+	config.BindEnvAndSetDefault("external_metrics_provider.grace_period", 0)              // value in seconds. Serve the last known values when the backend fails, for this duration after they become too old
+	config.BindEnvAndSetDefault("external_metrics_provider.backend", "datadog")           // Backend to retrieve the DatadogMetrics values from: datadog or prometheus
+	config.BindEnvAndSetDefault("external_metrics_provider.prometheus.url", "")           // URL of the Prometheus-compatible query API, when using the prometheus backend
+	config.BindEnvAndSetDefault("external_metrics_provider.prometheus.timeout", 10)       // value in seconds. Timeout of each refresh of all the Prometheus queries
 	// Cluster check Autodiscovery
 	config.BindEnvAndSetDefault("cluster_checks.enabled", false)
 	config.BindEnvAndSetDefault("cluster_checks.node_expiration_timeout", 30) // value in seconds
@@ -818,6 +855,15 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("cluster_checks.extra_tags", []string{})
 	config.BindEnvAndSetDefault("cluster_checks.advanced_dispatching_enabled", false)
 	config.BindEnvAndSetDefault("cluster_checks.clc_runners_port", 5005)
+	config.BindEnvAndSetDefault("cluster_checks.max_checks_per_runner", 0) // 0 means no limit
+	config.BindEnv("cluster_checks.dispatching_constraints")               // Scheduling constraints of the cluster checks, by check name
+	config.SetEnvKeyTransformer("cluster_checks.dispatching_constraints", func(in string) interface{} {
+		var constraints map[string]interface{}
+		if err := json.Unmarshal([]byte(in), &constraints); err != nil {
+			log.Warnf(`"cluster_checks.dispatching_constraints" can not be parsed: %v`, err)
+		}
+		return constraints
+	})
 	// Cluster check runner
 	config.BindEnvAndSetDefault("clc_runner_enabled", false)
 	config.BindEnvAndSetDefault("clc_runner_id", "")
@@ -837,10 +883,18 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("admission_controller.webhook_name", "datadog-webhook")
 	config.BindEnvAndSetDefault("admission_controller.inject_config.enabled", true)
 	config.BindEnvAndSetDefault("admission_controller.inject_config.endpoint", "/injectconfig")
+	config.BindEnvAndSetDefault("admission_controller.inject_config.mode", "hostip") // possible values: hostip / service / socket
+	config.BindEnvAndSetDefault("admission_controller.inject_config.local_service_name", "datadog")
+	config.BindEnvAndSetDefault("admission_controller.inject_config.trace_agent_socket", "/var/run/datadog/apm.socket")
+	config.BindEnvAndSetDefault("admission_controller.inject_config.dogstatsd_socket", "/var/run/datadog/dsd.socket")
 	config.BindEnvAndSetDefault("admission_controller.inject_tags.enabled", true)
 	config.BindEnvAndSetDefault("admission_controller.inject_tags.endpoint", "/injecttags")
 	config.BindEnvAndSetDefault("admission_controller.pod_owners_cache_validity", 10) // in minutes
 	config.BindEnvAndSetDefault("admission_controller.namespace_selector_fallback", false)
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.enabled", false)
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.endpoint", "/injectlib")
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.container_registry", "gcr.io/datadoghq")
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.enabled_namespaces", []string{}) // namespaces where the libraries are injected, in addition to the labelled ones
 
 	// Telemetry
 	// Enable telemetry metrics on the internals of the Agent.
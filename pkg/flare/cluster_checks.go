@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/fatih/color"
@@ -70,6 +71,9 @@ func GetClusterChecks(w io.Writer, checkName string) error {
 		fmt.Fprintln(w, "")
 	}
 
+	// Print dispatching constraints
+	printDispatchingConstraints(w, cr)
+
 	// Print dangling configs
 	if len(cr.Dangling) > 0 {
 		fmt.Fprintln(w, fmt.Sprintf("=== %s configurations ===", color.RedString("Unassigned")))
@@ -88,9 +92,9 @@ func GetClusterChecks(w io.Writer, checkName string) error {
 	fmt.Fprintln(w, fmt.Sprintf("=== %d agents reporting ===", len(cr.Nodes)))
 	sort.Slice(cr.Nodes, func(i, j int) bool { return cr.Nodes[i].Name < cr.Nodes[j].Name })
 	table := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(table, "\nName\tRunning checks")
+	fmt.Fprintln(table, "\nName\tRunning checks\tZone")
 	for _, n := range cr.Nodes {
-		fmt.Fprintf(table, "%s\t%d\n", n.Name, len(n.Configs))
+		fmt.Fprintf(table, "%s\t%d\t%s\n", n.Name, len(n.Configs), n.Zone)
 	}
 	table.Flush()
 
@@ -108,6 +112,50 @@ func GetClusterChecks(w io.Writer, checkName string) error {
 	return nil
 }
 
+// printDispatchingConstraints prints the constraints applied when dispatching the checks
+func printDispatchingConstraints(w io.Writer, cr types.StateResponse) {
+	if len(cr.Constraints) == 0 && cr.MaxChecksPerRunner == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, fmt.Sprintf("=== %s ===", color.BlueString("Dispatching constraints")))
+	if cr.MaxChecksPerRunner > 0 {
+		fmt.Fprintf(w, "Max checks per runner: %d\n", cr.MaxChecksPerRunner)
+	}
+
+	names := make([]string, 0, len(cr.Constraints))
+	for name := range cr.Constraints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		c := cr.Constraints[name]
+		fmt.Fprintf(w, "%s:\n", color.HiGreenString(name))
+		if len(c.NodeSelector) > 0 {
+			selector := make([]string, 0, len(c.NodeSelector))
+			for key, value := range c.NodeSelector {
+				selector = append(selector, fmt.Sprintf("%s=%s", key, value))
+			}
+			sort.Strings(selector)
+			fmt.Fprintf(w, "  Node selector: %s\n", strings.Join(selector, ", "))
+		}
+		if len(c.Affinity) > 0 {
+			fmt.Fprintf(w, "  Affinity: %s\n", strings.Join(c.Affinity, ", "))
+		}
+		if len(c.AntiAffinity) > 0 {
+			fmt.Fprintf(w, "  Anti-affinity: %s\n", strings.Join(c.AntiAffinity, ", "))
+		}
+		if c.SpreadZones {
+			fmt.Fprintln(w, "  Spread across zones")
+		}
+		if c.Weight > 0 {
+			fmt.Fprintf(w, "  Weight: %d\n", c.Weight)
+		}
+	}
+	fmt.Fprintln(w, "")
+}
+
 // GetEndpointsChecks dumps the endpointschecks dispatching state to the writer
 func GetEndpointsChecks(w io.Writer, checkName string) error {
 	if !endpointschecksEnabled() {
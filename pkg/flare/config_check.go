@@ -7,8 +7,10 @@ package flare
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/fatih/color"
 
@@ -16,14 +18,20 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/schema"
 	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
+// ErrInvalidConfigs is returned by GetConfigCheck when some of the configurations are invalid
+var ErrInvalidConfigs = errors.New("invalid check configurations")
+
 // configCheckURL contains the Agent API endpoint URL exposing the loaded checks
 var configCheckURL string
 
-// GetConfigCheck dump all loaded configurations to the writer
-func GetConfigCheck(w io.Writer, withDebug bool) error {
+// GetConfigCheck dump all loaded configurations to the writer, with withValidation the
+// validation errors of the configurations are also printed and an error is returned
+// if some of the configurations are invalid
+func GetConfigCheck(w io.Writer, withDebug bool, withValidation bool) error {
 	if w != color.Output {
 		color.NoColor = true
 	}
@@ -67,12 +75,21 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 		PrintConfig(w, c, "")
 	}
 
+	if !withDebug && len(cr.ResolveWarnings) > 0 {
+		fmt.Fprintln(w, fmt.Sprintf("\n%s: templates of %d check(s) could not be resolved, use --verbose to display the errors", color.YellowString("Warning"), len(cr.ResolveWarnings)))
+	}
+
 	if withDebug {
 		if len(cr.ResolveWarnings) > 0 {
 			fmt.Fprintln(w, fmt.Sprintf("\n=== Resolve %s ===", color.YellowString("warnings")))
-			for check, warnings := range cr.ResolveWarnings {
+			checks := make([]string, 0, len(cr.ResolveWarnings))
+			for check := range cr.ResolveWarnings {
+				checks = append(checks, check)
+			}
+			sort.Strings(checks)
+			for _, check := range checks {
 				fmt.Fprintln(w, fmt.Sprintf("\n%s", color.YellowString(check)))
-				for _, warning := range warnings {
+				for _, warning := range cr.ResolveWarnings[check] {
 					fmt.Fprintln(w, fmt.Sprintf("* %s", warning))
 				}
 			}
@@ -89,13 +106,53 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 		}
 	}
 
+	if withValidation {
+		return printValidationErrors(w, cr.ValidationErrors)
+	}
+
+	return nil
+}
+
+// printValidationErrors prints the validation errors of the configurations, it returns
+// an error if some of them have the error severity
+func printValidationErrors(w io.Writer, validationErrors map[string][]schema.Error) error {
+	if len(validationErrors) == 0 {
+		fmt.Fprintln(w, fmt.Sprintf("\n=== Validation: %s ===", color.GreenString("no issue found")))
+		return nil
+	}
+
+	fmt.Fprintln(w, fmt.Sprintf("\n=== Validation %s ===", color.RedString("errors")))
+	checks := make([]string, 0, len(validationErrors))
+	for check := range validationErrors {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+
+	invalid := 0
+	for _, check := range checks {
+		fmt.Fprintln(w, fmt.Sprintf("\n%s", color.YellowString(check)))
+		for _, e := range validationErrors[check] {
+			severity := color.YellowString(string(e.Severity))
+			if e.Severity == schema.SeverityError {
+				severity = color.RedString(string(e.Severity))
+			}
+			fmt.Fprintln(w, fmt.Sprintf("* [%s] %s (%s, %s)", severity, e.Error(), e.Kind, e.Source))
+		}
+		if schema.HasErrors(validationErrors[check]) {
+			invalid++
+		}
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%w: the configuration of %d check(s) is invalid", ErrInvalidConfigs, invalid)
+	}
 	return nil
 }
 
 // GetClusterAgentConfigCheck proxies GetConfigCheck overidding the URL
-func GetClusterAgentConfigCheck(w io.Writer, withDebug bool) error {
+func GetClusterAgentConfigCheck(w io.Writer, withDebug bool, withValidation bool) error {
 	configCheckURL = fmt.Sprintf("https://localhost:%v/config-check", config.Datadog.GetInt("cluster_agent.cmd_port"))
-	return GetConfigCheck(w, withDebug)
+	return GetConfigCheck(w, withDebug, withValidation)
 }
 
 // PrintConfig prints a human-readable representation of a configuration
@@ -12,11 +12,17 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/kubestatemetrics/customresource"
 	"github.com/DataDog/datadog-agent/pkg/kubestatemetrics/store"
 
 	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	apiwatch "k8s.io/apimachinery/pkg/watch"
 	vpaclientset "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/client/clientset/versioned"
+	"k8s.io/client-go/dynamic"
 	clientset "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/cache"
 	ksmbuild "k8s.io/kube-state-metrics/v2/pkg/builder"
@@ -34,6 +40,7 @@ type Builder struct {
 
 	kubeClient    clientset.Interface
 	vpaClient     vpaclientset.Interface
+	dynamicClient dynamic.Interface
 	namespaces    options.NamespaceList
 	ctx           context.Context
 	allowDenyList ksmtypes.AllowDenyLister
@@ -83,6 +90,11 @@ func (b *Builder) WithVPAClient(c vpaclientset.Interface) {
 	b.ksmBuilder.WithVPAClient(c)
 }
 
+// WithDynamicClient sets the dynamicClient property of a Builder so that the custom resources can be queried.
+func (b *Builder) WithDynamicClient(c dynamic.Interface) {
+	b.dynamicClient = c
+}
+
 // WithMetrics sets the metrics property of a Builder.
 func (b *Builder) WithMetrics(r prometheus.Registerer) {
 	b.ksmBuilder.WithMetrics(r)
@@ -127,6 +139,31 @@ func (b *Builder) BuildStores() [][]cache.Store {
 	return b.ksmBuilder.BuildStores()
 }
 
+// BuildCustomResourceStores initializes the stores of the given custom resources.
+// It returns metric cache stores.
+func (b *Builder) BuildCustomResourceStores(resources []customresource.Resource) [][]cache.Store {
+	allStores := make([][]cache.Store, 0, len(resources))
+	for _, resource := range resources {
+		gvr := resource.GroupVersionResource()
+		expectedType := &unstructured.Unstructured{}
+		expectedType.SetGroupVersionKind(resource.GroupVersionKind())
+
+		stores := b.GenerateStores(resource.FamilyGenerators(), expectedType, func(_ clientset.Interface, ns string) cache.ListerWatcher {
+			return &cache.ListWatch{
+				ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+					return b.dynamicClient.Resource(gvr).Namespace(ns).List(b.ctx, opts)
+				},
+				WatchFunc: func(opts metav1.ListOptions) (apiwatch.Interface, error) {
+					return b.dynamicClient.Resource(gvr).Namespace(ns).Watch(b.ctx, opts)
+				},
+			}
+		})
+		allStores = append(allStores, stores)
+	}
+
+	return allStores
+}
+
 // WithResync is used if a resync period is configured
 func (b *Builder) WithResync(r time.Duration) {
 	b.resync = r
@@ -0,0 +1,396 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+
+// Package customresource generates kube-state-metrics metric families from
+// custom resources, based on user-defined metric definitions.
+package customresource
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+	generator "k8s.io/kube-state-metrics/v2/pkg/metric_generator"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var (
+	invalidNameCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+	matchAllCap       = regexp.MustCompile("([a-z0-9])([A-Z])")
+)
+
+// Resource defines the metrics generated from the objects of a custom
+// resource.
+// Example: Report the replicas and the conditions of the Database objects.
+// group: example.com
+// version: v1
+// kind: Database
+// resource: databases
+// metrics:
+//   - name: status.replicas
+//     path: status.replicas
+//   - name: status.condition
+//     path: status.conditions
+//     value_path: status
+//     labels_from_path:
+//       condition: type
+type Resource struct {
+	Group   string `yaml:"group"`
+	Version string `yaml:"version"`
+	Kind    string `yaml:"kind"`
+	// Resource is the plural name of the resource, it defaults to the lower
+	// case kind followed by an "s".
+	Resource string `yaml:"resource"`
+
+	// LabelsFromPath adds labels to all the metrics of the resource, from the
+	// values of the fields at the given paths.
+	LabelsFromPath map[string]string `yaml:"labels_from_path"`
+
+	Metrics []Metric `yaml:"metrics"`
+}
+
+// Metric defines a metric generated from the field of a custom resource.
+type Metric struct {
+	// Name of the metric, its Datadog name is kubernetes_state.<kind>.<name>
+	Name string `yaml:"name"`
+
+	// Path of the field holding the value of the metric. When it holds a
+	// list, a metric is generated for each of its items.
+	Path string `yaml:"path"`
+
+	// ValuePath is the path of the value in each item, when Path holds a list.
+	ValuePath string `yaml:"value_path"`
+
+	// LabelsFromPath adds labels to the metric from the values of the fields
+	// at the given paths, relative to the items when Path holds a list.
+	LabelsFromPath map[string]string `yaml:"labels_from_path"`
+
+	// ValueMapping maps string values to metric values, e.g. to report the
+	// phase of the resource. The values that are not numbers nor booleans
+	// and are not mapped are ignored.
+	ValueMapping map[string]float64 `yaml:"value_mapping"`
+}
+
+// Validate checks that the definition of the resource is complete.
+func (r *Resource) Validate() error {
+	if r.Version == "" || r.Kind == "" {
+		return errors.New("custom resources need a version and a kind")
+	}
+
+	if len(r.Metrics) == 0 {
+		return fmt.Errorf("no metric defined for the custom resource %s", r.Kind)
+	}
+
+	for _, m := range r.Metrics {
+		if m.Name == "" || m.Path == "" {
+			return fmt.Errorf("the metrics of the custom resource %s need a name and a path", r.Kind)
+		}
+	}
+
+	return nil
+}
+
+// GroupVersionResource returns the group, version and resource to list and
+// watch the objects of the custom resource.
+func (r *Resource) GroupVersionResource() schema.GroupVersionResource {
+	resource := r.Resource
+	if resource == "" {
+		resource = strings.ToLower(r.Kind) + "s"
+	}
+
+	return schema.GroupVersionResource{
+		Group:    r.Group,
+		Version:  r.Version,
+		Resource: resource,
+	}
+}
+
+// GroupVersionKind returns the group, version and kind of the objects of the
+// custom resource.
+func (r *Resource) GroupVersionKind() schema.GroupVersionKind {
+	return schema.GroupVersionKind{
+		Group:   r.Group,
+		Version: r.Version,
+		Kind:    r.Kind,
+	}
+}
+
+// LabelName returns the name of the label holding the name of the objects,
+// it is also the resource name in the names of the metric families.
+func (r *Resource) LabelName() string {
+	return strings.ToLower(r.Kind)
+}
+
+// LabelsFamilyName returns the name of the metadata metric family holding the
+// Kubernetes labels of the objects.
+func (r *Resource) LabelsFamilyName() string {
+	return "kube_" + r.LabelName() + "_labels"
+}
+
+// FamilyName returns the name of the metric family of a metric.
+func (r *Resource) FamilyName(m Metric) string {
+	return "kube_" + r.LabelName() + "_" + sanitizeName(m.Name)
+}
+
+// DatadogName returns the name of a metric, without the check prefix.
+func (r *Resource) DatadogName(m Metric) string {
+	return r.LabelName() + "." + m.Name
+}
+
+// FamilyGenerators returns the generators of the metric families of the
+// resource, including the metadata one holding the Kubernetes labels.
+func (r *Resource) FamilyGenerators() []generator.FamilyGenerator {
+	generators := []generator.FamilyGenerator{
+		*generator.NewFamilyGenerator(
+			r.LabelsFamilyName(),
+			fmt.Sprintf("Kubernetes labels converted to Prometheus labels for %s.", r.Kind),
+			metric.Gauge,
+			"",
+			func(obj interface{}) *metric.Family {
+				u, ok := obj.(*unstructured.Unstructured)
+				if !ok {
+					return &metric.Family{}
+				}
+
+				keys, values := r.objectLabels(u)
+				labelKeys, labelValues := kubeLabelsToPrometheusLabels(u.GetLabels())
+
+				return &metric.Family{
+					Metrics: []*metric.Metric{
+						{
+							LabelKeys:   append(keys, labelKeys...),
+							LabelValues: append(values, labelValues...),
+							Value:       1,
+						},
+					},
+				}
+			},
+		),
+	}
+
+	for _, m := range r.Metrics {
+		m := m
+		generators = append(generators, *generator.NewFamilyGenerator(
+			r.FamilyName(m),
+			fmt.Sprintf("%s of %s, from %s.", m.Name, r.Kind, m.Path),
+			metric.Gauge,
+			"",
+			func(obj interface{}) *metric.Family {
+				u, ok := obj.(*unstructured.Unstructured)
+				if !ok {
+					return &metric.Family{}
+				}
+
+				return &metric.Family{
+					Metrics: r.generateMetrics(u, m),
+				}
+			},
+		))
+	}
+
+	return generators
+}
+
+// objectLabels returns the labels common to all the metrics of an object.
+func (r *Resource) objectLabels(u *unstructured.Unstructured) ([]string, []string) {
+	keys := []string{r.LabelName()}
+	values := []string{u.GetName()}
+
+	if namespace := u.GetNamespace(); namespace != "" {
+		keys = append(keys, "namespace")
+		values = append(values, namespace)
+	}
+
+	return appendLabelsFromPath(keys, values, u.Object, r.LabelsFromPath)
+}
+
+func (r *Resource) generateMetrics(u *unstructured.Unstructured, m Metric) []*metric.Metric {
+	field, found := lookup(u.Object, m.Path)
+	if !found {
+		return nil
+	}
+
+	keys, values := r.objectLabels(u)
+
+	items, isList := field.([]interface{})
+	if !isList {
+		value, ok := toValue(field, m.ValueMapping)
+		if !ok {
+			log.Debugf("Cannot convert the value of %s to a metric for %s %s/%s", m.Path, r.Kind, u.GetNamespace(), u.GetName())
+			return nil
+		}
+
+		keys, values = appendLabelsFromPath(keys, values, u.Object, m.LabelsFromPath)
+		return []*metric.Metric{{LabelKeys: keys, LabelValues: values, Value: value}}
+	}
+
+	metrics := make([]*metric.Metric, 0, len(items))
+	for _, item := range items {
+		var itemValue interface{} = item
+		if m.ValuePath != "" {
+			itemValue, found = lookup(item, m.ValuePath)
+			if !found {
+				continue
+			}
+		}
+
+		value, ok := toValue(itemValue, m.ValueMapping)
+		if !ok {
+			log.Debugf("Cannot convert the value of %s to a metric for %s %s/%s", m.Path, r.Kind, u.GetNamespace(), u.GetName())
+			continue
+		}
+
+		itemKeys, itemValues := appendLabelsFromPath(append([]string{}, keys...), append([]string{}, values...), item, m.LabelsFromPath)
+		metrics = append(metrics, &metric.Metric{LabelKeys: itemKeys, LabelValues: itemValues, Value: value})
+	}
+
+	return metrics
+}
+
+// appendLabelsFromPath appends the labels whose values are found at the given
+// paths of obj, in a stable order.
+func appendLabelsFromPath(keys, values []string, obj interface{}, labelsFromPath map[string]string) ([]string, []string) {
+	labels := make([]string, 0, len(labelsFromPath))
+	for label := range labelsFromPath {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		field, found := lookup(obj, labelsFromPath[label])
+		if !found {
+			continue
+		}
+
+		switch v := field.(type) {
+		case map[string]interface{}, []interface{}:
+			continue
+		default:
+			keys = append(keys, label)
+			values = append(values, fmt.Sprint(v))
+		}
+	}
+
+	return keys, values
+}
+
+// lookup returns the field of obj at path. Paths are JSONPath-like, with the
+// keys separated by dots and an optional leading "$." or ".", e.g.
+// "status.replicas". Keys containing dots, like labels and annotations, are
+// written between brackets: "metadata.labels[app.kubernetes.io/name]".
+func lookup(obj interface{}, path string) (interface{}, bool) {
+	current := obj
+	for _, key := range splitPath(path) {
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = fields[key]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, current != nil
+}
+
+func splitPath(path string) []string {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+
+	var keys []string
+	var key strings.Builder
+	inBrackets := false
+	for _, c := range path {
+		switch {
+		case c == '[' && !inBrackets:
+			inBrackets = true
+			if key.Len() > 0 {
+				keys = append(keys, key.String())
+				key.Reset()
+			}
+		case c == ']' && inBrackets:
+			inBrackets = false
+			keys = append(keys, strings.Trim(key.String(), `'"`))
+			key.Reset()
+		case c == '.' && !inBrackets:
+			if key.Len() > 0 {
+				keys = append(keys, key.String())
+				key.Reset()
+			}
+		default:
+			key.WriteRune(c)
+		}
+	}
+
+	if key.Len() > 0 {
+		keys = append(keys, key.String())
+	}
+
+	return keys
+}
+
+// toValue converts a field to a metric value. Numbers are used as-is, booleans
+// and the "True" and "False" strings used by the Kubernetes conditions are
+// converted to 1 and 0.
+func toValue(field interface{}, mapping map[string]float64) (float64, bool) {
+	switch v := field.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		if value, found := mapping[v]; found {
+			return value, true
+		}
+		if b, err := strconv.ParseBool(v); err == nil {
+			return toValue(b, nil)
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f, true
+		}
+	}
+
+	return 0, false
+}
+
+// kubeLabelsToPrometheusLabels converts the Kubernetes labels the same way
+// kube-state-metrics does for the built-in resources, so that they can be
+// used in label joins and labels as tags.
+func kubeLabelsToPrometheusLabels(labels map[string]string) ([]string, []string) {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	labelKeys := make([]string, 0, len(labels))
+	labelValues := make([]string, 0, len(labels))
+	for _, key := range keys {
+		labelKeys = append(labelKeys, "label_"+strings.ToLower(matchAllCap.ReplaceAllString(sanitizeName(key), "${1}_${2}")))
+		labelValues = append(labelValues, labels[key])
+	}
+
+	return labelKeys, labelValues
+}
+
+func sanitizeName(name string) string {
+	return invalidNameCharRE.ReplaceAllString(name, "_")
+}
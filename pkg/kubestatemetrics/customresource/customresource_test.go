@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build kubeapiserver
+
+package customresource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-state-metrics/v2/pkg/metric"
+)
+
+func TestFamilyGenerators(t *testing.T) {
+	resource := Resource{
+		Group:   "example.com",
+		Version: "v1",
+		Kind:    "Database",
+		LabelsFromPath: map[string]string{
+			"engine": "spec.engine",
+		},
+		Metrics: []Metric{
+			{
+				Name: "status.replicas",
+				Path: "status.replicas",
+			},
+			{
+				Name:      "status.condition",
+				Path:      "status.conditions",
+				ValuePath: "status",
+				LabelsFromPath: map[string]string{
+					"condition": "type",
+				},
+			},
+			{
+				Name:         "status.phase",
+				Path:         "status.phase",
+				ValueMapping: map[string]float64{"Running": 1, "Failed": 0},
+			},
+			{
+				Name: "spec.storage",
+				Path: "$.spec.storage[size.gb]",
+			},
+			{
+				Name: "status.missing",
+				Path: "status.missing",
+			},
+		},
+	}
+	require.NoError(t, resource.Validate())
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Database",
+			"metadata": map[string]interface{}{
+				"name":      "orders",
+				"namespace": "default",
+				"labels": map[string]interface{}{
+					"app.kubernetes.io/name": "orders",
+					"teamName":               "payments",
+				},
+			},
+			"spec": map[string]interface{}{
+				"engine": "postgres",
+				"storage": map[string]interface{}{
+					"size.gb": int64(20),
+				},
+			},
+			"status": map[string]interface{}{
+				"replicas": int64(3),
+				"phase":    "Running",
+				"conditions": []interface{}{
+					map[string]interface{}{"type": "Ready", "status": "True"},
+					map[string]interface{}{"type": "Degraded", "status": "False"},
+					map[string]interface{}{"type": "Upgrading", "status": "Unknown"},
+				},
+			},
+		},
+	}
+
+	families := map[string]metric.Family{}
+	for _, g := range resource.FamilyGenerators() {
+		family := g.Generate(obj)
+		families[family.Name] = *family
+	}
+
+	expected := map[string][]*metric.Metric{
+		"kube_database_labels": {
+			{
+				LabelKeys:   []string{"database", "namespace", "engine", "label_app_kubernetes_io_name", "label_team_name"},
+				LabelValues: []string{"orders", "default", "postgres", "orders", "payments"},
+				Value:       1,
+			},
+		},
+		"kube_database_status_replicas": {
+			{
+				LabelKeys:   []string{"database", "namespace", "engine"},
+				LabelValues: []string{"orders", "default", "postgres"},
+				Value:       3,
+			},
+		},
+		"kube_database_status_condition": {
+			{
+				LabelKeys:   []string{"database", "namespace", "engine", "condition"},
+				LabelValues: []string{"orders", "default", "postgres", "Ready"},
+				Value:       1,
+			},
+			{
+				LabelKeys:   []string{"database", "namespace", "engine", "condition"},
+				LabelValues: []string{"orders", "default", "postgres", "Degraded"},
+				Value:       0,
+			},
+		},
+		"kube_database_status_phase": {
+			{
+				LabelKeys:   []string{"database", "namespace", "engine"},
+				LabelValues: []string{"orders", "default", "postgres"},
+				Value:       1,
+			},
+		},
+		"kube_database_spec_storage": {
+			{
+				LabelKeys:   []string{"database", "namespace", "engine"},
+				LabelValues: []string{"orders", "default", "postgres"},
+				Value:       20,
+			},
+		},
+		"kube_database_status_missing": nil,
+	}
+
+	assert.Len(t, families, len(expected))
+	for name, metrics := range expected {
+		family, found := families[name]
+		require.True(t, found, name)
+		assert.Equal(t, metrics, family.Metrics, name)
+	}
+}
+
+func TestResourceNames(t *testing.T) {
+	resource := Resource{
+		Group:   "example.com",
+		Version: "v1",
+		Kind:    "Database",
+	}
+
+	assert.Equal(t, schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "databases"}, resource.GroupVersionResource())
+	assert.Equal(t, "kube_database_labels", resource.LabelsFamilyName())
+	assert.Equal(t, "kube_database_status_replicas", resource.FamilyName(Metric{Name: "status.replicas"}))
+	assert.Equal(t, "database.status.replicas", resource.DatadogName(Metric{Name: "status.replicas"}))
+
+	resource.Resource = "dbs"
+	assert.Equal(t, "dbs", resource.GroupVersionResource().Resource)
+}
+
+func TestValidate(t *testing.T) {
+	assert.Error(t, (&Resource{Kind: "Database"}).Validate())
+	assert.Error(t, (&Resource{Version: "v1", Kind: "Database"}).Validate())
+	assert.Error(t, (&Resource{Version: "v1", Kind: "Database", Metrics: []Metric{{Name: "replicas"}}}).Validate())
+	assert.NoError(t, (&Resource{Version: "v1", Kind: "Database", Metrics: []Metric{{Name: "replicas", Path: "status.replicas"}}}).Validate())
+}
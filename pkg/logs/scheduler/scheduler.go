@@ -19,6 +19,9 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// processEntityName is the entity prefix of the processes discovered by the process listener
+const processEntityName = "process"
+
 var (
 	// scheduler is plugged to autodiscovery to collect integration configs
 	// and schedule log collection for different kind of inputs
@@ -216,11 +219,15 @@ func (s *Scheduler) toSources(config integration.Config) ([]*logsConfig.LogSourc
 		if service != nil {
 			// a config defined in a container label or a pod annotation does not always contain a type,
 			// override it here to ensure that the config won't be dropped at validation.
-			if cfg.Type == logsConfig.FileType && (config.Provider == names.Kubernetes || config.Provider == names.Container) {
+			switch {
+			case service.Type == processEntityName:
+				// the logs of a process of the host are collected from the files or the journal
+				// set in its configuration, cfg.Type is not overwritten
+			case cfg.Type == logsConfig.FileType && (config.Provider == names.Kubernetes || config.Provider == names.Container):
 				// cfg.Type is not overwritten as tailing a file from a Docker or Kubernetes AD configuration
 				// is explicitly supported (other combinations may be supported later)
 				cfg.Identifier = service.Identifier
-			} else {
+			default:
 				cfg.Type = service.Type
 				cfg.Identifier = service.Identifier // used for matching a source with a service
 			}
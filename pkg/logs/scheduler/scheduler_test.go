@@ -102,6 +102,30 @@ func TestScheduleConfigCreatesNewSourceServiceOverride(t *testing.T) {
 	assert.Equal(t, "a1887023ed72a2b0d083ef465e8edfe4932a25731d4bda2f39f288f70af3405b", logSource.Config.Identifier)
 }
 
+func TestScheduleConfigCreatesNewSourceForProcess(t *testing.T) {
+	logSources := config.NewLogSources()
+	services := service.NewServices()
+	CreateScheduler(logSources, services)
+
+	logSourcesStream := logSources.GetAddedForType(config.FileType)
+
+	configSource := integration.Config{
+		LogsConfig:    []byte("logs:\n- type: file\n  path: /var/log/nginx/access.log\n  source: nginx\n"),
+		ADIdentifiers: []string{"process_name://nginx"},
+		Provider:      names.File,
+		TaggerEntity:  "process://1234",
+		Entity:        "process://1234",
+		ClusterCheck:  false,
+		CreationTime:  0,
+	}
+
+	go adScheduler.Schedule([]integration.Config{configSource})
+	logSource := <-logSourcesStream
+	assert.Equal(t, config.FileType, logSource.Config.Type)
+	assert.Equal(t, "/var/log/nginx/access.log", logSource.Config.Path)
+	assert.Equal(t, "nginx", logSource.Config.Source)
+}
+
 func TestScheduleConfigCreatesNewService(t *testing.T) {
 	logSources := config.NewLogSources()
 	services := service.NewServices()
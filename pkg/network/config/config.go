@@ -109,6 +109,10 @@ type Config struct {
 	// ClientStateExpiry specifies the max time a client (e.g. process-agent)'s state will be stored in memory before being evicted.
 	ClientStateExpiry time.Duration
 
+	// StateCheckpointFile is the path of the file the state of the clients is saved to when the tracer stops, and
+	// restored from when it starts again. An empty value disables the checkpointing of the state.
+	StateCheckpointFile string
+
 	// EnableConntrack enables probing conntrack for network address translation
 	EnableConntrack bool
 
@@ -190,6 +194,7 @@ func New() *Config {
 		ClosedChannelSize:            cfg.GetInt(join(spNS, "closed_channel_size")),
 		MaxConnectionsStateBuffered:  cfg.GetInt(join(spNS, "max_connection_state_buffered")),
 		ClientStateExpiry:            2 * time.Minute,
+		StateCheckpointFile:          cfg.GetString(join(netNS, "state_checkpoint_file")),
 
 		DNSInspection:       !cfg.GetBool(join(spNS, "disable_dns_inspection")),
 		CollectDNSStats:     cfg.GetBool(join(spNS, "collect_dns_stats")),
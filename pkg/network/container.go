@@ -0,0 +1,80 @@
+package network
+
+import (
+	"sort"
+)
+
+// ContainerIDResolver returns the ID of the container a process belongs to,
+// or an empty string if the process does not run in a container
+type ContainerIDResolver interface {
+	ContainerID(pid uint32) string
+}
+
+// ContainerStats holds the network traffic of a container, aggregated over its connections
+type ContainerStats struct {
+	ContainerID    string `json:"container_id"`
+	Connections    int    `json:"connections"`
+	SentBytes      uint64 `json:"sent_bytes"`
+	RecvBytes      uint64 `json:"recv_bytes"`
+	SentPackets    uint64 `json:"sent_packets"`
+	RecvPackets    uint64 `json:"recv_packets"`
+	Retransmits    uint32 `json:"retransmits"`
+	TCPEstablished uint32 `json:"tcp_established"`
+	TCPClosed      uint32 `json:"tcp_closed"`
+	// AvgRTT is the average RTT (in µs) of the TCP connections of the container reporting one
+	AvgRTT float64 `json:"avg_rtt"`
+	// MaxRTT is the highest RTT (in µs) among the connections of the container
+	MaxRTT uint32 `json:"max_rtt"`
+
+	rttSum   uint64
+	rttCount uint64
+}
+
+// AggregateByContainer aggregates the last stats of the given connections by container.
+// Connections of processes that are not running in a container are ignored.
+// The result is sorted by container ID.
+func AggregateByContainer(conns []ConnectionStats, resolver ContainerIDResolver) []*ContainerStats {
+	byContainer := make(map[string]*ContainerStats)
+	for i := range conns {
+		c := &conns[i]
+		containerID := resolver.ContainerID(c.Pid)
+		if containerID == "" {
+			continue
+		}
+
+		stats, ok := byContainer[containerID]
+		if !ok {
+			stats = &ContainerStats{ContainerID: containerID}
+			byContainer[containerID] = stats
+		}
+
+		stats.Connections++
+		stats.SentBytes += c.LastSentBytes
+		stats.RecvBytes += c.LastRecvBytes
+		stats.SentPackets += c.LastSentPackets
+		stats.RecvPackets += c.LastRecvPackets
+		stats.Retransmits += c.LastRetransmits
+		stats.TCPEstablished += c.LastTCPEstablished
+		stats.TCPClosed += c.LastTCPClosed
+
+		if c.Type == TCP && c.RTT > 0 {
+			stats.rttSum += uint64(c.RTT)
+			stats.rttCount++
+			if c.RTT > stats.MaxRTT {
+				stats.MaxRTT = c.RTT
+			}
+		}
+	}
+
+	result := make([]*ContainerStats, 0, len(byContainer))
+	for _, stats := range byContainer {
+		if stats.rttCount > 0 {
+			stats.AvgRTT = float64(stats.rttSum) / float64(stats.rttCount)
+		}
+		result = append(result, stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ContainerID < result[j].ContainerID
+	})
+	return result
+}
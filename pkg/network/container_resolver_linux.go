@@ -0,0 +1,79 @@
+// +build linux
+
+package network
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/cgroups"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// cgroupContainerResolver resolves the container of a process from the cgroups of the host
+type cgroupContainerResolver struct {
+	reader        *cgroups.Reader
+	cacheValidity time.Duration
+
+	mux            sync.Mutex
+	lastRefresh    time.Time
+	pidToContainer map[uint32]string
+}
+
+// NewContainerResolver creates a ContainerIDResolver reading cgroups.
+// The mapping of processes to containers is refreshed at most once per cacheValidity.
+func NewContainerResolver(procRoot string, cacheValidity time.Duration) (ContainerIDResolver, error) {
+	var hostPrefix string
+	if strings.HasPrefix(procRoot, "/host") {
+		hostPrefix = "/host"
+	}
+
+	reader, err := cgroups.NewReader(
+		cgroups.WithCgroupV1BaseController("freezer"),
+		cgroups.WithProcPath(procRoot),
+		cgroups.WithHostPrefix(hostPrefix),
+		cgroups.WithReaderFilter(cgroups.ContainerFilter),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cgroupContainerResolver{
+		reader:         reader,
+		cacheValidity:  cacheValidity,
+		pidToContainer: make(map[uint32]string),
+	}, nil
+}
+
+// ContainerID returns the ID of the container the given process belongs to
+func (r *cgroupContainerResolver) ContainerID(pid uint32) string {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	if time.Since(r.lastRefresh) > r.cacheValidity {
+		r.refresh()
+	}
+	return r.pidToContainer[pid]
+}
+
+func (r *cgroupContainerResolver) refresh() {
+	r.lastRefresh = time.Now()
+	if err := r.reader.RefreshCgroups(0); err != nil {
+		log.Debugf("unable to refresh cgroups, keeping previous container mapping: %s", err)
+		return
+	}
+
+	pidToContainer := make(map[uint32]string, len(r.pidToContainer))
+	var stats cgroups.PIDStats
+	for _, cg := range r.reader.ListCgroups() {
+		if err := cg.GetPIDStats(&stats); err != nil {
+			log.Tracef("unable to retrieve pids of container %s: %s", cg.Identifier(), err)
+			continue
+		}
+		for _, pid := range stats.PIDs {
+			pidToContainer[uint32(pid)] = cg.Identifier()
+		}
+	}
+	r.pidToContainer = pidToContainer
+}
@@ -0,0 +1,13 @@
+// +build !linux
+
+package network
+
+import (
+	"errors"
+	"time"
+)
+
+// NewContainerResolver is not supported
+func NewContainerResolver(_ string, _ time.Duration) (ContainerIDResolver, error) {
+	return nil, errors.New("container resolution is only supported on linux")
+}
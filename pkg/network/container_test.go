@@ -0,0 +1,60 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticContainerResolver map[uint32]string
+
+func (r staticContainerResolver) ContainerID(pid uint32) string {
+	return r[pid]
+}
+
+func TestAggregateByContainer(t *testing.T) {
+	resolver := staticContainerResolver{
+		1: "container-b",
+		2: "container-b",
+		3: "container-a",
+	}
+
+	conns := []ConnectionStats{
+		{Pid: 1, Type: TCP, LastSentBytes: 10, LastRecvBytes: 20, LastSentPackets: 1, LastRecvPackets: 2, LastRetransmits: 1, RTT: 100, LastTCPEstablished: 1},
+		{Pid: 2, Type: TCP, LastSentBytes: 5, LastRecvBytes: 5, LastSentPackets: 1, LastRecvPackets: 1, RTT: 300, LastTCPClosed: 1},
+		{Pid: 2, Type: UDP, LastSentBytes: 7, RTT: 1000},
+		{Pid: 3, Type: TCP, LastRecvBytes: 42},
+		// not running in a container
+		{Pid: 4, Type: TCP, LastSentBytes: 1000},
+	}
+
+	stats := AggregateByContainer(conns, resolver)
+	require.Len(t, stats, 2)
+
+	a := stats[0]
+	assert.Equal(t, "container-a", a.ContainerID)
+	assert.Equal(t, 1, a.Connections)
+	assert.Equal(t, uint64(42), a.RecvBytes)
+	assert.Equal(t, float64(0), a.AvgRTT)
+
+	b := stats[1]
+	assert.Equal(t, "container-b", b.ContainerID)
+	assert.Equal(t, 3, b.Connections)
+	assert.Equal(t, uint64(22), b.SentBytes)
+	assert.Equal(t, uint64(25), b.RecvBytes)
+	assert.Equal(t, uint64(2), b.SentPackets)
+	assert.Equal(t, uint64(3), b.RecvPackets)
+	assert.Equal(t, uint32(1), b.Retransmits)
+	assert.Equal(t, uint32(1), b.TCPEstablished)
+	assert.Equal(t, uint32(1), b.TCPClosed)
+	// UDP connections are not taken into account for RTT
+	assert.Equal(t, float64(200), b.AvgRTT)
+	assert.Equal(t, uint32(300), b.MaxRTT)
+}
+
+func TestAggregateByContainerEmpty(t *testing.T) {
+	stats := AggregateByContainer(nil, staticContainerResolver{})
+	assert.NotNil(t, stats)
+	assert.Empty(t, stats)
+}
@@ -0,0 +1,283 @@
+package encoding
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// DefaultStreamChunkSize is the default number of connections serialized in a single stream frame
+const DefaultStreamChunkSize = 1000
+
+// maxFrameSize bounds the size of a frame accepted by the StreamDecoder
+const maxFrameSize = 64 << 20
+
+// FrameKind identifies the kind of connection deltas carried by a stream frame
+type FrameKind uint8
+
+const (
+	// FrameOpened holds connections established since the last request of the client
+	FrameOpened FrameKind = iota + 1
+	// FrameUpdated holds connections that were already known by the client and have updated counters
+	FrameUpdated
+	// FrameClosed holds connections closed since the last request of the client
+	FrameClosed
+)
+
+func (k FrameKind) String() string {
+	switch k {
+	case FrameOpened:
+		return "opened"
+	case FrameUpdated:
+		return "updated"
+	case FrameClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifyConnection returns the FrameKind a connection delta belongs to
+func ClassifyConnection(c network.ConnectionStats) FrameKind {
+	switch {
+	case c.LastTCPClosed > 0:
+		return FrameClosed
+	case c.LastTCPEstablished > 0:
+		return FrameOpened
+	default:
+		return FrameUpdated
+	}
+}
+
+// StreamEncoder writes connection deltas as a sequence of length-prefixed frames,
+// so that a large set of connections never has to be serialized in a single buffer.
+//
+// Each frame is made of a 1-byte FrameKind, a 4-byte big-endian payload length
+// and a payload serialized with the underlying Marshaler.
+type StreamEncoder struct {
+	w         io.Writer
+	marshaler Marshaler
+	chunkSize int
+	header    [5]byte
+}
+
+// NewStreamEncoder returns a StreamEncoder writing frames of at most chunkSize connections to w
+func NewStreamEncoder(w io.Writer, marshaler Marshaler, chunkSize int) *StreamEncoder {
+	if chunkSize <= 0 {
+		chunkSize = DefaultStreamChunkSize
+	}
+	return &StreamEncoder{
+		w:         w,
+		marshaler: marshaler,
+		chunkSize: chunkSize,
+	}
+}
+
+// Encode splits the given connections by FrameKind and writes them as frames.
+// Telemetry is only attached to the first frame written. DNS, DNS stats and HTTP
+// entries are attached to the first frame holding a connection using them, so that
+// each entry is only sent, and counted, once per stream.
+// The onFrame callback, if not nil, is called after each frame is written.
+// It returns the number of frames written.
+func (e *StreamEncoder) Encode(all *network.Connections, onFrame func()) (int, error) {
+	frames := 0
+	withTelemetry := true
+	refs := newStreamRefs()
+	write := func(kind FrameKind, conns []network.ConnectionStats) error {
+		frame := refs.frame(all, conns)
+		if withTelemetry {
+			frame.ConnTelemetry = all.ConnTelemetry
+			frame.CompilationTelemetryByAsset = all.CompilationTelemetryByAsset
+			withTelemetry = false
+		}
+
+		if err := e.writeFrame(kind, frame); err != nil {
+			return err
+		}
+		frames++
+		if onFrame != nil {
+			onFrame()
+		}
+		return nil
+	}
+
+	// connections are copied in a single chunk-sized scratch slice, which bounds
+	// the memory used on top of the connections themselves
+	chunk := make([]network.ConnectionStats, 0, e.chunkSize)
+	for _, kind := range []FrameKind{FrameOpened, FrameUpdated, FrameClosed} {
+		chunk = chunk[:0]
+		for _, c := range all.Conns {
+			if ClassifyConnection(c) != kind {
+				continue
+			}
+			chunk = append(chunk, c)
+			if len(chunk) < e.chunkSize {
+				continue
+			}
+			if err := write(kind, chunk); err != nil {
+				return frames, err
+			}
+			chunk = chunk[:0]
+		}
+
+		if len(chunk) > 0 {
+			if err := write(kind, chunk); err != nil {
+				return frames, err
+			}
+		}
+	}
+
+	// always send at least one frame so that telemetry is reported
+	if frames == 0 {
+		if err := write(FrameUpdated, nil); err != nil {
+			return frames, err
+		}
+	}
+
+	return frames, nil
+}
+
+// streamRefs keeps track of the DNS, DNS stats and HTTP entries already sent in a stream
+type streamRefs struct {
+	dns      map[util.Address]struct{}
+	dnsStats map[dns.Key]struct{}
+	http     map[http.Key]struct{}
+}
+
+func newStreamRefs() *streamRefs {
+	return &streamRefs{
+		dns:      make(map[util.Address]struct{}),
+		dnsStats: make(map[dns.Key]struct{}),
+		http:     make(map[http.Key]struct{}),
+	}
+}
+
+// frame returns the Connections holding conns along with the DNS, DNS stats and HTTP
+// entries they use that were not sent in a previous frame
+func (r *streamRefs) frame(all *network.Connections, conns []network.ConnectionStats) *network.Connections {
+	frame := &network.Connections{}
+	frame.Conns = conns
+
+	addDNS := func(addr util.Address) {
+		if _, sent := r.dns[addr]; sent {
+			return
+		}
+		names, ok := all.DNS[addr]
+		if !ok {
+			return
+		}
+		if frame.DNS == nil {
+			frame.DNS = make(map[util.Address][]string)
+		}
+		frame.DNS[addr] = names
+		r.dns[addr] = struct{}{}
+	}
+
+	for i := range conns {
+		c := &conns[i]
+
+		if len(all.DNS) > 0 {
+			addDNS(c.Source)
+			addDNS(c.Dest)
+			if c.IPTranslation != nil {
+				addDNS(c.IPTranslation.ReplSrcIP)
+				addDNS(c.IPTranslation.ReplDstIP)
+			}
+		}
+
+		if key, ok := network.DNSKey(c); ok {
+			if _, sent := r.dnsStats[key]; !sent {
+				if stats, ok := all.DNSStats[key]; ok {
+					if frame.DNSStats == nil {
+						frame.DNSStats = make(dns.StatsByKeyByNameByType)
+					}
+					frame.DNSStats[key] = stats
+					r.dnsStats[key] = struct{}{}
+				}
+			}
+		}
+
+		if len(all.HTTP) > 0 {
+			key := httpKeyFromConn(*c)
+			if _, sent := r.http[key]; !sent {
+				if stats, ok := all.HTTP[key]; ok {
+					if frame.HTTP == nil {
+						frame.HTTP = make(map[http.Key]http.RequestStats)
+					}
+					frame.HTTP[key] = stats
+					r.http[key] = struct{}{}
+				}
+			}
+		}
+	}
+
+	return frame
+}
+
+func (e *StreamEncoder) writeFrame(kind FrameKind, conns *network.Connections) error {
+	buf, err := e.marshaler.Marshal(conns)
+	if err != nil {
+		return fmt.Errorf("unable to marshal %s frame: %w", kind, err)
+	}
+
+	e.header[0] = byte(kind)
+	binary.BigEndian.PutUint32(e.header[1:], uint32(len(buf)))
+	if _, err := e.w.Write(e.header[:]); err != nil {
+		return err
+	}
+	_, err = e.w.Write(buf)
+	return err
+}
+
+// StreamDecoder reads frames written by a StreamEncoder
+type StreamDecoder struct {
+	r           io.Reader
+	unmarshaler Unmarshaler
+	header      [5]byte
+	buf         []byte
+}
+
+// NewStreamDecoder returns a StreamDecoder reading frames from r
+func NewStreamDecoder(r io.Reader, unmarshaler Unmarshaler) *StreamDecoder {
+	return &StreamDecoder{
+		r:           r,
+		unmarshaler: unmarshaler,
+	}
+}
+
+// Next returns the next frame of the stream. It returns io.EOF once the stream is exhausted.
+func (d *StreamDecoder) Next() (FrameKind, *model.Connections, error) {
+	if _, err := io.ReadFull(d.r, d.header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, nil, fmt.Errorf("truncated frame header: %w", err)
+		}
+		return 0, nil, err
+	}
+
+	kind := FrameKind(d.header[0])
+	size := binary.BigEndian.Uint32(d.header[1:])
+	if size > maxFrameSize {
+		return 0, nil, fmt.Errorf("%s frame too large: %d bytes", kind, size)
+	}
+
+	if cap(d.buf) < int(size) {
+		d.buf = make([]byte, size)
+	}
+	d.buf = d.buf[:size]
+	if _, err := io.ReadFull(d.r, d.buf); err != nil {
+		return 0, nil, fmt.Errorf("truncated %s frame: %w", kind, err)
+	}
+
+	conns, err := d.unmarshaler.Unmarshal(d.buf)
+	if err != nil {
+		return 0, nil, err
+	}
+	return kind, conns, nil
+}
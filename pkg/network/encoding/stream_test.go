@@ -0,0 +1,197 @@
+package encoding
+
+import (
+	"bytes"
+	"io"
+	"syscall"
+	"testing"
+
+	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go4.org/intern"
+)
+
+func streamTestConnections() *network.Connections {
+	conn := func(sport uint16, established, closed uint32) network.ConnectionStats {
+		return network.ConnectionStats{
+			Source:             util.AddressFromString("10.1.1.1"),
+			Dest:               util.AddressFromString("10.2.2.2"),
+			SPort:              sport,
+			DPort:              80,
+			Type:               network.TCP,
+			Family:             network.AFINET,
+			Direction:          network.OUTGOING,
+			LastSentBytes:      10,
+			LastTCPEstablished: established,
+			LastTCPClosed:      closed,
+		}
+	}
+
+	return &network.Connections{
+		BufferedData: network.BufferedData{
+			Conns: []network.ConnectionStats{
+				conn(1000, 1, 0),
+				conn(1001, 0, 0),
+				conn(1002, 1, 1),
+				conn(1003, 1, 0),
+				conn(1004, 0, 0),
+				conn(1005, 1, 0),
+			},
+		},
+		ConnTelemetry: &network.ConnectionsTelemetry{
+			MonotonicKprobesTriggered: 10,
+		},
+	}
+}
+
+func decodeStream(t *testing.T, r io.Reader, u Unmarshaler) ([]FrameKind, []*model.Connections) {
+	decoder := NewStreamDecoder(r, u)
+	var kinds []FrameKind
+	var frames []*model.Connections
+	for {
+		kind, conns, err := decoder.Next()
+		if err == io.EOF {
+			return kinds, frames
+		}
+		require.NoError(t, err)
+		kinds = append(kinds, kind)
+		frames = append(frames, conns)
+	}
+}
+
+func TestClassifyConnection(t *testing.T) {
+	assert.Equal(t, FrameOpened, ClassifyConnection(network.ConnectionStats{LastTCPEstablished: 1}))
+	assert.Equal(t, FrameClosed, ClassifyConnection(network.ConnectionStats{LastTCPEstablished: 1, LastTCPClosed: 1}))
+	assert.Equal(t, FrameClosed, ClassifyConnection(network.ConnectionStats{LastTCPClosed: 1}))
+	assert.Equal(t, FrameUpdated, ClassifyConnection(network.ConnectionStats{LastSentBytes: 12}))
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	for _, contentType := range []string{ContentTypeJSON, ContentTypeProtobuf} {
+		t.Run(contentType, func(t *testing.T) {
+			in := streamTestConnections()
+			buf := new(bytes.Buffer)
+			flushed := 0
+
+			frames, err := NewStreamEncoder(buf, GetMarshaler(contentType), 2).Encode(in, func() { flushed++ })
+			require.NoError(t, err)
+			assert.Equal(t, 4, frames)
+			assert.Equal(t, frames, flushed)
+
+			kinds, out := decodeStream(t, buf, GetUnmarshaler(contentType))
+			assert.Equal(t, []FrameKind{FrameOpened, FrameOpened, FrameUpdated, FrameClosed}, kinds)
+
+			var ports []int32
+			for _, frame := range out {
+				assert.LessOrEqual(t, len(frame.Conns), 2)
+				for _, c := range frame.Conns {
+					ports = append(ports, c.Laddr.Port)
+				}
+			}
+			assert.Equal(t, []int32{1000, 1003, 1005, 1001, 1004, 1002}, ports)
+
+			// telemetry is only sent with the first frame
+			require.NotNil(t, out[0].ConnTelemetry)
+			assert.Equal(t, int64(10), out[0].ConnTelemetry.MonotonicKprobesTriggered)
+			for _, frame := range out[1:] {
+				assert.Nil(t, frame.ConnTelemetry)
+			}
+		})
+	}
+}
+
+func TestStreamEmptyConnections(t *testing.T) {
+	buf := new(bytes.Buffer)
+	in := &network.Connections{
+		ConnTelemetry: &network.ConnectionsTelemetry{MonotonicConnsClosed: 3},
+	}
+
+	frames, err := NewStreamEncoder(buf, GetMarshaler(ContentTypeProtobuf), 0).Encode(in, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, frames)
+
+	kinds, out := decodeStream(t, buf, GetUnmarshaler(ContentTypeProtobuf))
+	assert.Equal(t, []FrameKind{FrameUpdated}, kinds)
+	assert.Empty(t, out[0].Conns)
+	assert.Equal(t, int64(3), out[0].ConnTelemetry.MonotonicConnsClosed)
+}
+
+func TestStreamTruncated(t *testing.T) {
+	buf := new(bytes.Buffer)
+	_, err := NewStreamEncoder(buf, GetMarshaler(ContentTypeProtobuf), 10).Encode(streamTestConnections(), nil)
+	require.NoError(t, err)
+
+	truncated := bytes.NewReader(buf.Bytes()[:buf.Len()-1])
+	decoder := NewStreamDecoder(truncated, GetUnmarshaler(ContentTypeProtobuf))
+
+	var lastErr error
+	for lastErr == nil {
+		_, _, lastErr = decoder.Next()
+	}
+	assert.NotEqual(t, io.EOF, lastErr)
+}
+
+func TestStreamSendsDNSAndHTTPOnce(t *testing.T) {
+	conn := func(pid uint32) network.ConnectionStats {
+		return network.ConnectionStats{
+			Pid:       pid,
+			Source:    util.AddressFromString("10.1.1.1"),
+			Dest:      util.AddressFromString("8.8.8.8"),
+			SPort:     1000,
+			DPort:     53,
+			Type:      network.UDP,
+			Family:    network.AFINET,
+			Direction: network.OUTGOING,
+		}
+	}
+
+	in := &network.Connections{
+		BufferedData: network.BufferedData{
+			// same DNS key for both connections, as with a PID collision
+			Conns: []network.ConnectionStats{conn(1), conn(2)},
+		},
+		DNS: map[util.Address][]string{
+			util.AddressFromString("8.8.8.8"):  {"dns.google"},
+			util.AddressFromString("10.9.9.9"): {"unused.local"},
+		},
+		DNSStats: dns.StatsByKeyByNameByType{
+			dns.Key{
+				ClientIP:   util.AddressFromString("10.1.1.1"),
+				ServerIP:   util.AddressFromString("8.8.8.8"),
+				ClientPort: 1000,
+				Protocol:   syscall.IPPROTO_UDP,
+			}: map[*intern.Value]map[dns.QueryType]dns.Stats{
+				intern.GetByString("foo.com"): {
+					dns.TypeA: {CountByRcode: map[uint32]uint32{0: 1}},
+				},
+			},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	frames, err := NewStreamEncoder(buf, GetMarshaler(ContentTypeProtobuf), 1).Encode(in, nil)
+	require.NoError(t, err)
+	require.Equal(t, 2, frames)
+
+	_, out := decodeStream(t, buf, GetUnmarshaler(ContentTypeProtobuf))
+	require.Len(t, out, 2)
+
+	// only the entries used by the connections are sent, and only in the first frame using them
+	require.Len(t, out[0].Dns, 1)
+	assert.Equal(t, []string{"dns.google"}, out[0].Dns["8.8.8.8"].Names)
+	assert.Empty(t, out[1].Dns)
+
+	withDNSStats := 0
+	for _, frame := range out {
+		for _, c := range frame.Conns {
+			if len(c.DnsCountByRcode) > 0 || len(c.DnsStatsByDomain) > 0 || len(c.DnsStatsByDomainByQueryType) > 0 {
+				withDNSStats++
+			}
+		}
+	}
+	assert.Equal(t, 1, withDNSStats)
+}
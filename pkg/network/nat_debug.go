@@ -0,0 +1,140 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// NATTuple is one side of a conntrack entry
+type NATTuple struct {
+	Src   string `json:"src"`
+	SPort uint16 `json:"sport"`
+	Dst   string `json:"dst"`
+	DPort uint16 `json:"dport"`
+}
+
+func (t NATTuple) String() string {
+	return fmt.Sprintf("%s ⇄ %s",
+		net.JoinHostPort(t.Src, strconv.Itoa(int(t.SPort))),
+		net.JoinHostPort(t.Dst, strconv.Itoa(int(t.DPort))),
+	)
+}
+
+// NATEntry is a NAT translation as stored by a conntracker: connections matching Key are translated to Translation
+type NATEntry struct {
+	Proto       string   `json:"proto"`
+	NetNS       uint32   `json:"netns,omitempty"`
+	Key         NATTuple `json:"key"`
+	Translation NATTuple `json:"translation"`
+	// Orphan is set on cached entries that were registered from a conntrack event but never looked up
+	Orphan bool `json:"orphan,omitempty"`
+}
+
+// NewNATEntry returns the NATEntry translating the given connection key
+func NewNATEntry(proto ConnectionType, netns uint32, src util.Address, sport uint16, dst util.Address, dport uint16, t *IPTranslation) NATEntry {
+	e := NATEntry{
+		Proto: proto.String(),
+		NetNS: netns,
+		Key: NATTuple{
+			Src:   src.String(),
+			SPort: sport,
+			Dst:   dst.String(),
+			DPort: dport,
+		},
+	}
+	if t != nil {
+		e.Translation = NATTuple{
+			Src:   t.ReplSrcIP.String(),
+			SPort: t.ReplSrcPort,
+			Dst:   t.ReplDstIP.String(),
+			DPort: t.ReplDstPort,
+		}
+	}
+	return e
+}
+
+func (e NATEntry) String() string {
+	return fmt.Sprintf("[%s] [%s] -> [%s] (ns: %d)", e.Proto, e.Key, e.Translation, e.NetNS)
+}
+
+type natEntryKey struct {
+	proto string
+	key   NATTuple
+}
+
+// NATMismatch is a NAT entry whose cached translation differs from the one of the host conntrack table
+type NATMismatch struct {
+	Cached NATEntry `json:"cached"`
+	Host   NATEntry `json:"host"`
+}
+
+// NATDrift reports the differences between the conntrack cache of the tracer and the conntrack table of the host
+type NATDrift struct {
+	CachedEntries int `json:"cached_entries"`
+	HostEntries   int `json:"host_entries"`
+
+	// Missing holds the entries of the host table that are not cached
+	Missing []NATEntry `json:"missing"`
+	// Stale holds the cached entries that are not in the host table anymore
+	Stale []NATEntry `json:"stale"`
+	// Mismatched holds the entries that are both cached and in the host table with different translations
+	Mismatched []NATMismatch `json:"mismatched"`
+	// Orphans holds the cached entries that were never looked up
+	Orphans []NATEntry `json:"orphans"`
+}
+
+// CompareNATTables compares the cached NAT entries of a conntracker with the ones of the host conntrack table.
+// Entries are matched on their protocol and key tuple, regardless of their network namespace.
+func CompareNATTables(cached, host []NATEntry) NATDrift {
+	drift := NATDrift{
+		CachedEntries: len(cached),
+		HostEntries:   len(host),
+	}
+
+	hostByKey := make(map[natEntryKey]NATEntry, len(host))
+	for _, e := range host {
+		hostByKey[natEntryKey{e.Proto, e.Key}] = e
+	}
+
+	cachedKeys := make(map[natEntryKey]struct{}, len(cached))
+	for _, e := range cached {
+		k := natEntryKey{e.Proto, e.Key}
+		cachedKeys[k] = struct{}{}
+		if e.Orphan {
+			drift.Orphans = append(drift.Orphans, e)
+		}
+
+		h, ok := hostByKey[k]
+		switch {
+		case !ok:
+			drift.Stale = append(drift.Stale, e)
+		case h.Translation != e.Translation:
+			drift.Mismatched = append(drift.Mismatched, NATMismatch{Cached: e, Host: h})
+		}
+	}
+
+	for _, e := range host {
+		if _, ok := cachedKeys[natEntryKey{e.Proto, e.Key}]; !ok {
+			drift.Missing = append(drift.Missing, e)
+		}
+	}
+
+	SortNATEntries(drift.Missing)
+	SortNATEntries(drift.Stale)
+	SortNATEntries(drift.Orphans)
+	sort.Slice(drift.Mismatched, func(i, j int) bool {
+		return drift.Mismatched[i].Cached.String() < drift.Mismatched[j].Cached.String()
+	})
+	return drift
+}
+
+// SortNATEntries sorts NAT entries in a stable, human friendly order
+func SortNATEntries(entries []NATEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].String() < entries[j].String()
+	})
+}
@@ -0,0 +1,51 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareNATTables(t *testing.T) {
+	entry := func(proto string, src string, sport uint16, transSrc string) NATEntry {
+		return NATEntry{
+			Proto:       proto,
+			Key:         NATTuple{Src: src, SPort: sport, Dst: "10.0.0.100", DPort: 80},
+			Translation: NATTuple{Src: transSrc, SPort: 8080, Dst: src, DPort: sport},
+		}
+	}
+
+	inSync := entry("TCP", "10.0.0.1", 1000, "172.17.0.2")
+	stale := entry("TCP", "10.0.0.2", 1000, "172.17.0.3")
+	orphan := entry("UDP", "10.0.0.3", 1000, "172.17.0.4")
+	orphan.Orphan = true
+	mismatched := entry("TCP", "10.0.0.4", 1000, "172.17.0.5")
+	missing := entry("TCP", "10.0.0.5", 1000, "172.17.0.6")
+
+	// same key on another network namespace
+	hostInSync := inSync
+	hostInSync.NetNS = 4026531992
+	hostOrphan := orphan
+	hostOrphan.Orphan = false
+	hostMismatched := entry("TCP", "10.0.0.4", 1000, "172.17.0.42")
+
+	drift := CompareNATTables(
+		[]NATEntry{inSync, stale, orphan, mismatched},
+		[]NATEntry{hostInSync, hostOrphan, hostMismatched, missing, entry("UDP", "10.0.0.2", 1000, "172.17.0.3")},
+	)
+
+	assert.Equal(t, 4, drift.CachedEntries)
+	assert.Equal(t, 5, drift.HostEntries)
+	assert.Equal(t, []NATEntry{missing, entry("UDP", "10.0.0.2", 1000, "172.17.0.3")}, drift.Missing)
+	assert.Equal(t, []NATEntry{stale}, drift.Stale)
+	assert.Equal(t, []NATEntry{orphan}, drift.Orphans)
+	assert.Equal(t, []NATMismatch{{Cached: mismatched, Host: hostMismatched}}, drift.Mismatched)
+}
+
+func TestCompareNATTablesEmpty(t *testing.T) {
+	drift := CompareNATTables(nil, nil)
+	assert.Empty(t, drift.Missing)
+	assert.Empty(t, drift.Stale)
+	assert.Empty(t, drift.Mismatched)
+	assert.Empty(t, drift.Orphans)
+}
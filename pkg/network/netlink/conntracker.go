@@ -5,6 +5,7 @@ package netlink
 
 import (
 	"container/list"
+	"context"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -30,6 +31,8 @@ type Conntracker interface {
 	DeleteTranslation(network.ConnectionStats)
 	IsSampling() bool
 	GetStats() map[string]int64
+	// DumpCachedTable returns the NAT translations currently held by the conntracker, for debugging
+	DumpCachedTable(ctx context.Context) ([]network.NATEntry, error)
 	Close()
 }
 
@@ -0,0 +1,175 @@
+// +build linux
+// +build !android
+
+package netlink
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/mdlayher/netlink"
+	"golang.org/x/sys/unix"
+)
+
+// DumpCachedTable returns the NAT translations held in the conntrack cache
+func (ctr *realConntracker) DumpCachedTable(ctx context.Context) ([]network.NATEntry, error) {
+	ctr.RLock()
+	defer ctr.RUnlock()
+
+	return ctr.cache.dump(ctx)
+}
+
+func (cc *conntrackCache) dump(ctx context.Context) ([]network.NATEntry, error) {
+	keys := cc.cache.Keys()
+	entries := make([]network.NATEntry, 0, len(keys))
+	for _, k := range keys {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Peek does not update the recentness of the entry nor its orphan status
+		v, ok := cc.cache.Peek(k)
+		if !ok {
+			continue
+		}
+
+		key := k.(connKey)
+		t := v.(*translationEntry)
+		e := network.NewNATEntry(key.transport, 0, key.srcIP, key.srcPort, key.dstIP, key.dstPort, t.IPTranslation)
+		e.Orphan = t.orphan != nil
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// DumpHostTable dumps the conntrack tables of the host and returns their NAT entries, in both directions.
+// If capture is not nil, the raw netlink messages are recorded to it in the format expected by ReadCapture.
+func DumpHostTable(ctx context.Context, procRoot string, listenAllNamespaces bool, capture io.Writer) ([]network.NATEntry, error) {
+	consumer := NewConsumer(procRoot, -1, listenAllNamespaces)
+	defer consumer.Stop()
+
+	decoder := NewDecoder()
+	var entries []network.NATEntry
+	for _, family := range []uint8{unix.AF_INET, unix.AF_INET6} {
+		events, err := consumer.DumpTable(family)
+		if err != nil {
+			return nil, fmt.Errorf("error dumping conntrack table for family %d: %w", family, err)
+		}
+
+	ReadLoop:
+		for {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case e, ok := <-events:
+				if !ok {
+					break ReadLoop
+				}
+				if capture != nil {
+					if err := WriteCapture(capture, e.Messages()); err != nil {
+						e.Done()
+						return nil, err
+					}
+				}
+				entries = appendNATEntries(entries, decoder.DecodeAndReleaseEvent(e))
+			}
+		}
+	}
+	return entries, nil
+}
+
+// WriteCapture records netlink messages to w.
+// Each message is written as its length (4 bytes, little endian) followed by its data.
+func WriteCapture(w io.Writer, msgs []netlink.Message) error {
+	sizeBuffer := make([]byte, 4)
+	for _, m := range msgs {
+		binary.LittleEndian.PutUint32(sizeBuffer, uint32(len(m.Data)))
+		if _, err := w.Write(sizeBuffer); err != nil {
+			return err
+		}
+		if _, err := w.Write(m.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadCapture reads netlink messages recorded with WriteCapture
+func ReadCapture(r io.Reader) ([]netlink.Message, error) {
+	var messages []netlink.Message
+	sizeBuffer := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, sizeBuffer); err != nil {
+			if errors.Is(err, io.EOF) {
+				return messages, nil
+			}
+			return nil, fmt.Errorf("truncated netlink capture: %w", err)
+		}
+
+		size := binary.LittleEndian.Uint32(sizeBuffer)
+		m := netlink.Message{Data: make([]byte, size)}
+		if _, err := io.ReadFull(r, m.Data); err != nil {
+			return nil, fmt.Errorf("truncated netlink capture: %w", err)
+		}
+		messages = append(messages, m)
+	}
+}
+
+// ReplayCapture runs recorded netlink messages through the decoder and the cache of a conntracker
+// capped at maxStateSize entries. It returns the entries of the resulting cache along with
+// all the NAT entries found in the capture.
+func ReplayCapture(ctx context.Context, r io.Reader, maxStateSize int) (cached []network.NATEntry, recorded []network.NATEntry, err error) {
+	msgs, err := ReadCapture(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctr := &realConntracker{
+		cache:        newConntrackCache(maxStateSize, defaultOrphanTimeout),
+		maxStateSize: maxStateSize,
+		decoder:      NewDecoder(),
+	}
+
+	// recorded events don't hold pooled buffers, so they can be decoded more than once
+	events := make(chan Event, 1)
+	events <- Event{msgs: msgs}
+	close(events)
+	ctr.loadInitialState(events)
+
+	recorded = appendNATEntries(nil, NewDecoder().DecodeAndReleaseEvent(Event{msgs: msgs}))
+	cached, err = ctr.DumpCachedTable(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cached, recorded, nil
+}
+
+// appendNATEntries appends the translations of the NAT connections to entries, in both directions,
+// the same way they are registered in the conntrack cache
+func appendNATEntries(entries []network.NATEntry, conns []Con) []network.NATEntry {
+	for _, c := range conns {
+		if !IsNAT(c) {
+			continue
+		}
+
+		origin, ok := formatKey(c.Origin)
+		if !ok {
+			continue
+		}
+		reply, ok := formatKey(c.Reply)
+		if !ok {
+			continue
+		}
+
+		netns := uint32(c.NetNS)
+		entries = append(entries,
+			network.NewNATEntry(origin.transport, netns, origin.srcIP, origin.srcPort, origin.dstIP, origin.dstPort, formatIPTranslation(c.Reply)),
+			network.NewNATEntry(reply.transport, netns, reply.srcIP, reply.srcPort, reply.dstIP, reply.dstPort, formatIPTranslation(c.Origin)),
+		)
+	}
+	return entries
+}
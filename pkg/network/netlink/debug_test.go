@@ -0,0 +1,85 @@
+// +build linux
+// +build !android
+
+package netlink
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/mdlayher/netlink"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestCaptureRoundTrip(t *testing.T) {
+	msgs := []netlink.Message{
+		{Data: []byte{0x1, 0x2, 0x3}},
+		{Data: []byte{}},
+		{Data: []byte{0x4}},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCapture(&buf, msgs))
+
+	read, err := ReadCapture(bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+	require.Len(t, read, len(msgs))
+	for i := range msgs {
+		assert.Equal(t, msgs[i].Data, read[i].Data)
+	}
+
+	_, err = ReadCapture(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	assert.Error(t, err)
+}
+
+func TestReplayCapture(t *testing.T) {
+	conns := []Con{
+		makeTranslatedConn(net.ParseIP("10.0.0.1"), net.ParseIP("20.0.0.1"), net.ParseIP("30.0.0.1"), unix.IPPROTO_TCP, 12345, 80, 8080),
+		makeTranslatedConn(net.ParseIP("10.0.0.2"), net.ParseIP("20.0.0.2"), net.ParseIP("30.0.0.2"), unix.IPPROTO_UDP, 53000, 53, 5353),
+		makeUntranslatedConn(net.ParseIP("10.0.0.3"), net.ParseIP("30.0.0.3"), unix.IPPROTO_TCP, 40000, 443),
+	}
+
+	var msgs []netlink.Message
+	for i := range conns {
+		data, err := EncodeConn(&conns[i])
+		require.NoError(t, err)
+		msgs = append(msgs, netlink.Message{Data: append([]byte{unix.AF_INET, unix.NFNETLINK_V0, 0, 0}, data...)})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCapture(&buf, msgs))
+
+	t.Run("all entries fit", func(t *testing.T) {
+		cached, recorded, err := ReplayCapture(context.Background(), bytes.NewReader(buf.Bytes()), 100)
+		require.NoError(t, err)
+		// the untranslated connection is ignored, the others are registered in both directions
+		assert.Len(t, recorded, 4)
+		assert.ElementsMatch(t, recorded, cached)
+
+		assert.Contains(t, recorded, network.NATEntry{
+			Proto:       "TCP",
+			Key:         network.NATTuple{Src: "10.0.0.1", SPort: 12345, Dst: "30.0.0.1", DPort: 8080},
+			Translation: network.NATTuple{Src: "20.0.0.1", SPort: 80, Dst: "10.0.0.1", DPort: 12345},
+		})
+
+		drift := network.CompareNATTables(cached, recorded)
+		assert.Empty(t, drift.Missing)
+		assert.Empty(t, drift.Stale)
+		assert.Empty(t, drift.Mismatched)
+	})
+
+	t.Run("cache too small", func(t *testing.T) {
+		cached, recorded, err := ReplayCapture(context.Background(), bytes.NewReader(buf.Bytes()), 2)
+		require.NoError(t, err)
+		assert.Len(t, cached, 2)
+
+		drift := network.CompareNATTables(cached, recorded)
+		assert.Len(t, drift.Missing, 2)
+		assert.Empty(t, drift.Stale)
+	})
+}
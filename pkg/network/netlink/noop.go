@@ -3,7 +3,11 @@
 
 package netlink
 
-import "github.com/DataDog/datadog-agent/pkg/network"
+import (
+	"context"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+)
 
 type noOpConntracker struct{}
 
@@ -24,6 +28,10 @@ func (*noOpConntracker) IsSampling() bool {
 	return false
 }
 
+func (*noOpConntracker) DumpCachedTable(_ context.Context) ([]network.NATEntry, error) {
+	return nil, nil
+}
+
 func (*noOpConntracker) Close() {}
 
 func (*noOpConntracker) GetStats() map[string]int64 {
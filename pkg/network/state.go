@@ -58,6 +58,12 @@ type State interface {
 
 	// DebugState returns a map with the current network state for a client ID
 	DumpState(clientID string) map[string]interface{}
+
+	// Checkpoint returns a serializable copy of the bookkeeping of every client
+	Checkpoint() *StateCheckpoint
+
+	// Restore replaces the bookkeeping of every client with the content of a checkpoint
+	Restore(cp *StateCheckpoint) error
 }
 
 // Delta represents a delta of network data compared to the last call to State.
@@ -0,0 +1,328 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/dns"
+	"github.com/DataDog/datadog-agent/pkg/network/http"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// stateCheckpointVersion must be bumped whenever the format of StateCheckpoint changes
+const stateCheckpointVersion = 2
+
+// ErrInconsistentCheckpoint is returned when a checkpoint cannot be restored because it
+// does not match the current kernel state
+var ErrInconsistentCheckpoint = errors.New("inconsistent network state checkpoint")
+
+// StateCheckpoint is a serializable copy of the per-client bookkeeping of a State,
+// used to carry the state of the clients across system-probe restarts.
+//
+// The per-connection totals are not part of the checkpoint: the eBPF connection maps
+// are not pinned, so the kernel counters start again from 0 after a restart and the
+// totals of the previous run cannot be compared with them.
+type StateCheckpoint struct {
+	Version int `json:"version"`
+	// BootID identifies the boot during which the checkpoint was taken
+	BootID string `json:"boot_id"`
+	// LatestTimeEpoch is the latest bpf time known by the state when the checkpoint was taken
+	LatestTimeEpoch uint64    `json:"latest_bpf_time_ns"`
+	Timestamp       time.Time `json:"timestamp"`
+
+	Clients map[string]*ClientCheckpoint `json:"clients"`
+}
+
+// ClientCheckpoint holds the checkpointed state of a single client
+type ClientCheckpoint struct {
+	LastFetch         time.Time                    `json:"last_fetch"`
+	ClosedConnections []ClosedConnectionCheckpoint `json:"closed_connections"`
+}
+
+// ClosedConnectionCheckpoint holds a closed connection buffered for a client
+type ClosedConnectionCheckpoint struct {
+	Source string `json:"source"`
+	Dest   string `json:"dest"`
+
+	MonotonicSentBytes      uint64 `json:"monotonic_sent_bytes"`
+	MonotonicRecvBytes      uint64 `json:"monotonic_recv_bytes"`
+	MonotonicSentPackets    uint64 `json:"monotonic_sent_packets"`
+	MonotonicRecvPackets    uint64 `json:"monotonic_recv_packets"`
+	LastUpdateEpoch         uint64 `json:"last_update_epoch"`
+	MonotonicRetransmits    uint32 `json:"monotonic_retransmits"`
+	RTT                     uint32 `json:"rtt"`
+	RTTVar                  uint32 `json:"rtt_var"`
+	MonotonicTCPEstablished uint32 `json:"monotonic_tcp_established"`
+	MonotonicTCPClosed      uint32 `json:"monotonic_tcp_closed"`
+
+	Pid              uint32              `json:"pid"`
+	NetNS            uint32              `json:"netns"`
+	SPort            uint16              `json:"sport"`
+	DPort            uint16              `json:"dport"`
+	Type             ConnectionType      `json:"type"`
+	Family           ConnectionFamily    `json:"family"`
+	Direction        ConnectionDirection `json:"direction"`
+	SPortIsEphemeral EphemeralPortType   `json:"sport_is_ephemeral"`
+	IsAssured        bool                `json:"is_assured"`
+
+	IPTranslation *IPTranslationCheckpoint `json:"ip_translation,omitempty"`
+	ViaSubnet     string                   `json:"via_subnet,omitempty"`
+}
+
+// IPTranslationCheckpoint holds the NAT translation of a closed connection
+type IPTranslationCheckpoint struct {
+	ReplSrcIP   string `json:"repl_src_ip"`
+	ReplDstIP   string `json:"repl_dst_ip"`
+	ReplSrcPort uint16 `json:"repl_src_port"`
+	ReplDstPort uint16 `json:"repl_dst_port"`
+}
+
+// Validate checks that the checkpoint can be restored given the current boot ID and bpf time.
+// Checkpoints older than maxAge are rejected, since their clients would have expired anyway.
+func (cp *StateCheckpoint) Validate(bootID string, now time.Time, latestTime uint64, maxAge time.Duration) error {
+	switch {
+	case cp.Version != stateCheckpointVersion:
+		return fmt.Errorf("%w: unsupported version %d", ErrInconsistentCheckpoint, cp.Version)
+	case cp.BootID == "" || cp.BootID != bootID:
+		return fmt.Errorf("%w: checkpoint taken during boot %q, current boot is %q", ErrInconsistentCheckpoint, cp.BootID, bootID)
+	case cp.LatestTimeEpoch > latestTime:
+		return fmt.Errorf("%w: checkpoint bpf time %d is ahead of current bpf time %d", ErrInconsistentCheckpoint, cp.LatestTimeEpoch, latestTime)
+	case now.Sub(cp.Timestamp) > maxAge:
+		return fmt.Errorf("%w: checkpoint is older than %s", ErrInconsistentCheckpoint, maxAge)
+	}
+	return nil
+}
+
+// SaveStateCheckpoint atomically writes the checkpoint to the given path
+func SaveStateCheckpoint(path string, cp *StateCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("unable to serialize network state checkpoint: %w", err)
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("unable to create network state checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write network state checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write network state checkpoint: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadStateCheckpoint reads a checkpoint from the given path. The file is removed once read
+// so that a checkpoint is never restored twice.
+func LoadStateCheckpoint(path string) (*StateCheckpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, fmt.Errorf("unable to remove network state checkpoint: %w", err)
+	}
+
+	cp := new(StateCheckpoint)
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInconsistentCheckpoint, err)
+	}
+	return cp, nil
+}
+
+// Checkpoint returns a copy of the closed connections buffered for every client
+func (ns *networkState) Checkpoint() *StateCheckpoint {
+	ns.Lock()
+	defer ns.Unlock()
+
+	cp := &StateCheckpoint{
+		Version:         stateCheckpointVersion,
+		LatestTimeEpoch: ns.latestTimeEpoch,
+		Timestamp:       time.Now(),
+		Clients:         make(map[string]*ClientCheckpoint, len(ns.clients)),
+	}
+
+	for id, c := range ns.clients {
+		ccp := &ClientCheckpoint{
+			LastFetch:         c.lastFetch,
+			ClosedConnections: make([]ClosedConnectionCheckpoint, 0, len(c.closedConnections)),
+		}
+		for _, conn := range c.closedConnections {
+			ccp.ClosedConnections = append(ccp.ClosedConnections, closedConnectionCheckpoint(conn))
+		}
+		cp.Clients[id] = ccp
+	}
+
+	return cp
+}
+
+// Restore replaces the bookkeeping of every client with the content of the checkpoint.
+// The clients start with no per-connection totals, so that the first deltas they get
+// are computed against the counters of the freshly loaded eBPF maps.
+// The checkpoint must have been validated beforehand.
+func (ns *networkState) Restore(cp *StateCheckpoint) error {
+	if cp.Version != stateCheckpointVersion {
+		return fmt.Errorf("%w: unsupported version %d", ErrInconsistentCheckpoint, cp.Version)
+	}
+
+	ns.Lock()
+	defer ns.Unlock()
+
+	clients := make(map[string]*client, len(cp.Clients))
+	for id, ccp := range cp.Clients {
+		if ccp == nil {
+			return fmt.Errorf("%w: empty state for client %s", ErrInconsistentCheckpoint, id)
+		}
+
+		c := &client{
+			lastFetch:             ccp.LastFetch,
+			stats:                 map[string]*stats{},
+			closedConnections:     make([]ConnectionStats, 0, minClosedCapacity),
+			closedConnectionsKeys: make(map[string]int),
+			dnsStats:              dns.StatsByKeyByNameByType{},
+			httpStatsDelta:        map[http.Key]http.RequestStats{},
+		}
+
+		for _, ccc := range ccp.ClosedConnections {
+			conn, err := ccc.connectionStats()
+			if err != nil {
+				return fmt.Errorf("%w: client %s: %s", ErrInconsistentCheckpoint, id, err)
+			}
+
+			key, err := conn.ByteKey(ns.buf)
+			if err != nil {
+				continue
+			}
+			if i, ok := c.closedConnectionsKeys[string(key)]; ok {
+				addConnections(&c.closedConnections[i], &conn)
+				continue
+			}
+			if len(c.closedConnections) >= ns.maxClosedConns {
+				ns.telemetry.closedConnDropped++
+				continue
+			}
+			c.closedConnections = append(c.closedConnections, conn)
+			c.closedConnectionsKeys[string(key)] = len(c.closedConnections) - 1
+		}
+
+		clients[id] = c
+	}
+
+	ns.clients = clients
+	if cp.LatestTimeEpoch > ns.latestTimeEpoch {
+		ns.latestTimeEpoch = cp.LatestTimeEpoch
+	}
+	return nil
+}
+
+func closedConnectionCheckpoint(c ConnectionStats) ClosedConnectionCheckpoint {
+	ccp := ClosedConnectionCheckpoint{
+		Source:                  c.Source.String(),
+		Dest:                    c.Dest.String(),
+		MonotonicSentBytes:      c.MonotonicSentBytes,
+		MonotonicRecvBytes:      c.MonotonicRecvBytes,
+		MonotonicSentPackets:    c.MonotonicSentPackets,
+		MonotonicRecvPackets:    c.MonotonicRecvPackets,
+		LastUpdateEpoch:         c.LastUpdateEpoch,
+		MonotonicRetransmits:    c.MonotonicRetransmits,
+		RTT:                     c.RTT,
+		RTTVar:                  c.RTTVar,
+		MonotonicTCPEstablished: c.MonotonicTCPEstablished,
+		MonotonicTCPClosed:      c.MonotonicTCPClosed,
+		Pid:                     c.Pid,
+		NetNS:                   c.NetNS,
+		SPort:                   c.SPort,
+		DPort:                   c.DPort,
+		Type:                    c.Type,
+		Family:                  c.Family,
+		Direction:               c.Direction,
+		SPortIsEphemeral:        c.SPortIsEphemeral,
+		IsAssured:               c.IsAssured,
+	}
+	if c.IPTranslation != nil {
+		ccp.IPTranslation = &IPTranslationCheckpoint{
+			ReplSrcIP:   c.IPTranslation.ReplSrcIP.String(),
+			ReplDstIP:   c.IPTranslation.ReplDstIP.String(),
+			ReplSrcPort: c.IPTranslation.ReplSrcPort,
+			ReplDstPort: c.IPTranslation.ReplDstPort,
+		}
+	}
+	if c.Via != nil {
+		ccp.ViaSubnet = c.Via.Subnet.Alias
+	}
+	return ccp
+}
+
+func (ccp ClosedConnectionCheckpoint) connectionStats() (ConnectionStats, error) {
+	parse := func(addr string) (util.Address, error) {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid address %q", addr)
+		}
+		return util.AddressFromNetIP(ip), nil
+	}
+
+	source, err := parse(ccp.Source)
+	if err != nil {
+		return ConnectionStats{}, err
+	}
+	dest, err := parse(ccp.Dest)
+	if err != nil {
+		return ConnectionStats{}, err
+	}
+
+	c := ConnectionStats{
+		Source:                  source,
+		Dest:                    dest,
+		MonotonicSentBytes:      ccp.MonotonicSentBytes,
+		MonotonicRecvBytes:      ccp.MonotonicRecvBytes,
+		MonotonicSentPackets:    ccp.MonotonicSentPackets,
+		MonotonicRecvPackets:    ccp.MonotonicRecvPackets,
+		LastUpdateEpoch:         ccp.LastUpdateEpoch,
+		MonotonicRetransmits:    ccp.MonotonicRetransmits,
+		RTT:                     ccp.RTT,
+		RTTVar:                  ccp.RTTVar,
+		MonotonicTCPEstablished: ccp.MonotonicTCPEstablished,
+		MonotonicTCPClosed:      ccp.MonotonicTCPClosed,
+		Pid:                     ccp.Pid,
+		NetNS:                   ccp.NetNS,
+		SPort:                   ccp.SPort,
+		DPort:                   ccp.DPort,
+		Type:                    ccp.Type,
+		Family:                  ccp.Family,
+		Direction:               ccp.Direction,
+		SPortIsEphemeral:        ccp.SPortIsEphemeral,
+		IsAssured:               ccp.IsAssured,
+	}
+
+	if t := ccp.IPTranslation; t != nil {
+		srcIP, err := parse(t.ReplSrcIP)
+		if err != nil {
+			return ConnectionStats{}, err
+		}
+		dstIP, err := parse(t.ReplDstIP)
+		if err != nil {
+			return ConnectionStats{}, err
+		}
+		c.IPTranslation = &IPTranslation{
+			ReplSrcIP:   srcIP,
+			ReplDstIP:   dstIP,
+			ReplSrcPort: t.ReplSrcPort,
+			ReplDstPort: t.ReplDstPort,
+		}
+	}
+	if ccp.ViaSubnet != "" {
+		c.Via = &Via{Subnet: Subnet{Alias: ccp.ViaSubnet}}
+	}
+	return c, nil
+}
@@ -0,0 +1,140 @@
+package network
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateCheckpointRoundTrip(t *testing.T) {
+	active := ConnectionStats{
+		Pid:                123,
+		Type:               TCP,
+		Family:             AFINET,
+		Source:             util.AddressFromString("10.0.0.1"),
+		Dest:               util.AddressFromString("10.0.0.2"),
+		SPort:              31890,
+		DPort:              80,
+		MonotonicSentBytes: 100,
+		MonotonicRecvBytes: 200,
+	}
+	closed := ConnectionStats{
+		Pid:                456,
+		Type:               TCP,
+		Family:             AFINET6,
+		Source:             util.AddressFromString("fd00::1"),
+		Dest:               util.AddressFromString("fd00::2"),
+		SPort:              40000,
+		DPort:              443,
+		MonotonicSentBytes: 10,
+		MonotonicTCPClosed: 1,
+		LastUpdateEpoch:    latestEpochTime(),
+		IPTranslation: &IPTranslation{
+			ReplSrcIP:   util.AddressFromString("fd00::3"),
+			ReplDstIP:   util.AddressFromString("fd00::1"),
+			ReplSrcPort: 443,
+			ReplDstPort: 40000,
+		},
+		Via: &Via{Subnet: Subnet{Alias: "subnet-1"}},
+	}
+	clientID := "1"
+
+	state := newDefaultState()
+	state.GetDelta(clientID, latestEpochTime(), []ConnectionStats{active}, nil, nil)
+	state.StoreClosedConnections([]ConnectionStats{closed})
+
+	path := filepath.Join(t.TempDir(), "network_state.json")
+	cp := state.Checkpoint()
+	cp.BootID = "boot"
+	require.NoError(t, SaveStateCheckpoint(path, cp))
+
+	loaded, err := LoadStateCheckpoint(path)
+	require.NoError(t, err)
+	require.NoError(t, loaded.Validate("boot", time.Now(), latestEpochTime(), time.Minute))
+
+	// the checkpoint can only be loaded once
+	_, err = LoadStateCheckpoint(path)
+	assert.True(t, os.IsNotExist(err))
+
+	restored := newDefaultState()
+	require.NoError(t, restored.Restore(loaded))
+
+	// the kernel counters start again from 0 after a restart, so the totals
+	// of the active connections are not restored
+	active.MonotonicSentBytes = 50
+	active.MonotonicRecvBytes = 10
+	conns := restored.GetDelta(clientID, latestEpochTime(), []ConnectionStats{active}, nil, nil).Conns
+	require.Len(t, conns, 2)
+
+	byPid := map[uint32]ConnectionStats{}
+	for _, c := range conns {
+		byPid[c.Pid] = c
+	}
+	assert.Equal(t, uint64(50), byPid[123].LastSentBytes)
+	assert.Equal(t, uint64(10), byPid[123].LastRecvBytes)
+
+	restoredClosed := byPid[456]
+	assert.Equal(t, closed.Source, restoredClosed.Source)
+	assert.Equal(t, closed.Dest, restoredClosed.Dest)
+	assert.Equal(t, uint64(10), restoredClosed.LastSentBytes)
+	assert.Equal(t, closed.IPTranslation, restoredClosed.IPTranslation)
+	assert.Equal(t, closed.Via, restoredClosed.Via)
+}
+
+func TestStateCheckpointValidate(t *testing.T) {
+	now := time.Now()
+	valid := func() *StateCheckpoint {
+		return &StateCheckpoint{
+			Version:         stateCheckpointVersion,
+			BootID:          "boot",
+			LatestTimeEpoch: 100,
+			Timestamp:       now.Add(-10 * time.Second),
+		}
+	}
+
+	assert.NoError(t, valid().Validate("boot", now, 200, time.Minute))
+
+	for name, tc := range map[string]struct {
+		mutate     func(*StateCheckpoint)
+		bootID     string
+		latestTime uint64
+	}{
+		"unknown version": {mutate: func(cp *StateCheckpoint) { cp.Version = 42 }, bootID: "boot", latestTime: 200},
+		"missing boot id": {mutate: func(cp *StateCheckpoint) { cp.BootID = "" }, bootID: "", latestTime: 200},
+		"other boot":      {mutate: func(*StateCheckpoint) {}, bootID: "other", latestTime: 200},
+		"bpf time ahead":  {mutate: func(*StateCheckpoint) {}, bootID: "boot", latestTime: 50},
+		"too old":         {mutate: func(cp *StateCheckpoint) { cp.Timestamp = now.Add(-2 * time.Minute) }, bootID: "boot", latestTime: 200},
+	} {
+		t.Run(name, func(t *testing.T) {
+			cp := valid()
+			tc.mutate(cp)
+			err := cp.Validate(tc.bootID, now, tc.latestTime, time.Minute)
+			assert.ErrorIs(t, err, ErrInconsistentCheckpoint)
+		})
+	}
+}
+
+func TestStateCheckpointCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "network_state.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte("{not json"), 0600))
+
+	_, err := LoadStateCheckpoint(path)
+	assert.ErrorIs(t, err, ErrInconsistentCheckpoint)
+
+	state := newDefaultState()
+	err = state.Restore(&StateCheckpoint{
+		Version: stateCheckpointVersion,
+		Clients: map[string]*ClientCheckpoint{
+			"1": {ClosedConnections: []ClosedConnectionCheckpoint{{Source: "not an ip", Dest: "10.0.0.1"}}},
+		},
+	})
+	assert.ErrorIs(t, err, ErrInconsistentCheckpoint)
+	// a failed restore leaves the state untouched
+	assert.Empty(t, state.(*networkState).getClients())
+}
@@ -340,6 +340,32 @@ func (e *ebpfConntracker) GetStats() map[string]int64 {
 	return m
 }
 
+func (e *ebpfConntracker) DumpCachedTable(ctx context.Context) ([]network.NATEntry, error) {
+	var entries []network.NATEntry
+	var src, dst netebpf.ConntrackTuple
+	it := e.ctMap.Iterate()
+	for it.Next(unsafe.Pointer(&src), unsafe.Pointer(&dst)) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		proto := network.TCP
+		if src.Type() == netebpf.UDP {
+			proto = network.UDP
+		}
+		entries = append(entries, network.NewNATEntry(proto, src.Netns, src.SourceAddress(), src.Sport, src.DestAddress(), src.Dport, &network.IPTranslation{
+			ReplSrcIP:   dst.SourceAddress(),
+			ReplDstIP:   dst.DestAddress(),
+			ReplSrcPort: dst.Sport,
+			ReplDstPort: dst.Dport,
+		}))
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("unable to iterate ebpf conntrack map: %w", err)
+	}
+	return entries, nil
+}
+
 func (e *ebpfConntracker) Close() {
 	err := e.m.Stop(manager.CleanAll)
 	if err != nil {
@@ -0,0 +1,85 @@
+// +build linux_bpf
+
+package tracer
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ddebpf "github.com/DataDog/datadog-agent/pkg/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+func readBootID(procRoot string) (string, error) {
+	raw, err := ioutil.ReadFile(filepath.Join(procRoot, "sys/kernel/random/boot_id"))
+	if err != nil {
+		return "", fmt.Errorf("unable to read boot id: %w", err)
+	}
+	return strings.TrimSpace(string(raw)), nil
+}
+
+// restoreState restores the state of the clients from the checkpoint file, if any.
+// Failing to restore the state is not fatal: the tracer then starts with an empty state.
+func restoreState(cfg *config.Config, state network.State) {
+	if cfg.StateCheckpointFile == "" {
+		return
+	}
+
+	cp, err := network.LoadStateCheckpoint(cfg.StateCheckpointFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		log.Warnf("unable to load network state checkpoint, starting with an empty state: %s", err)
+		return
+	}
+
+	bootID, err := readBootID(cfg.ProcRoot)
+	if err != nil {
+		log.Warnf("unable to validate network state checkpoint, starting with an empty state: %s", err)
+		return
+	}
+	latestTime, err := ddebpf.NowNanoseconds()
+	if err != nil {
+		log.Warnf("unable to validate network state checkpoint, starting with an empty state: %s", err)
+		return
+	}
+
+	if err := cp.Validate(bootID, time.Now(), uint64(latestTime), cfg.ClientStateExpiry); err != nil {
+		log.Infof("discarding network state checkpoint: %s", err)
+		return
+	}
+	if err := state.Restore(cp); err != nil {
+		log.Warnf("unable to restore network state checkpoint, starting with an empty state: %s", err)
+		return
+	}
+	log.Infof("restored network state of %d client(s) from %s", len(cp.Clients), cfg.StateCheckpointFile)
+}
+
+// checkpointState saves the state of the clients to the checkpoint file, if configured
+func checkpointState(cfg *config.Config, state network.State) {
+	if cfg.StateCheckpointFile == "" {
+		return
+	}
+
+	bootID, err := readBootID(cfg.ProcRoot)
+	if err != nil {
+		log.Warnf("unable to checkpoint network state: %s", err)
+		return
+	}
+
+	cp := state.Checkpoint()
+	cp.BootID = bootID
+	if err := network.SaveStateCheckpoint(cfg.StateCheckpointFile, cp); err != nil {
+		log.Warnf("unable to checkpoint network state: %s", err)
+		return
+	}
+	log.Infof("saved network state of %d client(s) to %s", len(cp.Clients), cfg.StateCheckpointFile)
+}
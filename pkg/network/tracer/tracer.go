@@ -3,6 +3,7 @@
 package tracer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -136,6 +137,7 @@ func NewTracer(config *config.Config) (*Tracer, error) {
 		config.MaxDNSStatsBuffered,
 		config.MaxHTTPStatsBuffered,
 	)
+	restoreState(config, state)
 
 	tr := &Tracer{
 		config:                     config,
@@ -294,6 +296,7 @@ func (t *Tracer) Stop() {
 	t.ebpfTracer.Stop()
 	t.httpMonitor.Stop()
 	t.conntracker.Close()
+	checkpointState(t.config, t.state)
 }
 
 func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, error) {
@@ -579,6 +582,16 @@ func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 
 }
 
+// DebugCachedConntrack dumps the NAT translations cached by the conntracker
+func (t *Tracer) DebugCachedConntrack(ctx context.Context) ([]network.NATEntry, error) {
+	return t.conntracker.DumpCachedTable(ctx)
+}
+
+// DebugHostConntrack dumps the NAT translations of the conntrack tables of the host
+func (t *Tracer) DebugHostConntrack(ctx context.Context) ([]network.NATEntry, error) {
+	return netlink.DumpHostTable(ctx, t.config.ProcRoot, t.config.EnableConntrackAllNamespaces, nil)
+}
+
 // DebugEBPFMaps returns all maps registred in the eBPF manager
 func (t *Tracer) DebugEBPFMaps(maps ...string) (string, error) {
 	tracerMaps, err := t.ebpfTracer.DumpMaps(maps...)
@@ -3,6 +3,8 @@
 package tracer
 
 import (
+	"context"
+
 	"github.com/DataDog/datadog-agent/pkg/ebpf"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/network/config"
@@ -39,6 +41,16 @@ func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 	return nil, ebpf.ErrNotImplemented
 }
 
+// DebugCachedConntrack is not implemented on this OS for Tracer
+func (t *Tracer) DebugCachedConntrack(_ context.Context) ([]network.NATEntry, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
+// DebugHostConntrack is not implemented on this OS for Tracer
+func (t *Tracer) DebugHostConntrack(_ context.Context) ([]network.NATEntry, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
 // DebugEBPFMaps is not implemented on this OS for Tracer
 func (t *Tracer) DebugEBPFMaps(maps ...string) (string, error) {
 	return "", ebpf.ErrNotImplemented
@@ -3,6 +3,7 @@
 package tracer
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sync"
@@ -161,6 +162,16 @@ func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 	return nil, ebpf.ErrNotImplemented
 }
 
+// DebugCachedConntrack is not implemented on this OS for Tracer
+func (t *Tracer) DebugCachedConntrack(_ context.Context) ([]network.NATEntry, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
+// DebugHostConntrack is not implemented on this OS for Tracer
+func (t *Tracer) DebugHostConntrack(_ context.Context) ([]network.NATEntry, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
 // DebugEBPFMaps is not implemented on this OS for Tracer
 func (t *Tracer) DebugEBPFMaps(maps ...string) (string, error) {
 	return "", ebpf.ErrNotImplemented
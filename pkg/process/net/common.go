@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	model "github.com/DataDog/agent-payload/v5/process"
+	"github.com/DataDog/datadog-agent/pkg/network"
 	netEncoding "github.com/DataDog/datadog-agent/pkg/network/encoding"
 	procEncoding "github.com/DataDog/datadog-agent/pkg/process/encoding"
 	reqEncoding "github.com/DataDog/datadog-agent/pkg/process/encoding/request"
@@ -158,6 +160,75 @@ func (r *RemoteSysProbeUtil) GetConnections(clientID string) (*model.Connections
 	return conns, nil
 }
 
+// StreamConnections retrieves the active network connections of the given client from the system probe
+// service as a stream of frames, calling fn for each of them. Unlike GetConnections, the whole set of
+// connections is never held in a single payload.
+func (r *RemoteSysProbeUtil) StreamConnections(clientID string, fn func(netEncoding.FrameKind, *model.Connections) error) error {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s?client_id=%s", connectionsStreamURL, clientID), nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", contentTypeProtobuf)
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("conn stream request failed: Probe Path %s, url: %s, status code: %d", r.path, connectionsStreamURL, resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-type")
+	decoder := netEncoding.NewStreamDecoder(resp.Body, netEncoding.GetUnmarshaler(contentType))
+	for {
+		kind, conns, err := decoder.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := fn(kind, conns); err != nil {
+			return err
+		}
+	}
+}
+
+// GetContainerStats returns the network traffic of the given client aggregated by container, retrieved from the system probe service
+func (r *RemoteSysProbeUtil) GetContainerStats(clientID string) ([]*network.ContainerStats, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s?client_id=%s", containerStatsURL, clientID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("container stats request failed: Probe Path %s, url: %s, status code: %d", r.path, containerStatsURL, resp.StatusCode)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var stats []*network.ContainerStats
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
 // GetStats returns the expvar stats of the system probe
 func (r *RemoteSysProbeUtil) GetStats() (map[string]interface{}, error) {
 	req, err := http.NewRequest("GET", statsURL, nil)
@@ -8,10 +8,12 @@ import (
 )
 
 const (
-	connectionsURL = "http://unix/connections"
-	statsURL       = "http://unix/debug/stats"
-	procStatsURL   = "http://unix/proc/stats"
-	netType        = "unix"
+	connectionsURL       = "http://unix/connections"
+	connectionsStreamURL = "http://unix/connections/stream"
+	containerStatsURL    = "http://unix/connections/containers"
+	statsURL             = "http://unix/debug/stats"
+	procStatsURL         = "http://unix/proc/stats"
+	netType              = "unix"
 )
 
 // CheckPath is used in conjunction with calling the stats endpoint, since we are calling this
@@ -5,6 +5,8 @@ package net
 import (
 	model "github.com/DataDog/agent-payload/v5/process"
 	"github.com/DataDog/datadog-agent/pkg/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/network"
+	netEncoding "github.com/DataDog/datadog-agent/pkg/network/encoding"
 )
 
 // RemoteSysProbeUtil is not supported
@@ -30,6 +32,16 @@ func (r *RemoteSysProbeUtil) GetConnections(clientID string) (*model.Connections
 	return nil, ebpf.ErrNotImplemented
 }
 
+// StreamConnections is not supported
+func (r *RemoteSysProbeUtil) StreamConnections(clientID string, fn func(netEncoding.FrameKind, *model.Connections) error) error {
+	return ebpf.ErrNotImplemented
+}
+
+// GetContainerStats is not supported
+func (r *RemoteSysProbeUtil) GetContainerStats(clientID string) ([]*network.ContainerStats, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
 // GetStats is not supported
 func (r *RemoteSysProbeUtil) GetStats() (map[string]interface{}, error) {
 	return nil, ebpf.ErrNotImplemented
@@ -5,8 +5,10 @@ package net
 import "fmt"
 
 const (
-	connectionsURL = "http://localhost:3333/connections"
-	statsURL       = "http://localhost:3333/debug/stats"
+	connectionsURL       = "http://localhost:3333/connections"
+	connectionsStreamURL = "http://localhost:3333/connections/stream"
+	containerStatsURL    = "http://localhost:3333/connections/containers"
+	statsURL             = "http://localhost:3333/debug/stats"
 	// procStatsURL is not used in windows, the value is added to avoid compilation error in windows
 	procStatsURL = "http://localhost:3333/proc/stats"
 	netType      = "tcp"
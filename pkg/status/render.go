@@ -68,6 +68,9 @@ func FormatStatus(data []byte) (string, error) {
 	renderStatusTemplate(b, "/trace-agent.tmpl", stats["apmStats"])
 	renderStatusTemplate(b, "/aggregator.tmpl", aggregatorStats)
 	renderStatusTemplate(b, "/dogstatsd.tmpl", dogstatsdStats)
+	if config.Datadog.GetBool("tag_cardinality_budget.enabled") {
+		renderStatusTemplate(b, "/tagcardinality.tmpl", stats["tagCardinalityStats"])
+	}
 	if config.Datadog.GetBool("cluster_agent.enabled") || config.Datadog.GetBool("cluster_checks.enabled") {
 		renderStatusTemplate(b, "/clusteragent.tmpl", dcaStats)
 	}
@@ -0,0 +1,258 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+// Sources of the values of the tag rules
+const (
+	tagRuleSourceImage      = "image"
+	tagRuleSourceLabel      = "label"
+	tagRuleSourceAnnotation = "annotation"
+	tagRuleSourceEnv        = "env"
+	tagRuleSourceStatic     = "static"
+)
+
+// kubernetesNamespaceLabel is the label set by the kubelet on the containers of the pods
+const kubernetesNamespaceLabel = "io.kubernetes.pod.namespace"
+
+// TagRule is a user-defined tag extraction rule, configured in `tag_rules`
+type TagRule struct {
+	// Name identifies the rule in `agent tagger-list --explain`, defaults to its position
+	Name string `mapstructure:"name"`
+	// Kinds restricts the rule to some workloadmeta entity kinds: container or kubernetes_pod
+	Kinds []string `mapstructure:"kinds"`
+	// Namespaces restricts the rule to the entities of some Kubernetes namespaces
+	Namespaces []string `mapstructure:"namespaces"`
+	// Source is where the value of the tag is read: image, label, annotation, env or static
+	Source string `mapstructure:"source"`
+	// Key is the name of the label, annotation or environment variable
+	Key string `mapstructure:"key"`
+	// Pattern is a regular expression the source value must match
+	Pattern string `mapstructure:"pattern"`
+	// Tag is the name of the tag
+	Tag string `mapstructure:"tag"`
+	// Value is the value of the tag, it can reference the groups of the pattern like $1 or ${name}.
+	// Without a value, the tag has the first group of the pattern or the whole source value.
+	Value string `mapstructure:"value"`
+	// Transform is applied to the value of the tag: lowercase or uppercase
+	Transform string `mapstructure:"transform"`
+	// Cardinality of the tag: low, orchestrator or high, defaults to low
+	Cardinality string `mapstructure:"cardinality"`
+}
+
+// tagRule is a validated TagRule
+type tagRule struct {
+	TagRule
+	kinds       map[workloadmeta.Kind]struct{}
+	namespaces  map[string]struct{}
+	pattern     *regexp.Regexp
+	cardinality TagCardinality
+}
+
+// tagRuleInput holds the metadata of an entity the tag rules are evaluated on
+type tagRuleInput struct {
+	kind        workloadmeta.Kind
+	namespace   string
+	image       string
+	labels      map[string]string
+	annotations map[string]string
+	envVars     map[string]string
+}
+
+// RuleTags maps the tags produced by tag rules to the name of their rule
+type RuleTags map[string]string
+
+// loadTagRules loads the tag rules of the configuration, invalid rules are skipped
+func loadTagRules() []*tagRule {
+	var rules []TagRule
+	if err := config.Datadog.UnmarshalKey("tag_rules", &rules); err != nil {
+		log.Errorf("Unable to parse tag_rules, the tag rules are ignored: %s", err)
+		return nil
+	}
+
+	compiled := make([]*tagRule, 0, len(rules))
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("tag_rules[%d]", i)
+		}
+		r, err := newTagRule(rule)
+		if err != nil {
+			log.Errorf("Invalid tag rule %s, skipping it: %s", rule.Name, err)
+			continue
+		}
+		compiled = append(compiled, r)
+	}
+
+	return compiled
+}
+
+func newTagRule(rule TagRule) (*tagRule, error) {
+	r := &tagRule{TagRule: rule}
+
+	if rule.Tag == "" {
+		return nil, errors.New("tag must be set")
+	}
+
+	switch rule.Source {
+	case tagRuleSourceLabel, tagRuleSourceAnnotation, tagRuleSourceEnv:
+		if rule.Key == "" {
+			return nil, fmt.Errorf("key must be set for the %s source", rule.Source)
+		}
+	case tagRuleSourceStatic:
+		if rule.Value == "" {
+			return nil, errors.New("value must be set for the static source")
+		}
+	case tagRuleSourceImage:
+	default:
+		return nil, fmt.Errorf("unknown source %q, expected image, label, annotation, env or static", rule.Source)
+	}
+
+	if len(rule.Kinds) > 0 {
+		r.kinds = make(map[workloadmeta.Kind]struct{}, len(rule.Kinds))
+		for _, kind := range rule.Kinds {
+			switch k := workloadmeta.Kind(kind); k {
+			case workloadmeta.KindContainer, workloadmeta.KindKubernetesPod:
+				r.kinds[k] = struct{}{}
+			default:
+				return nil, fmt.Errorf("unsupported kind %q, expected container or kubernetes_pod", kind)
+			}
+		}
+	}
+
+	if len(rule.Namespaces) > 0 {
+		r.namespaces = make(map[string]struct{}, len(rule.Namespaces))
+		for _, ns := range rule.Namespaces {
+			r.namespaces[ns] = struct{}{}
+		}
+	}
+
+	if rule.Pattern != "" {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %w", err)
+		}
+		r.pattern = re
+	}
+
+	switch rule.Transform {
+	case "", "lowercase", "uppercase":
+	default:
+		return nil, fmt.Errorf("unknown transform %q, expected lowercase or uppercase", rule.Transform)
+	}
+
+	r.cardinality = LowCardinality
+	if rule.Cardinality != "" {
+		cardinality, err := StringToTagCardinality(rule.Cardinality)
+		if err != nil {
+			return nil, err
+		}
+		r.cardinality = cardinality
+	}
+
+	return r, nil
+}
+
+// value returns the value of the tag produced by the rule for an entity, if any
+func (r *tagRule) value(input tagRuleInput) (string, bool) {
+	if r.kinds != nil {
+		if _, found := r.kinds[input.kind]; !found {
+			return "", false
+		}
+	}
+
+	if r.namespaces != nil {
+		if _, found := r.namespaces[input.namespace]; !found {
+			return "", false
+		}
+	}
+
+	var source string
+	var found bool
+	switch r.Source {
+	case tagRuleSourceImage:
+		source, found = input.image, input.image != ""
+	case tagRuleSourceLabel:
+		source, found = input.labels[r.Key]
+	case tagRuleSourceAnnotation:
+		source, found = input.annotations[r.Key]
+	case tagRuleSourceEnv:
+		source, found = input.envVars[r.Key]
+	case tagRuleSourceStatic:
+		source, found = r.Value, true
+	}
+	if !found {
+		return "", false
+	}
+
+	value := source
+	if r.pattern != nil {
+		match := r.pattern.FindStringSubmatchIndex(source)
+		if match == nil {
+			return "", false
+		}
+		switch {
+		case r.Value != "":
+			value = string(r.pattern.ExpandString(nil, r.Value, source, match))
+		case len(match) >= 4 && match[2] >= 0:
+			value = source[match[2]:match[3]]
+		}
+	} else if r.Value != "" {
+		value = r.Value
+	}
+
+	switch r.Transform {
+	case "lowercase":
+		value = strings.ToLower(value)
+	case "uppercase":
+		value = strings.ToUpper(value)
+	}
+
+	return value, value != ""
+}
+
+// applyTagRules adds the tags produced by the rules for an entity to the tag list,
+// and records the rule that produced each of them in ruleTags
+func applyTagRules(rules []*tagRule, input tagRuleInput, tags *utils.TagList, ruleTags RuleTags) {
+	for _, r := range rules {
+		value, ok := r.value(input)
+		if !ok {
+			continue
+		}
+
+		switch r.cardinality {
+		case HighCardinality:
+			tags.AddHigh(r.Tag, value)
+		case OrchestratorCardinality:
+			tags.AddOrchestrator(r.Tag, value)
+		default:
+			tags.AddLow(r.Tag, value)
+		}
+		ruleTags[r.Tag+":"+value] = r.Name
+	}
+}
+
+// copyRuleTags returns a copy of ruleTags, nil if it's empty
+func copyRuleTags(ruleTags RuleTags) RuleTags {
+	if len(ruleTags) == 0 {
+		return nil
+	}
+	c := make(RuleTags, len(ruleTags))
+	for tag, rule := range ruleTags {
+		c[tag] = rule
+	}
+	return c
+}
@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package collectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/tagger/utils"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+func TestNewTagRule(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		rule TagRule
+		err  string
+	}{
+		{name: "no tag", rule: TagRule{Source: "image"}, err: "tag must be set"},
+		{name: "unknown source", rule: TagRule{Source: "file", Tag: "team"}, err: "unknown source"},
+		{name: "no key", rule: TagRule{Source: "label", Tag: "team"}, err: "key must be set"},
+		{name: "static without value", rule: TagRule{Source: "static", Tag: "team"}, err: "value must be set"},
+		{name: "unknown kind", rule: TagRule{Source: "image", Tag: "team", Kinds: []string{"ecs_task"}}, err: "unsupported kind"},
+		{name: "invalid pattern", rule: TagRule{Source: "image", Tag: "team", Pattern: "("}, err: "invalid pattern"},
+		{name: "unknown transform", rule: TagRule{Source: "image", Tag: "team", Transform: "title"}, err: "unknown transform"},
+		{name: "unknown cardinality", rule: TagRule{Source: "image", Tag: "team", Cardinality: "medium"}, err: "unsupported value"},
+		{name: "valid", rule: TagRule{Source: "env", Key: "TEAM", Tag: "team", Kinds: []string{"container"}, Cardinality: "orchestrator"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r, err := newTagRule(tc.rule)
+			if tc.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, OrchestratorCardinality, r.cardinality)
+		})
+	}
+}
+
+func TestApplyTagRules(t *testing.T) {
+	rules := []TagRule{
+		{Name: "team-from-image", Source: "image", Pattern: `^registry\.example\.com/(?P<team>[a-z]+)/`, Tag: "team"},
+		{Name: "image-registry", Source: "image", Pattern: `^([^/]+)/([a-z]+)/`, Tag: "registry", Value: "$1-$2"},
+		{Name: "channel", Source: "label", Key: "channel", Tag: "channel", Transform: "lowercase"},
+		{Name: "region", Source: "env", Key: "APP_REGION", Tag: "region", Cardinality: "high"},
+		{Name: "critical", Source: "static", Tag: "tier", Value: "critical", Namespaces: []string{"payments"}},
+		{Name: "pod-only", Source: "annotation", Key: "owner", Tag: "owner", Kinds: []string{"kubernetes_pod"}},
+	}
+	compiled := make([]*tagRule, 0, len(rules))
+	for _, rule := range rules {
+		r, err := newTagRule(rule)
+		require.NoError(t, err)
+		compiled = append(compiled, r)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		input    tagRuleInput
+		low      []string
+		high     []string
+		ruleTags RuleTags
+	}{
+		{
+			name: "container",
+			input: tagRuleInput{
+				kind:        workloadmeta.KindContainer,
+				namespace:   "payments",
+				image:       "registry.example.com/billing/api:1.2",
+				labels:      map[string]string{"channel": "Stable"},
+				envVars:     map[string]string{"APP_REGION": "eu-west-1"},
+				annotations: map[string]string{"owner": "alice"},
+			},
+			low:  []string{"channel:stable", "registry:registry.example.com-billing", "team:billing", "tier:critical"},
+			high: []string{"region:eu-west-1"},
+			ruleTags: RuleTags{
+				"team:billing":                          "team-from-image",
+				"registry:registry.example.com-billing": "image-registry",
+				"channel:stable":                        "channel",
+				"region:eu-west-1":                      "region",
+				"tier:critical":                         "critical",
+			},
+		},
+		{
+			name: "pod of another namespace",
+			input: tagRuleInput{
+				kind:        workloadmeta.KindKubernetesPod,
+				namespace:   "default",
+				annotations: map[string]string{"owner": "alice"},
+			},
+			low:      []string{"owner:alice"},
+			ruleTags: RuleTags{"owner:alice": "pod-only"},
+		},
+		{
+			name: "image not matching",
+			input: tagRuleInput{
+				kind:  workloadmeta.KindContainer,
+				image: "nginx:latest",
+			},
+			ruleTags: RuleTags{},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tags := utils.NewTagList()
+			ruleTags := make(RuleTags)
+			applyTagRules(compiled, tc.input, tags, ruleTags)
+
+			low, _, high, _ := tags.Compute()
+			assert.ElementsMatch(t, tc.low, low)
+			assert.ElementsMatch(t, tc.high, high)
+			assert.Equal(t, tc.ruleTags, ruleTags)
+		})
+	}
+}
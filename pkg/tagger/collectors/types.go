@@ -19,6 +19,7 @@ type TagInfo struct {
 	OrchestratorCardTags []string  // orchestrator cardinality tags that have as many combination as pods/tasks
 	LowCardTags          []string  // low cardinality tags safe for every pipeline
 	StandardTags         []string  // the discovered standard tags (env, version, service) for the entity
+	RuleTags             RuleTags  // the tags produced by user-defined tag rules, mapped to the name of their rule
 	DeleteEntity         bool      // true if the entity is to be deleted from the store
 	ExpiryDate           time.Time // keep in cache until expiryDate
 }
@@ -130,6 +130,10 @@ func (c *WorkloadMetaCollector) processEvents(evBundle workloadmeta.EventBundle)
 				tagInfos = append(tagInfos, c.handleKubePod(ev)...)
 			case workloadmeta.KindECSTask:
 				tagInfos = append(tagInfos, c.handleECSTask(ev)...)
+			case workloadmeta.KindProcess:
+				tagInfos = append(tagInfos, c.handleProcess(ev)...)
+			case workloadmeta.KindSystemdUnit:
+				tagInfos = append(tagInfos, c.handleSystemdUnit(ev)...)
 			default:
 				log.Errorf("cannot handle event for entity %q with kind %q", entityID.ID, entityID.Kind)
 			}
@@ -218,6 +222,16 @@ func (c *WorkloadMetaCollector) handleContainer(ev workloadmeta.Event) []*TagInf
 		tags.AddLow(tag, value)
 	}
 
+	// user-defined tag rules
+	ruleTags := make(RuleTags)
+	applyTagRules(c.tagRules, tagRuleInput{
+		kind:      workloadmeta.KindContainer,
+		namespace: container.Labels[kubernetesNamespaceLabel],
+		image:     image.RawName,
+		labels:    container.Labels,
+		envVars:   container.EnvVars,
+	}, tags, ruleTags)
+
 	low, orch, high, standard := tags.Compute()
 	return []*TagInfo{
 		{
@@ -227,6 +241,7 @@ func (c *WorkloadMetaCollector) handleContainer(ev workloadmeta.Event) []*TagInf
 			OrchestratorCardTags: orch,
 			LowCardTags:          low,
 			StandardTags:         standard,
+			RuleTags:             copyRuleTags(ruleTags),
 		},
 	}
 }
@@ -271,6 +286,15 @@ func (c *WorkloadMetaCollector) handleKubePod(ev workloadmeta.Event) []*TagInfo
 		c.extractTagsFromPodOwner(pod, owner, tags)
 	}
 
+	// user-defined tag rules, the containers of the pod inherit their tags
+	ruleTags := make(RuleTags)
+	applyTagRules(c.tagRules, tagRuleInput{
+		kind:        workloadmeta.KindKubernetesPod,
+		namespace:   pod.Namespace,
+		labels:      pod.Labels,
+		annotations: pod.Annotations,
+	}, tags, ruleTags)
+
 	low, orch, high, standard := tags.Compute()
 	tagInfos := []*TagInfo{
 		{
@@ -280,11 +304,12 @@ func (c *WorkloadMetaCollector) handleKubePod(ev workloadmeta.Event) []*TagInfo
 			OrchestratorCardTags: orch,
 			LowCardTags:          low,
 			StandardTags:         standard,
+			RuleTags:             copyRuleTags(ruleTags),
 		},
 	}
 
 	for _, podContainer := range pod.Containers {
-		cTagInfo, err := c.extractTagsFromPodContainer(pod, podContainer, tags.Copy())
+		cTagInfo, err := c.extractTagsFromPodContainer(pod, podContainer, tags.Copy(), ruleTags)
 		if err != nil {
 			log.Debugf("cannot extract tags from pod container: %s", err)
 			continue
@@ -368,6 +393,46 @@ func (c *WorkloadMetaCollector) handleECSTask(ev workloadmeta.Event) []*TagInfo
 	return tagInfos
 }
 
+func (c *WorkloadMetaCollector) handleProcess(ev workloadmeta.Event) []*TagInfo {
+	process := ev.Entity.(*workloadmeta.Process)
+
+	tags := utils.NewTagList()
+	tags.AddLow("process_name", process.Name)
+	tags.AddLow("user", process.User)
+	tags.AddLow("systemd_unit", process.SystemdUnit)
+
+	low, orch, high, standard := tags.Compute()
+	return []*TagInfo{
+		{
+			Source:               processSource,
+			Entity:               buildTaggerEntityID(process.EntityID),
+			HighCardTags:         high,
+			OrchestratorCardTags: orch,
+			LowCardTags:          low,
+			StandardTags:         standard,
+		},
+	}
+}
+
+func (c *WorkloadMetaCollector) handleSystemdUnit(ev workloadmeta.Event) []*TagInfo {
+	unit := ev.Entity.(*workloadmeta.SystemdUnit)
+
+	tags := utils.NewTagList()
+	tags.AddLow("systemd_unit", unit.ID)
+
+	low, orch, high, standard := tags.Compute()
+	return []*TagInfo{
+		{
+			Source:               unitSource,
+			Entity:               buildTaggerEntityID(unit.EntityID),
+			HighCardTags:         high,
+			OrchestratorCardTags: orch,
+			LowCardTags:          low,
+			StandardTags:         standard,
+		},
+	}
+}
+
 func (c *WorkloadMetaCollector) extractTagsFromPodLabels(pod *workloadmeta.KubernetesPod, tags *utils.TagList) {
 	for name, value := range pod.Labels {
 		switch name {
@@ -437,7 +502,7 @@ func (c *WorkloadMetaCollector) extractTagsFromPodOwner(pod *workloadmeta.Kubern
 	}
 }
 
-func (c *WorkloadMetaCollector) extractTagsFromPodContainer(pod *workloadmeta.KubernetesPod, podContainer workloadmeta.OrchestratorContainer, tags *utils.TagList) (*TagInfo, error) {
+func (c *WorkloadMetaCollector) extractTagsFromPodContainer(pod *workloadmeta.KubernetesPod, podContainer workloadmeta.OrchestratorContainer, tags *utils.TagList, ruleTags RuleTags) (*TagInfo, error) {
 	container, err := c.store.GetContainer(podContainer.ID)
 	if err != nil {
 		return nil, fmt.Errorf("pod %q has reference to non-existing container %q", pod.Name, podContainer.ID)
@@ -484,6 +549,7 @@ func (c *WorkloadMetaCollector) extractTagsFromPodContainer(pod *workloadmeta.Ku
 		OrchestratorCardTags: orch,
 		LowCardTags:          low,
 		StandardTags:         standard,
+		RuleTags:             copyRuleTags(ruleTags),
 	}, nil
 }
 
@@ -569,6 +635,10 @@ func buildTaggerEntityID(entityID workloadmeta.EntityID) string {
 		return kubelet.PodUIDToTaggerEntityName(entityID.ID)
 	case workloadmeta.KindECSTask:
 		return fmt.Sprintf("ecs_task://%s", entityID.ID)
+	case workloadmeta.KindProcess:
+		return fmt.Sprintf("process://%s", entityID.ID)
+	case workloadmeta.KindSystemdUnit:
+		return fmt.Sprintf("systemd_unit://%s", entityID.ID)
 	default:
 		log.Errorf("can't recognize entity %q with kind %q, but building a a tagger ID anyway", entityID.ID, entityID.Kind)
 		return containers.BuildEntityName(string(entityID.Kind), entityID.ID)
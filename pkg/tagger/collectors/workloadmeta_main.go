@@ -25,6 +25,8 @@ const (
 	podSource       = workloadmetaCollectorName + "-" + string(workloadmeta.KindKubernetesPod)
 	taskSource      = workloadmetaCollectorName + "-" + string(workloadmeta.KindECSTask)
 	containerSource = workloadmetaCollectorName + "-" + string(workloadmeta.KindContainer)
+	processSource   = workloadmetaCollectorName + "-" + string(workloadmeta.KindProcess)
+	unitSource      = workloadmetaCollectorName + "-" + string(workloadmeta.KindSystemdUnit)
 )
 
 // WorkloadMetaCollector collects tags from the metadata in the workloadmeta
@@ -49,6 +51,8 @@ type WorkloadMetaCollector struct {
 	globContainerEnvLabels map[string]glob.Glob
 
 	collectEC2ResourceTags bool
+
+	tagRules []*tagRule
 }
 
 // Detect initializes the WorkloadMetaCollector.
@@ -75,6 +79,8 @@ func (c *WorkloadMetaCollector) Detect(ctx context.Context, out chan<- []*TagInf
 
 	c.staticTags = fargateStaticTags(ctx)
 
+	c.tagRules = loadTagRules()
+
 	return StreamCollection, nil
 }
 
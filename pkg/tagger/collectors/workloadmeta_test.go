@@ -864,6 +864,76 @@ func TestHandleContainer(t *testing.T) {
 	}
 }
 
+func TestHandleProcess(t *testing.T) {
+	collector := &WorkloadMetaCollector{
+		children: make(map[string]map[string]struct{}),
+	}
+
+	actual := collector.handleProcess(workloadmeta.Event{
+		Type: workloadmeta.EventTypeSet,
+		Entity: &workloadmeta.Process{
+			EntityID: workloadmeta.EntityID{
+				Kind: workloadmeta.KindProcess,
+				ID:   "1234",
+			},
+			EntityMeta: workloadmeta.EntityMeta{
+				Name: "nginx",
+			},
+			PID:         1234,
+			Cmdline:     []string{"/usr/sbin/nginx", "-g", "daemon off;"},
+			User:        "www-data",
+			SystemdUnit: "nginx.service",
+		},
+	})
+
+	assertTagInfoListEqual(t, []*TagInfo{
+		{
+			Source:               processSource,
+			Entity:               "process://1234",
+			HighCardTags:         []string{},
+			OrchestratorCardTags: []string{},
+			LowCardTags: []string{
+				"process_name:nginx",
+				"systemd_unit:nginx.service",
+				"user:www-data",
+			},
+			StandardTags: []string{},
+		},
+	}, actual)
+}
+
+func TestHandleSystemdUnit(t *testing.T) {
+	collector := &WorkloadMetaCollector{
+		children: make(map[string]map[string]struct{}),
+	}
+
+	actual := collector.handleSystemdUnit(workloadmeta.Event{
+		Type: workloadmeta.EventTypeSet,
+		Entity: &workloadmeta.SystemdUnit{
+			EntityID: workloadmeta.EntityID{
+				Kind: workloadmeta.KindSystemdUnit,
+				ID:   "nginx.service",
+			},
+			EntityMeta: workloadmeta.EntityMeta{
+				Name: "nginx",
+			},
+			ActiveState: "active",
+			MainPID:     1234,
+		},
+	})
+
+	assertTagInfoListEqual(t, []*TagInfo{
+		{
+			Source:               unitSource,
+			Entity:               "systemd_unit://nginx.service",
+			HighCardTags:         []string{},
+			OrchestratorCardTags: []string{},
+			LowCardTags:          []string{"systemd_unit:nginx.service"},
+			StandardTags:         []string{},
+		},
+	}, actual)
+}
+
 func TestHandleDelete(t *testing.T) {
 	const (
 		podName       = "datadog-agent-foobar"
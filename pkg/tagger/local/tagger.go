@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator/tagbudget"
 	"github.com/DataDog/datadog-agent/pkg/tagset"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -77,6 +78,15 @@ func NewTagger(catalog collectors.Catalog) *Tagger {
 func (t *Tagger) Init() error {
 	t.retryTicker = time.NewTicker(30 * time.Second)
 
+	if budgetConfig := tagbudget.ConfigFromDatadog(); budgetConfig != nil {
+		budget, err := tagbudget.New("tagger", *budgetConfig)
+		if err != nil {
+			log.Errorf("Invalid tag_cardinality_budget, the tag cardinality budget of the tagger is disabled: %s", err)
+		} else {
+			t.store.SetCardinalityBudget(budget)
+		}
+	}
+
 	t.startCollectors(t.ctx)
 
 	go t.runPuller(t.ctx)
@@ -9,6 +9,7 @@ type sourceTags struct {
 	orchestratorCardTags []string
 	highCardTags         []string
 	standardTags         []string
+	ruleTags             map[string]string
 	expiryDate           time.Time
 }
 
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/api/response"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/tagbudget"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/tagger/subscriber"
@@ -43,6 +44,9 @@ type TagStore struct {
 
 	subscriber *subscriber.Subscriber
 
+	// budget limits the distinct values of the low cardinality tag keys, nil when disabled
+	budget *tagbudget.Budget
+
 	clock clock.Clock
 }
 
@@ -61,6 +65,14 @@ func newTagStoreWithClock(clock clock.Clock) *TagStore {
 	}
 }
 
+// SetCardinalityBudget applies a tag cardinality budget to the low cardinality tags
+// of the entities, the tags stored before aren't affected
+func (s *TagStore) SetCardinalityBudget(budget *tagbudget.Budget) {
+	s.Lock()
+	defer s.Unlock()
+	s.budget = budget
+}
+
 // Run performs background maintenance for TagStore.
 func (s *TagStore) Run(ctx context.Context) {
 	pruneTicker := time.NewTicker(1 * time.Minute)
@@ -134,6 +146,9 @@ func (s *TagStore) ProcessTagInfo(tagInfos []*collectors.TagInfo) {
 		// TODO: check if real change
 
 		telemetry.UpdatedEntities.Inc()
+		if s.budget != nil {
+			info.LowCardTags = s.budget.ApplyToSlice(info.LowCardTags, info.Source)
+		}
 		updateStoredTags(storedTags, info)
 
 		events = append(events, types.EntityEvent{
@@ -154,6 +169,7 @@ func updateStoredTags(storedTags *EntityTags, info *collectors.TagInfo) {
 		orchestratorCardTags: info.OrchestratorCardTags,
 		highCardTags:         info.HighCardTags,
 		standardTags:         info.StandardTags,
+		ruleTags:             info.RuleTags,
 		expiryDate:           info.ExpiryDate,
 	}
 }
@@ -329,6 +345,13 @@ func (s *TagStore) List() response.TaggerListResponse {
 			tags = append(tags, sourceTags.orchestratorCardTags...)
 			tags = append(tags, sourceTags.highCardTags...)
 			entity.Tags[source] = tags
+
+			if len(sourceTags.ruleTags) > 0 {
+				if entity.Rules == nil {
+					entity.Rules = make(map[string]map[string]string)
+				}
+				entity.Rules[source] = sourceTags.ruleTags
+			}
 		}
 
 		r.Entities[entityID] = entity
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/DataDog/datadog-agent/pkg/aggregator/tagbudget"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
 	"github.com/DataDog/datadog-agent/pkg/tagger/types"
 )
@@ -106,6 +107,57 @@ func (s *StoreTestSuite) TestLookupStandard() {
 	assert.NotNil(s.T(), err)
 }
 
+func (s *StoreTestSuite) TestListRules() {
+	s.store.ProcessTagInfo([]*collectors.TagInfo{
+		{
+			Source:      "source1",
+			Entity:      "test",
+			LowCardTags: []string{"tag", "team:billing"},
+			RuleTags:    collectors.RuleTags{"team:billing": "team-from-image"},
+		},
+		{
+			Source:      "source2",
+			Entity:      "test",
+			LowCardTags: []string{"tag"},
+		},
+	})
+
+	entity := s.store.List().Entities["test"]
+	assert.Len(s.T(), entity.Tags, 2)
+	assert.Equal(s.T(), map[string]map[string]string{
+		"source1": {"team:billing": "team-from-image"},
+	}, entity.Rules)
+}
+
+func (s *StoreTestSuite) TestCardinalityBudget() {
+	budget, err := tagbudget.New("tagger", tagbudget.Config{
+		Limits: map[string]int{"request_id": 1},
+		Action: tagbudget.ActionDrop,
+	})
+	assert.NoError(s.T(), err)
+	s.store.SetCardinalityBudget(budget)
+
+	s.store.ProcessTagInfo([]*collectors.TagInfo{
+		{
+			Source:       "source1",
+			Entity:       "test1",
+			LowCardTags:  []string{"request_id:1", "env:prod"},
+			HighCardTags: []string{"container_id:1"},
+		},
+		{
+			Source:       "source1",
+			Entity:       "test2",
+			LowCardTags:  []string{"request_id:2", "env:prod"},
+			HighCardTags: []string{"request_id:2"},
+		},
+	})
+
+	assert.ElementsMatch(s.T(), []string{"request_id:1", "env:prod"}, s.store.Lookup("test1", collectors.LowCardinality))
+	assert.ElementsMatch(s.T(), []string{"env:prod"}, s.store.Lookup("test2", collectors.LowCardinality))
+	// only the low cardinality tags are limited
+	assert.ElementsMatch(s.T(), []string{"env:prod", "request_id:2"}, s.store.Lookup("test2", collectors.HighCardinality))
+}
+
 func (s *StoreTestSuite) TestLookupNotPresent() {
 	tags := s.store.Lookup("test", collectors.LowCardinality)
 	assert.Nil(s.T(), tags)
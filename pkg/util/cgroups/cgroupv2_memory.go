@@ -79,6 +79,8 @@ func (c *cgroupV2) GetMemoryStats(stats *MemoryStats) error {
 	nilIfZero(&stats.SwapLimit)
 
 	if err := parse2ColumnStatsWithMapping(c.fr, c.pathFor("memory.events"), 0, 1, map[string]**uint64{
+		"high":     &stats.HighEvents,
+		"max":      &stats.MaxEvents,
 		"oom":      &stats.OOMEvents,
 		"oom_kill": &stats.OOMKiilEvents,
 	}); err != nil {
@@ -127,6 +127,8 @@ func TestCgroupV2MemoryStats(t *testing.T) {
 		ActiveFile:    uint64Ptr(0),
 		Unevictable:   uint64Ptr(0),
 		KernelMemory:  uint64Ptr(49152),
+		HighEvents:    uint64Ptr(1),
+		MaxEvents:     uint64Ptr(2),
 		OOMEvents:     uint64Ptr(3),
 		OOMKiilEvents: uint64Ptr(0),
 		PSISome: PSIStats{
@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package cgroups
+
+import (
+	"path/filepath"
+)
+
+// PressureStats holds the system-wide Pressure Stall Information
+// Source: /proc/pressure (Linux 4.20+, disabled by the psi=0 kernel parameter)
+type PressureStats struct {
+	CPUSome    PSIStats
+	CPUFull    PSIStats // Linux 5.13+, always zero at the system level
+	MemorySome PSIStats
+	MemoryFull PSIStats
+	IOSome     PSIStats
+	IOFull     PSIStats
+}
+
+// GetPressureStats reads the system-wide Pressure Stall Information from `$procPath/pressure`.
+// An error is returned if PSI is not available, missing `full` lines are left empty.
+func GetPressureStats(procPath string) (*PressureStats, error) {
+	return getPressureStats(defaultFileReader, procPath)
+}
+
+func getPressureStats(fr fileReader, procPath string) (*PressureStats, error) {
+	stats := &PressureStats{}
+
+	for _, resource := range []struct {
+		name       string
+		some, full *PSIStats
+	}{
+		{"cpu", &stats.CPUSome, &stats.CPUFull},
+		{"memory", &stats.MemorySome, &stats.MemoryFull},
+		{"io", &stats.IOSome, &stats.IOFull},
+	} {
+		if err := parsePSI(fr, filepath.Join(procPath, "pressure", resource.name), resource.some, resource.full); err != nil {
+			return nil, err
+		}
+	}
+
+	return stats, nil
+}
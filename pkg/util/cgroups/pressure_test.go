@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux
+// +build linux
+
+package cgroups
+
+import (
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPressureStats(t *testing.T) {
+	cfs := newCgroupMemoryFS("/test/fs/cgroup")
+
+	// PSI not available
+	tr.reset()
+	stats, err := getPressureStats(cfs, "/proc")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Nil(t, stats)
+
+	tr.reset()
+	cfs.files["/proc/pressure/cpu"] = "some avg10=1.50 avg60=0.75 avg300=0.10 total=123456"
+	cfs.files["/proc/pressure/memory"] = `some avg10=0.00 avg60=0.00 avg300=0.00 total=10
+full avg10=0.00 avg60=0.00 avg300=0.00 total=5`
+	cfs.files["/proc/pressure/io"] = `some avg10=12.00 avg60=6.00 avg300=2.00 total=987654
+full avg10=10.00 avg60=5.00 avg300=1.00 total=876543`
+	stats, err = getPressureStats(cfs, "/proc")
+	assert.NoError(t, err)
+	assert.Empty(t, tr.errors)
+	assert.Empty(t, cmp.Diff(PressureStats{
+		CPUSome: PSIStats{
+			Avg10:  float64Ptr(1.5),
+			Avg60:  float64Ptr(0.75),
+			Avg300: float64Ptr(0.1),
+			Total:  uint64Ptr(123456),
+		},
+		MemorySome: PSIStats{
+			Avg10:  float64Ptr(0),
+			Avg60:  float64Ptr(0),
+			Avg300: float64Ptr(0),
+			Total:  uint64Ptr(10),
+		},
+		MemoryFull: PSIStats{
+			Avg10:  float64Ptr(0),
+			Avg60:  float64Ptr(0),
+			Avg300: float64Ptr(0),
+			Total:  uint64Ptr(5),
+		},
+		IOSome: PSIStats{
+			Avg10:  float64Ptr(12),
+			Avg60:  float64Ptr(6),
+			Avg300: float64Ptr(2),
+			Total:  uint64Ptr(987654),
+		},
+		IOFull: PSIStats{
+			Avg10:  float64Ptr(10),
+			Avg60:  float64Ptr(5),
+			Avg300: float64Ptr(1),
+			Total:  uint64Ptr(876543),
+		},
+	}, *stats))
+}
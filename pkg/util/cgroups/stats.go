@@ -16,7 +16,7 @@ type PSIStats struct {
 	Avg10  *float64 // Percentage (0-100)
 	Avg60  *float64 // Percentage (0-100)
 	Avg300 *float64 // Percentage (0-100)
-	Total  *uint64  // Nanoseconds
+	Total  *uint64  // Microseconds
 }
 
 // MemoryStats - all metrics in bytes except if otherwise specified
@@ -49,6 +49,8 @@ type MemoryStats struct {
 	// This field is mapped to `memory.failcnt` for cgroupv1 and to "oom" in `memory.event`, it does not mean an OOMKill event happened.
 	OOMEvents     *uint64 // Number (no unit).
 	OOMKiilEvents *uint64 // cgroupv2 only
+	HighEvents    *uint64 // Number of times the cgroup was throttled above memory.high, cgroupv2 only
+	MaxEvents     *uint64 // Number of times the cgroup reached memory.max, cgroupv2 only
 
 	Limit             *uint64
 	MinThreshold      *uint64 // cgroupv2 only
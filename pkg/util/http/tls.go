@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+)
+
+// TLSOptions holds the usual `tls_*` options of the checks querying an HTTP endpoint
+type TLSOptions struct {
+	// Verify checks the certificate of the server
+	Verify bool
+	// ServerName overrides the name the certificate of the server is checked against
+	ServerName string
+	// CACert is the path of the CA certificates to check the certificate of the server with,
+	// the ones of the host are used when empty
+	CACert string
+	// Cert is the path of the client certificate
+	Cert string
+	// PrivateKey is the path of the private key of the client certificate, Cert is
+	// expected to hold it when empty
+	PrivateKey string
+}
+
+// NewTLSConfig returns the TLS configuration of a client per the options
+func NewTLSConfig(o TLSOptions) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !o.Verify,
+		ServerName:         o.ServerName,
+	}
+	if o.CACert != "" {
+		caCert, err := ioutil.ReadFile(o.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read tls_ca_cert: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in tls_ca_cert %s", o.CACert)
+		}
+	}
+	if o.Cert != "" {
+		keyFile := o.PrivateKey
+		if keyFile == "" {
+			keyFile = o.Cert
+		}
+		cert, err := tls.LoadX509KeyPair(o.Cert, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load tls_cert: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
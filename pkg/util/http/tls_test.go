@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package http
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTLSConfig(t *testing.T) {
+	tlsConfig, err := NewTLSConfig(TLSOptions{ServerName: "example.com"})
+	require.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify)
+	assert.Equal(t, "example.com", tlsConfig.ServerName)
+	assert.Nil(t, tlsConfig.RootCAs)
+	assert.Empty(t, tlsConfig.Certificates)
+}
+
+func TestNewTLSConfigCACert(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	caCert := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caCert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0600))
+
+	tlsConfig, err := NewTLSConfig(TLSOptions{Verify: true, CACert: caCert})
+	require.NoError(t, err)
+	assert.False(t, tlsConfig.InsecureSkipVerify)
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	invalid := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a certificate"), 0600))
+	_, err = NewTLSConfig(TLSOptions{Verify: true, CACert: invalid})
+	assert.Error(t, err)
+
+	_, err = NewTLSConfig(TLSOptions{Verify: true, CACert: filepath.Join(dir, "missing.pem")})
+	assert.Error(t, err)
+}
+
+func TestNewTLSConfigCertError(t *testing.T) {
+	_, err := NewTLSConfig(TLSOptions{Cert: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.Error(t, err)
+}
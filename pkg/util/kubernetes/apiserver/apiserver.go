@@ -206,7 +206,8 @@ func GetKubeClient(timeout time.Duration) (kubernetes.Interface, error) {
 	return kubernetes.NewForConfig(clientConfig)
 }
 
-func getKubeDynamicClient(timeout time.Duration) (dynamic.Interface, error) {
+// GetKubeDynamicClient returns a dynamic client to query custom resources.
+func GetKubeDynamicClient(timeout time.Duration) (dynamic.Interface, error) {
 	clientConfig, err := getClientConfig(timeout)
 	if err != nil {
 		return nil, err
@@ -236,7 +237,7 @@ func getKubeVPAClient(timeout time.Duration) (vpa.Interface, error) {
 func getWPAInformerFactory() (dynamicinformer.DynamicSharedInformerFactory, error) {
 	// default to 300s
 	resyncPeriodSeconds := time.Duration(config.Datadog.GetInt64("kubernetes_informers_resync_period"))
-	client, err := getKubeDynamicClient(0) // No timeout for the Informers, to allow long watch.
+	client, err := GetKubeDynamicClient(0) // No timeout for the Informers, to allow long watch.
 	if err != nil {
 		log.Infof("Could not get apiserver client: %v", err)
 		return nil, err
@@ -256,7 +257,7 @@ func getDDClient(timeout time.Duration) (dynamic.Interface, error) {
 func getDDInformerFactory() (dynamicinformer.DynamicSharedInformerFactory, error) {
 	// default to 300s
 	resyncPeriodSeconds := time.Duration(config.Datadog.GetInt64("kubernetes_informers_resync_period"))
-	client, err := getKubeDynamicClient(0) // No timeout for the Informers, to allow long watch.
+	client, err := GetKubeDynamicClient(0) // No timeout for the Informers, to allow long watch.
 	if err != nil {
 		log.Infof("Could not get apiserver client: %v", err)
 		return nil, err
@@ -299,7 +300,7 @@ func (c *APIClient) connect() error {
 	}
 
 	if config.Datadog.GetBool("admission_controller.enabled") || config.Datadog.GetBool("compliance_config.enabled") {
-		c.DynamicCl, err = getKubeDynamicClient(time.Duration(c.timeoutSeconds) * time.Second)
+		c.DynamicCl, err = GetKubeDynamicClient(time.Duration(c.timeoutSeconds) * time.Second)
 		if err != nil {
 			log.Infof("Could not get apiserver dynamic client: %v", err)
 			return err
@@ -351,7 +352,7 @@ func (c *APIClient) connect() error {
 			log.Errorf("Error getting WPA Informer Factory: %s", err.Error())
 			return err
 		}
-		if c.WPAClient, err = getKubeDynamicClient(time.Duration(c.timeoutSeconds) * time.Second); err != nil {
+		if c.WPAClient, err = GetKubeDynamicClient(time.Duration(c.timeoutSeconds) * time.Second); err != nil {
 			log.Errorf("Error getting WPA Client: %s", err.Error())
 			return err
 		}
@@ -11,6 +11,7 @@
 
 // +build systemd
 
+// Package systemd holds the helpers shared by the components talking to systemd
 package systemd
 
 import (
@@ -16,6 +16,13 @@ var tmplVarRegex = regexp.MustCompile(`%%.+?%%`)
 // TemplateVar is the info for a parsed template variable.
 type TemplateVar struct {
 	Raw, Name, Key []byte
+	Filters        []Filter
+}
+
+// Filter is a transformation applied to the value of a template variable,
+// written after the variable name: %%env_FOO|default:bar|lower%%
+type Filter struct {
+	Name, Arg []byte
 }
 
 // ParseString returns parsed template variables found in the input string.
@@ -28,21 +35,24 @@ func Parse(b []byte) []TemplateVar {
 	var parsed []TemplateVar
 	vars := tmplVarRegex.FindAll(b, -1)
 	for _, v := range vars {
-		name, key := parseTemplateVar(v)
-		parsed = append(parsed, TemplateVar{v, name, key})
+		name, key, filters := parseTemplateVar(v)
+		parsed = append(parsed, TemplateVar{v, name, key, filters})
 	}
 	return parsed
 }
 
-// parseTemplateVar extracts the name of the var and the key (or index if it can be
-// cast to an int)
-func parseTemplateVar(v []byte) (name, key []byte) {
+// parseTemplateVar extracts the name of the var, the key (or index if it can be
+// cast to an int) and the filters to apply to its value
+func parseTemplateVar(v []byte) (name, key []byte, filters []Filter) {
+	v = bytes.TrimSuffix(bytes.TrimPrefix(v, []byte("%%")), []byte("%%"))
+	parts := bytes.Split(v, []byte("|"))
+
 	stripped := bytes.Map(func(r rune) rune {
 		if unicode.IsSpace(r) || r == '%' {
 			return -1
 		}
 		return r
-	}, v)
+	}, parts[0])
 	split := bytes.SplitN(stripped, []byte("_"), 2)
 	name = split[0]
 	if len(split) == 2 {
@@ -50,5 +60,14 @@ func parseTemplateVar(v []byte) (name, key []byte) {
 	} else {
 		key = []byte("")
 	}
-	return name, key
+
+	for _, part := range parts[1:] {
+		split := bytes.SplitN(part, []byte(":"), 2)
+		filter := Filter{Name: bytes.TrimSpace(split[0])}
+		if len(split) == 2 {
+			filter.Arg = bytes.TrimSpace(split[1])
+		}
+		filters = append(filters, filter)
+	}
+	return name, key, filters
 }
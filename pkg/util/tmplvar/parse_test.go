@@ -45,9 +45,53 @@ func TestParseTemplateVar(t *testing.T) {
 
 	for i, testCase := range testCases {
 		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
-			name, key := parseTemplateVar([]byte(testCase.tmpl))
+			name, key, filters := parseTemplateVar([]byte(testCase.tmpl))
 			assert.Equal(t, testCase.name, string(name))
 			assert.Equal(t, testCase.key, string(key))
+			assert.Empty(t, filters)
+		})
+	}
+}
+
+func TestParseTemplateVarFilters(t *testing.T) {
+	testCases := []struct {
+		tmpl, name, key string
+		filters         []Filter
+	}{
+		{
+			"%%port_named_http|default:8080%%",
+			"port",
+			"named_http",
+			[]Filter{{Name: []byte("default"), Arg: []byte("8080")}},
+		},
+		{
+			"%%env_FOO | default: some value | lower%%",
+			"env",
+			"FOO",
+			[]Filter{{Name: []byte("default"), Arg: []byte("some value")}, {Name: []byte("lower")}},
+		},
+		{
+			"%%label_app.kubernetes.io/name|upper%%",
+			"label",
+			"app.kubernetes.io/name",
+			[]Filter{{Name: []byte("upper")}},
+		},
+		{
+			"%%host|default:%%",
+			"host",
+			"",
+			[]Filter{{Name: []byte("default")}},
+		},
+	}
+
+	for i, testCase := range testCases {
+		t.Run(fmt.Sprintf("#%d", i), func(t *testing.T) {
+			vars := ParseString(testCase.tmpl)
+			assert.Len(t, vars, 1)
+			assert.Equal(t, testCase.tmpl, string(vars[0].Raw))
+			assert.Equal(t, testCase.name, string(vars[0].Name))
+			assert.Equal(t, testCase.key, string(vars[0].Key))
+			assert.Equal(t, testCase.filters, vars[0].Filters)
 		})
 	}
 }
@@ -17,4 +17,7 @@ import (
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/kubelet"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/kubemetadata"
 	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/podman"
+	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/process"
+	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/snapshot"
+	_ "github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/systemd"
 )
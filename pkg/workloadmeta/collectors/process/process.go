@@ -0,0 +1,194 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package process
+
+import (
+	"context"
+	"os/user"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	dderrors "github.com/DataDog/datadog-agent/pkg/errors"
+	procconfig "github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID   = "process"
+	componentName = "workloadmeta-process"
+)
+
+// for testing purpose
+var (
+	newProbe = func() procutil.Probe {
+		return procutil.NewProcessProbe(procutil.WithPermission(true))
+	}
+	lookupUsername = func(uid int) string {
+		u, err := user.LookupId(strconv.Itoa(uid))
+		if err != nil {
+			return ""
+		}
+		return u.Username
+	}
+)
+
+type collector struct {
+	store                workloadmeta.Store
+	probe                procutil.Probe
+	scrubber             *procconfig.DataScrubber
+	procPath             string
+	interval             time.Duration
+	includeContainerized bool
+	lastPull             time.Time
+
+	// processes are the processes sent to the store on the previous pull, by PID
+	processes map[int32]*workloadmeta.Process
+	usernames map[int]string
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{}
+	})
+}
+
+func (c *collector) Start(_ context.Context, store workloadmeta.Store) error {
+	if !config.Datadog.GetBool("workloadmeta.process_collection.enabled") {
+		return dderrors.NewDisabled(componentName, "process collection is disabled")
+	}
+
+	c.store = store
+	c.probe = newProbe()
+	c.scrubber = procconfig.NewDefaultDataScrubber()
+	c.procPath = util.HostProc()
+	c.interval = config.Datadog.GetDuration("workloadmeta.process_collection.interval") * time.Second
+	c.includeContainerized = config.Datadog.GetBool("workloadmeta.process_collection.include_containerized")
+	c.processes = make(map[int32]*workloadmeta.Process)
+	c.usernames = make(map[int]string)
+
+	return nil
+}
+
+// Pull lists the processes of the host, it sends an event for the processes
+// that started, changed or exited since the previous pull
+func (c *collector) Pull(_ context.Context) error {
+	now := time.Now()
+	if now.Sub(c.lastPull) < c.interval {
+		return nil
+	}
+	c.lastPull = now
+
+	// the creation time is read from /proc/<pid>/stat even without the stats
+	procs, err := c.probe.ProcessesByPID(now, false)
+	if err != nil {
+		return err
+	}
+
+	sockets := &listeningSockets{procPath: c.procPath}
+
+	var events []workloadmeta.CollectorEvent
+	processes := make(map[int32]*workloadmeta.Process, len(procs))
+	for pid, p := range procs {
+		// kernel threads have no command line
+		if len(p.Cmdline) == 0 {
+			continue
+		}
+
+		process := c.buildProcess(p, c.processes[pid], sockets)
+		if process == nil {
+			continue
+		}
+		processes[pid] = process
+
+		if prev, found := c.processes[pid]; found && reflect.DeepEqual(prev, process) {
+			continue
+		}
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceProcess,
+			Entity: process,
+		})
+	}
+
+	for pid, prev := range c.processes {
+		if _, found := processes[pid]; !found {
+			events = append(events, workloadmeta.CollectorEvent{
+				Type:   workloadmeta.EventTypeUnset,
+				Source: workloadmeta.SourceProcess,
+				Entity: prev.EntityID,
+			})
+		}
+	}
+	c.processes = processes
+
+	c.store.Notify(events)
+
+	return nil
+}
+
+// buildProcess returns the entity of a process, nil if it's running in a
+// container and the containerized processes aren't collected. The listening
+// ports are only read for the processes that weren't seen on the previous pull.
+func (c *collector) buildProcess(p *procutil.Process, prev *workloadmeta.Process, sockets *listeningSockets) *workloadmeta.Process {
+	cgroups, _ := readCgroups(c.procPath, p.Pid)
+	containerID := containerIDFromCgroups(cgroups)
+	if containerID != "" && !c.includeContainerized {
+		return nil
+	}
+
+	cmdline, _ := c.scrubber.ScrubCommand(p.Cmdline)
+
+	process := &workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindProcess,
+			ID:   strconv.Itoa(int(p.Pid)),
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: p.Name,
+		},
+		PID:         int(p.Pid),
+		PPID:        int(p.Ppid),
+		Cmdline:     cmdline,
+		Exe:         p.Exe,
+		ContainerID: containerID,
+		SystemdUnit: systemdUnitFromCgroups(cgroups),
+	}
+
+	if len(p.Uids) > 0 {
+		process.UID = int(p.Uids[0])
+		process.User = c.username(process.UID)
+	}
+
+	// the creation time is in milliseconds since the epoch
+	if p.Stats != nil && p.Stats.CreateTime > 0 {
+		process.StartedAt = time.Unix(0, p.Stats.CreateTime*int64(time.Millisecond))
+	}
+
+	// a PID with the same creation time is the same process
+	if prev != nil && !process.StartedAt.IsZero() && prev.StartedAt.Equal(process.StartedAt) {
+		process.ListeningPorts = prev.ListeningPorts
+	} else {
+		process.ListeningPorts = processListeningPorts(c.procPath, p.Pid, sockets.get())
+	}
+
+	return process
+}
+
+// username returns the cached name of a user
+func (c *collector) username(uid int) string {
+	name, found := c.usernames[uid]
+	if !found {
+		name = lookupUsername(uid)
+		c.usernames[uid] = name
+	}
+	return name
+}
@@ -0,0 +1,237 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package process
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	procconfig "github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/datadog-agent/pkg/process/procutil"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const containerID = "3e8b9e8d1c1ae0d6e6a5c1f45d4d5d23e6d6c6b5d1f0f4e8a9b4c7d6e5f4a3b2"
+
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Store
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+type fakeProbe struct {
+	procutil.Probe
+	processes map[int32]*procutil.Process
+}
+
+func (p *fakeProbe) ProcessesByPID(_ time.Time, collectStats bool) (map[int32]*procutil.Process, error) {
+	if collectStats {
+		return nil, errors.New("the stats of the processes aren't needed")
+	}
+	return p.processes, nil
+}
+
+func writeProcFile(t *testing.T, procPath string, name string, content string) {
+	path := filepath.Join(procPath, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func writeSocketFd(t *testing.T, procPath string, pid int, fd int, inode int) {
+	dir := filepath.Join(procPath, strconv.Itoa(pid), "fd")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.Symlink("socket:["+strconv.Itoa(inode)+"]", filepath.Join(dir, strconv.Itoa(fd))))
+}
+
+func newTestProcFS(t *testing.T) string {
+	procPath := t.TempDir()
+
+	// port 8080 (0x1F90) listening, port 443 (0x01BB) established, udp port 53 (0x0035) bound
+	writeProcFile(t, procPath, "1/net/tcp", `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000   998        0 1001 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:01BB 0100007F:9C40 01 00000000:00000000 00:00000000 00000000   998        0 1002 1 0000000000000000 100 0 0 10 0
+`)
+	writeProcFile(t, procPath, "1/net/tcp6", `  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000000000000:1F90 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000   998        0 1003 1 0000000000000000 100 0 0 10 0
+`)
+	writeProcFile(t, procPath, "1/net/udp", `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+   0: 00000000:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 1004 2 0000000000000000 0
+`)
+
+	writeProcFile(t, procPath, "100/cgroup", "0::/system.slice/nginx.service\n")
+	writeSocketFd(t, procPath, 100, 3, 1001)
+	writeSocketFd(t, procPath, 100, 4, 1002)
+	writeSocketFd(t, procPath, 100, 5, 1003)
+	writeSocketFd(t, procPath, 100, 6, 1004)
+
+	writeProcFile(t, procPath, "200/cgroup", "0::/system.slice/docker-"+containerID+".scope\n")
+
+	return procPath
+}
+
+func newTestCollector(t *testing.T, processes map[int32]*procutil.Process, includeContainerized bool) (*collector, *fakeWorkloadmetaStore) {
+	store := &fakeWorkloadmetaStore{}
+	return &collector{
+		store:                store,
+		probe:                &fakeProbe{processes: processes},
+		scrubber:             procconfig.NewDefaultDataScrubber(),
+		procPath:             newTestProcFS(t),
+		interval:             30 * time.Second,
+		includeContainerized: includeContainerized,
+		processes:            make(map[int32]*workloadmeta.Process),
+		usernames:            map[int]string{33: "www-data"},
+	}, store
+}
+
+func TestPull(t *testing.T) {
+	startTime := time.Unix(1600000000, 0)
+
+	processes := map[int32]*procutil.Process{
+		2: {Pid: 2, Name: "kthreadd"},
+		100: {
+			Pid:     100,
+			Ppid:    1,
+			Name:    "nginx",
+			Exe:     "/usr/sbin/nginx",
+			Cmdline: []string{"/usr/sbin/nginx", "-g", "daemon off;"},
+			Uids:    []int32{33, 33, 33, 33},
+			Stats:   &procutil.Stats{CreateTime: startTime.UnixNano() / int64(time.Millisecond)},
+		},
+		200: {
+			Pid:     200,
+			Ppid:    150,
+			Name:    "redis-server",
+			Cmdline: []string{"redis-server", "--password", "secret"},
+		},
+	}
+
+	c, store := newTestCollector(t, processes, false)
+
+	require.NoError(t, c.Pull(context.TODO()))
+
+	expected := &workloadmeta.Process{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindProcess,
+			ID:   "100",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "nginx",
+		},
+		PID:         100,
+		PPID:        1,
+		Cmdline:     []string{"/usr/sbin/nginx", "-g", "daemon off;"},
+		Exe:         "/usr/sbin/nginx",
+		User:        "www-data",
+		UID:         33,
+		SystemdUnit: "nginx.service",
+		StartedAt:   startTime,
+		ListeningPorts: []workloadmeta.ContainerPort{
+			{Port: 53, Protocol: "UDP"},
+			{Port: 8080, Protocol: "TCP"},
+		},
+	}
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceProcess,
+			Entity: expected,
+		},
+	}, store.notifiedEvents)
+
+	// the pulls are rate limited
+	store.notifiedEvents = nil
+	delete(processes, 100)
+	require.NoError(t, c.Pull(context.TODO()))
+	assert.Empty(t, store.notifiedEvents)
+
+	c.lastPull = time.Time{}
+	require.NoError(t, c.Pull(context.TODO()))
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceProcess,
+			Entity: expected.EntityID,
+		},
+	}, store.notifiedEvents)
+}
+
+func TestPullContainerized(t *testing.T) {
+	processes := map[int32]*procutil.Process{
+		200: {
+			Pid:     200,
+			Ppid:    150,
+			Name:    "redis-server",
+			Cmdline: []string{"redis-server", "--password", "secret"},
+		},
+	}
+
+	c, store := newTestCollector(t, processes, true)
+
+	require.NoError(t, c.Pull(context.TODO()))
+	require.Len(t, store.notifiedEvents, 1)
+
+	process := store.notifiedEvents[0].Entity.(*workloadmeta.Process)
+	assert.Equal(t, containerID, process.ContainerID)
+	assert.Equal(t, []string{"redis-server", "--password", "********"}, process.Cmdline)
+
+	// unchanged processes aren't sent again
+	store.notifiedEvents = nil
+	c.lastPull = time.Time{}
+	require.NoError(t, c.Pull(context.TODO()))
+	assert.Empty(t, store.notifiedEvents)
+}
+
+func TestPullListeningPorts(t *testing.T) {
+	startTime := time.Unix(1600000000, 0)
+
+	processes := map[int32]*procutil.Process{
+		100: {
+			Pid:     100,
+			Name:    "nginx",
+			Cmdline: []string{"/usr/sbin/nginx"},
+			Stats:   &procutil.Stats{CreateTime: startTime.UnixNano() / int64(time.Millisecond)},
+		},
+	}
+
+	c, store := newTestCollector(t, processes, false)
+
+	require.NoError(t, c.Pull(context.TODO()))
+	require.Len(t, store.notifiedEvents, 1)
+	ports := []workloadmeta.ContainerPort{
+		{Port: 53, Protocol: "UDP"},
+		{Port: 8080, Protocol: "TCP"},
+	}
+	assert.Equal(t, ports, store.notifiedEvents[0].Entity.(*workloadmeta.Process).ListeningPorts)
+
+	// the sockets of a known process aren't read again
+	require.NoError(t, os.RemoveAll(filepath.Join(c.procPath, "100", "fd")))
+	store.notifiedEvents = nil
+	c.lastPull = time.Time{}
+	require.NoError(t, c.Pull(context.TODO()))
+	assert.Empty(t, store.notifiedEvents)
+
+	// a new process with the same PID is read again
+	processes[100].Stats = &procutil.Stats{CreateTime: startTime.Add(time.Minute).UnixNano() / int64(time.Millisecond)}
+	c.lastPull = time.Time{}
+	require.NoError(t, c.Pull(context.TODO()))
+	require.Len(t, store.notifiedEvents, 1)
+	process := store.notifiedEvents[0].Entity.(*workloadmeta.Process)
+	assert.Equal(t, startTime.Add(time.Minute), process.StartedAt)
+	assert.Empty(t, process.ListeningPorts)
+}
@@ -0,0 +1,184 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build linux
+
+package process
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/cgroups"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	// tcpListen is the state of the listening TCP sockets in /proc/net/tcp
+	tcpListen = "0A"
+	// udpUnconnected is the state of the bound but unconnected UDP sockets in /proc/net/udp
+	udpUnconnected = "07"
+)
+
+// readCgroups returns the cgroup paths of a process, read from /proc/<pid>/cgroup
+func readCgroups(procPath string, pid int32) ([]string, error) {
+	f, err := os.Open(filepath.Join(procPath, strconv.Itoa(int(pid)), "cgroup"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(scanner.Text(), ":", 3)
+		if len(parts) == 3 {
+			paths = append(paths, parts[2])
+		}
+	}
+	return paths, scanner.Err()
+}
+
+// containerIDFromCgroups returns the ID of the container a process runs in, if any
+func containerIDFromCgroups(paths []string) string {
+	for _, p := range paths {
+		for dir := p; dir != "/" && dir != "." && dir != ""; dir = path.Dir(dir) {
+			if id, _ := cgroups.ContainerFilter(dir, path.Base(dir)); id != "" {
+				return id
+			}
+		}
+	}
+	return ""
+}
+
+// systemdUnitFromCgroups returns the systemd service a process belongs to, if any
+func systemdUnitFromCgroups(paths []string) string {
+	for _, p := range paths {
+		for dir := p; dir != "/" && dir != "." && dir != ""; dir = path.Dir(dir) {
+			if name := path.Base(dir); strings.HasSuffix(name, ".service") && !strings.HasPrefix(name, "user@") {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// listeningSockets reads the listening sockets of the host once, when they are first needed
+type listeningSockets struct {
+	procPath string
+	sockets  map[uint64]workloadmeta.ContainerPort
+}
+
+func (l *listeningSockets) get() map[uint64]workloadmeta.ContainerPort {
+	if l.sockets == nil {
+		l.sockets = readListeningSockets(l.procPath)
+	}
+	return l.sockets
+}
+
+// readListeningSockets returns the listening TCP and UDP sockets of the host network
+// namespace, by inode
+func readListeningSockets(procPath string) map[uint64]workloadmeta.ContainerPort {
+	sockets := make(map[uint64]workloadmeta.ContainerPort)
+	for _, file := range []struct {
+		name     string
+		protocol string
+		state    string
+	}{
+		{name: "tcp", protocol: "TCP", state: tcpListen},
+		{name: "tcp6", protocol: "TCP", state: tcpListen},
+		{name: "udp", protocol: "UDP", state: udpUnconnected},
+		{name: "udp6", protocol: "UDP", state: udpUnconnected},
+	} {
+		// the network namespace of the init process is the one of the host
+		if err := readSockets(filepath.Join(procPath, "1", "net", file.name), file.protocol, file.state, sockets); err != nil {
+			log.Debugf("Unable to read the %s sockets: %s", file.name, err)
+		}
+	}
+	return sockets
+}
+
+// readSockets reads the sockets of a /proc/net file in a given state
+func readSockets(file, protocol, state string, sockets map[uint64]workloadmeta.ContainerPort) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != state {
+			continue
+		}
+
+		local := strings.Split(fields[1], ":")
+		if len(local) != 2 {
+			continue
+		}
+		port, err := strconv.ParseUint(local[1], 16, 16)
+		if err != nil || port == 0 {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+
+		sockets[inode] = workloadmeta.ContainerPort{Port: int(port), Protocol: protocol}
+	}
+	return scanner.Err()
+}
+
+// processListeningPorts returns the ports a process listens on, sorted
+func processListeningPorts(procPath string, pid int32, sockets map[uint64]workloadmeta.ContainerPort) []workloadmeta.ContainerPort {
+	if len(sockets) == 0 {
+		return nil
+	}
+
+	fdPath := filepath.Join(procPath, strconv.Itoa(int(pid)), "fd")
+	fds, err := os.ReadDir(fdPath)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[workloadmeta.ContainerPort]struct{})
+	var ports []workloadmeta.ContainerPort
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(fdPath, fd.Name()))
+		if err != nil || !strings.HasPrefix(target, "socket:[") {
+			continue
+		}
+		inode, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(target, "socket:["), "]"), 10, 64)
+		if err != nil {
+			continue
+		}
+		// a port can be bound for IPv4 and IPv6
+		if port, found := sockets[inode]; found {
+			if _, dup := seen[port]; !dup {
+				seen[port] = struct{}{}
+				ports = append(ports, port)
+			}
+		}
+	}
+
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Port != ports[j].Port {
+			return ports[i].Port < ports[j].Port
+		}
+		return ports[i].Protocol < ports[j].Protocol
+	})
+	return ports
+}
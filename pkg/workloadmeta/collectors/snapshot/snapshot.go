@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snapshot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	dderrors "github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta/collectors/util"
+)
+
+const (
+	collectorID   = "snapshot"
+	componentName = "workloadmeta-snapshot"
+)
+
+// collector rehydrates the store with the entities of the snapshot taken
+// before the agent restarted. The entities it sets are stale until a
+// collector confirms them, the ones that aren't confirmed are removed after
+// `workloadmeta.snapshot.expire`.
+type collector struct {
+	store  workloadmeta.Store
+	expire *util.Expire
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{}
+	})
+}
+
+func (c *collector) Start(_ context.Context, store workloadmeta.Store) error {
+	if !config.Datadog.GetBool("workloadmeta.snapshot.enabled") {
+		return dderrors.NewDisabled(componentName, "workloadmeta snapshots are disabled")
+	}
+
+	path := workloadmeta.SnapshotPath()
+	snapshot, err := workloadmeta.ReadSnapshot(path)
+	if err != nil {
+		return dderrors.NewDisabled(componentName, fmt.Sprintf("cannot read the snapshot %s: %s", path, err))
+	}
+
+	maxAge := config.Datadog.GetDuration("workloadmeta.snapshot.max_age") * time.Second
+	if age := time.Since(snapshot.Timestamp); age > maxAge {
+		return dderrors.NewDisabled(componentName, fmt.Sprintf("the snapshot %s is too old: %s", path, age))
+	}
+
+	c.store = store
+	c.expire = util.NewExpire(config.Datadog.GetDuration("workloadmeta.snapshot.expire") * time.Second)
+
+	events := c.rehydrate(snapshot.Entities)
+	log.Infof("Rehydrating the store with %d entities from the snapshot %s", len(events), path)
+	c.store.Notify(events)
+
+	return nil
+}
+
+// Pull removes the entities of the snapshot that weren't confirmed by a
+// collector in time, the store ignores the ones that were
+func (c *collector) Pull(_ context.Context) error {
+	expires := c.expire.ComputeExpires()
+	if len(expires) == 0 {
+		return nil
+	}
+
+	events := make([]workloadmeta.CollectorEvent, 0, len(expires))
+	for _, id := range expires {
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceSnapshot,
+			Entity: id,
+		})
+	}
+
+	c.store.Notify(events)
+
+	return nil
+}
+
+func (c *collector) rehydrate(entities workloadmeta.EntitiesDump) []workloadmeta.CollectorEvent {
+	now := time.Now()
+
+	var events []workloadmeta.CollectorEvent
+	add := func(entity workloadmeta.Entity) {
+		c.expire.Update(entity.GetID(), now)
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceSnapshot,
+			Entity: entity,
+		})
+	}
+
+	for _, container := range entities.Containers {
+		add(container)
+	}
+	for _, pod := range entities.KubernetesPods {
+		add(pod)
+	}
+	for _, task := range entities.ECSTasks {
+		add(task)
+	}
+
+	return events
+}
@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Store
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+func writeTestSnapshot(t *testing.T, path string, timestamp time.Time, entities workloadmeta.EntitiesDump) {
+	content, err := json.Marshal(workloadmeta.Snapshot{Timestamp: timestamp, Entities: entities})
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path, content, 0600))
+}
+
+func TestStartAndPull(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workloadmeta.json")
+
+	cfg := config.Mock()
+	cfg.Set("workloadmeta.snapshot.enabled", true)
+	cfg.Set("workloadmeta.snapshot.path", path)
+	cfg.Set("workloadmeta.snapshot.max_age", 3600)
+	cfg.Set("workloadmeta.snapshot.expire", 0)
+	defer cfg.Set("workloadmeta.snapshot.enabled", false)
+	defer cfg.Set("workloadmeta.snapshot.path", "")
+
+	container := &workloadmeta.Container{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "ctr-id"},
+		EnvVars:  map[string]string{"DD_ENV": "prod"},
+		Runtime:  workloadmeta.ContainerRuntimeDocker,
+	}
+	pod := &workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesPod, ID: "pod-id"},
+	}
+	writeTestSnapshot(t, path, time.Now(), workloadmeta.EntitiesDump{
+		Containers:     []*workloadmeta.Container{container},
+		KubernetesPods: []*workloadmeta.KubernetesPod{pod},
+	})
+
+	store := &fakeWorkloadmetaStore{}
+	c := &collector{}
+	require.NoError(t, c.Start(context.TODO(), store))
+
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{Type: workloadmeta.EventTypeSet, Source: workloadmeta.SourceSnapshot, Entity: container},
+		{Type: workloadmeta.EventTypeSet, Source: workloadmeta.SourceSnapshot, Entity: pod},
+	}, store.notifiedEvents)
+
+	// the entities are expired, the store ignores the ones confirmed by a collector
+	store.notifiedEvents = nil
+	require.NoError(t, c.Pull(context.TODO()))
+	assert.ElementsMatch(t, []workloadmeta.CollectorEvent{
+		{Type: workloadmeta.EventTypeUnset, Source: workloadmeta.SourceSnapshot, Entity: container.EntityID},
+		{Type: workloadmeta.EventTypeUnset, Source: workloadmeta.SourceSnapshot, Entity: pod.EntityID},
+	}, store.notifiedEvents)
+
+	store.notifiedEvents = nil
+	require.NoError(t, c.Pull(context.TODO()))
+	assert.Empty(t, store.notifiedEvents)
+}
+
+func TestStartOldSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "workloadmeta.json")
+
+	cfg := config.Mock()
+	cfg.Set("workloadmeta.snapshot.enabled", true)
+	cfg.Set("workloadmeta.snapshot.path", path)
+	cfg.Set("workloadmeta.snapshot.max_age", 3600)
+	defer cfg.Set("workloadmeta.snapshot.enabled", false)
+	defer cfg.Set("workloadmeta.snapshot.path", "")
+
+	c := &collector{}
+	assert.Error(t, c.Start(context.TODO(), &fakeWorkloadmetaStore{}))
+
+	writeTestSnapshot(t, path, time.Now().Add(-2*time.Hour), workloadmeta.EntitiesDump{})
+	assert.Error(t, c.Start(context.TODO(), &fakeWorkloadmetaStore{}))
+}
@@ -0,0 +1,182 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build systemd
+
+package systemd
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/coreos/go-systemd/dbus"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	dderrors "github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	systemdutil "github.com/DataDog/datadog-agent/pkg/util/systemd"
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+const (
+	collectorID   = "systemd"
+	componentName = "workloadmeta-systemd"
+
+	defaultPrivateSocket = "/run/systemd/private"
+	serviceSuffix        = ".service"
+)
+
+// systemdClient is the subset of the methods of a dbus.Conn used by the collector
+type systemdClient interface {
+	ListUnits() ([]dbus.UnitStatus, error)
+	GetUnitProperties(unit string) (map[string]interface{}, error)
+	GetUnitTypeProperties(unit string, unitType string) (map[string]interface{}, error)
+	Close()
+}
+
+// for testing purpose
+var connect = func(privateSocket string) (systemdClient, error) {
+	if privateSocket != "" {
+		return systemdutil.NewSystemdConnection(privateSocket)
+	}
+	if config.IsContainerized() {
+		return systemdutil.NewSystemdConnection("/host" + defaultPrivateSocket)
+	}
+
+	conn, err := dbus.NewSystemConnection()
+	if err != nil {
+		log.Debugf("Error getting new connection using system bus socket: %v", err)
+		return systemdutil.NewSystemdConnection(defaultPrivateSocket)
+	}
+	return conn, nil
+}
+
+type collector struct {
+	client        systemdClient
+	store         workloadmeta.Store
+	privateSocket string
+
+	// units are the units sent to the store on the previous pull, by name
+	units map[string]*workloadmeta.SystemdUnit
+}
+
+func init() {
+	workloadmeta.RegisterCollector(collectorID, func() workloadmeta.Collector {
+		return &collector{}
+	})
+}
+
+func (c *collector) Start(_ context.Context, store workloadmeta.Store) error {
+	if !config.Datadog.GetBool("workloadmeta.systemd_collection.enabled") {
+		return dderrors.NewDisabled(componentName, "systemd collection is disabled")
+	}
+
+	c.privateSocket = config.Datadog.GetString("workloadmeta.systemd_collection.private_socket")
+	client, err := connect(c.privateSocket)
+	if err != nil {
+		return err
+	}
+
+	c.client = client
+	c.store = store
+	c.units = make(map[string]*workloadmeta.SystemdUnit)
+
+	return nil
+}
+
+// Pull lists the services of systemd, it sends an event for the services that
+// were added, changed or removed since the previous pull
+func (c *collector) Pull(_ context.Context) error {
+	if c.client == nil {
+		client, err := connect(c.privateSocket)
+		if err != nil {
+			return err
+		}
+		c.client = client
+	}
+
+	statuses, err := c.client.ListUnits()
+	if err != nil {
+		// the connection is reestablished on the next pull, systemd may have been restarted
+		c.client.Close()
+		c.client = nil
+		return err
+	}
+
+	var events []workloadmeta.CollectorEvent
+	units := make(map[string]*workloadmeta.SystemdUnit, len(statuses))
+	for _, status := range statuses {
+		if !strings.HasSuffix(status.Name, serviceSuffix) {
+			continue
+		}
+
+		prev := c.units[status.Name]
+		unit := c.buildUnit(status, prev)
+		units[status.Name] = unit
+
+		if prev != nil && reflect.DeepEqual(prev, unit) {
+			continue
+		}
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceSystemd,
+			Entity: unit,
+		})
+	}
+
+	for name, prev := range c.units {
+		if _, found := units[name]; !found {
+			events = append(events, workloadmeta.CollectorEvent{
+				Type:   workloadmeta.EventTypeUnset,
+				Source: workloadmeta.SourceSystemd,
+				Entity: prev.EntityID,
+			})
+		}
+	}
+	c.units = units
+
+	c.store.Notify(events)
+
+	return nil
+}
+
+// buildUnit returns the entity of a unit, its properties are only queried
+// when its state changed since the previous pull
+func (c *collector) buildUnit(status dbus.UnitStatus, prev *workloadmeta.SystemdUnit) *workloadmeta.SystemdUnit {
+	unit := &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   status.Name,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: strings.TrimSuffix(status.Name, serviceSuffix),
+		},
+		Description: status.Description,
+		LoadState:   status.LoadState,
+		ActiveState: status.ActiveState,
+		SubState:    status.SubState,
+	}
+
+	if prev != nil && prev.LoadState == unit.LoadState && prev.ActiveState == unit.ActiveState && prev.SubState == unit.SubState {
+		unit.MainPID = prev.MainPID
+		unit.FragmentPath = prev.FragmentPath
+		return unit
+	}
+
+	if properties, err := c.client.GetUnitProperties(status.Name); err != nil {
+		log.Debugf("Unable to get the properties of unit %s: %v", status.Name, err)
+	} else if path, ok := properties["FragmentPath"].(string); ok {
+		unit.FragmentPath = path
+	}
+
+	if properties, err := c.client.GetUnitTypeProperties(status.Name, "Service"); err != nil {
+		log.Debugf("Unable to get the service properties of unit %s: %v", status.Name, err)
+	} else if pid, ok := properties["MainPID"].(uint32); ok {
+		unit.MainPID = int(pid)
+	}
+
+	return unit
+}
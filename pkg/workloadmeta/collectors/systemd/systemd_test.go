@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// +build systemd
+
+package systemd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/coreos/go-systemd/dbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/workloadmeta"
+)
+
+type fakeWorkloadmetaStore struct {
+	workloadmeta.Store
+	notifiedEvents []workloadmeta.CollectorEvent
+}
+
+func (store *fakeWorkloadmetaStore) Notify(events []workloadmeta.CollectorEvent) {
+	store.notifiedEvents = append(store.notifiedEvents, events...)
+}
+
+type fakeSystemdClient struct {
+	units         []dbus.UnitStatus
+	listErr       error
+	propertyCalls int
+	closed        bool
+}
+
+func (c *fakeSystemdClient) ListUnits() ([]dbus.UnitStatus, error) {
+	return c.units, c.listErr
+}
+
+func (c *fakeSystemdClient) GetUnitProperties(unit string) (map[string]interface{}, error) {
+	c.propertyCalls++
+	return map[string]interface{}{"FragmentPath": "/lib/systemd/system/" + unit}, nil
+}
+
+func (c *fakeSystemdClient) GetUnitTypeProperties(unit string, unitType string) (map[string]interface{}, error) {
+	return map[string]interface{}{"MainPID": uint32(1234)}, nil
+}
+
+func (c *fakeSystemdClient) Close() {
+	c.closed = true
+}
+
+func TestPull(t *testing.T) {
+	client := &fakeSystemdClient{
+		units: []dbus.UnitStatus{
+			{Name: "nginx.service", Description: "A high performance web server", LoadState: "loaded", ActiveState: "active", SubState: "running"},
+			{Name: "sshd.socket", LoadState: "loaded", ActiveState: "active", SubState: "listening"},
+		},
+	}
+	store := &fakeWorkloadmetaStore{}
+	c := &collector{
+		client: client,
+		store:  store,
+		units:  make(map[string]*workloadmeta.SystemdUnit),
+	}
+
+	require.NoError(t, c.Pull(context.TODO()))
+
+	expected := &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   "nginx.service",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "nginx",
+		},
+		Description:  "A high performance web server",
+		LoadState:    "loaded",
+		ActiveState:  "active",
+		SubState:     "running",
+		MainPID:      1234,
+		FragmentPath: "/lib/systemd/system/nginx.service",
+	}
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceSystemd,
+			Entity: expected,
+		},
+	}, store.notifiedEvents)
+
+	// unchanged units are neither queried nor sent again
+	store.notifiedEvents = nil
+	require.NoError(t, c.Pull(context.TODO()))
+	assert.Empty(t, store.notifiedEvents)
+	assert.Equal(t, 1, client.propertyCalls)
+
+	client.units = nil
+	require.NoError(t, c.Pull(context.TODO()))
+	assert.Equal(t, []workloadmeta.CollectorEvent{
+		{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceSystemd,
+			Entity: expected.EntityID,
+		},
+	}, store.notifiedEvents)
+}
+
+func TestPullReconnects(t *testing.T) {
+	client := &fakeSystemdClient{listErr: errors.New("connection closed")}
+	c := &collector{
+		client: client,
+		store:  &fakeWorkloadmetaStore{},
+		units:  make(map[string]*workloadmeta.SystemdUnit),
+	}
+
+	assert.Error(t, c.Pull(context.TODO()))
+	assert.True(t, client.closed)
+	assert.Nil(t, c.client)
+
+	newClient := &fakeSystemdClient{}
+	connect = func(string) (systemdClient, error) {
+		return newClient, nil
+	}
+	require.NoError(t, c.Pull(context.TODO()))
+	assert.Equal(t, newClient, c.client)
+}
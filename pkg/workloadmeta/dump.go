@@ -8,6 +8,7 @@ package workloadmeta
 import (
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/fatih/color"
 
@@ -24,6 +25,15 @@ type WorkloadEntity struct {
 	Infos map[string]string `json:"infos"`
 }
 
+// EntitiesDump holds the merged entities of the store. Unlike WorkloadDumpResponse
+// it can be loaded back, e.g. with NewOfflineStore. The environment variables of
+// the containers are left out as they can hold secrets.
+type EntitiesDump struct {
+	Containers     []*Container     `json:"containers,omitempty"`
+	KubernetesPods []*KubernetesPod `json:"kubernetes_pods,omitempty"`
+	ECSTasks       []*ECSTask       `json:"ecs_tasks,omitempty"`
+}
+
 // Write writes the stores content in a given writer.
 // Useful for agent's CLI and Flare.
 func (wdr WorkloadDumpResponse) Write(writer io.Writer) {
@@ -56,6 +66,10 @@ func (s *store) Dump(verbose bool) WorkloadDumpResponse {
 			info = e.String(verbose)
 		case *ECSTask:
 			info = e.String(verbose)
+		case *Process:
+			info = e.String(verbose)
+		case *SystemdUnit:
+			info = e.String(verbose)
 		default:
 			return "", fmt.Errorf("unsupported type %T", e)
 		}
@@ -96,3 +110,66 @@ func (s *store) Dump(verbose bool) WorkloadDumpResponse {
 
 	return workloadList
 }
+
+// DumpEntities returns the merged containers, pods and ECS tasks of the store,
+// sorted by ID.
+// Useful for agent's CLI.
+func (s *store) DumpEntities() EntitiesDump {
+	return s.dumpEntities(false)
+}
+
+// dumpEntities returns the merged containers, pods and ECS tasks of the store,
+// sorted by ID. A snapshot keeps the unified service tagging environment
+// variables of the containers, and leaves out the entities only known from the
+// previous snapshot.
+func (s *store) dumpEntities(snapshot bool) EntitiesDump {
+	var dump EntitiesDump
+
+	s.storeMut.RLock()
+	defer s.storeMut.RUnlock()
+
+	isStale := func(srcToEntity sourceToEntity) bool {
+		_, found := srcToEntity[SourceSnapshot]
+		return snapshot && found && len(srcToEntity) == 1
+	}
+
+	for _, srcToEntity := range s.store[KindContainer] {
+		if isStale(srcToEntity) {
+			continue
+		}
+
+		container := srcToEntity.merge(nil).(*Container)
+		if snapshot {
+			container.EnvVars = snapshotEnvVars(container.EnvVars)
+		} else {
+			container.EnvVars = nil
+		}
+		dump.Containers = append(dump.Containers, container)
+	}
+
+	for _, srcToEntity := range s.store[KindKubernetesPod] {
+		if isStale(srcToEntity) {
+			continue
+		}
+		dump.KubernetesPods = append(dump.KubernetesPods, srcToEntity.merge(nil).(*KubernetesPod))
+	}
+
+	for _, srcToEntity := range s.store[KindECSTask] {
+		if isStale(srcToEntity) {
+			continue
+		}
+		dump.ECSTasks = append(dump.ECSTasks, srcToEntity.merge(nil).(*ECSTask))
+	}
+
+	sort.Slice(dump.Containers, func(i, j int) bool {
+		return dump.Containers[i].ID < dump.Containers[j].ID
+	})
+	sort.Slice(dump.KubernetesPods, func(i, j int) bool {
+		return dump.KubernetesPods[i].ID < dump.KubernetesPods[j].ID
+	})
+	sort.Slice(dump.ECSTasks, func(i, j int) bool {
+		return dump.ECSTasks[i].ID < dump.ECSTasks[j].ID
+	})
+
+	return dump
+}
@@ -160,3 +160,63 @@ PID: 1
 
 	assert.EqualValues(t, expectedVerbose, verboseDump)
 }
+
+func TestDumpEntities(t *testing.T) {
+	s := newStore()
+
+	s.handleEvents([]CollectorEvent{
+		{
+			Type:   EventTypeSet,
+			Source: "source1",
+			Entity: &Container{
+				EntityID: EntityID{Kind: KindContainer, ID: "ctr-id"},
+				EnvVars:  map[string]string{"PASSWORD": "secret"},
+				Runtime:  ContainerRuntimeDocker,
+			},
+		},
+		{
+			Type:   EventTypeSet,
+			Source: "source2",
+			Entity: &Container{
+				EntityID:   EntityID{Kind: KindContainer, ID: "ctr-id"},
+				EntityMeta: EntityMeta{Labels: map[string]string{"foo": "bar"}},
+			},
+		},
+		{
+			Type:   EventTypeSet,
+			Source: SourceKubelet,
+			Entity: &KubernetesPod{
+				EntityID:   EntityID{Kind: KindKubernetesPod, ID: "pod-id"},
+				Containers: []OrchestratorContainer{{ID: "ctr-id", Name: "ctr-name"}},
+			},
+		},
+	})
+
+	expected := EntitiesDump{
+		Containers: []*Container{
+			{
+				EntityID:   EntityID{Kind: KindContainer, ID: "ctr-id"},
+				EntityMeta: EntityMeta{Labels: map[string]string{"foo": "bar"}},
+				Runtime:    ContainerRuntimeDocker,
+			},
+		},
+		KubernetesPods: []*KubernetesPod{
+			{
+				EntityID:   EntityID{Kind: KindKubernetesPod, ID: "pod-id"},
+				Containers: []OrchestratorContainer{{ID: "ctr-id", Name: "ctr-name"}},
+			},
+		},
+	}
+
+	dump := s.DumpEntities()
+	assert.Equal(t, expected, dump)
+
+	// the dump can be loaded back
+	offline := NewOfflineStore(dump)
+	container, err := offline.GetContainer("ctr-id")
+	assert.NoError(t, err)
+	assert.Equal(t, expected.Containers[0], container)
+	pod, err := offline.GetKubernetesPodForContainer("ctr-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "pod-id", pod.ID)
+}
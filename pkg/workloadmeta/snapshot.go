@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package workloadmeta
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// snapshotEnvVarKeys are the environment variables of the containers kept in
+// the snapshots, the other ones can hold secrets
+var snapshotEnvVarKeys = []string{"DD_ENV", "DD_SERVICE", "DD_VERSION"}
+
+// Snapshot is the content of the store saved on disk, to rehydrate the store
+// when the agent restarts.
+type Snapshot struct {
+	Timestamp time.Time    `json:"timestamp"`
+	Entities  EntitiesDump `json:"entities"`
+}
+
+// SnapshotPath returns the path of the snapshots of the store, set in
+// `workloadmeta.snapshot.path`. It defaults to a file named after the running
+// binary in the run path, as several agents can run a store on a host.
+func SnapshotPath() string {
+	if path := config.Datadog.GetString("workloadmeta.snapshot.path"); path != "" {
+		return path
+	}
+
+	name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	return filepath.Join(config.Datadog.GetString("run_path"), "workloadmeta-"+name+".json")
+}
+
+// ReadSnapshot reads a snapshot of the store.
+func ReadSnapshot(path string) (*Snapshot, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(content, &snapshot); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// runSnapshots saves a snapshot of the store periodically, and a last one when
+// ctx is done.
+func (s *store) runSnapshots(ctx context.Context, path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.writeSnapshot(path); err != nil {
+				log.Warnf("cannot write the workloadmeta snapshot to %s: %s", path, err)
+			}
+
+		case <-ctx.Done():
+			if err := s.writeSnapshot(path); err != nil {
+				log.Warnf("cannot write the workloadmeta snapshot to %s: %s", path, err)
+			}
+
+			return
+		}
+	}
+}
+
+// writeSnapshot saves a snapshot of the store to path. The file is replaced
+// atomically so that a crash never leaves a truncated snapshot.
+func (s *store) writeSnapshot(path string) error {
+	content, err := json.Marshal(Snapshot{
+		Timestamp: time.Now(),
+		Entities:  s.dumpEntities(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	return nil
+}
+
+// snapshotEnvVars returns the environment variables of a container kept in the
+// snapshots.
+func snapshotEnvVars(envVars map[string]string) map[string]string {
+	var kept map[string]string
+	for _, key := range snapshotEnvVarKeys {
+		if value, found := envVars[key]; found {
+			if kept == nil {
+				kept = make(map[string]string)
+			}
+			kept[key] = value
+		}
+	}
+	return kept
+}
@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package workloadmeta
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSnapshot(t *testing.T) {
+	s := newStore()
+
+	s.handleEvents([]CollectorEvent{
+		{
+			Type:   EventTypeSet,
+			Source: SourceDocker,
+			Entity: &Container{
+				EntityID: EntityID{Kind: KindContainer, ID: "ctr-id"},
+				EnvVars:  map[string]string{"PASSWORD": "secret", "DD_ENV": "prod"},
+				Runtime:  ContainerRuntimeDocker,
+			},
+		},
+		{
+			Type:   EventTypeSet,
+			Source: SourceECS,
+			Entity: &ECSTask{
+				EntityID: EntityID{Kind: KindECSTask, ID: "task-id"},
+				Family:   "datadog-agent",
+			},
+		},
+		{
+			// stale entities aren't saved again
+			Type:   EventTypeSet,
+			Source: SourceSnapshot,
+			Entity: &KubernetesPod{
+				EntityID: EntityID{Kind: KindKubernetesPod, ID: "pod-id"},
+			},
+		},
+	})
+
+	path := filepath.Join(t.TempDir(), "snapshots", "workloadmeta.json")
+	require.NoError(t, s.writeSnapshot(path))
+
+	snapshot, err := ReadSnapshot(path)
+	require.NoError(t, err)
+
+	assert.WithinDuration(t, time.Now(), snapshot.Timestamp, time.Minute)
+	assert.Equal(t, EntitiesDump{
+		Containers: []*Container{
+			{
+				EntityID: EntityID{Kind: KindContainer, ID: "ctr-id"},
+				EnvVars:  map[string]string{"DD_ENV": "prod"},
+				Runtime:  ContainerRuntimeDocker,
+			},
+		},
+		ECSTasks: []*ECSTask{
+			{
+				EntityID: EntityID{Kind: KindECSTask, ID: "task-id"},
+				Family:   "datadog-agent",
+			},
+		},
+	}, snapshot.Entities)
+
+	// the dump of the CLI still leaves all the environment variables out
+	assert.Nil(t, s.DumpEntities().Containers[0].EnvVars)
+}
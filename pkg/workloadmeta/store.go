@@ -8,9 +8,11 @@ package workloadmeta
 import (
 	"context"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/errors"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -108,6 +110,31 @@ func NewStore(catalog map[string]collectorFactory) Store {
 	}
 }
 
+// NewOfflineStore returns a store holding the entities of a dump, without any
+// collector. It's meant to work on the content of a store outside of a running
+// agent, e.g. to simulate autodiscovery.
+func NewOfflineStore(dump EntitiesDump) Store {
+	s := NewStore(nil).(*store)
+
+	events := make([]CollectorEvent, 0, len(dump.Containers)+len(dump.KubernetesPods)+len(dump.ECSTasks))
+	for _, container := range dump.Containers {
+		source := Source(container.Runtime)
+		if source == "" {
+			source = SourceDocker
+		}
+		events = append(events, CollectorEvent{Type: EventTypeSet, Source: source, Entity: container})
+	}
+	for _, pod := range dump.KubernetesPods {
+		events = append(events, CollectorEvent{Type: EventTypeSet, Source: SourceKubelet, Entity: pod})
+	}
+	for _, task := range dump.ECSTasks {
+		events = append(events, CollectorEvent{Type: EventTypeSet, Source: SourceECS, Entity: task})
+	}
+	s.handleEvents(events)
+
+	return s
+}
+
 // Start starts the workload metadata store.
 func (s *store) Start(ctx context.Context) {
 	go func() {
@@ -176,6 +203,11 @@ func (s *store) Start(ctx context.Context) {
 		}
 	}()
 
+	if config.Datadog.GetBool("workloadmeta.snapshot.enabled") {
+		interval := config.Datadog.GetDuration("workloadmeta.snapshot.interval") * time.Second
+		go s.runSnapshots(ctx, SnapshotPath(), interval)
+	}
+
 	s.startCandidates(ctx)
 
 	log.Info("workloadmeta store initialized successfully")
@@ -324,6 +356,41 @@ func (s *store) GetECSTask(id string) (*ECSTask, error) {
 	return entity.(*ECSTask), nil
 }
 
+// GetProcess returns metadata about a process.
+func (s *store) GetProcess(pid int) (*Process, error) {
+	entity, err := s.getEntityByKind(KindProcess, strconv.Itoa(pid))
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*Process), nil
+}
+
+// ListProcesses returns metadata about all known processes.
+func (s *store) ListProcesses() ([]*Process, error) {
+	entities, err := s.listEntitiesByKind(KindProcess)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make([]*Process, 0, len(entities))
+	for _, entity := range entities {
+		processes = append(processes, entity.(*Process))
+	}
+
+	return processes, nil
+}
+
+// GetSystemdUnit returns metadata about a systemd unit.
+func (s *store) GetSystemdUnit(name string) (*SystemdUnit, error) {
+	entity, err := s.getEntityByKind(KindSystemdUnit, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*SystemdUnit), nil
+}
+
 // Notify notifies the store with a slice of events.
 func (s *store) Notify(events []CollectorEvent) {
 	if len(events) > 0 {
@@ -382,6 +449,7 @@ func (s *store) pull(ctx context.Context) {
 func (s *store) handleEvents(evs []CollectorEvent) {
 	s.storeMut.Lock()
 
+	handled := make([]CollectorEvent, 0, len(evs))
 	for _, ev := range evs {
 		meta := ev.Entity.GetID()
 
@@ -395,6 +463,14 @@ func (s *store) handleEvents(evs []CollectorEvent) {
 
 		entityOfSource, ok := entitiesOfKind[meta.ID]
 
+		// the snapshot must neither override nor unset an entity
+		// confirmed by a collector
+		if ev.Source == SourceSnapshot {
+			if _, found := entityOfSource[SourceSnapshot]; !found && (ok || ev.Type == EventTypeUnset) {
+				continue
+			}
+		}
+
 		switch ev.Type {
 		case EventTypeSet:
 			if !ok {
@@ -407,6 +483,13 @@ func (s *store) handleEvents(evs []CollectorEvent) {
 			}
 
 			entityOfSource[ev.Source] = ev.Entity
+
+			// the entity is confirmed by a collector, its copy
+			// from the snapshot is dropped
+			if _, found := entityOfSource[SourceSnapshot]; found && ev.Source != SourceSnapshot {
+				telemetry.StoredEntities.Dec(string(meta.Kind), string(SourceSnapshot))
+				delete(entityOfSource, SourceSnapshot)
+			}
 		case EventTypeUnset:
 			if ok {
 				if _, found := entityOfSource[ev.Source]; found {
@@ -422,7 +505,10 @@ func (s *store) handleEvents(evs []CollectorEvent) {
 		default:
 			log.Errorf("cannot handle event of type %d. event dump: %+v", ev)
 		}
+
+		handled = append(handled, ev)
 	}
+	evs = handled
 
 	// unlock the store before notifying subscribers, as they might need to
 	// read it for related entities (such as a pod's containers) while they
@@ -377,6 +377,86 @@ func TestSubscribe(t *testing.T) {
 				},
 			},
 		},
+		{
+			// entities rehydrated from the snapshot are replaced
+			// by the ones of the collectors, and the snapshot
+			// cannot unset or override a confirmed entity
+			name: "replaces snapshot entities with confirmed ones",
+			preEvents: []CollectorEvent{
+				{
+					Type:   EventTypeSet,
+					Source: SourceSnapshot,
+					Entity: fooContainerToMerge,
+				},
+				{
+					Type:   EventTypeSet,
+					Source: SourceSnapshot,
+					Entity: barContainer,
+				},
+			},
+			postEvents: [][]CollectorEvent{
+				{
+					{
+						Type:   EventTypeSet,
+						Source: fooSource,
+						Entity: fooContainer,
+					},
+				},
+				{
+					{
+						Type:   EventTypeUnset,
+						Source: SourceSnapshot,
+						Entity: fooContainer.GetID(),
+					},
+					{
+						Type:   EventTypeUnset,
+						Source: SourceSnapshot,
+						Entity: barContainer.GetID(),
+					},
+				},
+				{
+					{
+						Type:   EventTypeSet,
+						Source: SourceSnapshot,
+						Entity: fooContainerToMerge,
+					},
+				},
+			},
+			expected: []EventBundle{
+				{
+					Events: []Event{
+						{
+							Type:    EventTypeSet,
+							Sources: []Source{SourceSnapshot},
+							Entity:  barContainer,
+						},
+						{
+							Type:    EventTypeSet,
+							Sources: []Source{SourceSnapshot},
+							Entity:  fooContainerToMerge,
+						},
+					},
+				},
+				{
+					Events: []Event{
+						{
+							Type:    EventTypeSet,
+							Sources: []Source{fooSource},
+							Entity:  fooContainer,
+						},
+					},
+				},
+				{
+					Events: []Event{
+						{
+							Type:    EventTypeUnset,
+							Sources: []Source{SourceSnapshot},
+							Entity:  barContainer.GetID(),
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
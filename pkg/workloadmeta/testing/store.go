@@ -2,6 +2,7 @@ package testing
 
 import (
 	"context"
+	"strconv"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/pkg/errors"
@@ -89,6 +90,41 @@ func (s *Store) GetECSTask(id string) (*workloadmeta.ECSTask, error) {
 	return entity.(*workloadmeta.ECSTask), nil
 }
 
+// GetProcess returns metadata about a process.
+func (s *Store) GetProcess(pid int) (*workloadmeta.Process, error) {
+	entity, err := s.getEntityByKind(workloadmeta.KindProcess, strconv.Itoa(pid))
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*workloadmeta.Process), nil
+}
+
+// ListProcesses returns metadata about all known processes.
+func (s *Store) ListProcesses() ([]*workloadmeta.Process, error) {
+	entities, err := s.listEntitiesByKind(workloadmeta.KindProcess)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := make([]*workloadmeta.Process, 0, len(entities))
+	for _, entity := range entities {
+		processes = append(processes, entity.(*workloadmeta.Process))
+	}
+
+	return processes, nil
+}
+
+// GetSystemdUnit returns metadata about a systemd unit.
+func (s *Store) GetSystemdUnit(name string) (*workloadmeta.SystemdUnit, error) {
+	entity, err := s.getEntityByKind(workloadmeta.KindSystemdUnit, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*workloadmeta.SystemdUnit), nil
+}
+
 // Set sets an entity in the store.
 func (s *Store) Set(entity workloadmeta.Entity) {
 	s.mu.Lock()
@@ -141,6 +177,11 @@ func (s *Store) Dump(verbose bool) workloadmeta.WorkloadDumpResponse {
 	panic("not implemented")
 }
 
+// DumpEntities is not implemented in the testing store.
+func (s *Store) DumpEntities() workloadmeta.EntitiesDump {
+	panic("not implemented")
+}
+
 func (s *Store) getEntityByKind(kind workloadmeta.Kind, id string) (workloadmeta.Entity, error) {
 	entitiesOfKind, ok := s.store[kind]
 	if !ok {
@@ -30,8 +30,12 @@ type Store interface {
 	GetKubernetesPod(id string) (*KubernetesPod, error)
 	GetKubernetesPodForContainer(containerID string) (*KubernetesPod, error)
 	GetECSTask(id string) (*ECSTask, error)
+	GetProcess(pid int) (*Process, error)
+	ListProcesses() ([]*Process, error)
+	GetSystemdUnit(name string) (*SystemdUnit, error)
 	Notify(events []CollectorEvent)
 	Dump(verbose bool) WorkloadDumpResponse
+	DumpEntities() EntitiesDump
 }
 
 // Kind is the kind of an entity.
@@ -42,6 +46,8 @@ const (
 	KindContainer     Kind = "container"
 	KindKubernetesPod Kind = "kubernetes_pod"
 	KindECSTask       Kind = "ecs_task"
+	KindProcess       Kind = "process"
+	KindSystemdUnit   Kind = "systemd_unit"
 )
 
 // Source is the source name of an entity.
@@ -56,6 +62,13 @@ const (
 	SourceKubelet      Source = "kubelet"
 	SourceKubeMetadata Source = "kube_metadata"
 	SourcePodman       Source = "podman"
+	SourceProcess      Source = "process"
+	SourceSystemd      Source = "systemd"
+
+	// SourceSnapshot holds the entities loaded from the snapshot of the
+	// store taken before the agent restarted. An entity only held by this
+	// source is stale: it's not confirmed by a collector yet.
+	SourceSnapshot Source = "snapshot"
 )
 
 // ContainerRuntime is the container runtime used by a container.
@@ -485,6 +498,139 @@ func (t ECSTask) String(verbose bool) string {
 
 var _ Entity = &ECSTask{}
 
+// Process is a process running on the host, outside of a container unless
+// the collection of the containerized processes is enabled. Its ID is its PID.
+type Process struct {
+	EntityID
+	EntityMeta
+	PID            int
+	PPID           int
+	Cmdline        []string
+	Exe            string
+	User           string
+	UID            int
+	ContainerID    string
+	SystemdUnit    string
+	StartedAt      time.Time
+	ListeningPorts []ContainerPort
+}
+
+// GetID returns the Process's EntityID.
+func (p Process) GetID() EntityID {
+	return p.EntityID
+}
+
+// Merge merges a Process with another. Returns an error if trying to merge
+// with another kind.
+func (p *Process) Merge(e Entity) error {
+	pp, ok := e.(*Process)
+	if !ok {
+		return fmt.Errorf("cannot merge Process with different kind %T", e)
+	}
+
+	return mergo.Merge(p, pp)
+}
+
+// DeepCopy returns a deep copy of the process.
+func (p Process) DeepCopy() Entity {
+	cp := deepcopy.Copy(p).(Process)
+	return &cp
+}
+
+// String returns a string representation of Process.
+func (p Process) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, p.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, p.EntityMeta.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Process Info -----------")
+	_, _ = fmt.Fprintln(&sb, "PID:", p.PID)
+	_, _ = fmt.Fprintln(&sb, "Command Line:", strings.Join(p.Cmdline, " "))
+	_, _ = fmt.Fprintln(&sb, "User:", p.User)
+	_, _ = fmt.Fprintln(&sb, "Container ID:", p.ContainerID)
+	_, _ = fmt.Fprintln(&sb, "Systemd Unit:", p.SystemdUnit)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "PPID:", p.PPID)
+		_, _ = fmt.Fprintln(&sb, "Executable:", p.Exe)
+		_, _ = fmt.Fprintln(&sb, "UID:", p.UID)
+		_, _ = fmt.Fprintln(&sb, "Started At:", p.StartedAt)
+	}
+
+	if len(p.ListeningPorts) > 0 && verbose {
+		_, _ = fmt.Fprintln(&sb, "----------- Listening Ports -----------")
+		for _, port := range p.ListeningPorts {
+			_, _ = fmt.Fprint(&sb, port.String(verbose))
+		}
+	}
+
+	return sb.String()
+}
+
+var _ Entity = &Process{}
+
+// SystemdUnit is a systemd unit of the host. Its ID is the name of the unit.
+type SystemdUnit struct {
+	EntityID
+	EntityMeta
+	Description  string
+	LoadState    string
+	ActiveState  string
+	SubState     string
+	MainPID      int
+	FragmentPath string
+}
+
+// GetID returns the SystemdUnit's EntityID.
+func (u SystemdUnit) GetID() EntityID {
+	return u.EntityID
+}
+
+// Merge merges a SystemdUnit with another. Returns an error if trying to merge
+// with another kind.
+func (u *SystemdUnit) Merge(e Entity) error {
+	uu, ok := e.(*SystemdUnit)
+	if !ok {
+		return fmt.Errorf("cannot merge SystemdUnit with different kind %T", e)
+	}
+
+	return mergo.Merge(u, uu)
+}
+
+// DeepCopy returns a deep copy of the unit.
+func (u SystemdUnit) DeepCopy() Entity {
+	cp := deepcopy.Copy(u).(SystemdUnit)
+	return &cp
+}
+
+// String returns a string representation of SystemdUnit.
+func (u SystemdUnit) String(verbose bool) string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprint(&sb, u.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, u.EntityMeta.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Unit Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Active State:", u.ActiveState)
+	_, _ = fmt.Fprintln(&sb, "Sub State:", u.SubState)
+	_, _ = fmt.Fprintln(&sb, "Main PID:", u.MainPID)
+
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "Description:", u.Description)
+		_, _ = fmt.Fprintln(&sb, "Load State:", u.LoadState)
+		_, _ = fmt.Fprintln(&sb, "Fragment Path:", u.FragmentPath)
+	}
+
+	return sb.String()
+}
+
+var _ Entity = &SystemdUnit{}
+
 // CollectorEvent is an event generated by a metadata collector, to be handled
 // by the metadata store.
 type CollectorEvent struct {
@@ -55,6 +55,7 @@ AGENT_CORECHECKS = [
     "memory",
     "ntp",
     "oom_kill",
+    "process_network",
     "systemd",
     "tcp_queue_length",
     "uptime",